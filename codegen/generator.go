@@ -0,0 +1,240 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package codegen generates Go types from the schemas of an OpenAPI 3 document, for the schemas of its components
+// and the inline schemas of the request bodies and responses of its operations.
+//
+// Objects are generated as structs with json tags, optional properties are pointers (or nil-able slices and maps)
+// that are omitted when empty. Enums are typed constants, allOf embeds the structs it references and merges any
+// other schemas, and oneOf (or anyOf) with a discriminator is a sum type with a field for each of its variants, that
+// decodes the variant chosen by the discriminator. Objects without properties, or with additionalProperties, are
+// maps, and date-time strings are time.Time.
+//
+// Output is sorted and formatted, so the same document always generates the same code, and can be written by a
+// program run with go generate.
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// componentSchemaPrefix is the prefix of references to the schemas of components.
+const componentSchemaPrefix = "#/components/schemas/"
+
+// Generator generates Go types from the schemas of documents.
+type Generator struct {
+	packageName string
+	inline      bool
+}
+
+// NewGenerator creates a new Generator, generating code for a package.
+func NewGenerator(packageName string) *Generator {
+	return &Generator{packageName: packageName, inline: true}
+}
+
+// SetInlineSchemas sets if types are generated for the inline schemas of request bodies and responses, the default
+// is true. Types of inline schemas are named after their operation, such as CreateBurgerRequest and
+// CreateBurgerResponse201.
+func (g *Generator) SetInlineSchemas(inline bool) {
+	g.inline = inline
+}
+
+// Generate generates the (formatted) source of a Go file, declaring the types of the schemas of a document.
+func (g *Generator) Generate(document *v3.Document) ([]byte, error) {
+	f := newFile()
+	var names []string
+	var schemas map[string]*base.SchemaProxy
+	if document.Components != nil {
+		schemas = document.Components.Schemas
+	}
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// name every component first, so references can be resolved in any order.
+	for _, name := range names {
+		f.components[name] = f.unique(goName(name), "Schema")
+		f.schemas[name] = schemas[name]
+	}
+	for _, name := range names {
+		doc := fmt.Sprintf("%s is generated from the schema '%s%s'.", f.components[name], componentSchemaPrefix, name)
+		if err := f.declare(f.components[name], schemas[name], doc); err != nil {
+			return nil, err
+		}
+	}
+	if g.inline && document.Paths != nil {
+		if err := f.declareOperations(document.Paths); err != nil {
+			return nil, err
+		}
+	}
+	return f.source(g.packageName)
+}
+
+// declareOperations declares the types of the inline schemas of the request bodies and responses of every operation.
+func (f *file) declareOperations(paths *v3.Paths) error {
+	var templates []string
+	for template := range paths.PathItems {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	for _, template := range templates {
+		for _, op := range operations(paths.PathItems[template]) {
+			name := goName(op.operation.OperationId)
+			if name == "" {
+				name = goName(strings.ToLower(op.method) + " " + template)
+			}
+			if body := op.operation.RequestBody; body != nil {
+				if err := f.declareInline(name+"Request", body.Content, op.method, template, "request body"); err != nil {
+					return err
+				}
+			}
+			if op.operation.Responses == nil {
+				continue
+			}
+			var codes []string
+			for code := range op.operation.Responses.Codes {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			for _, code := range codes {
+				if err := f.declareInline(name+"Response"+camel(code), op.operation.Responses.Codes[code].Content,
+					op.method, template, fmt.Sprintf("%s response", code)); err != nil {
+					return err
+				}
+			}
+			if response := op.operation.Responses.Default; response != nil {
+				if err := f.declareInline(name+"ResponseDefault", response.Content, op.method, template,
+					"default response"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// declareInline declares the type of the schema of the preferred media type of some content, if it is inline.
+func (f *file) declareInline(name string, content map[string]*v3.MediaType, method, template, what string) error {
+	mediaType := preferredMediaType(content)
+	if mediaType == nil || mediaType.Schema == nil || f.componentName(mediaType.Schema) != "" {
+		return nil
+	}
+	name = f.unique(name, "Body")
+	return f.declare(name, mediaType.Schema, fmt.Sprintf("%s is generated from the schema of the %s of %s %s.",
+		name, what, method, template))
+}
+
+// preferredMediaType returns the JSON media type of some content, or the first media type in order if there is no
+// JSON media type.
+func preferredMediaType(content map[string]*v3.MediaType) *v3.MediaType {
+	if mediaType := content["application/json"]; mediaType != nil {
+		return mediaType
+	}
+	var names []string
+	for name := range content {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.Contains(name, "json") {
+			return content[name]
+		}
+	}
+	if len(names) > 0 {
+		return content[names[0]]
+	}
+	return nil
+}
+
+type methodOperation struct {
+	method    string
+	operation *v3.Operation
+}
+
+// operations returns the operations of a path item, in a fixed order.
+func operations(pathItem *v3.PathItem) []methodOperation {
+	var ops []methodOperation
+	for _, op := range []methodOperation{
+		{http.MethodGet, pathItem.Get},
+		{http.MethodPut, pathItem.Put},
+		{http.MethodPost, pathItem.Post},
+		{http.MethodDelete, pathItem.Delete},
+		{http.MethodOptions, pathItem.Options},
+		{http.MethodHead, pathItem.Head},
+		{http.MethodPatch, pathItem.Patch},
+		{http.MethodTrace, pathItem.Trace},
+	} {
+		if op.operation != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// file collects the declarations of a generated file.
+type file struct {
+	decls      []string
+	imports    map[string]bool
+	used       map[string]bool
+	components map[string]string // the Go types of the schemas of components.
+	schemas    map[string]*base.SchemaProxy
+}
+
+func newFile() *file {
+	return &file{
+		imports:    make(map[string]bool),
+		used:       make(map[string]bool),
+		components: make(map[string]string),
+		schemas:    make(map[string]*base.SchemaProxy),
+	}
+}
+
+// unique returns a name that is not used yet, and marks it used. Names that are used, or empty, have a suffix added
+// (and a number, if the name with the suffix is used as well).
+func (f *file) unique(name, suffix string) string {
+	if name == "" {
+		name = suffix
+	}
+	candidate := name
+	if f.used[candidate] {
+		candidate = name + suffix
+	}
+	for i := 2; f.used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%s%d", name, suffix, i)
+	}
+	f.used[candidate] = true
+	return candidate
+}
+
+// source returns the formatted source of the file.
+func (f *file) source(packageName string) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("// Code generated by libopenapi. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	if len(f.imports) > 0 {
+		var imports []string
+		for imp := range f.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		b.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(&b, "\t%q\n", imp)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(strings.Join(f.decls, "\n"))
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code: %w", err)
+	}
+	return source, nil
+}
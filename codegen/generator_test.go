@@ -0,0 +1,335 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var codegenSpec = `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      operationId: createBurger
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "201":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+        default:
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  message:
+                    type: string
+components:
+  schemas:
+    Burger:
+      type: object
+      description: A tasty burger.
+      required: [id, name, createdAt]
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+        createdAt:
+          type: string
+          format: date-time
+        updatedAt:
+          type: string
+          format: date-time
+        rating:
+          type: [number, "null"]
+        status:
+          type: string
+          enum: [fresh, cold, "half-eaten"]
+        toppings:
+          type: array
+          items:
+            type: object
+            properties:
+              name:
+                type: string
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        parent:
+          $ref: '#/components/schemas/Burger'
+        legacy:
+          type: string
+          deprecated: true
+    Size:
+      type: integer
+      enum: [1, 2, -3]
+    Entity:
+      type: object
+      required: [uuid]
+      properties:
+        uuid:
+          type: string
+    Meal:
+      allOf:
+        - $ref: '#/components/schemas/Entity'
+        - type: object
+          required: [burger]
+          properties:
+            burger:
+              $ref: '#/components/schemas/Burger'
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: petType
+        mapping:
+          kitty: '#/components/schemas/Cat'
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+        meow:
+          type: boolean
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+        bark:
+          type: boolean
+    Extra:
+      type: object
+      properties:
+        known:
+          type: string
+      additionalProperties:
+        type: integer
+    Burgers:
+      $ref: '#/components/schemas/Burger'
+    BurgerList:
+      type: array
+      items:
+        $ref: '#/components/schemas/Burger'`
+
+// checkSource type checks generated source.
+func checkSource(t *testing.T, source []byte) {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "models.go", source, parser.ParseComments)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conf := types.Config{Importer: importer.Default()}
+	_, err = conf.Check("models", fset, []*ast.File{parsed}, nil)
+	assert.NoError(t, err)
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(codegenSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	source, err := NewGenerator("models").Generate(&model.Model)
+	assert.NoError(t, err)
+	checkSource(t, source)
+	code := string(source)
+
+	assert.Contains(t, code, "// Code generated by libopenapi. DO NOT EDIT.\n\npackage models\n")
+	assert.Contains(t, code, "import (\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"time\"\n)")
+
+	// required and optional properties, formats, enums and nested types.
+	assert.Contains(t, code, `// Burger is generated from the schema '#/components/schemas/Burger'.
+//
+// A tasty burger.
+type Burger struct {
+	CreatedAt time.Time         `+"`json:\"createdAt\"`"+`
+	ID        int64             `+"`json:\"id\"`"+`
+	Labels    map[string]string `+"`json:\"labels,omitempty\"`"+`
+	// Deprecated: the property is deprecated.
+	Legacy    *string              `+"`json:\"legacy,omitempty\"`"+`
+	Name      string               `+"`json:\"name\"`"+`
+	Parent    *Burger              `+"`json:\"parent,omitempty\"`"+`
+	Rating    *float64             `+"`json:\"rating,omitempty\"`"+`
+	Status    *BurgerStatus        `+"`json:\"status,omitempty\"`"+`
+	Toppings  []BurgerToppingsItem `+"`json:\"toppings,omitempty\"`"+`
+	UpdatedAt *time.Time           `+"`json:\"updatedAt,omitempty\"`"+`
+}`)
+	assert.Contains(t, code, `const (
+	BurgerStatusFresh     BurgerStatus = "fresh"
+	BurgerStatusCold      BurgerStatus = "cold"
+	BurgerStatusHalfEaten BurgerStatus = "half-eaten"
+)`)
+	assert.Contains(t, code, "type BurgerToppingsItem struct {")
+	assert.Contains(t, code, "type Size int\n")
+	assert.Contains(t, code, "SizeMinus3 Size = -3")
+
+	// references, arrays and allOf.
+	assert.Contains(t, code, "type Burgers = Burger\n")
+	assert.Contains(t, code, "type BurgerList []Burger\n")
+	assert.Contains(t, code, "type Meal struct {\n\tEntity\n\tBurger Burger `json:\"burger\"`\n}")
+
+	// sum types and additional properties.
+	assert.Contains(t, code, "\t// Cat is set when petType is 'kitty'.\n\tCat *Cat\n")
+	assert.Contains(t, code, "\tcase \"kitty\":\n\t\tv.Cat = new(Cat)\n")
+	assert.Contains(t, code, "\tcase \"Dog\":\n\t\tv.Dog = new(Dog)\n")
+	assert.Contains(t, code, "AdditionalProperties map[string]int `json:\"-\"`")
+	assert.Contains(t, code, "for _, key := range []string{\"known\"} {")
+
+	// inline schemas of operations, in order after the components.
+	assert.Contains(t, code, "// CreateBurgerRequest is generated from the schema of the request body of POST /burgers.")
+	assert.Contains(t, code, "type CreateBurgerResponseDefault struct {")
+	assert.NotContains(t, code, "CreateBurgerResponse201")
+
+	// output is stable.
+	again, err := NewGenerator("models").Generate(&model.Model)
+	assert.NoError(t, err)
+	assert.Equal(t, code, string(again))
+}
+
+func TestGenerator_SetInlineSchemas(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(codegenSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	generator := NewGenerator("models")
+	generator.SetInlineSchemas(false)
+	source, err := generator.Generate(&model.Model)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(source), "CreateBurger")
+}
+
+func TestGenerator_Generate_Names(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers/{id}:
+    get:
+      responses:
+        "2XX":
+          content:
+            application/problem+json:
+              schema:
+                type: array
+                items:
+                  type: string
+            text/plain:
+              schema:
+                type: string
+components:
+  schemas:
+    pet:
+      type: string
+    Pet:
+      type: object
+      properties:
+        user_id:
+          type: string
+        userId:
+          type: integer
+        tags:
+          type: array
+          items:
+            type: string
+            enum: [a, b]
+        nullable:
+          type: string
+          nullable: true
+        anything: {}
+        kind:
+          type: [string, integer]
+      required: [nullable, anything]`
+
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	source, err := NewGenerator("models").Generate(&model.Model)
+	assert.NoError(t, err)
+	checkSource(t, source)
+	code := string(source)
+	assert.Contains(t, code, "type Pet struct {\n"+
+		"\tAnything any           `json:\"anything\"`\n"+
+		"\tKind     any           `json:\"kind,omitempty\"`\n"+
+		"\tNullable *string       `json:\"nullable\"`\n"+
+		"\tTags     []PetTagsItem `json:\"tags,omitempty\"`\n"+
+		"\tUserID   *int          `json:\"userId,omitempty\"`\n"+
+		"\tUserID2  *string       `json:\"user_id,omitempty\"`\n}")
+	assert.Contains(t, code, "type PetSchema string\n")
+	assert.Contains(t, code, "PetTagsItemA PetTagsItem = \"a\"")
+	assert.Contains(t, code, "// GetBurgersIDResponse2XX is generated from the schema of the 2XX response of "+
+		"GET /burgers/{id}.\ntype GetBurgersIDResponse2XX []string\n")
+}
+
+func TestGenerator_Generate_Empty(t *testing.T) {
+	source, err := NewGenerator("models").Generate(&v3.Document{})
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by libopenapi. DO NOT EDIT.\n\npackage models\n", string(source))
+}
+
+func TestGenerator_Generate_Recursive(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    One:
+      type: object
+      required: [two, list]
+      properties:
+        two:
+          $ref: '#/components/schemas/Two'
+        list:
+          type: array
+          items:
+            $ref: '#/components/schemas/One'
+    Two:
+      type: object
+      required: [one, three]
+      properties:
+        one:
+          $ref: '#/components/schemas/One'
+        three:
+          $ref: '#/components/schemas/Three'
+    Three:
+      type: object
+      properties:
+        name:
+          type: string`
+
+	// the required properties are an infinite circular reference, which is reported, but the model is built.
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	assert.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	assert.NotEmpty(t, errs)
+
+	source, err := NewGenerator("models").Generate(&model.Model)
+	assert.NoError(t, err)
+	checkSource(t, source)
+	code := string(source)
+	assert.Contains(t, code, "\tTwo  *Two  `json:\"two\"`")
+	assert.Contains(t, code, "\tList []One `json:\"list\"`")
+	assert.Contains(t, code, "\tOne   *One  `json:\"one\"`")
+	assert.Contains(t, code, "\tThree Three `json:\"three\"`")
+}
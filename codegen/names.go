@@ -0,0 +1,78 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package codegen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// initialisms are words that are written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "eof": true, "guid": true, "html": true,
+	"http": true, "https": true, "id": true, "ip": true, "json": true, "jwt": true, "os": true, "sql": true,
+	"ssh": true, "tcp": true, "tls": true, "ttl": true, "udp": true, "ui": true, "uid": true, "uri": true,
+	"url": true, "utf8": true, "uuid": true, "xml": true,
+}
+
+// goName converts a name from a document (a schema, property, operation or enum value) into an exported Go
+// identifier. Words are split on characters that are not letters or digits, and on changes from lower to upper case,
+// then capitalized, with initialisms in upper case. Names that start with a digit are prefixed with 'N', and a name
+// without any letters or digits is empty.
+func goName(name string) string {
+	ident := camel(name)
+	if ident != "" && unicode.IsDigit([]rune(ident)[0]) {
+		ident = "N" + ident
+	}
+	return ident
+}
+
+// camel joins the capitalized words of a name.
+func camel(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	return b.String()
+}
+
+// words splits a name into its words.
+func words(name string) []string {
+	var result []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+	}
+	for i, r := range []rune(name) {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && len(current) > 0 && !unicode.IsUpper(current[len(current)-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return result
+}
+
+// comment formats text as a Go comment, one comment line for every line of text.
+func comment(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+strings.TrimRight(line, " \t\r"), " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"burger":        "Burger",
+		"petType":       "PetType",
+		"pet_type":      "PetType",
+		"user-id":       "UserID",
+		"userId":        "UserID",
+		"HTTPServer":    "HTTPServer",
+		"api url":       "APIURL",
+		"2xl":           "N2xl",
+		"get /burgers":  "GetBurgers",
+		"$ref":          "Ref",
+		"---":           "",
+		"café-au-lait":  "CaféAuLait",
+		"x.y.z":         "XYZ",
+		"already_Fine1": "AlreadyFine1",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, goName(name), name)
+	}
+	assert.Equal(t, "2XX", camel("2XX"))
+}
+
+func TestComment(t *testing.T) {
+	assert.Equal(t, "// one\n//\n// two\n", comment("one\n\ntwo  \n"))
+	assert.Equal(t, "\t// one\n\t// two\n", indent(comment("one\ntwo")))
+}
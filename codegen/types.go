@@ -0,0 +1,633 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// declare declares a named type for a schema. The declaration is reserved before it is generated, so it comes before
+// the declarations of any inline schemas it contains.
+func (f *file) declare(name string, proxy *base.SchemaProxy, doc string) error {
+	slot := len(f.decls)
+	f.decls = append(f.decls, "")
+	var b strings.Builder
+	b.WriteString(comment(doc))
+
+	// references to other components are aliases, so they keep the methods of the type.
+	if target := f.componentName(proxy); target != "" && f.components[target] != name {
+		fmt.Fprintf(&b, "type %s = %s\n", name, f.components[target])
+		f.decls[slot] = b.String()
+		return nil
+	}
+	schema, err := build(proxy)
+	if err != nil {
+		return fmt.Errorf("unable to generate type '%s': %w", name, err)
+	}
+	if schema.Description != "" {
+		b.WriteString("//\n")
+		b.WriteString(comment(schema.Description))
+	}
+	if isDeprecated(schema) {
+		b.WriteString("//\n// Deprecated: the schema is deprecated.\n")
+	}
+	switch {
+	case len(schema.Enum) > 0:
+		err = f.enumDecl(&b, name, schema)
+	case isUnion(schema):
+		err = f.unionDecl(&b, name, schema)
+	case isStruct(schema):
+		err = f.structDecl(&b, name, schema)
+	case len(schema.AllOf) == 1 && f.componentName(schema.AllOf[0]) != "":
+		fmt.Fprintf(&b, "type %s = %s\n", name, f.components[f.componentName(schema.AllOf[0])])
+	default:
+		var t string
+		if t, err = f.underlying(schema, name); err == nil {
+			fmt.Fprintf(&b, "type %s %s\n", name, t)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	f.decls[slot] = b.String()
+	return nil
+}
+
+// goType returns the Go type of a schema. References to components use the type of the component, and inline
+// schemas that need a declaration (enums, sum types and structs) are declared with a name derived from a hint.
+func (f *file) goType(proxy *base.SchemaProxy, hint string) (string, error) {
+	if proxy == nil {
+		return "any", nil
+	}
+	if target := f.componentName(proxy); target != "" {
+		return f.components[target], nil
+	}
+	schema, err := build(proxy)
+	if err != nil {
+		return "", err
+	}
+	if len(schema.Enum) > 0 || isUnion(schema) || isStruct(schema) {
+		name := f.unique(hint, "Type")
+		return name, f.declare(name, proxy, fmt.Sprintf("%s is generated from an inline schema.", name))
+	}
+	return f.underlying(schema, hint)
+}
+
+// underlying returns the Go type of a schema that does not need a declaration.
+func (f *file) underlying(schema *base.Schema, hint string) (string, error) {
+	if len(schema.AllOf) == 1 && len(schema.Properties) == 0 {
+		return f.goType(schema.AllOf[0], hint)
+	}
+	var t string
+	switch types := nonNullTypes(schema); {
+	case len(types) > 1:
+		return "any", nil
+	case len(types) == 1:
+		t = types[0]
+	case schema.Items != nil:
+		t = "array"
+	case len(schema.Properties) > 0 || schema.AdditionalProperties != nil:
+		t = "object"
+	default:
+		return "any", nil
+	}
+	switch t {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			f.imports["time"] = true
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		switch schema.Format {
+		case "int32", "int64":
+			return schema.Format, nil
+		}
+		return "int", nil
+	case "number":
+		if schema.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if schema.Items == nil || !schema.Items.IsA() {
+			return "[]any", nil
+		}
+		item, err := f.goType(schema.Items.A, hint+"Item")
+		return "[]" + item, err
+	case "object":
+		value, err := f.additionalType(schema, hint)
+		return "map[string]" + value, err
+	}
+	return "any", nil
+}
+
+// additionalType returns the Go type of the additional properties of an object.
+func (f *file) additionalType(schema *base.Schema, hint string) (string, error) {
+	if schema.AdditionalProperties == nil || !schema.AdditionalProperties.IsA() {
+		return "any", nil
+	}
+	return f.goType(schema.AdditionalProperties.A, hint+"Value")
+}
+
+// structFields are the properties of a struct, collected from a schema and the schemas it merges with allOf.
+type structFields struct {
+	embeds     []string // the names of the components of embedded structs.
+	properties map[string]*base.SchemaProxy
+	required   map[string]bool
+}
+
+// collect collects the properties of a schema, embedding the components of allOf that are structs and merging the
+// properties of every other allOf schema.
+func (f *file) collect(schema *base.Schema, fields *structFields) error {
+	for _, member := range schema.AllOf {
+		if target := f.componentName(member); target != "" && f.isEmbeddable(target) {
+			fields.embeds = append(fields.embeds, target)
+			continue
+		}
+		memberSchema, err := build(member)
+		if err != nil {
+			return err
+		}
+		if err = f.collect(memberSchema, fields); err != nil {
+			return err
+		}
+	}
+	for name, property := range schema.Properties {
+		fields.properties[name] = property
+	}
+	for _, name := range schema.Required {
+		fields.required[name] = true
+	}
+	return nil
+}
+
+// propertyNames returns the name of every property of a struct, including the properties of embedded structs.
+func (f *file) propertyNames(schema *base.Schema) ([]string, error) {
+	fields := &structFields{properties: make(map[string]*base.SchemaProxy), required: make(map[string]bool)}
+	if err := f.collect(schema, fields); err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range fields.properties {
+		names = append(names, name)
+	}
+	for _, target := range fields.embeds {
+		embedded, err := build(f.schemas[target])
+		if err != nil {
+			return nil, err
+		}
+		embeddedNames, err := f.propertyNames(embedded)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, embeddedNames...)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isRecursive returns true if a property references a component that contains a struct by value (in a required
+// field, or embedded), directly or through other components, so the property must be a pointer.
+func (f *file) isRecursive(proxy *base.SchemaProxy, structName string) bool {
+	target := f.componentName(proxy)
+	return target != "" && f.containsStruct(target, structName, make(map[string]bool))
+}
+
+func (f *file) containsStruct(component, structName string, seen map[string]bool) bool {
+	if f.components[component] == structName {
+		return true
+	}
+	if seen[component] {
+		return false
+	}
+	seen[component] = true
+	if target := f.componentName(f.schemas[component]); target != "" {
+		return f.containsStruct(target, structName, seen)
+	}
+	schema, err := build(f.schemas[component])
+	if err != nil || !isStruct(schema) {
+		return false
+	}
+	fields := &structFields{properties: make(map[string]*base.SchemaProxy), required: make(map[string]bool)}
+	if f.collect(schema, fields) != nil {
+		return false
+	}
+	for _, target := range fields.embeds {
+		if f.containsStruct(target, structName, seen) {
+			return true
+		}
+	}
+	for property, proxy := range fields.properties {
+		target := f.componentName(proxy)
+		if !fields.required[property] || target == "" {
+			continue
+		}
+		if propertySchema, err := build(proxy); err == nil && !isNullable(propertySchema) &&
+			f.containsStruct(target, structName, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmbeddable returns true if the type of a component is a struct without custom JSON methods, so embedding it
+// adds its fields to the JSON of a struct.
+func (f *file) isEmbeddable(component string) bool {
+	schema, err := build(f.schemas[component])
+	return err == nil && isStruct(schema) && !hasAdditionalProperties(schema)
+}
+
+func (f *file) structDecl(b *strings.Builder, name string, schema *base.Schema) error {
+	fields := &structFields{properties: make(map[string]*base.SchemaProxy), required: make(map[string]bool)}
+	if err := f.collect(schema, fields); err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, target := range fields.embeds {
+		fmt.Fprintf(b, "\t%s\n", f.components[target])
+		used[f.components[target]] = true
+	}
+	additional := hasAdditionalProperties(schema)
+	if additional {
+		used["AdditionalProperties"] = true
+	}
+
+	var names []string
+	for property := range fields.properties {
+		names = append(names, property)
+	}
+	sort.Strings(names)
+	for _, property := range names {
+		field := goName(property)
+		if field == "" {
+			field = "Field"
+		}
+		for i, prefix := 2, field; used[field]; i++ {
+			field = fmt.Sprintf("%s%d", prefix, i)
+		}
+		used[field] = true
+
+		proxy := fields.properties[property]
+		t, err := f.goType(proxy, name+field)
+		if err != nil {
+			return err
+		}
+		propertySchema, err := build(proxy)
+		if err != nil {
+			return err
+		}
+		required := fields.required[property]
+		if (!required || isNullable(propertySchema) || f.isRecursive(proxy, name)) && isPointable(t) {
+			t = "*" + t
+		}
+		tag := property
+		if !required {
+			tag += ",omitempty"
+		}
+		// the description of a component is documented by its type.
+		description := propertySchema.Description
+		if f.componentName(proxy) != "" {
+			description = ""
+		}
+		if description != "" {
+			b.WriteString(indent(comment(description)))
+		}
+		if isDeprecated(propertySchema) {
+			if description != "" {
+				b.WriteString("\t//\n")
+			}
+			b.WriteString("\t// Deprecated: the property is deprecated.\n")
+		}
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`\n", field, t, tag)
+	}
+
+	if !additional {
+		b.WriteString("}\n")
+		return nil
+	}
+	value, err := f.additionalType(schema, name)
+	if err != nil {
+		return err
+	}
+	known, err := f.propertyNames(schema)
+	if err != nil {
+		return err
+	}
+	quoted := make([]string, len(known))
+	for i := range known {
+		quoted[i] = strconv.Quote(known[i])
+	}
+	f.imports["encoding/json"] = true
+	b.WriteString("\n\t// AdditionalProperties are the properties that are not defined by the schema.\n")
+	fmt.Fprintf(b, "\tAdditionalProperties map[string]%s `json:\"-\"`\n}\n", value)
+	fmt.Fprintf(b, `
+// MarshalJSON encodes %[1]s, with its additional properties.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(v))
+	if err != nil || len(v.AdditionalProperties) == 0 {
+		return data, err
+	}
+	object := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for key, value := range v.AdditionalProperties {
+		if _, ok := object[key]; ok {
+			continue
+		}
+		if object[key], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(object)
+}
+
+// UnmarshalJSON decodes %[1]s, collecting the properties that are not defined into its additional properties.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	type plain %[1]s
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+	object := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	for _, key := range []string{%[3]s} {
+		delete(object, key)
+	}
+	v.AdditionalProperties = nil
+	if len(object) == 0 {
+		return nil
+	}
+	v.AdditionalProperties = make(map[string]%[2]s, len(object))
+	for key, raw := range object {
+		var value %[2]s
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		v.AdditionalProperties[key] = value
+	}
+	return nil
+}
+`, name, value, strings.Join(quoted, ", "))
+	return nil
+}
+
+// unionVariant is a variant of a sum type, and the values of the discriminator that choose it.
+type unionVariant struct {
+	field  string
+	goType string
+	values []string
+}
+
+func (f *file) unionDecl(b *strings.Builder, name string, schema *base.Schema) error {
+	proxies := schema.OneOf
+	if len(proxies) == 0 {
+		proxies = schema.AnyOf
+	}
+	property := schema.Discriminator.PropertyName
+	var mapped []string
+	for value := range schema.Discriminator.Mapping {
+		mapped = append(mapped, value)
+	}
+	sort.Strings(mapped)
+
+	chosen := make(map[string]bool)
+	var variants []*unionVariant
+	for i, proxy := range proxies {
+		target := f.componentName(proxy)
+		t, err := f.goType(proxy, fmt.Sprintf("%sVariant%d", name, i+1))
+		if err != nil {
+			return err
+		}
+		variant := &unionVariant{field: t, goType: t}
+		if !isIdentifier(t) {
+			variant.field = fmt.Sprintf("Variant%d", i+1)
+		}
+		for _, value := range mapped {
+			ref := schema.Discriminator.Mapping[value]
+			if target != "" && (ref == target || ref == componentSchemaPrefix+target) && !chosen[value] {
+				variant.values = append(variant.values, value)
+				chosen[value] = true
+			}
+		}
+		if target != "" && len(variant.values) == 0 && !chosen[target] {
+			variant.values = []string{target}
+			chosen[target] = true
+		}
+		variants = append(variants, variant)
+	}
+
+	f.imports["encoding/json"] = true
+	f.imports["fmt"] = true
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, variant := range variants {
+		if len(variant.values) > 0 {
+			fmt.Fprintf(b, "\t// %s is set when %s is %s.\n", variant.field, property, orList(variant.values))
+		}
+		fmt.Fprintf(b, "\t%s *%s\n", variant.field, variant.goType)
+	}
+	fmt.Fprintf(b, "}\n\n// MarshalJSON encodes the variant of %[1]s that is set.\n"+
+		"func (v %[1]s) MarshalJSON() ([]byte, error) {\n\tswitch {\n", name)
+	for _, variant := range variants {
+		fmt.Fprintf(b, "\tcase v.%[1]s != nil:\n\t\treturn json.Marshal(v.%[1]s)\n", variant.field)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn []byte(\"null\"), nil\n}\n\n"+
+		"// UnmarshalJSON decodes the variant of %[1]s chosen by its '%[2]s' property.\n"+
+		"func (v *%[1]s) UnmarshalJSON(data []byte) error {\n"+
+		"\tvar discriminator struct {\n\t\tValue string `json:%[3]q`\n\t}\n"+
+		"\tif err := json.Unmarshal(data, &discriminator); err != nil {\n\t\treturn err\n\t}\n"+
+		"\t*v = %[1]s{}\n\tswitch discriminator.Value {\n", name, property, property)
+	for _, variant := range variants {
+		if len(variant.values) == 0 {
+			continue
+		}
+		quoted := make([]string, len(variant.values))
+		for i := range variant.values {
+			quoted[i] = strconv.Quote(variant.values[i])
+		}
+		fmt.Fprintf(b, "\tcase %[1]s:\n\t\tv.%[2]s = new(%[3]s)\n\t\treturn json.Unmarshal(data, v.%[2]s)\n",
+			strings.Join(quoted, ", "), variant.field, variant.goType)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn fmt.Errorf(\"unknown %s '%%s' of %s\", discriminator.Value)\n}\n", property, name)
+	return nil
+}
+
+func (f *file) enumDecl(b *strings.Builder, name string, schema *base.Schema) error {
+	t, err := f.underlying(schema, name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b, "type %s %s\n", name, t)
+	var constants []string
+	for _, value := range schema.Enum {
+		literal, ok := enumLiteral(value, t)
+		if !ok {
+			continue
+		}
+		constant := f.unique(name+enumName(value), "Value")
+		constants = append(constants, fmt.Sprintf("\t%s %s = %s\n", constant, name, literal))
+	}
+	if len(constants) > 0 {
+		fmt.Fprintf(b, "\n// The values of %s.\nconst (\n%s)\n", name, strings.Join(constants, ""))
+	}
+	return nil
+}
+
+// enumLiteral returns the Go literal of a value of an enum, if the value has the type of the enum.
+func enumLiteral(value any, goType string) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		if goType == "string" {
+			return strconv.Quote(v), true
+		}
+	case int, int64, int32:
+		switch goType {
+		case "int", "int32", "int64", "float32", "float64":
+			return fmt.Sprint(v), true
+		}
+	case float64:
+		switch goType {
+		case "float32", "float64":
+			return strconv.FormatFloat(v, 'g', -1, 64), true
+		case "int", "int32", "int64":
+			if v == float64(int64(v)) {
+				return strconv.FormatInt(int64(v), 10), true
+			}
+		}
+	}
+	return "", false
+}
+
+// enumName returns the suffix of the name of the constant of a value of an enum.
+func enumName(value any) string {
+	s := fmt.Sprint(value)
+	if _, ok := value.(string); !ok {
+		s = strings.NewReplacer("-", "Minus", ".", "Point", "+", "").Replace(s)
+	}
+	if name := camel(s); name != "" {
+		return name
+	}
+	return "Empty"
+}
+
+// componentName returns the name of the component a schema references, or an empty string if it is not a reference
+// to a component.
+func (f *file) componentName(proxy *base.SchemaProxy) string {
+	if proxy == nil || !proxy.IsReference() {
+		return ""
+	}
+	name, ok := strings.CutPrefix(proxy.GetReference(), componentSchemaPrefix)
+	if !ok || f.components[name] == "" {
+		return ""
+	}
+	return name
+}
+
+func build(proxy *base.SchemaProxy) (*base.Schema, error) {
+	schema, err := proxy.BuildSchema()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build schema: %w", err)
+	}
+	if schema == nil {
+		return &base.Schema{}, nil
+	}
+	return schema, nil
+}
+
+// isStruct returns true if a schema is generated as a struct.
+func isStruct(schema *base.Schema) bool {
+	if len(schema.Enum) > 0 || isUnion(schema) {
+		return false
+	}
+	if len(schema.AllOf) > 1 || (len(schema.AllOf) == 1 && len(schema.Properties) > 0) {
+		return true
+	}
+	if len(schema.Properties) == 0 {
+		return false
+	}
+	types := nonNullTypes(schema)
+	return len(types) == 0 || (len(types) == 1 && types[0] == "object")
+}
+
+// isUnion returns true if a schema is generated as a sum type.
+func isUnion(schema *base.Schema) bool {
+	return schema.Discriminator != nil && schema.Discriminator.PropertyName != "" &&
+		len(schema.OneOf)+len(schema.AnyOf) > 0
+}
+
+func hasAdditionalProperties(schema *base.Schema) bool {
+	return schema.AdditionalProperties != nil && (schema.AdditionalProperties.IsA() || schema.AdditionalProperties.B)
+}
+
+func isNullable(schema *base.Schema) bool {
+	if schema.Nullable != nil && *schema.Nullable {
+		return true
+	}
+	for _, t := range schema.Type {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func isDeprecated(schema *base.Schema) bool {
+	return schema.Deprecated != nil && *schema.Deprecated
+}
+
+// nonNullTypes returns the types of a schema, without null.
+func nonNullTypes(schema *base.Schema) []string {
+	var types []string
+	for _, t := range schema.Type {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// isPointable returns true if an optional value of a type is a pointer, slices, maps and any are already nil-able.
+func isPointable(goType string) bool {
+	return !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map[") && goType != "any"
+}
+
+// isIdentifier returns true if a type is an exported identifier, that can be the name of a field.
+func isIdentifier(goType string) bool {
+	for i, r := range goType {
+		if i == 0 && !unicode.IsUpper(r) {
+			return false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return goType != ""
+}
+
+func indent(text string) string {
+	return "\t" + strings.ReplaceAll(strings.TrimSuffix(text, "\n"), "\n", "\n\t") + "\n"
+}
+
+// orList joins values as a list of alternatives.
+func orList(values []string) string {
+	if len(values) == 1 {
+		return "'" + values[0] + "'"
+	}
+	return "'" + strings.Join(values[:len(values)-1], "', '") + "' or '" + values[len(values)-1] + "'"
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package codegen
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumLiteral(t *testing.T) {
+	tests := []struct {
+		value   any
+		goType  string
+		literal string
+		ok      bool
+	}{
+		{"fresh", "string", `"fresh"`, true},
+		{"fresh", "int", "", false},
+		{1, "int", "1", true},
+		{int64(-3), "int64", "-3", true},
+		{2, "float64", "2", true},
+		{1.5, "float64", "1.5", true},
+		{2.0, "int", "2", true},
+		{1.5, "int", "", false},
+		{true, "bool", "", false},
+		{nil, "string", "", false},
+	}
+	for _, tc := range tests {
+		literal, ok := enumLiteral(tc.value, tc.goType)
+		assert.Equal(t, tc.ok, ok, tc.value)
+		assert.Equal(t, tc.literal, literal, tc.value)
+	}
+}
+
+func TestEnumName(t *testing.T) {
+	assert.Equal(t, "HalfEaten", enumName("half-eaten"))
+	assert.Equal(t, "2xl", enumName("2xl"))
+	assert.Equal(t, "Minus3", enumName(-3))
+	assert.Equal(t, "1Point5", enumName(1.5))
+	assert.Equal(t, "Empty", enumName(""))
+}
+
+func TestIsStruct(t *testing.T) {
+	object := base.CreateSchemaProxy(&base.Schema{Type: []string{"object"}})
+	assert.True(t, isStruct(&base.Schema{Properties: map[string]*base.SchemaProxy{"a": object}}))
+	assert.True(t, isStruct(&base.Schema{Type: []string{"object", "null"},
+		Properties: map[string]*base.SchemaProxy{"a": object}}))
+	assert.True(t, isStruct(&base.Schema{AllOf: []*base.SchemaProxy{object, object}}))
+	assert.False(t, isStruct(&base.Schema{AllOf: []*base.SchemaProxy{object}}))
+	assert.False(t, isStruct(&base.Schema{Type: []string{"object"}}))
+	assert.False(t, isStruct(&base.Schema{Type: []string{"string"}, Enum: []any{"a"}}))
+	assert.False(t, isStruct(&base.Schema{Properties: map[string]*base.SchemaProxy{"a": object},
+		OneOf: []*base.SchemaProxy{object}, Discriminator: &base.Discriminator{PropertyName: "a"}}))
+}
+
+func TestIsPointable(t *testing.T) {
+	assert.True(t, isPointable("string"))
+	assert.True(t, isPointable("time.Time"))
+	assert.False(t, isPointable("[]string"))
+	assert.False(t, isPointable("map[string]any"))
+	assert.False(t, isPointable("any"))
+}
+
+func TestIsIdentifier(t *testing.T) {
+	assert.True(t, isIdentifier("Burger"))
+	assert.False(t, isIdentifier("string"))
+	assert.False(t, isIdentifier("[]Burger"))
+	assert.False(t, isIdentifier("time.Time"))
+	assert.False(t, isIdentifier(""))
+}
+
+func TestOrList(t *testing.T) {
+	assert.Equal(t, "'a'", orList([]string{"a"}))
+	assert.Equal(t, "'a', 'b' or 'c'", orList([]string{"a", "b", "c"}))
+}
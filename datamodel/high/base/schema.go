@@ -483,9 +483,8 @@ func (s *Schema) MarshalYAML() (interface{}, error) {
 	nb := high.NewNodeBuilder(s, s.low)
 
 	// determine index version
-	idx := s.GoLow().Index
-	if idx != nil {
-		if idx.GetConfig().SpecInfo != nil {
+	if s.low != nil && s.low.Index != nil {
+		if idx := s.low.Index; idx.GetConfig().SpecInfo != nil {
 			nb.Version = idx.GetConfig().SpecInfo.VersionNumeric
 		}
 	}
@@ -496,9 +495,8 @@ func (s *Schema) MarshalYAMLInline() (interface{}, error) {
 	nb := high.NewNodeBuilder(s, s.low)
 	nb.Resolve = true
 	// determine index version
-	idx := s.GoLow().Index
-	if idx != nil {
-		if idx.GetConfig().SpecInfo != nil {
+	if s.low != nil && s.low.Index != nil {
+		if idx := s.low.Index; idx.GetConfig().SpecInfo != nil {
 			nb.Version = idx.GetConfig().SpecInfo.VersionNumeric
 		}
 	}
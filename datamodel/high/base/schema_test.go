@@ -1286,3 +1286,19 @@ func TestSchema_RenderProxyWithConfig_Corrected_3(t *testing.T) {
 	schemaBytes, _ = compiled.RenderInline()
 	assert.Equal(t, testSpecCorrect, strings.TrimSpace(string(schemaBytes)))
 }
+
+func TestSchema_RenderWithoutLow(t *testing.T) {
+	minimum := 0.0
+	schema := &Schema{
+		Type:    []string{"integer"},
+		Minimum: &minimum,
+	}
+
+	schemaBytes, err := schema.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, "type: integer\nminimum: 0", strings.TrimSpace(string(schemaBytes)))
+
+	schemaBytes, err = schema.RenderInline()
+	assert.NoError(t, err)
+	assert.Equal(t, "type: integer\nminimum: 0", strings.TrimSpace(string(schemaBytes)))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/pb33f/libopenapi/datamodel/low"
 	"github.com/pb33f/libopenapi/utils"
 	"gopkg.in/yaml.v3"
+	"math"
 	"reflect"
 	"sort"
 	"strconv"
@@ -351,15 +352,14 @@ func (n *NodeBuilder) AddYAMLNode(parent *yaml.Node, entry *NodeEntry) *yaml.Nod
 
 		var orderedCollection []*NodeEntry
 		m := reflect.ValueOf(value)
-		for g, k := range m.MapKeys() {
-			var x string
-			// extract key
-			yu := k.Interface()
-			if o, ok := yu.(low.HasKeyNode); ok {
-				x = o.GetKeyNode().Value
-			} else {
-				x = k.String()
-			}
+
+		// sort the keys, so keys without low level details are rendered in a stable order.
+		keys := m.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return mapKeyName(keys[i]) < mapKeyName(keys[j])
+		})
+		for g, k := range keys {
+			x := mapKeyName(k)
 
 			// go low and pull out the line number.
 			lowProps := reflect.ValueOf(n.Low)
@@ -573,14 +573,13 @@ func (n *NodeBuilder) AddYAMLNode(parent *yaml.Node, entry *NodeEntry) *yaml.Nod
 			}
 			if b, bok := value.(*float64); bok {
 				encodeSkip = true
-				if *b > 0 || (entry.RenderZero && entry.Line > 0) {
-					if *b > 0 {
-						valueNode = utils.CreateFloatNode(strconv.FormatFloat(*b, 'f', -1, 64))
-					} else {
-						valueNode = utils.CreateIntNode(strconv.FormatFloat(*b, 'f', -1, 64))
-					}
-					valueNode.Line = line
+				// whole numbers are rendered as integers, so they are not tagged as floats.
+				if *b == math.Trunc(*b) {
+					valueNode = utils.CreateIntNode(strconv.FormatFloat(*b, 'f', -1, 64))
+				} else {
+					valueNode = utils.CreateFloatNode(strconv.FormatFloat(*b, 'f', -1, 64))
 				}
+				valueNode.Line = line
 			}
 			if !encodeSkip {
 				var rawNode yaml.Node
@@ -673,3 +672,11 @@ type Renderable interface {
 type RenderableInline interface {
 	MarshalYAMLInline() (interface{}, error)
 }
+
+// mapKeyName returns the name of a key of a map, from its key node if it has one.
+func mapKeyName(k reflect.Value) string {
+	if o, ok := k.Interface().(low.HasKeyNode); ok {
+		return o.GetKeyNode().Value
+	}
+	return k.String()
+}
@@ -962,6 +962,26 @@ func TestNewNodeBuilder_TestRenderZero_Float_NotZero(t *testing.T) {
 	assert.Equal(t, desired, strings.TrimSpace(string(data)))
 }
 
+func TestNewNodeBuilder_TestRenderFloat_WholeNumbers(t *testing.T) {
+
+	f := 5.0
+	n := -1.0
+	t1 := test1{
+		Thral: &f,
+		Throo: &n,
+	}
+
+	nb := NewNodeBuilder(&t1, &t1)
+	node := nb.Render()
+
+	data, _ := yaml.Marshal(node)
+
+	desired := `thral: 5
+throo: -1`
+
+	assert.Equal(t, desired, strings.TrimSpace(string(data)))
+}
+
 func TestNewNodeBuilder_TestRenderServerVariableSimulation(t *testing.T) {
 
 	t1 := test1{
@@ -1026,3 +1046,28 @@ func TestNewNodeBuilder_ShouldHaveNotDoneTestsLikeThisOhWell(t *testing.T) {
 
 	assert.Equal(t, desired, strings.TrimSpace(string(data)))
 }
+
+func TestNewNodeBuilder_MapKeysSortedWithoutLow(t *testing.T) {
+
+	t1 := test1{
+		Thrug: map[string]string{
+			"pizza":  "pie",
+			"burger": "bun",
+			"chips":  "salt",
+			"apple":  "pie",
+		},
+	}
+
+	nb := NewNodeBuilder(&t1, nil)
+	node := nb.Render()
+
+	data, _ := yaml.Marshal(node)
+
+	desired := `thrug:
+    apple: pie
+    burger: bun
+    chips: salt
+    pizza: pie`
+
+	assert.Equal(t, desired, strings.TrimSpace(string(data)))
+}
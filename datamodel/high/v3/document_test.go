@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
 	lowv2 "github.com/pb33f/libopenapi/datamodel/low/v2"
 	lowv3 "github.com/pb33f/libopenapi/datamodel/low/v3"
@@ -714,3 +715,50 @@ components:
 
 	assert.Equal(t, desired, strings.TrimSpace(string(r)))
 }
+
+func TestDocument_MarshalYAML_TestNumberKeywords(t *testing.T) {
+	// whole numbers render without a float tag, and zero or negative numbers are not dropped.
+	yml := `openapi: 3.1.0
+components:
+    schemas:
+        Burger:
+            type: object
+            properties:
+                calories:
+                    type: number
+                    multipleOf: 0.5
+                    maximum: 5000
+                    minimum: 0
+                temperature:
+                    type: integer
+                    maximum: 100
+                    minimum: -1`
+
+	info, _ := datamodel.ExtractSpecInfo([]byte(yml))
+	var err []error
+	lowDoc, err = lowv3.CreateDocumentFromConfig(info, &datamodel.DocumentConfiguration{
+		AllowFileReferences:   true,
+		AllowRemoteReferences: true,
+	})
+	if err != nil {
+		panic("broken something")
+	}
+	h := NewDocument(lowDoc)
+
+	r, _ := h.Render()
+	assert.Equal(t, yml, strings.TrimSpace(string(r)))
+
+	// properties added without a low-level model are rendered in order of their name.
+	burger := h.Components.Schemas["Burger"].Schema()
+	for _, name := range []string{"weight", "price", "name"} {
+		burger.Properties[name] = base.CreateSchemaProxy(&base.Schema{Type: []string{"string"}})
+	}
+	r, _ = h.Render()
+	assert.Contains(t, string(r), `                    minimum: -1
+                name:
+                    type: string
+                price:
+                    type: string
+                weight:
+                    type: string`)
+}
@@ -80,6 +80,15 @@ func (o *Operation) Build(_, root *yaml.Node, idx *index.SpecIndex) error {
 			ValueNode: svn,
 		}
 	}
+
+	// if security is set, but no requirements are defined, security is removed for the operation.
+	if sln != nil && sec == nil {
+		o.Security = low.NodeReference[[]low.ValueReference[*base.SecurityRequirement]]{
+			Value:     []low.ValueReference[*base.SecurityRequirement]{}, // empty
+			KeyNode:   sln,
+			ValueNode: svn,
+		}
+	}
 	return nil
 }
 
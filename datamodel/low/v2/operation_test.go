@@ -184,3 +184,24 @@ security:
 	assert.Len(t, n.GetSecurity().Value, 1)
 	assert.Len(t, n.GetExtensions(), 1)
 }
+
+func TestOperation_EmptySecurity(t *testing.T) {
+
+	yml := `
+security: []`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+	idx := index.NewSpecIndex(&idxNode)
+
+	var n Operation
+	err := low.BuildModel(idxNode.Content[0], &n)
+	assert.NoError(t, err)
+
+	err = n.Build(nil, idxNode.Content[0], idx)
+	assert.NoError(t, err)
+
+	assert.False(t, n.Security.IsEmpty())
+	assert.Len(t, n.Security.Value, 0)
+
+}
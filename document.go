@@ -14,8 +14,11 @@
 package libopenapi
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/pb33f/libopenapi/index"
 
@@ -24,6 +27,8 @@ import (
 	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
 	v2low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	v3low "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/pb33f/libopenapi/merge"
+	"github.com/pb33f/libopenapi/patch"
 	"github.com/pb33f/libopenapi/resolver"
 	"github.com/pb33f/libopenapi/utils"
 	what_changed "github.com/pb33f/libopenapi/what-changed"
@@ -87,6 +92,15 @@ type Document interface {
 	// **IMPORTANT** This method only supports OpenAPI Documents.
 	Render() ([]byte, error)
 
+	// ApplyPatch will apply an RFC 6902 JSON Patch (supplied as either JSON or YAML) to the yaml.Node tree that
+	// backs the document. Applying the patch directly to the node tree means comments, ordering and formatting of
+	// everything the patch does not touch are preserved. Once patched, the document is reloaded from the new bytes
+	// in the same way as RenderAndReload, so line numbers, column numbers and the index are all correct.
+	//
+	// The method returns the patched bytes, the new Document and (for OpenAPI 3+ documents only) the newly built
+	// model, and any errors that occurred. The original document is left untouched if the patch fails.
+	ApplyPatch(patch []byte) ([]byte, Document, *DocumentModel[v3high.Document], []error)
+
 	// Serialize will re-render a Document back into a []byte slice. If any modifications have been made to the
 	// underlying data model using low level APIs, then those changes will be reflected in the serialized output.
 	//
@@ -203,6 +217,62 @@ func (d *document) RenderAndReload() ([]byte, Document, *DocumentModel[v3high.Do
 	return newBytes, newDoc, model, nil
 }
 
+func (d *document) ApplyPatch(patchBytes []byte) ([]byte, Document, *DocumentModel[v3high.Document], []error) {
+	if d.info == nil || d.info.RootNode == nil {
+		return nil, nil, nil, []error{fmt.Errorf("unable to apply patch, document has not yet been initialized")}
+	}
+	p, err := patch.ParsePatch(patchBytes)
+	if err != nil {
+		return nil, nil, nil, []error{err}
+	}
+	patched, err := p.ApplyToCopy(d.info.RootNode)
+	if err != nil {
+		return nil, nil, nil, []error{err}
+	}
+
+	return d.reload(patched)
+}
+
+// reload renders a yaml.Node tree in the same format and indentation as the document, and creates a new Document
+// (and model, for OpenAPI 3+ documents) from the rendered bytes.
+func (d *document) reload(root *yaml.Node) ([]byte, Document, *DocumentModel[v3high.Document], []error) {
+	indent := d.info.OriginalIndentation
+	if indent <= 0 {
+		indent = 2
+	}
+	var newBytes []byte
+	if d.info.SpecFileType == datamodel.JSONFileType {
+		compact, jErr := patch.MarshalNodeJSON(root)
+		if jErr != nil {
+			return nil, nil, nil, []error{jErr}
+		}
+		var buf bytes.Buffer
+		_ = json.Indent(&buf, compact, "", strings.Repeat(" ", indent))
+		newBytes = buf.Bytes()
+	} else {
+		var buf bytes.Buffer
+		yamlEncoder := yaml.NewEncoder(&buf)
+		yamlEncoder.SetIndent(indent)
+		if yErr := yamlEncoder.Encode(root); yErr != nil {
+			return nil, nil, nil, []error{yErr}
+		}
+		newBytes = buf.Bytes()
+	}
+
+	newDoc, err := NewDocumentWithConfiguration(newBytes, d.config)
+	if err != nil {
+		return newBytes, newDoc, nil, []error{err}
+	}
+	if newDoc.GetSpecInfo().SpecFormat != datamodel.OAS3 {
+		return newBytes, newDoc, nil, nil
+	}
+	model, buildErrs := newDoc.BuildV3Model()
+	if buildErrs != nil {
+		return newBytes, newDoc, model, buildErrs
+	}
+	return newBytes, newDoc, model, nil
+}
+
 func (d *document) Render() ([]byte, error) {
 	if d.highSwaggerModel != nil && d.highOpenAPI3Model == nil {
 		return nil, errors.New("this method only supports OpenAPI 3 documents, not Swagger")
@@ -320,6 +390,14 @@ func (d *document) BuildV3Model() (*DocumentModel[v3high.Document], []error) {
 // model.DocumentChanges. If there are any changes found however between either Document, then a pointer to
 // model.DocumentChanges is returned containing every single change, broken down, model by model.
 func CompareDocuments(original, updated Document) (*model.DocumentChanges, []error) {
+	return CompareDocumentsWithOptions(original, updated, nil)
+}
+
+// CompareDocumentsWithOptions is the same as CompareDocuments, except it accepts model.ComparisonOptions that
+// can be used to ignore cosmetic changes (descriptions, examples, extensions etc.). If options is nil,
+// every change found is returned.
+func CompareDocumentsWithOptions(original, updated Document,
+	options *model.ComparisonOptions) (*model.DocumentChanges, []error) {
 	var errors []error
 	if original.GetSpecInfo().SpecType == utils.OpenApi3 && updated.GetSpecInfo().SpecType == utils.OpenApi3 {
 		v3ModelLeft, errs := original.BuildV3Model()
@@ -331,7 +409,8 @@ func CompareDocuments(original, updated Document) (*model.DocumentChanges, []err
 			errors = append(errors, errs...)
 		}
 		if v3ModelLeft != nil && v3ModelRight != nil {
-			return what_changed.CompareOpenAPIDocuments(v3ModelLeft.Model.GoLow(), v3ModelRight.Model.GoLow()), errors
+			return what_changed.CompareOpenAPIDocumentsWithOptions(v3ModelLeft.Model.GoLow(),
+				v3ModelRight.Model.GoLow(), options), errors
 		} else {
 			return nil, errors
 		}
@@ -346,10 +425,122 @@ func CompareDocuments(original, updated Document) (*model.DocumentChanges, []err
 			errors = append(errors, errs...)
 		}
 		if v2ModelLeft != nil && v2ModelRight != nil {
-			return what_changed.CompareSwaggerDocuments(v2ModelLeft.Model.GoLow(), v2ModelRight.Model.GoLow()), errors
+			return what_changed.CompareSwaggerDocumentsWithOptions(v2ModelLeft.Model.GoLow(),
+				v2ModelRight.Model.GoLow(), options), errors
 		} else {
 			return nil, errors
 		}
 	}
 	return nil, []error{fmt.Errorf("unable to compare documents, one or both documents are not of the same version")}
 }
+
+// CompareDocumentsAcrossVersions is the same as CompareDocuments, except the original and updated documents can be
+// different versions, for example, when proving a contract has not changed after migrating from Swagger to OpenAPI 3.
+//
+// Any Swagger document is normalized into an equivalent OpenAPI 3 document before being compared, so only semantic
+// differences are reported, differences in how each version expresses the same contract are not. If both documents
+// are the same version, this behaves exactly like CompareDocuments.
+func CompareDocumentsAcrossVersions(original, updated Document) (*model.DocumentChanges, []error) {
+	lType := original.GetSpecInfo().SpecType
+	rType := updated.GetSpecInfo().SpecType
+	if lType == rType {
+		return CompareDocuments(original, updated)
+	}
+	if lType == utils.OpenApi2 && rType == utils.OpenApi3 {
+		v2Model, errs := original.BuildV2Model()
+		v3Model, v3Errs := updated.BuildV3Model()
+		errs = append(errs, v3Errs...)
+		if v2Model == nil || v3Model == nil {
+			return nil, errs
+		}
+		changes, cErrs := what_changed.CompareSwaggerToOpenAPIDocuments(v2Model.Model.GoLow(), v3Model.Model.GoLow())
+		return changes, append(errs, cErrs...)
+	}
+	if lType == utils.OpenApi3 && rType == utils.OpenApi2 {
+		v3Model, errs := original.BuildV3Model()
+		v2Model, v2Errs := updated.BuildV2Model()
+		errs = append(errs, v2Errs...)
+		if v2Model == nil || v3Model == nil {
+			return nil, errs
+		}
+		changes, cErrs := what_changed.CompareOpenAPIToSwaggerDocuments(v3Model.Model.GoLow(), v2Model.Model.GoLow())
+		return changes, append(errs, cErrs...)
+	}
+	return nil, []error{fmt.Errorf("unable to compare documents, unknown document types '%s' and '%s'", lType, rType)}
+}
+
+// GeneratePatch will compare an original and updated Document (of the same version) and return an RFC 6902 JSON Patch
+// that transforms the original document into the updated document. The patch is generated from the changes found by
+// CompareDocuments, with renamed and moved objects expressed as 'move' operations.
+//
+// Use Render() on the returned patch.Patch to serialize it, and ApplyPatch() on a Document to apply it.
+func GeneratePatch(original, updated Document) (patch.Patch, []error) {
+	changes, errs := CompareDocuments(original, updated)
+	if changes == nil && len(errs) > 0 {
+		return nil, errs
+	}
+	var all []*model.Change
+	if changes != nil {
+		all = changes.GetAllChanges()
+	}
+	return patch.FromChanges(all, original.GetSpecInfo().RootNode, updated.GetSpecInfo().RootNode), errs
+}
+
+// MergeResult is the outcome of a three-way merge performed by MergeDocuments.
+type MergeResult struct {
+	Bytes         []byte                          // the rendered merged document.
+	Document      Document                        // the merged document.
+	Model         *DocumentModel[v3high.Document] // the merged model (OpenAPI 3+ documents only).
+	Conflicts     []*merge.Conflict               // changes made by both sides to the same location.
+	OursChanges   *model.DocumentChanges          // changes between the base document and ours.
+	TheirsChanges *model.DocumentChanges          // changes between the base document and theirs.
+}
+
+// MergeDocuments will perform a three-way merge of two Documents (ours and theirs) that were both edited from a
+// common base Document. All three documents must be the same version.
+//
+// Both sets of changes are found using CompareDocuments. Changes made by only one side are applied automatically
+// (including renamed and moved objects), and changes made by both sides to the same location are reported as
+// conflicts in the result, each one has a JSON Pointer and the values from both sides. When a conflict occurs,
+// the value from ours is kept.
+//
+// The merged document is rendered in the same format as the base document, comments and ordering from ours are
+// preserved.
+func MergeDocuments(base, ours, theirs Document) (*MergeResult, []error) {
+	if base == nil || ours == nil || theirs == nil {
+		return nil, []error{fmt.Errorf("unable to merge documents, base, ours and theirs are all required")}
+	}
+	oursChanges, errs := CompareDocuments(base, ours)
+	if oursChanges == nil && len(errs) > 0 {
+		return nil, errs
+	}
+	theirsChanges, tErrs := CompareDocuments(base, theirs)
+	if theirsChanges == nil && len(tErrs) > 0 {
+		return nil, append(errs, tErrs...)
+	}
+	errs = append(errs, tErrs...)
+
+	var oursAll, theirsAll []*model.Change
+	if oursChanges != nil {
+		oursAll = oursChanges.GetAllChanges()
+	}
+	if theirsChanges != nil {
+		theirsAll = theirsChanges.GetAllChanges()
+	}
+	merged, conflicts := merge.Documents(base.GetSpecInfo().RootNode, ours.GetSpecInfo().RootNode,
+		theirs.GetSpecInfo().RootNode, oursAll, theirsAll)
+
+	d, ok := base.(*document)
+	if !ok {
+		d = &document{info: base.GetSpecInfo()}
+	}
+	newBytes, newDoc, newModel, buildErrs := d.reload(merged)
+	return &MergeResult{
+		Bytes:         newBytes,
+		Document:      newDoc,
+		Model:         newModel,
+		Conflicts:     conflicts,
+		OursChanges:   oursChanges,
+		TheirsChanges: theirsChanges,
+	}, append(errs, buildErrs...)
+}
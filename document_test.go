@@ -858,3 +858,358 @@ components:
 	assert.Len(t, m.Index.GetCircularReferences(), 0)
 
 }
+
+func TestDocument_GeneratePatch_ApplyPatch(t *testing.T) {
+
+	original := `openapi: 3.1.0
+# burgers are great
+info:
+  title: burger shop # the name
+  version: 1.0.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      description: get a burger
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+        fries:
+          type: boolean`
+
+	updated := `openapi: 3.1.0
+# burgers are great
+info:
+  title: burger emporium # the name
+  version: 1.1.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      description: get a tasty burger
+      parameters:
+        - name: burgerId
+          in: path
+          required: true
+          schema:
+            type: string
+components:
+  schemas:
+    Hamburger:
+      type: object
+      properties:
+        name:
+          type: string
+        fries:
+          type: boolean`
+
+	lDoc, _ := NewDocument([]byte(original))
+	rDoc, _ := NewDocument([]byte(updated))
+
+	p, errs := GeneratePatch(lDoc, rDoc)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, "move", p[0].Op)
+	assert.Equal(t, "/components/schemas/Burger", p[0].From)
+	assert.Equal(t, "/components/schemas/Hamburger", p[0].Path)
+
+	rendered, err := p.Render()
+	assert.NoError(t, err)
+
+	patched, newDoc, newModel, errs := lDoc.ApplyPatch(rendered)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, "burger emporium", newModel.Model.Info.Title)
+	assert.NotNil(t, newModel.Model.Components.Schemas["Hamburger"])
+	assert.Equal(t, updated, strings.TrimSpace(string(patched)))
+
+	// once applied, there should be nothing left to compare.
+	changes, _ := CompareDocuments(newDoc, rDoc)
+	assert.Nil(t, changes)
+}
+
+func TestDocument_ApplyPatch_JSON(t *testing.T) {
+
+	d := `{
+    "openapi": "3.1.0",
+    "info": {
+        "title": "burgers"
+    }
+}`
+	doc, _ := NewDocument([]byte(d))
+	patched, newDoc, _, errs := doc.ApplyPatch([]byte(`[{"op": "add", "path": "/info/version", "value": "1.0"}]`))
+	assert.Len(t, errs, 0)
+	assert.Equal(t, `{
+    "openapi": "3.1.0",
+    "info": {
+        "title": "burgers",
+        "version": "1.0"
+    }
+}`, string(patched))
+	assert.Equal(t, datamodel.JSONFileType, newDoc.GetSpecInfo().SpecFileType)
+}
+
+func TestDocument_ApplyPatch_Swagger(t *testing.T) {
+
+	d := `swagger: 2.0
+info:
+  title: burgers`
+	doc, _ := NewDocument([]byte(d))
+	patched, newDoc, newModel, errs := doc.ApplyPatch([]byte(`[{"op": "replace", "path": "/info/title", "value": "fries"}]`))
+	assert.Len(t, errs, 0)
+	assert.Nil(t, newModel)
+	assert.Contains(t, string(patched), "title: fries")
+	assert.Equal(t, datamodel.OAS2, newDoc.GetSpecInfo().SpecFormat)
+}
+
+func TestDocument_ApplyPatch_Errors(t *testing.T) {
+
+	doc, _ := NewDocument([]byte(`openapi: 3.1.0`))
+
+	_, _, _, errs := doc.ApplyPatch([]byte(`not a patch`))
+	assert.Len(t, errs, 1)
+
+	_, _, _, errs = doc.ApplyPatch([]byte(`[{"op": "remove", "path": "/info"}]`))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "unable to apply patch operation 0 (remove /info): path does not exist", errs[0].Error())
+
+	_, _, _, errs = (&document{}).ApplyPatch([]byte(`[]`))
+	assert.Len(t, errs, 1)
+}
+
+func TestMergeDocuments(t *testing.T) {
+
+	base := `openapi: 3.1.0
+info:
+  title: burger shop
+  version: 1.0.0
+paths:
+  /burgers:
+    get:
+      description: get burgers
+      responses:
+        "200":
+          description: burgers
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+        fries:
+          type: boolean`
+
+	// ours renames the Burger schema.
+	ours := `openapi: 3.1.0
+info:
+  title: burger shop
+  version: 1.0.0
+paths:
+  /burgers:
+    get:
+      description: get burgers
+      responses:
+        "200":
+          description: burgers
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Hamburger'
+components:
+  schemas:
+    Hamburger:
+      type: object
+      properties:
+        name:
+          type: string
+        fries:
+          type: boolean`
+
+	// theirs modifies the Burger schema and bumps the version.
+	theirs := `openapi: 3.1.0
+info:
+  title: burger shop
+  version: 1.1.0
+paths:
+  /burgers:
+    get:
+      description: get burgers
+      responses:
+        "200":
+          description: burgers
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+        fries:
+          type: boolean
+        cheese:
+          type: boolean`
+
+	baseDoc, _ := NewDocument([]byte(base))
+	oursDoc, _ := NewDocument([]byte(ours))
+	theirsDoc, _ := NewDocument([]byte(theirs))
+
+	result, errs := MergeDocuments(baseDoc, oursDoc, theirsDoc)
+	assert.Len(t, errs, 0)
+	assert.Len(t, result.Conflicts, 0)
+	assert.NotNil(t, result.OursChanges)
+	assert.NotNil(t, result.TheirsChanges)
+	assert.Equal(t, "1.1.0", result.Model.Model.Info.Version)
+
+	hamburger := result.Model.Model.Components.Schemas["Hamburger"].Schema()
+	assert.NotNil(t, hamburger)
+	assert.Len(t, hamburger.Properties, 3)
+	assert.Nil(t, result.Model.Model.Components.Schemas["Burger"])
+	assert.Contains(t, string(result.Bytes), "$ref: '#/components/schemas/Hamburger'")
+}
+
+func TestMergeDocuments_Conflict(t *testing.T) {
+
+	base := `openapi: 3.1.0
+info:
+  title: burger shop`
+	ours := `openapi: 3.1.0
+info:
+  title: burger emporium`
+	theirs := `openapi: 3.1.0
+info:
+  title: burger palace`
+
+	baseDoc, _ := NewDocument([]byte(base))
+	oursDoc, _ := NewDocument([]byte(ours))
+	theirsDoc, _ := NewDocument([]byte(theirs))
+
+	result, errs := MergeDocuments(baseDoc, oursDoc, theirsDoc)
+	assert.Len(t, errs, 0)
+	assert.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "/info/title", result.Conflicts[0].Path)
+	assert.Equal(t, "burger emporium", result.Conflicts[0].Ours.Value)
+	assert.Equal(t, "burger palace", result.Conflicts[0].Theirs.Value)
+	assert.Equal(t, "burger emporium", result.Model.Model.Info.Title)
+}
+
+func TestMergeDocuments_Errors(t *testing.T) {
+
+	_, errs := MergeDocuments(nil, nil, nil)
+	assert.Len(t, errs, 1)
+
+	v3Doc, _ := NewDocument([]byte(`openapi: 3.1.0`))
+	v2Doc, _ := NewDocument([]byte(`swagger: 2.0`))
+
+	_, errs = MergeDocuments(v3Doc, v2Doc, v3Doc)
+	assert.Len(t, errs, 1)
+
+	_, errs = MergeDocuments(v3Doc, v3Doc, v2Doc)
+	assert.Len(t, errs, 1)
+}
+
+func TestCompareDocumentsAcrossVersions(t *testing.T) {
+
+	swagger, _ := os.ReadFile("test_specs/burgershop-cross-version.swagger.yaml")
+	openapi, _ := os.ReadFile("test_specs/burgershop-cross-version.openapi.yaml")
+
+	swaggerDoc, _ := NewDocument(swagger)
+	openapiDoc, _ := NewDocument(openapi)
+
+	// the migrated contract is identical.
+	changes, errs := CompareDocumentsAcrossVersions(swaggerDoc, openapiDoc)
+	assert.Len(t, errs, 0)
+	assert.Nil(t, changes)
+
+	// the migrated contract no longer accepts a caption.
+	modified := strings.Replace(string(openapi), "                caption:\n                  type: string\n", "", 1)
+	modifiedDoc, _ := NewDocument([]byte(modified))
+
+	changes, errs = CompareDocumentsAcrossVersions(swaggerDoc, modifiedDoc)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, changes.TotalChanges())
+	assert.Equal(t, "caption", changes.GetAllChanges()[0].Original)
+
+	changes, errs = CompareDocumentsAcrossVersions(modifiedDoc, swaggerDoc)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, changes.TotalChanges())
+	assert.Equal(t, "caption", changes.GetAllChanges()[0].New)
+
+	// same versions are compared as normal.
+	changes, errs = CompareDocumentsAcrossVersions(openapiDoc, modifiedDoc)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, changes.TotalChanges())
+}
+
+func TestCompareDocumentsAcrossVersions_TabSeparated(t *testing.T) {
+
+	swaggerDoc, _ := NewDocument([]byte(`swagger: "2.0"
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: toppings
+          in: query
+          type: array
+          items:
+            type: string
+          collectionFormat: tsv
+      responses:
+        "200":
+          description: ok`))
+	openapiDoc, _ := NewDocument([]byte(`openapi: 3.0.3
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: toppings
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+      responses:
+        "200":
+          description: ok`))
+
+	// tsv has no OpenAPI 3 equivalent, so it is not the same as the default (multi) style.
+	changes, errs := CompareDocumentsAcrossVersions(swaggerDoc, openapiDoc)
+	assert.Len(t, errs, 0)
+	assert.NotNil(t, changes)
+}
+
+func TestCompareDocumentsAcrossVersions_Errors(t *testing.T) {
+
+	swaggerDoc, _ := NewDocument([]byte(`swagger: 2.0
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          $ref: '#/nowhere'`))
+	openapiDoc, _ := NewDocument([]byte(`openapi: 3.1.0`))
+
+	_, errs := CompareDocumentsAcrossVersions(swaggerDoc, openapiDoc)
+	assert.NotEmpty(t, errs)
+
+	_, errs = CompareDocumentsAcrossVersions(openapiDoc, swaggerDoc)
+	assert.NotEmpty(t, errs)
+
+	unknown, _ := NewDocumentWithTypeCheck([]byte(`burgers: true`), true)
+	_, errs = CompareDocumentsAcrossVersions(unknown, openapiDoc)
+	assert.Len(t, errs, 1)
+}
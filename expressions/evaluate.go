@@ -0,0 +1,220 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package expressions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNoValue is wrapped by the errors of expressions that refer to a value the exchange does not have, such as a
+// missing header.
+var ErrNoValue = errors.New("the exchange has no value for the expression")
+
+// Exchange is a request and the response to it, which runtime expressions are evaluated against.
+type Exchange struct {
+	Request    *http.Request
+	PathParams map[string]string // values of the path parameters of the request, such as router.Route.PathParams.
+	Response   *http.Response    // nil if $response and $statusCode expressions are not evaluated.
+
+	requestBody  *body
+	responseBody *body
+}
+
+// body is a read (and decoded) body of a request or response.
+type body struct {
+	raw     []byte
+	value   any
+	decoded bool
+	err     error
+}
+
+// Evaluate evaluates the expression against an exchange. $url and $method are strings, $statusCode is an int and
+// headers, query and path parameters are strings, multiple header values are joined with commas. $request.body
+// and $response.body decode JSON bodies, so values are strings, json.Numbers, bools, nil, []any and map[string]any;
+// bodies that are not JSON are strings, and cannot be used with a JSON pointer.
+//
+// Bodies are read once, and replaced so they can be read again. An error wrapping ErrNoValue is returned if the
+// exchange does not have the value.
+func (e *Expression) Evaluate(x *Exchange) (any, error) {
+	noValue := func(format string, args ...any) error {
+		return fmt.Errorf("%w '%s': %s", ErrNoValue, e.Raw, fmt.Sprintf(format, args...))
+	}
+	response := e.Source == SourceStatusCode || e.Source == SourceResponse
+	switch {
+	case response && x.Response == nil:
+		return nil, noValue("there is no response")
+	case !response && x.Request == nil:
+		return nil, noValue("there is no request")
+	}
+	switch e.Source {
+	case SourceURL:
+		return requestURL(x.Request), nil
+	case SourceMethod:
+		return x.Request.Method, nil
+	case SourceStatusCode:
+		return x.Response.StatusCode, nil
+	}
+
+	var header http.Header
+	if response {
+		header = x.Response.Header
+	} else {
+		header = x.Request.Header
+	}
+	switch e.Location {
+	case LocationHeader:
+		values := header.Values(e.Name)
+		if len(values) == 0 {
+			return nil, noValue("header '%s' is not set", e.Name)
+		}
+		return strings.Join(values, ","), nil
+	case LocationQuery:
+		if response {
+			return nil, noValue("a response has no query")
+		}
+		values, ok := x.Request.URL.Query()[e.Name]
+		if !ok || len(values) == 0 {
+			return nil, noValue("query parameter '%s' is not set", e.Name)
+		}
+		return values[0], nil
+	case LocationPath:
+		value, ok := x.PathParams[e.Name]
+		if !ok || response {
+			return nil, noValue("path parameter '%s' is not set", e.Name)
+		}
+		return value, nil
+	}
+
+	b := x.body(response)
+	if b.err != nil {
+		return nil, fmt.Errorf("unable to read the body for '%s': %w", e.Raw, b.err)
+	}
+	if e.Pointer == "" {
+		if b.decoded {
+			return b.value, nil
+		}
+		return string(b.raw), nil
+	}
+	if !b.decoded {
+		return nil, noValue("the body is not JSON")
+	}
+	value, err := resolvePointer(b.value, e.Pointer)
+	if err != nil {
+		return nil, noValue("%s", err)
+	}
+	return value, nil
+}
+
+// Evaluate evaluates the expressions of the template against an exchange, and replaces them with their values.
+// Strings and numbers are embedded as they are, other values are embedded as JSON.
+func (t *Template) Evaluate(x *Exchange) (string, error) {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.expression == nil {
+			b.WriteString(p.literal)
+			continue
+		}
+		value, err := p.expression.Evaluate(x)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(format(value))
+	}
+	return b.String(), nil
+}
+
+// format formats a value embedded in a template.
+func format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// body reads the body of the request or response once, and decodes it if it is JSON.
+func (x *Exchange) body(response bool) *body {
+	var cached **body
+	var rc *io.ReadCloser
+	var contentType string
+	if response {
+		cached, rc, contentType = &x.responseBody, &x.Response.Body, x.Response.Header.Get("Content-Type")
+	} else {
+		cached, rc, contentType = &x.requestBody, &x.Request.Body, x.Request.Header.Get("Content-Type")
+	}
+	if *cached != nil {
+		return *cached
+	}
+	b := new(body)
+	*cached = b
+	if *rc == nil {
+		return b
+	}
+	b.raw, b.err = io.ReadAll(*rc)
+	_ = (*rc).Close()
+	*rc = io.NopCloser(bytes.NewReader(b.raw))
+	if b.err != nil || len(b.raw) == 0 {
+		return b
+	}
+	// bodies without a content type are decoded if they are valid JSON.
+	if contentType == "" || strings.Contains(strings.ToLower(contentType), "json") {
+		decoder := json.NewDecoder(bytes.NewReader(b.raw))
+		decoder.UseNumber()
+		if decoder.Decode(&b.value) == nil {
+			b.decoded = true
+		}
+	}
+	return b
+}
+
+// requestURL returns the full URL of a request, server requests only have the host in the Host header.
+func requestURL(r *http.Request) string {
+	if r.URL.IsAbs() {
+		return r.URL.String()
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// resolvePointer resolves a JSON pointer into a decoded JSON value.
+func resolvePointer(value any, pointer string) (any, error) {
+	if pointer == "" {
+		return value, nil
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]any:
+			item, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("property '%s' is not set", token)
+			}
+			value = item
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("index '%s' is not in the array", token)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("'%s' cannot be resolved in a %T", token, value)
+		}
+	}
+	return value, nil
+}
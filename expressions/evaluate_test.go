@@ -0,0 +1,118 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package expressions
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newExchange() *Exchange {
+	request := httptest.NewRequest(http.MethodPost, "/subscribe/42?queryUrl=http://example.com/hook",
+		strings.NewReader(`{"id": 7, "user": {"email": "dave@pb33f.io", "tags": ["a", "b"]}}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add("X-Ids", "1")
+	request.Header.Add("X-Ids", "2")
+
+	response := httptest.NewRecorder()
+	response.Header().Set("Location", "/subscriptions/99")
+	response.WriteHeader(http.StatusCreated)
+	_, _ = response.WriteString(`{"id": 99, "a/b": {"~c": true}}`)
+
+	return &Exchange{Request: request, PathParams: map[string]string{"id": "42"}, Response: response.Result()}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	x := newExchange()
+	tests := []struct {
+		expression string
+		want       any
+	}{
+		{"$url", "http://example.com/subscribe/42?queryUrl=http://example.com/hook"},
+		{"$method", "POST"},
+		{"$statusCode", 201},
+		{"$request.header.X-Ids", "1,2"},
+		{"$request.query.queryUrl", "http://example.com/hook"},
+		{"$request.path.id", "42"},
+		{"$request.body#/id", json.Number("7")},
+		{"$request.body#/user/tags/1", "b"},
+		{"$request.body#/user/tags", []any{"a", "b"}},
+		{"$response.header.Location", "/subscriptions/99"},
+		{"$response.body#/a~1b/~0c", true},
+	}
+	for _, tc := range tests {
+		e, err := Parse(tc.expression)
+		assert.NoError(t, err)
+		value, err := e.Evaluate(x)
+		assert.NoError(t, err, tc.expression)
+		assert.Equal(t, tc.want, value, tc.expression)
+	}
+
+	// the bodies can be read again.
+	b, _ := io.ReadAll(x.Request.Body)
+	assert.Contains(t, string(b), `"id": 7`)
+	b, _ = io.ReadAll(x.Response.Body)
+	assert.Contains(t, string(b), `"id": 99`)
+}
+
+func TestExpression_Evaluate_NoValue(t *testing.T) {
+	x := newExchange()
+	for _, expression := range []string{
+		"$request.header.X-Missing",
+		"$request.query.missing",
+		"$request.path.missing",
+		"$request.body#/user/tags/2",
+		"$request.body#/user/email/domain",
+		"$response.query.id",
+		"$response.path.id",
+	} {
+		e, _ := Parse(expression)
+		_, err := e.Evaluate(x)
+		assert.True(t, errors.Is(err, ErrNoValue), expression)
+	}
+
+	e, _ := Parse("$request.header.X-Missing")
+	_, err := e.Evaluate(x)
+	assert.Equal(t, "the exchange has no value for the expression '$request.header.X-Missing': header 'X-Missing' "+
+		"is not set", err.Error())
+
+	e, _ = Parse("$statusCode")
+	_, err = e.Evaluate(&Exchange{Request: x.Request})
+	assert.True(t, errors.Is(err, ErrNoValue))
+	e, _ = Parse("$method")
+	_, err = e.Evaluate(&Exchange{Response: x.Response})
+	assert.True(t, errors.Is(err, ErrNoValue))
+
+	// bodies that are not JSON are strings.
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("plain"))
+	request.Header.Set("Content-Type", "text/plain")
+	x = &Exchange{Request: request}
+	e, _ = Parse("$request.body")
+	value, err := e.Evaluate(x)
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", value)
+	e, _ = Parse("$request.body#/id")
+	_, err = e.Evaluate(x)
+	assert.True(t, errors.Is(err, ErrNoValue))
+}
+
+func TestTemplate_Evaluate(t *testing.T) {
+	x := newExchange()
+	tmpl, _ := ParseTemplate("{$request.query.queryUrl}?id={$response.body#/id}&status={$statusCode}" +
+		"&tags={$request.body#/user/tags}")
+	url, err := tmpl.Evaluate(x)
+	assert.NoError(t, err)
+	assert.Equal(t, `http://example.com/hook?id=99&status=201&tags=["a","b"]`, url)
+
+	tmpl, _ = ParseTemplate("{$request.header.X-Missing}")
+	_, err = tmpl.Evaluate(x)
+	assert.True(t, errors.Is(err, ErrNoValue))
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package expressions parses and evaluates runtime expressions, used by links and callbacks to refer to values of
+// an HTTP request or response, as defined by https://spec.openapis.org/oas/v3.1.0#runtime-expressions
+//
+// A runtime expression is a complete value, like '$request.body#/id', while a template embeds runtime expressions
+// in braces, like the callback URL '{$request.query.callbackUrl}/data?id={$response.body#/id}'.
+package expressions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sources of runtime expressions.
+const (
+	SourceURL        = "url"
+	SourceMethod     = "method"
+	SourceStatusCode = "statusCode"
+	SourceRequest    = "request"
+	SourceResponse   = "response"
+)
+
+// Locations of the values of request and response expressions.
+const (
+	LocationHeader = "header"
+	LocationQuery  = "query"
+	LocationPath   = "path"
+	LocationBody   = "body"
+)
+
+// Expression is a parsed runtime expression.
+type Expression struct {
+	Raw      string // the expression as it was parsed.
+	Source   string // url, method, statusCode, request or response.
+	Location string // header, query, path or body for request and response expressions, empty otherwise.
+	Name     string // the name of the header, query or path parameter.
+	Pointer  string // the JSON pointer into the body, without the '#', empty for the whole body.
+}
+
+// SyntaxError is a runtime expression or template that does not match the ABNF syntax of runtime expressions.
+type SyntaxError struct {
+	Expression string // the expression or template.
+	Offset     int    // the offset of the invalid character (in bytes) in the expression.
+	Message    string // describes the error.
+}
+
+// Error returns a description of the syntax error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("runtime expression '%s' is invalid at offset %d: %s", e.Expression, e.Offset, e.Message)
+}
+
+// IsExpression returns true if a value is meant to be a runtime expression, because it starts with '$'. Values of
+// link parameters that are not expressions are constants.
+func IsExpression(value string) bool {
+	return strings.HasPrefix(value, "$")
+}
+
+// Parse parses a runtime expression. A *SyntaxError is returned if the expression is not valid.
+func Parse(expression string) (*Expression, error) {
+	e, offset, message := parse(expression)
+	if message != "" {
+		return nil, &SyntaxError{Expression: expression, Offset: offset, Message: message}
+	}
+	return e, nil
+}
+
+// String returns the expression as it was parsed.
+func (e *Expression) String() string {
+	return e.Raw
+}
+
+// parse parses an expression, returning the offset and a message if it is not valid.
+func parse(expression string) (*Expression, int, string) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, 0, "expression must start with '$'"
+	}
+	e := &Expression{Raw: expression}
+	source, rest, dotted := strings.Cut(expression[1:], ".")
+	switch source {
+	case SourceURL, SourceMethod, SourceStatusCode:
+		if dotted {
+			return nil, len(source) + 1, fmt.Sprintf("'$%s' cannot be followed by a source", source)
+		}
+		e.Source = source
+		return e, 0, ""
+	case SourceRequest, SourceResponse:
+		e.Source = source
+	default:
+		return nil, 1, fmt.Sprintf("unknown expression '$%s', expected $url, $method, $statusCode, $request or "+
+			"$response", source)
+	}
+	offset := len(source) + 2
+	if !dotted {
+		return nil, offset - 1, fmt.Sprintf("'$%s' must be followed by '.header', '.query', '.path' or '.body'",
+			source)
+	}
+
+	if rest == LocationBody || strings.HasPrefix(rest, LocationBody+"#") {
+		e.Location = LocationBody
+		if pointer, ok := strings.CutPrefix(rest, LocationBody+"#"); ok {
+			if i, message := checkPointer(pointer); message != "" {
+				return nil, offset + len(LocationBody) + 1 + i, message
+			}
+			e.Pointer = pointer
+		}
+		return e, 0, ""
+	}
+	location, name, ok := strings.Cut(rest, ".")
+	switch location {
+	case LocationHeader, LocationQuery, LocationPath:
+		e.Location = location
+	default:
+		return nil, offset, fmt.Sprintf("unknown source '%s', expected header, query, path or body", location)
+	}
+	offset += len(location) + 1
+	if !ok || name == "" {
+		return nil, offset, fmt.Sprintf("%s must be followed by a name", location)
+	}
+	for i, r := range name {
+		if location == LocationHeader && !isTokenChar(r) {
+			return nil, offset + i, fmt.Sprintf("character '%c' is not allowed in a header name", r)
+		}
+		if r > 0x7f {
+			return nil, offset + i, fmt.Sprintf("character '%c' is not allowed in a %s name", r, location)
+		}
+	}
+	e.Name = name
+	return e, 0, ""
+}
+
+// checkPointer checks the syntax of a JSON pointer, returning the offset and a message if it is not valid.
+func checkPointer(pointer string) (int, string) {
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return 0, "a JSON pointer must start with '/'"
+	}
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] == '~' && (i+1 == len(pointer) || (pointer[i+1] != '0' && pointer[i+1] != '1')) {
+			return i, "'~' must be followed by '0' or '1' in a JSON pointer"
+		}
+	}
+	return 0, ""
+}
+
+// isTokenChar returns true if a rune is a tchar of https://www.rfc-editor.org/rfc/rfc7230#section-3.2.6
+func isTokenChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
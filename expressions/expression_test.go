@@ -0,0 +1,74 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       Expression
+	}{
+		{"$url", Expression{Source: SourceURL}},
+		{"$method", Expression{Source: SourceMethod}},
+		{"$statusCode", Expression{Source: SourceStatusCode}},
+		{"$request.header.X-Trace-Id", Expression{Source: SourceRequest, Location: LocationHeader, Name: "X-Trace-Id"}},
+		{"$request.query.queryUrl", Expression{Source: SourceRequest, Location: LocationQuery, Name: "queryUrl"}},
+		{"$request.path.id", Expression{Source: SourceRequest, Location: LocationPath, Name: "id"}},
+		{"$request.body", Expression{Source: SourceRequest, Location: LocationBody}},
+		{"$request.body#/user/uuid", Expression{Source: SourceRequest, Location: LocationBody, Pointer: "/user/uuid"}},
+		{"$response.header.Location", Expression{Source: SourceResponse, Location: LocationHeader, Name: "Location"}},
+		{"$response.body#/a~1b/~0c/0", Expression{Source: SourceResponse, Location: LocationBody,
+			Pointer: "/a~1b/~0c/0"}},
+		{"$response.body#", Expression{Source: SourceResponse, Location: LocationBody}},
+	}
+	for _, tc := range tests {
+		e, err := Parse(tc.expression)
+		assert.NoError(t, err, tc.expression)
+		tc.want.Raw = tc.expression
+		assert.Equal(t, &tc.want, e)
+		assert.Equal(t, tc.expression, e.String())
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		expression string
+		offset     int
+		message    string
+	}{
+		{"request.body", 0, "expression must start with '$'"},
+		{"$uri", 1, "unknown expression '$uri', expected $url, $method, $statusCode, $request or $response"},
+		{"$url.path", 4, "'$url' cannot be followed by a source"},
+		{"$request", 8, "'$request' must be followed by '.header', '.query', '.path' or '.body'"},
+		{"$request.cookie.id", 9, "unknown source 'cookie', expected header, query, path or body"},
+		{"$request.header", 16, "header must be followed by a name"},
+		{"$request.query.", 15, "query must be followed by a name"},
+		{"$request.header.X Trace", 17, "character ' ' is not allowed in a header name"},
+		{"$request.path.ünïcode", 14, "character 'ü' is not allowed in a path name"},
+		{"$response.body#user", 15, "a JSON pointer must start with '/'"},
+		{"$response.body#/a~2", 17, "'~' must be followed by '0' or '1' in a JSON pointer"},
+		{"$response.bodies", 10, "unknown source 'bodies', expected header, query, path or body"},
+	}
+	for _, tc := range tests {
+		_, err := Parse(tc.expression)
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr, tc.expression) {
+			assert.Equal(t, tc.offset, syntaxErr.Offset, tc.expression)
+			assert.Equal(t, tc.message, syntaxErr.Message, tc.expression)
+		}
+	}
+	_, err := Parse("$request.header")
+	assert.Equal(t, "runtime expression '$request.header' is invalid at offset 16: header must be followed by a name",
+		err.Error())
+}
+
+func TestIsExpression(t *testing.T) {
+	assert.True(t, IsExpression("$request.body"))
+	assert.False(t, IsExpression("constant"))
+}
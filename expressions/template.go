@@ -0,0 +1,70 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package expressions
+
+import (
+	"strings"
+)
+
+// Template is a string with embedded runtime expressions in braces, such as a callback URL.
+type Template struct {
+	Raw   string
+	parts []templatePart
+}
+
+// templatePart is a literal part of a template, or an expression.
+type templatePart struct {
+	literal    string
+	expression *Expression
+}
+
+// ParseTemplate parses a template, every part in braces must be a valid runtime expression. A *SyntaxError is
+// returned if the template has an invalid expression or unbalanced braces.
+func ParseTemplate(template string) (*Template, error) {
+	t := &Template{Raw: template}
+	var literal strings.Builder
+	for i := 0; i < len(template); i++ {
+		switch template[i] {
+		case '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, &SyntaxError{Expression: template, Offset: i, Message: "'{' is not closed by '}'"}
+			}
+			e, offset, message := parse(template[i+1 : i+end])
+			if message != "" {
+				return nil, &SyntaxError{Expression: template, Offset: i + 1 + offset, Message: message}
+			}
+			if literal.Len() > 0 {
+				t.parts = append(t.parts, templatePart{literal: literal.String()})
+				literal.Reset()
+			}
+			t.parts = append(t.parts, templatePart{expression: e})
+			i += end
+		case '}':
+			return nil, &SyntaxError{Expression: template, Offset: i, Message: "'}' is not opened by '{'"}
+		default:
+			literal.WriteByte(template[i])
+		}
+	}
+	if literal.Len() > 0 {
+		t.parts = append(t.parts, templatePart{literal: literal.String()})
+	}
+	return t, nil
+}
+
+// Expressions returns the runtime expressions embedded in the template, in order.
+func (t *Template) Expressions() []*Expression {
+	var expressions []*Expression
+	for _, p := range t.parts {
+		if p.expression != nil {
+			expressions = append(expressions, p.expression)
+		}
+	}
+	return expressions
+}
+
+// String returns the template as it was parsed.
+func (t *Template) String() string {
+	return t.Raw
+}
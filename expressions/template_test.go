@@ -0,0 +1,52 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("http://notificationServer.com?transactionId={$request.body#/id}&email={$request.body#/email}")
+	assert.NoError(t, err)
+	var raw []string
+	for _, e := range tmpl.Expressions() {
+		raw = append(raw, e.Raw)
+	}
+	assert.Equal(t, []string{"$request.body#/id", "$request.body#/email"}, raw)
+	assert.Len(t, tmpl.parts, 4)
+
+	tmpl, err = ParseTemplate("{$request.query.queryUrl}")
+	assert.NoError(t, err)
+	assert.Len(t, tmpl.parts, 1)
+	assert.Equal(t, "{$request.query.queryUrl}", tmpl.String())
+
+	tmpl, err = ParseTemplate("http://example.com/callback")
+	assert.NoError(t, err)
+	assert.Empty(t, tmpl.Expressions())
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	tests := []struct {
+		template string
+		offset   int
+		message  string
+	}{
+		{"http://example.com/{$request.body#/id", 19, "'{' is not closed by '}'"},
+		{"http://example.com/$request.body}", 32, "'}' is not opened by '{'"},
+		{"http://example.com/{$request.bdy}", 29, "unknown source 'bdy', expected header, query, path or body"},
+		{"http://example.com/{id}", 20, "expression must start with '$'"},
+	}
+	for _, tc := range tests {
+		_, err := ParseTemplate(tc.template)
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr, tc.template) {
+			assert.Equal(t, tc.template, syntaxErr.Expression)
+			assert.Equal(t, tc.offset, syntaxErr.Offset, tc.template)
+			assert.Equal(t, tc.message, syntaxErr.Message, tc.template)
+		}
+	}
+}
@@ -226,6 +226,7 @@ type SpecIndex struct {
 	externalSpecIndex                   map[string]*SpecIndex                         // create a primary index of all external specs and componentIds
 	refErrors                           []error                                       // errors when indexing references
 	operationParamErrors                []error                                       // errors when indexing parameters
+	runtimeExpressionErrors             []error                                       // errors when checking runtime expressions
 	allDescriptions                     []*DescriptionReference                       // every single description found in the spec.
 	allSummaries                        []*DescriptionReference                       // every single summary found in the spec.
 	allEnums                            []*EnumReference                              // every single enum found in the spec.
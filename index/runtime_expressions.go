@@ -0,0 +1,138 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/expressions"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// GetRuntimeExpressionErrors will return any errors found when checking the syntax of the runtime expressions used
+// by the parameters and request bodies of links, and the expressions of callbacks.
+func (index *SpecIndex) GetRuntimeExpressionErrors() []error {
+	return index.runtimeExpressionErrors
+}
+
+// scanRuntimeExpressions checks the syntax of every runtime expression in the links and callbacks of paths,
+// webhooks and components. References are not followed, referenced links and callbacks are checked where they are
+// defined.
+func (index *SpecIndex) scanRuntimeExpressions() {
+	if index.root == nil || len(index.root.Content) == 0 {
+		return
+	}
+	root := index.root.Content[0]
+	for _, section := range []string{"paths", "webhooks"} {
+		_, pathsNode := utils.FindKeyNodeTop(section, root.Content)
+		forEachEntry(pathsNode, func(key, value *yaml.Node) {
+			index.scanPathItemExpressions(fmt.Sprintf("$.%s.%s", section, key.Value), value)
+		})
+	}
+	_, components := utils.FindKeyNodeTop("components", root.Content)
+	if components == nil {
+		return
+	}
+	_, links := utils.FindKeyNodeTop("links", components.Content)
+	forEachEntry(links, func(key, value *yaml.Node) {
+		index.scanLinkExpressions(fmt.Sprintf("$.components.links.%s", key.Value), key.Value, value)
+	})
+	_, callbacks := utils.FindKeyNodeTop("callbacks", components.Content)
+	forEachEntry(callbacks, func(key, value *yaml.Node) {
+		index.scanCallbackExpressions(fmt.Sprintf("$.components.callbacks.%s", key.Value), value)
+	})
+	_, responses := utils.FindKeyNodeTop("responses", components.Content)
+	forEachEntry(responses, func(key, value *yaml.Node) {
+		index.scanResponseExpressions(fmt.Sprintf("$.components.responses.%s", key.Value), value)
+	})
+}
+
+func (index *SpecIndex) scanPathItemExpressions(path string, pathItem *yaml.Node) {
+	forEachEntry(pathItem, func(key, operation *yaml.Node) {
+		if !isHttpMethod(key.Value) {
+			return
+		}
+		opPath := fmt.Sprintf("%s.%s", path, key.Value)
+		_, responses := utils.FindKeyNodeTop("responses", operation.Content)
+		forEachEntry(responses, func(code, response *yaml.Node) {
+			index.scanResponseExpressions(fmt.Sprintf("%s.responses.%s", opPath, code.Value), response)
+		})
+		_, callbacks := utils.FindKeyNodeTop("callbacks", operation.Content)
+		forEachEntry(callbacks, func(name, callback *yaml.Node) {
+			index.scanCallbackExpressions(fmt.Sprintf("%s.callbacks.%s", opPath, name.Value), callback)
+		})
+	})
+}
+
+func (index *SpecIndex) scanResponseExpressions(path string, response *yaml.Node) {
+	_, links := utils.FindKeyNodeTop("links", response.Content)
+	forEachEntry(links, func(key, value *yaml.Node) {
+		index.scanLinkExpressions(fmt.Sprintf("%s.links.%s", path, key.Value), key.Value, value)
+	})
+}
+
+// scanLinkExpressions checks the parameters and request body of a link, values that start with '$' are runtime
+// expressions, and other strings are constants (or templates, if they embed runtime expressions).
+func (index *SpecIndex) scanLinkExpressions(path, name string, link *yaml.Node) {
+	check := func(path, what string, value *yaml.Node) {
+		if !utils.IsNodeStringValue(value) {
+			return
+		}
+		var err error
+		switch {
+		case expressions.IsExpression(value.Value):
+			_, err = expressions.Parse(value.Value)
+		case strings.Contains(value.Value, "{$"):
+			_, err = expressions.ParseTemplate(value.Value)
+		}
+		if err != nil {
+			index.addRuntimeExpressionError(path, value, fmt.Errorf("the %s of link '%s' (line %d, column %d) "+
+				"is invalid: %w", what, name, value.Line, value.Column, err))
+		}
+	}
+	_, parameters := utils.FindKeyNodeTop("parameters", link.Content)
+	forEachEntry(parameters, func(key, value *yaml.Node) {
+		check(fmt.Sprintf("%s.parameters.%s", path, key.Value), fmt.Sprintf("parameter '%s'", key.Value), value)
+	})
+	_, requestBody := utils.FindKeyNodeTop("requestBody", link.Content)
+	if requestBody != nil {
+		check(path+".requestBody", "request body", requestBody)
+	}
+}
+
+// scanCallbackExpressions checks the expressions of a callback, which are templates of the URLs of its path items.
+func (index *SpecIndex) scanCallbackExpressions(path string, callback *yaml.Node) {
+	forEachEntry(callback, func(key, pathItem *yaml.Node) {
+		if key.Value == "$ref" || strings.HasPrefix(key.Value, "x-") {
+			return
+		}
+		itemPath := fmt.Sprintf("%s.%s", path, key.Value)
+		if _, err := expressions.ParseTemplate(key.Value); err != nil {
+			index.addRuntimeExpressionError(itemPath, key, fmt.Errorf("the callback expression (line %d, column %d) "+
+				"is invalid: %w", key.Line, key.Column, err))
+		}
+		index.scanPathItemExpressions(itemPath, pathItem)
+	})
+}
+
+func (index *SpecIndex) addRuntimeExpressionError(path string, node *yaml.Node, err error) {
+	index.runtimeExpressionErrors = append(index.runtimeExpressionErrors, &IndexingError{
+		Err:  err,
+		Node: node,
+		Path: path,
+	})
+}
+
+// forEachEntry calls a func with the key and value of every entry of a map node, a node that is not a map is
+// skipped.
+func forEachEntry(node *yaml.Node, f func(key, value *yaml.Node)) {
+	if node == nil || !utils.IsNodeMap(node) {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		f(node.Content[i], node.Content[i+1])
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pb33f/libopenapi/expressions"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSpecIndex_GetRuntimeExpressionErrors(t *testing.T) {
+	yml := `openapi: 3.1.0
+paths:
+  /subscribe:
+    post:
+      responses:
+        "201":
+          links:
+            GetSubscription:
+              operationId: getSubscription
+              parameters:
+                id: $response.body#/id
+                region: eu
+                mine: $request.bdy#/id
+                url: '{$request.header.Host}/{$request.query.}'
+              requestBody: $request.body
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}':
+            post:
+              responses:
+                "200":
+                  links:
+                    Nested:
+                      requestBody: $reqest.body
+          '{$request.body#/callbackUrl':
+            $ref: '#/components/pathItems/Event'
+          x-internal: true
+webhooks:
+  newSubscription:
+    post:
+      callbacks:
+        onDone:
+          '$url}': {}
+components:
+  links:
+    Valid:
+      parameters:
+        id: $request.path.id
+        status: $statusCode
+  callbacks:
+    Invalid:
+      '{$request.body#user}': {}
+  responses:
+    Created:
+      links:
+        Invalid:
+          parameters:
+            id: $response.header.
+            count: 1`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &rootNode)
+	index := NewSpecIndexWithConfig(&rootNode, CreateOpenAPIIndexConfig())
+
+	errs := index.GetRuntimeExpressionErrors()
+	var paths, messages []string
+	for _, err := range errs {
+		var indexingErr *IndexingError
+		if assert.True(t, errors.As(err, &indexingErr)) {
+			paths = append(paths, indexingErr.Path)
+			messages = append(messages, indexingErr.Error())
+		}
+		var syntaxErr *expressions.SyntaxError
+		assert.True(t, errors.As(indexingErr.Err, &syntaxErr))
+	}
+	assert.Equal(t, []string{
+		"$.paths./subscribe.post.responses.201.links.GetSubscription.parameters.mine",
+		"$.paths./subscribe.post.responses.201.links.GetSubscription.parameters.url",
+		"$.paths./subscribe.post.callbacks.onEvent.{$request.body#/callbackUrl}.post.responses.200.links.Nested" +
+			".requestBody",
+		"$.paths./subscribe.post.callbacks.onEvent.{$request.body#/callbackUrl",
+		"$.webhooks.newSubscription.post.callbacks.onDone.$url}",
+		"$.components.callbacks.Invalid.{$request.body#user}",
+		"$.components.responses.Created.links.Invalid.parameters.id",
+	}, paths)
+	assert.Equal(t, "the parameter 'mine' of link 'GetSubscription' (line 13, column 23) is invalid: runtime "+
+		"expression '$request.bdy#/id' is invalid at offset 9: unknown source 'bdy#/id', expected header, query, "+
+		"path or body", messages[0])
+	assert.Equal(t, "the callback expression (line 25, column 11) is invalid: runtime expression "+
+		"'{$request.body#/callbackUrl' is invalid at offset 0: '{' is not closed by '}'", messages[3])
+	assert.Equal(t, 13, errs[0].(*IndexingError).Node.Line)
+}
+
+func TestSpecIndex_GetRuntimeExpressionErrors_None(t *testing.T) {
+	yml := `openapi: 3.1.0
+paths:
+  /subscribe:
+    post:
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}?id={$response.body#/id}': {}
+          'http://example.com/static': {}`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &rootNode)
+	index := NewSpecIndexWithConfig(&rootNode, CreateOpenAPIIndexConfig())
+	assert.Empty(t, index.GetRuntimeExpressionErrors())
+}
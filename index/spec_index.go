@@ -129,6 +129,7 @@ func (index *SpecIndex) BuildIndex() {
 	index.GetInlineDuplicateParamCount()
 	index.GetAllDescriptionsCount()
 	index.GetTotalTagsCount()
+	index.scanRuntimeExpressions()
 }
 
 // GetRootNode returns document root node.
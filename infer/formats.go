@@ -0,0 +1,31 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package infer
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// detectFormat returns the format of a string: date-time, uuid, email or uri, or an empty string if it does not
+// match any of them.
+func detectFormat(value string) string {
+	if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return "date-time"
+	}
+	if uuidPattern.MatchString(value) {
+		return "uuid"
+	}
+	if address, err := mail.ParseAddress(value); err == nil && address.Address == value {
+		return "email"
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return "uri"
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package infer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]string{
+		"2023-04-01T12:30:00Z":                 "date-time",
+		"2023-04-01T12:30:00.123+02:00":        "date-time",
+		"0b5f3e7c-9a1d-4c4e-8f4a-2d7e1c3b5a6f": "uuid",
+		"quarter.pounder@pb33f.io":             "email",
+		"https://pb33f.io/burgers?size=large":  "uri",
+		"2023-04-01":                           "",
+		"Quarter Pounder <qp@pb33f.io>":        "",
+		"burgers:cheese":                       "",
+		"/burgers/1":                           "",
+		"cheese":                               "",
+		"":                                     "",
+	}
+	for value, format := range tests {
+		assert.Equal(t, format, detectFormat(value), value)
+	}
+}
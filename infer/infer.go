@@ -0,0 +1,262 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package infer creates schemas from sample JSON payloads, so undocumented endpoints can be described from the
+// requests and responses they are seen to send.
+//
+// Samples are merged: objects have the properties of every sample, and properties that are present in every sample are
+// required. Integers and numbers are merged into numbers, values that are null in some samples have the null type (or
+// are nullable, for OpenAPI 3.0), and values of different types in different samples (or items of different types in an
+// array) are composed with oneOf. Strings have the format (date-time, uuid, email or uri) that every one of their
+// values matches, and can be proposed as an enum when they only have a few distinct values.
+package infer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Inferrer infers schemas from sample JSON payloads.
+type Inferrer struct {
+	enumLimit int
+	nullable  bool
+}
+
+// NewInferrer creates a new Inferrer, that does not propose enums and creates OpenAPI 3.1 schemas.
+func NewInferrer() *Inferrer {
+	return &Inferrer{}
+}
+
+// SetEnumLimit sets the number of distinct values that strings can have to be proposed as an enum, zero (the
+// default) never proposes enums. Strings are only proposed as an enum when at least one of their values repeats, and
+// when they do not have a format.
+func (i *Inferrer) SetEnumLimit(limit int) {
+	i.enumLimit = limit
+}
+
+// SetVersion sets the OpenAPI version of the document the schemas are for, such as "3.0" or "3.1.0" (the default).
+// OpenAPI 3.0 does not have the null type, so 3.0 schemas of values that can be null are nullable instead.
+func (i *Inferrer) SetVersion(version string) {
+	i.nullable = strings.HasPrefix(version, "3.0")
+}
+
+// Infer returns the schema of some sample JSON payloads, that every sample is valid against.
+func (i *Inferrer) Infer(samples ...[]byte) (*base.Schema, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("cannot infer a schema without any samples")
+	}
+	var values []any
+	for n, sample := range samples {
+		value, err := decode(sample)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode sample %d: %w", n, err)
+		}
+		values = append(values, value)
+	}
+	return i.InferValues(values...), nil
+}
+
+// InferValues returns the schema of some sample values, as decoded by encoding/json. Numbers can be float64 values,
+// or json.Number values (which are integers if they do not have a fraction or an exponent).
+func (i *Inferrer) InferValues(values ...any) *base.Schema {
+	s := new(shape)
+	for _, value := range values {
+		s.add(value)
+	}
+	return i.schema(s)
+}
+
+// decode decodes a single JSON value, keeping numbers as json.Number, so integers can be told from numbers.
+func decode(sample []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(sample))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("sample has data after its first value")
+	}
+	return value, nil
+}
+
+// shape collects the values seen in one place of the samples: the values themselves, the properties of an object
+// or the items of an array.
+type shape struct {
+	nulls      int
+	booleans   int
+	integers   int
+	numbers    int
+	strings    int
+	objects    int
+	arrays     int
+	values     map[string]int // the number of times every string value is seen.
+	formats    map[string]int // the number of strings that match every format.
+	properties map[string]*shape
+	present    map[string]int // the number of objects every property is present in.
+	items      *shape
+}
+
+func (s *shape) add(value any) {
+	switch v := value.(type) {
+	case nil:
+		s.nulls++
+	case bool:
+		s.booleans++
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			s.numbers++
+		} else {
+			s.integers++
+		}
+	case float64:
+		if v == math.Trunc(v) {
+			s.integers++
+		} else {
+			s.numbers++
+		}
+	case string:
+		s.strings++
+		if s.values == nil {
+			s.values, s.formats = make(map[string]int), make(map[string]int)
+		}
+		s.values[v]++
+		if format := detectFormat(v); format != "" {
+			s.formats[format]++
+		}
+	case map[string]any:
+		s.objects++
+		if s.properties == nil {
+			s.properties, s.present = make(map[string]*shape), make(map[string]int)
+		}
+		for name, property := range v {
+			if s.properties[name] == nil {
+				s.properties[name] = new(shape)
+			}
+			s.properties[name].add(property)
+			s.present[name]++
+		}
+	case []any:
+		s.arrays++
+		if s.items == nil {
+			s.items = new(shape)
+		}
+		for _, item := range v {
+			s.items.add(item)
+		}
+	}
+}
+
+// schema returns the schema of a shape. A shape with one type of value has the schema of that type (that is null as
+// well if it has been null), one with several types is oneOf the schema of each type.
+func (i *Inferrer) schema(s *shape) *base.Schema {
+	var variants []*base.Schema
+	if s.booleans > 0 {
+		variants = append(variants, &base.Schema{Type: []string{"boolean"}})
+	}
+	if s.numbers > 0 {
+		variants = append(variants, &base.Schema{Type: []string{"number"}})
+	} else if s.integers > 0 {
+		variants = append(variants, &base.Schema{Type: []string{"integer"}})
+	}
+	if s.strings > 0 {
+		variants = append(variants, i.stringSchema(s))
+	}
+	if s.objects > 0 {
+		variants = append(variants, i.objectSchema(s))
+	}
+	if s.arrays > 0 {
+		array := &base.Schema{Type: []string{"array"}}
+		if !s.items.empty() {
+			array.Items = &base.DynamicValue[*base.SchemaProxy, bool]{A: base.CreateSchemaProxy(i.schema(s.items))}
+		}
+		variants = append(variants, array)
+	}
+	switch {
+	case len(variants) == 0 && s.nulls > 0:
+		return i.null(&base.Schema{})
+	case len(variants) == 0:
+		return &base.Schema{}
+	case len(variants) == 1:
+		if s.nulls > 0 {
+			return i.null(variants[0])
+		}
+		return variants[0]
+	}
+	switch {
+	case s.nulls > 0 && i.nullable:
+		// only one variant is nullable, so null matches exactly one of them.
+		i.null(variants[0])
+	case s.nulls > 0:
+		variants = append(variants, &base.Schema{Type: []string{"null"}})
+	}
+	oneOf := &base.Schema{}
+	for _, variant := range variants {
+		oneOf.OneOf = append(oneOf.OneOf, base.CreateSchemaProxy(variant))
+	}
+	return oneOf
+}
+
+// null allows a schema to be null, with the null type or by making it nullable for OpenAPI 3.0.
+func (i *Inferrer) null(schema *base.Schema) *base.Schema {
+	if i.nullable {
+		nullable := true
+		schema.Nullable = &nullable
+		return schema
+	}
+	schema.Type = append(schema.Type, "null")
+	return schema
+}
+
+// stringSchema returns the schema of the strings of a shape, with the format that every string matches, or an enum
+// of its values if there are few enough of them.
+func (i *Inferrer) stringSchema(s *shape) *base.Schema {
+	schema := &base.Schema{Type: []string{"string"}}
+	for format, count := range s.formats {
+		if count == s.strings {
+			schema.Format = format
+			return schema
+		}
+	}
+	if len(s.values) > i.enumLimit || len(s.values) == s.strings {
+		return schema
+	}
+	var values []string
+	for value := range s.values {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		schema.Enum = append(schema.Enum, value)
+	}
+	return schema
+}
+
+// objectSchema returns the schema of the objects of a shape, requiring the properties present in every object.
+func (i *Inferrer) objectSchema(s *shape) *base.Schema {
+	schema := &base.Schema{Type: []string{"object"}}
+	if len(s.properties) == 0 {
+		return schema
+	}
+	schema.Properties = make(map[string]*base.SchemaProxy)
+	for name, property := range s.properties {
+		schema.Properties[name] = base.CreateSchemaProxy(i.schema(property))
+		if s.present[name] == s.objects {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// empty returns true if no values have been seen in a shape, such as the items of arrays that are always empty.
+func (s *shape) empty() bool {
+	return s.nulls+s.booleans+s.integers+s.numbers+s.strings+s.objects+s.arrays == 0
+}
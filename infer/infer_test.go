@@ -0,0 +1,209 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package infer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+var burgerSamples = [][]byte{
+	[]byte(`{"id": "0b5f3e7c-9a1d-4c4e-8f4a-2d7e1c3b5a6f", "name": "Quarter Pounder", "price": 5,
+		"createdAt": "2023-04-01T12:30:00Z", "size": "large", "toppings": ["cheese", "pickles"], "chef": null}`),
+	[]byte(`{"id": "6f0a2c1e-3b4d-4e5f-9a8b-7c6d5e4f3a2b", "name": "Cheeseburger", "price": 4.5,
+		"createdAt": "2023-04-02T08:00:00Z", "size": "small", "toppings": [], "chef": "chef@pb33f.io"}`),
+	[]byte(`{"id": "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d", "name": "Big Burger", "price": 7,
+		"createdAt": "2023-04-03T18:45:00Z", "size": "large", "reviews": [{"stars": 5}, {"stars": 4, "text": "yum"}]}`),
+}
+
+func TestInferrer_Infer(t *testing.T) {
+	schema, err := NewInferrer().Infer(burgerSamples...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, schema.Type)
+	assert.Equal(t, []string{"createdAt", "id", "name", "price", "size"}, schema.Required)
+	assert.Len(t, schema.Properties, 8)
+
+	id := schema.Properties["id"].Schema()
+	assert.Equal(t, []string{"string"}, id.Type)
+	assert.Equal(t, "uuid", id.Format)
+	assert.Equal(t, "date-time", schema.Properties["createdAt"].Schema().Format)
+	assert.Empty(t, schema.Properties["name"].Schema().Format)
+
+	// integers and numbers are merged into numbers.
+	assert.Equal(t, []string{"number"}, schema.Properties["price"].Schema().Type)
+
+	// enums are not proposed by default.
+	assert.Nil(t, schema.Properties["size"].Schema().Enum)
+
+	toppings := schema.Properties["toppings"].Schema()
+	assert.Equal(t, []string{"array"}, toppings.Type)
+	assert.Equal(t, []string{"string"}, toppings.Items.A.Schema().Type)
+
+	chef := schema.Properties["chef"].Schema()
+	assert.Equal(t, []string{"string", "null"}, chef.Type)
+	assert.Equal(t, "email", chef.Format)
+
+	review := schema.Properties["reviews"].Schema().Items.A.Schema()
+	assert.Equal(t, []string{"stars"}, review.Required)
+	assert.Equal(t, []string{"integer"}, review.Properties["stars"].Schema().Type)
+	assert.Equal(t, []string{"string"}, review.Properties["text"].Schema().Type)
+}
+
+func TestInferrer_SetEnumLimit(t *testing.T) {
+	i := NewInferrer()
+	i.SetEnumLimit(3)
+	schema, err := i.Infer(burgerSamples...)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"large", "small"}, schema.Properties["size"].Schema().Enum)
+
+	// values that never repeat are not an enum, and neither are strings with a format.
+	assert.Nil(t, schema.Properties["name"].Schema().Enum)
+	assert.Nil(t, schema.Properties["id"].Schema().Enum)
+
+	i.SetEnumLimit(1)
+	schema, err = i.Infer(burgerSamples...)
+	assert.NoError(t, err)
+	assert.Nil(t, schema.Properties["size"].Schema().Enum)
+}
+
+func TestInferrer_Infer_OneOf(t *testing.T) {
+	schema, err := NewInferrer().Infer([]byte(`[1, "two", {"three": 3}, [4], true, null]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"array"}, schema.Type)
+	items := schema.Items.A.Schema()
+	assert.Empty(t, items.Type)
+
+	var types []string
+	for _, variant := range items.OneOf {
+		types = append(types, variant.Schema().Type...)
+	}
+	assert.Equal(t, []string{"boolean", "integer", "string", "object", "array", "null"}, types)
+
+	// values of different types in different samples are composed with oneOf as well.
+	schema, err = NewInferrer().Infer([]byte(`{"size": 1}`), []byte(`{"size": "large"}`))
+	assert.NoError(t, err)
+	assert.Len(t, schema.Properties["size"].Schema().OneOf, 2)
+}
+
+func TestInferrer_Infer_Empty(t *testing.T) {
+	schema, err := NewInferrer().Infer([]byte(`[]`), []byte(`{}`), []byte(`null`))
+	assert.NoError(t, err)
+	assert.Len(t, schema.OneOf, 3)
+	assert.Nil(t, schema.OneOf[0].Schema().Properties)
+	assert.Nil(t, schema.OneOf[1].Schema().Items)
+
+	schema, err = NewInferrer().Infer([]byte(`null`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"null"}, schema.Type)
+
+	schema, err = NewInferrer().Infer([]byte(`[[], []]`))
+	assert.NoError(t, err)
+	assert.Nil(t, schema.Items.A.Schema().Items)
+}
+
+func TestInferrer_SetVersion(t *testing.T) {
+	i := NewInferrer()
+	i.SetVersion("3.0.3")
+	schema, err := i.Infer(burgerSamples...)
+	assert.NoError(t, err)
+	chef := schema.Properties["chef"].Schema()
+	assert.Equal(t, []string{"string"}, chef.Type)
+	assert.True(t, *chef.Nullable)
+
+	schema, err = i.Infer([]byte(`[1, "two", null]`))
+	assert.NoError(t, err)
+	items := schema.Items.A.Schema()
+	assert.Len(t, items.OneOf, 2)
+	assert.True(t, *items.OneOf[0].Schema().Nullable)
+	assert.Nil(t, items.OneOf[1].Schema().Nullable)
+
+	schema, err = i.Infer([]byte(`null`))
+	assert.NoError(t, err)
+	assert.Empty(t, schema.Type)
+	assert.True(t, *schema.Nullable)
+
+	i.SetVersion("3.1")
+	schema, _ = i.Infer([]byte(`null`))
+	assert.Equal(t, []string{"null"}, schema.Type)
+	assert.Nil(t, schema.Nullable)
+}
+
+func TestInferrer_Infer_Errors(t *testing.T) {
+	_, err := NewInferrer().Infer()
+	assert.EqualError(t, err, "cannot infer a schema without any samples")
+
+	_, err = NewInferrer().Infer([]byte(`{}`), []byte(`{"name":`))
+	assert.EqualError(t, err, "unable to decode sample 1: unexpected EOF")
+
+	_, err = NewInferrer().Infer([]byte(`{} {}`))
+	assert.EqualError(t, err, "unable to decode sample 0: sample has data after its first value")
+}
+
+func TestInferrer_InferValues(t *testing.T) {
+	var values []any
+	for _, sample := range []string{`{"stars": 5}`, `{"stars": 4.5}`, `{"stars": 3}`} {
+		var value any
+		assert.NoError(t, json.Unmarshal([]byte(sample), &value))
+		values = append(values, value)
+	}
+	schema := NewInferrer().InferValues(values...)
+	assert.Equal(t, []string{"number"}, schema.Properties["stars"].Schema().Type)
+
+	schema = NewInferrer().InferValues(float64(5), json.Number("4"))
+	assert.Equal(t, []string{"integer"}, schema.Type)
+}
+
+func TestInferrer_Render(t *testing.T) {
+	i := NewInferrer()
+	i.SetEnumLimit(2)
+	schema, err := i.Infer(
+		[]byte(`{"name": "Quarter Pounder", "size": "large", "tags": ["beef"], "url": "https://pb33f.io/qp"}`),
+		[]byte(`{"name": "Cheeseburger", "size": "large", "tags": [1]}`))
+	assert.NoError(t, err)
+
+	rendered, err := schema.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `type: object
+properties:
+    name:
+        type: string
+    size:
+        type: string
+        enum:
+            - large
+    tags:
+        type: array
+        items:
+            oneOf:
+                - type: integer
+                - type: string
+    url:
+        type: string
+        format: uri
+required:
+    - name
+    - size
+    - tags`, strings.TrimSpace(string(rendered)))
+
+	doc := &v3.Document{
+		Version: "3.1.0",
+		Info:    &base.Info{Title: "Burgers", Version: "1.0"},
+		Components: &v3.Components{Schemas: map[string]*base.SchemaProxy{
+			"Burger": base.CreateSchemaProxy(schema),
+		}},
+	}
+	rendered, err = doc.Render()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rendered), `components:
+    schemas:
+        Burger:
+            type: object
+            properties:
+                name:`)
+}
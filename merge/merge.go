@@ -0,0 +1,353 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package merge provides a three-way merge of OpenAPI and Swagger documents.
+//
+// Rather than merging the text of a specification line by line (which constantly conflicts on structure), the
+// yaml.Node trees of a common base and two edited versions ('ours' and 'theirs') are merged structurally. Changes
+// made by only one side are applied automatically, and changes made by both sides to the same location are
+// reported as conflicts, identified by a JSON Pointer and holding the value from each side.
+//
+// Objects that have been renamed or moved (as detected by what-changed) are followed, so a schema that was renamed
+// by one side and modified by the other, is merged as a renamed and modified schema.
+package merge
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/patch"
+	"github.com/pb33f/libopenapi/what-changed/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict represents a location in a document that was changed by both sides of a merge in different ways.
+// When a conflict is found, the value from 'ours' is kept in the merged document.
+type Conflict struct {
+	Path   string     // JSON Pointer of the conflicting value in the merged document, or in theirs if ours removed it.
+	Base   *yaml.Node // value in the base document, nil if the value did not exist.
+	Ours   *yaml.Node // value in our document, nil if the value was removed.
+	Theirs *yaml.Node // value in their document, nil if the value was removed.
+}
+
+// Error returns a description of the conflict, so a Conflict can be used as an error.
+func (c *Conflict) Error() string {
+	return fmt.Sprintf("merge conflict at '%s': ours (%s), theirs (%s)", c.Path, describe(c.Ours), describe(c.Theirs))
+}
+
+func describe(n *yaml.Node) string {
+	if n == nil {
+		return "removed"
+	}
+	b, _ := patch.MarshalNodeJSON(n)
+	if len(b) > 60 {
+		return string(b[:57]) + "..."
+	}
+	return string(b)
+}
+
+// Documents will perform a three-way merge of the yaml.Node trees of a base document and two documents that
+// were both edited from that base. The changes (as found by what-changed) between base and each side are used
+// to follow anything renamed or moved, either set of changes can be nil.
+//
+// The merged tree is returned along with any conflicts found. None of the supplied trees are modified.
+func Documents(base, ours, theirs *yaml.Node, oursChanges, theirsChanges []*model.Change) (*yaml.Node, []*Conflict) {
+	m := &merger{}
+	b := patch.CopyNode(base)
+	o := patch.CopyNode(ours)
+	t := patch.CopyNode(theirs)
+
+	oursMoves := moves(patch.FromChanges(oursChanges, base, ours))
+	theirsMoves := moves(patch.FromChanges(theirsChanges, base, theirs))
+
+	// align every tree with the moves made by each side, so moved objects are compared with each other.
+	for _, mv := range oursMoves {
+		if other := findMove(theirsMoves, mv.From); other != nil && other.Path != mv.Path {
+			// ours is kept, so the conflict is where ours moved the value to.
+			m.conflict(mv.Path, find(base, mv.From), find(ours, mv.Path), find(theirs, other.Path))
+			continue
+		}
+		applyMove(b, mv)
+		applyMove(t, mv)
+	}
+	for _, mv := range theirsMoves {
+		if other := findMove(oursMoves, mv.From); other != nil {
+			continue // either already applied, or a conflict.
+		}
+		applyMove(b, mv)
+		applyMove(o, mv)
+	}
+
+	merged := m.merge(unwrap(b), unwrap(o), unwrap(t), "")
+	if ours != nil && ours.Kind == yaml.DocumentNode {
+		doc := *ours
+		doc.Content = []*yaml.Node{merged}
+		return &doc, m.conflicts
+	}
+	return merged, m.conflicts
+}
+
+// Nodes will perform a three-way merge of any yaml.Node trees, without following renamed or moved objects.
+func Nodes(base, ours, theirs *yaml.Node) (*yaml.Node, []*Conflict) {
+	return Documents(base, ours, theirs, nil, nil)
+}
+
+func moves(p patch.Patch) patch.Patch {
+	var result patch.Patch
+	for _, op := range p {
+		if op.Op == patch.OpMove {
+			result = append(result, op)
+		}
+	}
+	return result
+}
+
+func findMove(p patch.Patch, from string) *patch.Operation {
+	for _, op := range p {
+		if op.From == from {
+			return op
+		}
+	}
+	return nil
+}
+
+// applyMove will move a value, as long as it exists and there is nothing at the destination.
+func applyMove(root *yaml.Node, mv *patch.Operation) {
+	if find(root, mv.From) == nil || find(root, mv.Path) != nil {
+		return
+	}
+	_ = patch.Patch{mv}.Apply(root)
+}
+
+func find(root *yaml.Node, pointer string) *yaml.Node {
+	if root == nil {
+		return nil
+	}
+	n, err := patch.Find(root, pointer)
+	if err != nil {
+		return nil
+	}
+	return n
+}
+
+func unwrap(n *yaml.Node) *yaml.Node {
+	if n != nil && n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return n.Content[0]
+	}
+	return n
+}
+
+type merger struct {
+	conflicts []*Conflict
+}
+
+func (m *merger) conflict(pointer string, base, ours, theirs *yaml.Node) {
+	m.conflicts = append(m.conflicts, &Conflict{Path: pointer, Base: base, Ours: ours, Theirs: theirs})
+}
+
+func equal(l, r *yaml.Node) bool {
+	if l == nil || r == nil {
+		return l == r
+	}
+	return patch.NodesEqual(l, r)
+}
+
+// merge returns the merged value of a location, nil means the value has been removed.
+func (m *merger) merge(b, o, t *yaml.Node, pointer string) *yaml.Node {
+	b, o, t = resolve(b), resolve(o), resolve(t)
+	switch {
+	case equal(o, t), equal(b, t):
+		return o
+	case equal(b, o):
+		return t
+	case o != nil && t != nil && o.Kind == yaml.MappingNode && t.Kind == yaml.MappingNode &&
+		(b == nil || b.Kind == yaml.MappingNode):
+		return m.mergeMappings(b, o, t, pointer)
+	case o != nil && t != nil && o.Kind == yaml.SequenceNode && t.Kind == yaml.SequenceNode &&
+		(b == nil || b.Kind == yaml.SequenceNode):
+		if merged := m.mergeSequences(b, o, t, pointer); merged != nil {
+			return merged
+		}
+	}
+	m.conflict(pointer, b, o, t)
+	return o
+}
+
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// entry is a key (or identity) and value from a mapping or sequence.
+type entry struct {
+	id    string
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// entries is an ordered set of entries, that can be looked up by identity.
+type entries struct {
+	list   []*entry
+	lookup map[string]*entry
+}
+
+func (e *entries) add(en *entry) bool {
+	if _, ok := e.lookup[en.id]; ok {
+		return false
+	}
+	e.list = append(e.list, en)
+	e.lookup[en.id] = en
+	return true
+}
+
+func mappingEntries(n *yaml.Node) *entries {
+	e := &entries{lookup: make(map[string]*entry)}
+	if n == nil {
+		return e
+	}
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		e.add(&entry{id: n.Content[i].Value, key: n.Content[i], value: n.Content[i+1]})
+	}
+	return e
+}
+
+// sequenceEntries identifies each element of a sequence. Objects with a 'name' (parameters, tags, headers) or
+// a '$ref' are identified by those values, everything else is identified by its content. If two elements share
+// an identity, nil is returned.
+func sequenceEntries(n *yaml.Node) *entries {
+	e := &entries{lookup: make(map[string]*entry)}
+	if n == nil {
+		return e
+	}
+	for _, v := range n.Content {
+		if !e.add(&entry{id: identity(resolve(v)), value: v}) {
+			return nil
+		}
+	}
+	return e
+}
+
+func identity(n *yaml.Node) string {
+	if n != nil && n.Kind == yaml.MappingNode {
+		var name, in, ref string
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			switch n.Content[i].Value {
+			case "name":
+				name = n.Content[i+1].Value
+			case "in":
+				in = n.Content[i+1].Value
+			case "$ref":
+				ref = n.Content[i+1].Value
+			}
+		}
+		if ref != "" {
+			return "$ref:" + ref
+		}
+		if name != "" {
+			return "name:" + name + ":" + in
+		}
+	}
+	b, _ := patch.MarshalNodeJSON(n)
+	return string(b)
+}
+
+// mergeEntries merges two ordered sets of entries against a base set. The order of 'ours' is kept, and anything
+// added by 'theirs' is placed after the entry that precedes it in 'theirs'. The path function returns the
+// pointer of an entry, given its position in the merged entries (or -1 if it's not in the merged entries).
+func (m *merger) mergeEntries(b, o, t *entries, path func(e *entry, index int) string) []*entry {
+	var result []*entry
+	for _, oe := range o.list {
+		be := b.lookup[oe.id]
+		te := t.lookup[oe.id]
+		switch {
+		case te != nil:
+			var bv *yaml.Node
+			if be != nil {
+				bv = be.value
+			}
+			merged := m.merge(bv, oe.value, te.value, path(oe, len(result)))
+			result = append(result, &entry{id: oe.id, key: oe.key, value: merged})
+		case be != nil:
+			// removed by theirs.
+			if !equal(resolve(be.value), resolve(oe.value)) {
+				m.conflict(path(oe, len(result)), be.value, oe.value, nil)
+				result = append(result, oe)
+			}
+		default:
+			// added by ours.
+			result = append(result, oe)
+		}
+	}
+	for i, te := range t.list {
+		if o.lookup[te.id] != nil {
+			continue
+		}
+		if be := b.lookup[te.id]; be != nil {
+			// removed by ours.
+			if !equal(resolve(be.value), resolve(te.value)) {
+				m.conflict(path(te, -1), be.value, nil, te.value)
+			}
+			continue
+		}
+		// added by theirs, insert after whatever precedes it (and anything ours added in the same place).
+		pos := len(result)
+		if i == 0 {
+			pos = 0
+		} else {
+			for j, re := range result {
+				if re.id == t.list[i-1].id {
+					pos = j + 1
+					break
+				}
+			}
+		}
+		for pos < len(result) && b.lookup[result[pos].id] == nil && t.lookup[result[pos].id] == nil {
+			pos++
+		}
+		result = append(result, nil)
+		copy(result[pos+1:], result[pos:])
+		result[pos] = te
+	}
+	return result
+}
+
+func (m *merger) mergeMappings(b, o, t *yaml.Node, pointer string) *yaml.Node {
+	result := m.mergeEntries(mappingEntries(b), mappingEntries(o), mappingEntries(t), func(e *entry, _ int) string {
+		return pointer + "/" + patch.EscapeToken(e.id)
+	})
+	merged := *o
+	merged.Content = make([]*yaml.Node, 0, len(result)*2)
+	for _, e := range result {
+		merged.Content = append(merged.Content, e.key, e.value)
+	}
+	return &merged
+}
+
+// mergeSequences merges elements by identity, nil is returned if the elements of any sequence cannot be
+// identified, which means the whole sequence is treated as a single value.
+func (m *merger) mergeSequences(b, o, t *yaml.Node, pointer string) *yaml.Node {
+	be, oe, te := sequenceEntries(b), sequenceEntries(o), sequenceEntries(t)
+	if be == nil || oe == nil || te == nil {
+		return nil
+	}
+	result := m.mergeEntries(be, oe, te, func(e *entry, index int) string {
+		if index < 0 {
+			for i, en := range te.list {
+				if en == e {
+					index = i
+				}
+			}
+		}
+		return fmt.Sprintf("%s/%d", pointer, index)
+	})
+	merged := *o
+	merged.Content = make([]*yaml.Node, 0, len(result))
+	for _, e := range result {
+		merged.Content = append(merged.Content, e.value)
+	}
+	return &merged
+}
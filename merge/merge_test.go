@@ -0,0 +1,239 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package merge
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/what-changed/model"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func parse(t *testing.T, s string) *yaml.Node {
+	var n yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(s), &n))
+	return &n
+}
+
+func render(t *testing.T, n *yaml.Node) string {
+	b, err := yaml.Marshal(n)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestNodes_NonOverlapping(t *testing.T) {
+	base := parse(t, `info:
+  title: burgers
+  version: 1.0.0
+tags:
+  - name: burgers
+  - name: fries`)
+	ours := parse(t, `info:
+  title: burger shop # renamed
+  version: 1.0.0
+tags:
+  - name: burgers
+    description: tasty
+  - name: fries
+  - name: drinks`)
+	theirs := parse(t, `info:
+  title: burgers
+  version: 1.1.0
+  contact:
+    name: dave
+tags:
+  - name: sides
+  - name: burgers
+  - name: fries`)
+
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, `info:
+    title: burger shop # renamed
+    version: 1.1.0
+    contact:
+        name: dave
+tags:
+    - name: sides
+    - name: burgers
+      description: tasty
+    - name: fries
+    - name: drinks
+`, render(t, merged))
+}
+
+func TestNodes_Removals(t *testing.T) {
+	base := parse(t, `a: 1
+b: 2
+c: [x, y, z]`)
+	ours := parse(t, `a: 1
+c: [x, z]`)
+	theirs := parse(t, `a: 1
+b: 2
+c: [x, y]`)
+
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "a: 1\nc: [x]\n", render(t, merged))
+}
+
+func TestNodes_Conflicts(t *testing.T) {
+	base := parse(t, `info:
+  title: burgers
+  description: all about burgers
+paths:
+  /burgers:
+    get:
+      summary: get burgers`)
+	ours := parse(t, `info:
+  title: burger shop
+paths:
+  /burgers:
+    get:
+      summary: get all burgers`)
+	theirs := parse(t, `info:
+  title: burger emporium
+  description: all about burgers and fries
+paths: {}`)
+
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Len(t, conflicts, 3)
+
+	assert.Equal(t, "/info/title", conflicts[0].Path)
+	assert.Equal(t, "burgers", conflicts[0].Base.Value)
+	assert.Equal(t, "burger shop", conflicts[0].Ours.Value)
+	assert.Equal(t, "burger emporium", conflicts[0].Theirs.Value)
+	assert.Equal(t, `merge conflict at '/info/title': ours ("burger shop"), theirs ("burger emporium")`,
+		conflicts[0].Error())
+
+	assert.Equal(t, "/info/description", conflicts[1].Path)
+	assert.Nil(t, conflicts[1].Ours)
+	assert.Equal(t, "all about burgers and fries", conflicts[1].Theirs.Value)
+
+	assert.Equal(t, "/paths/~1burgers", conflicts[2].Path)
+	assert.NotNil(t, conflicts[2].Ours)
+	assert.Nil(t, conflicts[2].Theirs)
+	assert.Contains(t, conflicts[2].Error(), "theirs (removed)")
+
+	// ours wins.
+	assert.Equal(t, `info:
+    title: burger shop
+paths:
+    /burgers:
+        get:
+            summary: get all burgers
+`, render(t, merged))
+}
+
+func TestNodes_BothAdded(t *testing.T) {
+	base := parse(t, `a: 1`)
+	ours := parse(t, `a: 1
+b: {c: 1, d: 2}`)
+	theirs := parse(t, `a: 1
+b: {c: 1, e: 3}`)
+
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "a: 1\nb: {c: 1, d: 2, e: 3}\n", render(t, merged))
+}
+
+func TestNodes_KindChange(t *testing.T) {
+	base := parse(t, `a: 1`)
+	ours := parse(t, `a: [1]`)
+	theirs := parse(t, `a: {b: 1}`)
+
+	_, conflicts := Nodes(base, ours, theirs)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "/a", conflicts[0].Path)
+}
+
+func TestNodes_RemovedByOursConflict(t *testing.T) {
+	base := parse(t, `tags: [{name: a}, {name: b, description: x}]`)
+	ours := parse(t, `tags: [{name: a}]`)
+	theirs := parse(t, `tags: [{name: z}, {name: a}, {name: b, description: y}]`)
+
+	// the value removed by ours is not in the merged document, so the conflict points to it in theirs.
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "/tags/2", conflicts[0].Path)
+	assert.Nil(t, conflicts[0].Ours)
+	assert.Equal(t, "tags: [{name: z}, {name: a}]\n", render(t, merged))
+}
+
+func TestNodes_DuplicateSequenceElements(t *testing.T) {
+	base := parse(t, `a: [1, 1]`)
+	ours := parse(t, `a: [1, 1, 2]`)
+	theirs := parse(t, `a: [1, 1, 3]`)
+
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "/a", conflicts[0].Path)
+	assert.Equal(t, "a: [1, 1, 2]\n", render(t, merged))
+}
+
+func TestNodes_NotDocuments(t *testing.T) {
+	base := parse(t, `a: 1`).Content[0]
+	ours := parse(t, `a: 2`).Content[0]
+	theirs := parse(t, `a: 1`).Content[0]
+	merged, conflicts := Nodes(base, ours, theirs)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, yaml.MappingNode, merged.Kind)
+	assert.Equal(t, "2", merged.Content[1].Value)
+}
+
+func TestDocuments_FollowsMoves(t *testing.T) {
+	base := parse(t, `schemas:
+  Pet:
+    type: object
+    description: a pet`)
+	ours := parse(t, `schemas:
+  Animal:
+    type: object
+    description: a pet`)
+	theirs := parse(t, `schemas:
+  Pet:
+    type: object
+    description: a lovely pet`)
+
+	pet := base.Content[0].Content[1].Content[1]
+	animal := ours.Content[0].Content[1].Content[1]
+	oursChanges := []*model.Change{{ChangeType: model.Renamed, Context: model.CreateContext(pet, animal)}}
+
+	merged, conflicts := Documents(base, ours, theirs, oursChanges, nil)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, `schemas:
+    Animal:
+        type: object
+        description: a lovely pet
+`, render(t, merged))
+
+	// without following the rename, the modification conflicts with the removal.
+	_, conflicts = Nodes(base, ours, theirs)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "/schemas/Pet", conflicts[0].Path)
+}
+
+func TestDocuments_ConflictingMoves(t *testing.T) {
+	base := parse(t, `schemas:
+  Pet:
+    type: object`)
+	ours := parse(t, `schemas:
+  Animal:
+    type: object`)
+	theirs := parse(t, `schemas:
+  Creature:
+    type: object`)
+
+	pet := base.Content[0].Content[1].Content[1]
+	oursChanges := []*model.Change{{ChangeType: model.Renamed,
+		Context: model.CreateContext(pet, ours.Content[0].Content[1].Content[1])}}
+	theirsChanges := []*model.Change{{ChangeType: model.Renamed,
+		Context: model.CreateContext(pet, theirs.Content[0].Content[1].Content[1])}}
+
+	merged, conflicts := Documents(base, ours, theirs, oursChanges, theirsChanges)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "/schemas/Animal", conflicts[0].Path)
+	assert.Equal(t, "schemas:\n    Animal:\n        type: object\n    Creature:\n        type: object\n", render(t, merged))
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package mock
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/renderer"
+)
+
+// preferences are the mock preferences set by a Prefer header, for example 'Prefer: code=404, example=notFound'.
+type preferences struct {
+	code    string
+	example string
+}
+
+func parsePrefer(header http.Header) preferences {
+	var p preferences
+	for _, value := range header.Values("Prefer") {
+		for _, token := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+			k, v, found := strings.Cut(strings.TrimSpace(token), "=")
+			if !found {
+				continue
+			}
+			v = strings.Trim(strings.TrimSpace(v), `"`)
+			switch strings.ToLower(strings.TrimSpace(k)) {
+			case "code":
+				p.code = v
+			case "example":
+				p.example = v
+			}
+		}
+	}
+	return p
+}
+
+// acceptRange is a media range from an Accept header, with its quality.
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qv, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(qv, 64); err == nil {
+				q = f
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	return ranges
+}
+
+// matchesRange returns true if a media type (from the document) is matched by a media range (from the request).
+// Either side may contain wildcards, such as 'application/*'.
+func matchesRange(mediaType, accepted string) bool {
+	if mt, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = mt
+	}
+	if accepted == "*/*" || mediaType == "*/*" || strings.EqualFold(mediaType, accepted) {
+		return true
+	}
+	mType, mSub, _ := strings.Cut(mediaType, "/")
+	aType, aSub, _ := strings.Cut(accepted, "/")
+	return strings.EqualFold(mType, aType) && (mSub == "*" || aSub == "*")
+}
+
+// negotiate chooses which media type of a response to return, based on the Accept header. JSON is preferred when
+// the client accepts anything. An empty string is returned if nothing is acceptable.
+func negotiate(mediaTypes []string, accept string) string {
+	if len(mediaTypes) == 0 {
+		return ""
+	}
+	sort.Strings(mediaTypes)
+	ranges := parseAccept(accept)
+	if strings.TrimSpace(accept) == "" || len(ranges) == 0 {
+		ranges = []acceptRange{{mediaType: "*/*", quality: 1}}
+	}
+	for _, r := range ranges {
+		if r.quality <= 0 {
+			continue
+		}
+		if r.mediaType == "*/*" {
+			for _, mt := range mediaTypes {
+				if isJSON(mt) {
+					return mt
+				}
+			}
+			return mediaTypes[0]
+		}
+		for _, mt := range mediaTypes {
+			if matchesRange(mt, r.mediaType) {
+				return mt
+			}
+		}
+	}
+	return ""
+}
+
+func isJSON(mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = mediaType
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+func isYAML(mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = mediaType
+	}
+	return strings.HasSuffix(mt, "/yaml") || strings.HasSuffix(mt, "/x-yaml") || strings.HasSuffix(mt, "+yaml")
+}
+
+// mockType returns the renderer mock type of an XML, form or multipart media type. Bodies of these media types are
+// encoded by a renderer.MockGenerator, using the XML objects of the schema and the encoding of the media type.
+func mockType(mediaType string) (renderer.MockType, bool) {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = mediaType
+	}
+	switch {
+	case strings.HasSuffix(mt, "/xml") || strings.HasSuffix(mt, "+xml"):
+		return renderer.XML, true
+	case mt == "application/x-www-form-urlencoded":
+		return renderer.FormURLEncoded, true
+	case strings.HasPrefix(mt, "multipart/") && mt != "multipart/*":
+		return renderer.Multipart, true
+	}
+	return 0, false
+}
+
+// encoders encode XML, form and multipart bodies, with a mock generator for each type. Like the generators, encoders
+// must not be used by more than one goroutine at a time.
+type encoders map[renderer.MockType]*renderer.MockGenerator
+
+func newEncoders() encoders {
+	return encoders{
+		renderer.XML:            renderer.NewMockGenerator(renderer.XML),
+		renderer.FormURLEncoded: renderer.NewMockGenerator(renderer.FormURLEncoded),
+		renderer.Multipart:      renderer.NewMockGenerator(renderer.Multipart),
+	}
+}
+
+// encode encodes the value of a body of a media type, returns false if the media type is not encoded by a mock
+// generator.
+func (e encoders) encode(value any, mediaType string, content *v3.MediaType) ([]byte, bool) {
+	mt, ok := mockType(mediaType)
+	if !ok {
+		return nil, false
+	}
+	if value == nil {
+		return nil, true
+	}
+	mock := &v3.MediaType{Example: value}
+	if content != nil {
+		mock.Schema, mock.Encoding = content.Schema, content.Encoding
+	}
+	b, _ := e[mt].GenerateMock(mock, "")
+	return b, true
+}
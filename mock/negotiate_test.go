@@ -0,0 +1,43 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package mock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrefer(t *testing.T) {
+	h := http.Header{}
+	h.Add("Prefer", "return=minimal; code=404")
+	h.Add("Prefer", `example="notFound", broken`)
+	p := parsePrefer(h)
+	assert.Equal(t, "404", p.code)
+	assert.Equal(t, "notFound", p.example)
+}
+
+func TestNegotiate(t *testing.T) {
+	types := []string{"text/plain", "application/xml", "application/problem+json"}
+	assert.Equal(t, "application/problem+json", negotiate(types, ""))
+	assert.Equal(t, "application/problem+json", negotiate(types, "*/*"))
+	assert.Equal(t, "application/xml", negotiate(types, "application/xml"))
+	assert.Equal(t, "text/plain", negotiate(types, "application/xml;q=0.1, text/*"))
+	assert.Equal(t, "application/problem+json", negotiate(types, "application/xml;q=0, application/*"))
+	assert.Equal(t, "", negotiate(types, "image/png"))
+	assert.Equal(t, "", negotiate(nil, "*/*"))
+	assert.Equal(t, "text/plain", negotiate([]string{"text/plain"}, "*/*"))
+	assert.Equal(t, "text/*", negotiate([]string{"text/*"}, "text/html"))
+	assert.Equal(t, "application/json", negotiate([]string{"application/json"}, "bad;;, application/json"))
+}
+
+func TestMediaTypes(t *testing.T) {
+	assert.True(t, isJSON("application/json; charset=utf-8"))
+	assert.True(t, isJSON("application/vnd.burger+json"))
+	assert.False(t, isJSON("text/plain"))
+	assert.True(t, isYAML("application/x-yaml"))
+	assert.True(t, isYAML("application/vnd.burger+yaml"))
+	assert.False(t, isYAML("application/json"))
+}
@@ -0,0 +1,272 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/parameters"
+	"github.com/pb33f/libopenapi/renderer"
+	"github.com/pb33f/libopenapi/servers"
+	"gopkg.in/yaml.v3"
+)
+
+// templateParam matches a parameter of a path template.
+var templateParam = regexp.MustCompile(`{[^{}/]+}`)
+
+// DefaultBaseURL is the base URL of generated requests when no server is defined, relative server URLs are resolved
+// against it.
+const DefaultBaseURL = "http://localhost"
+
+// Request is a sample request for an operation, generated by a RequestGenerator.
+type Request struct {
+	Method  string
+	URL     string         // the full URL, with path parameters substituted and query parameters serialized.
+	Header  http.Header    // header parameters, and the Content-Type of the body.
+	Cookies []*http.Cookie // cookie parameters.
+	Body    []byte         // the body, serialized for the Content-Type, nil if there is no request body.
+}
+
+// RequestGenerator generates sample requests for operations, with values for every parameter and the request body.
+// Values come from the examples of each parameter and media type, otherwise they are rendered from the schema.
+// Use NewRequestGenerator to create a new RequestGenerator. Like the renderer it uses, a RequestGenerator must not be
+// used by more than one goroutine at a time.
+type RequestGenerator struct {
+	renderer  *renderer.SchemaRenderer
+	encoders  encoders
+	document  *v3.Document
+	baseURL   string
+	mediaType string
+}
+
+// NewRequestGenerator creates a new RequestGenerator, using the default dictionary to render values.
+func NewRequestGenerator() *RequestGenerator {
+	return &RequestGenerator{renderer: renderer.CreateRendererUsingDefaultDictionary(), encoders: newEncoders()}
+}
+
+// SetSeed seeds the values rendered from schemas, so the same operation always generates the same request.
+func (rg *RequestGenerator) SetSeed(seed int64) {
+	rg.renderer.SetSeed(seed)
+}
+
+// SetBaseURL sets the base URL of generated requests. Without a base URL, the first server the operation is served
+// from (see servers.Effective) is used.
+func (rg *RequestGenerator) SetBaseURL(baseURL string) {
+	rg.baseURL = baseURL
+}
+
+// SetDocument sets the document of the operations requests are generated for, so the servers of the document are
+// used when an operation and its path item do not define any.
+func (rg *RequestGenerator) SetDocument(document *v3.Document) {
+	rg.document = document
+}
+
+// SetMediaType sets the preferred media type of request bodies. If the request body does not support the media type,
+// then JSON is used, or the first media type (by name).
+func (rg *RequestGenerator) SetMediaType(mediaType string) {
+	rg.mediaType = mediaType
+}
+
+// GenerateRequest generates a sample request for an operation of a path item. The path is the path template the
+// path item is defined for, such as /burgers/{burgerId}. Parameters of the path item are included, unless the
+// operation overrides them.
+func (rg *RequestGenerator) GenerateRequest(path string, pathItem *v3.PathItem, operation *v3.Operation) (*Request, error) {
+	if pathItem == nil || operation == nil {
+		return nil, errors.New("a path item and an operation are required to generate a request")
+	}
+	method := ""
+	for m, op := range pathItem.GetOperations() {
+		if op == operation {
+			method = strings.ToUpper(m)
+		}
+	}
+	if method == "" {
+		return nil, fmt.Errorf("operation is not an operation of the path item for '%s'", path)
+	}
+
+	req := &Request{Method: method, Header: http.Header{}}
+	var query []string
+	for _, param := range parameters.Merge(pathItem.Parameters, operation.Parameters) {
+		value := rg.parameterValue(param)
+		codec := parameters.NewCodec(param)
+		switch param.In {
+		case parameters.InPath:
+			placeholder := "{" + param.Name + "}"
+			if !strings.Contains(path, placeholder) {
+				return nil, fmt.Errorf("path parameter '%s' is not in the path '%s'", param.Name, path)
+			}
+			path = strings.ReplaceAll(path, placeholder, codec.Serialize(value))
+		case parameters.InQuery:
+			query = append(query, codec.Serialize(value))
+		case parameters.InHeader:
+			// these headers are defined by the request, not parameters (as per the spec).
+			switch http.CanonicalHeaderKey(param.Name) {
+			case "Accept", "Content-Type", "Authorization":
+				continue
+			}
+			req.Header.Set(param.Name, codec.Serialize(value))
+		case parameters.InCookie:
+			req.Cookies = append(req.Cookies, &http.Cookie{Name: param.Name, Value: codec.Serialize(value)})
+		}
+	}
+	if loc := templateParam.FindString(path); loc != "" {
+		return nil, fmt.Errorf("path parameter '%s' is not defined", strings.Trim(loc, "{}"))
+	}
+
+	req.URL = strings.TrimSuffix(rg.base(pathItem, operation), "/") + path
+	if len(query) > 0 {
+		req.URL += "?" + strings.Join(query, "&")
+	}
+
+	if operation.RequestBody != nil && len(operation.RequestBody.Content) > 0 {
+		mediaTypes := make([]string, 0, len(operation.RequestBody.Content))
+		for mt := range operation.RequestBody.Content {
+			mediaTypes = append(mediaTypes, mt)
+		}
+		accept := rg.mediaType
+		if accept == "" {
+			accept = "*/*"
+		}
+		mediaType := negotiate(mediaTypes, accept)
+		if mediaType == "" {
+			mediaType = negotiate(mediaTypes, "*/*")
+		}
+		content := operation.RequestBody.Content[mediaType]
+		value := renderValue(rg.renderer, content.Example, content.Examples, content.Schema)
+		body, err := rg.serializeBody(value, mediaType, content)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+		req.Header.Set("Content-Type", contentType(mediaType))
+	}
+	return req, nil
+}
+
+// base returns the base URL for a request, with any server variables replaced by their defaults.
+func (rg *RequestGenerator) base(pathItem *v3.PathItem, operation *v3.Operation) string {
+	if rg.baseURL != "" {
+		return rg.baseURL
+	}
+	server := servers.Effective(rg.document, pathItem, operation)[0]
+	u, err := servers.Expand(server, nil)
+	if err != nil {
+		u = server.URL
+	}
+	defaultBase, _ := url.Parse(DefaultBaseURL)
+	if resolved, err := servers.Resolve(u, defaultBase); err == nil {
+		return resolved
+	}
+	return u
+}
+
+// parameterValue renders the value of a parameter, from its examples, schema or content.
+func (rg *RequestGenerator) parameterValue(param *v3.Parameter) any {
+	if param.Schema == nil && len(param.Content) > 0 {
+		mediaTypes := make([]string, 0, len(param.Content))
+		for mt := range param.Content {
+			mediaTypes = append(mediaTypes, mt)
+		}
+		sort.Strings(mediaTypes)
+		content := param.Content[mediaTypes[0]]
+		value := renderValue(rg.renderer, content.Example, content.Examples, content.Schema)
+		b, _ := json.Marshal(value)
+		return string(b)
+	}
+	return renderValue(rg.renderer, param.Example, param.Examples, param.Schema)
+}
+
+// serializeBody serializes a request body for a media type. XML, form and multipart bodies are encoded by a mock
+// generator, using the XML objects of the schema and the encoding of the media type.
+func (rg *RequestGenerator) serializeBody(value any, mediaType string, content *v3.MediaType) ([]byte, error) {
+	if b, ok := rg.encoders.encode(value, mediaType, content); ok {
+		return b, nil
+	}
+	switch {
+	case isYAML(mediaType):
+		return yaml.Marshal(value)
+	case isJSON(mediaType):
+		return json.Marshal(value)
+	}
+	switch v := value.(type) {
+	case map[string]any, []any:
+		return json.Marshal(v)
+	}
+	return []byte(primitive(value)), nil
+}
+
+func primitive(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
+
+// HTTPRequest returns the sample request as an *http.Request, ready to be sent by an http.Client.
+func (r *Request) HTTPRequest() (*http.Request, error) {
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+	req, err := http.NewRequest(r.Method, r.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for _, c := range r.Cookies {
+		req.AddCookie(c)
+	}
+	return req, nil
+}
+
+// Curl returns the sample request as a curl command.
+func (r *Request) Curl() string {
+	parts := []string{"curl", "-X", r.Method, shellQuote(r.URL)}
+	names := make([]string, 0, len(r.Header))
+	for name := range r.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range r.Header[name] {
+			parts = append(parts, "-H", shellQuote(name+": "+v))
+		}
+	}
+	if len(r.Cookies) > 0 {
+		cookies := make([]string, 0, len(r.Cookies))
+		for _, c := range r.Cookies {
+			cookies = append(cookies, c.Name+"="+c.Value)
+		}
+		parts = append(parts, "--cookie", shellQuote(strings.Join(cookies, "; ")))
+	}
+	if r.Body != nil {
+		parts = append(parts, "--data-raw", shellQuote(string(r.Body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes a string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,298 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package mock
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/renderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const burgerOrders = `openapi: 3.1.0
+info:
+  title: orders
+  version: 1.0.0
+paths:
+  /shops/{shopId}/orders/{orderId}:
+    servers:
+      - url: https://{region}.pb33f.io/api
+        variables:
+          region:
+            default: eu
+            enum: [eu, us]
+    parameters:
+      - name: shopId
+        in: path
+        required: true
+        schema:
+          type: integer
+        example: 42
+      - name: orderId
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      parameters:
+        - name: orderId
+          in: path
+          required: true
+          style: label
+          example: abc
+        - name: toppings
+          in: query
+          example: [cheese, pickles]
+        - name: filter
+          in: query
+          style: deepObject
+          example:
+            size: large
+        - name: X-Request-Id
+          in: header
+          example: 123-456
+        - name: Accept
+          in: header
+          example: text/plain
+        - name: session
+          in: cookie
+          example: s3cr3t
+        - name: where
+          in: query
+          content:
+            application/json:
+              example:
+                lat: 1
+      responses:
+        "200":
+          description: an order
+    put:
+      servers:
+        - url: https://orders.pb33f.io
+      requestBody:
+        content:
+          application/json:
+            example:
+              name: big mac
+              extras: [fries, shake]
+          application/x-www-form-urlencoded:
+            example:
+              name: big mac
+              extras: [fries, shake]
+            encoding:
+              extras:
+                style: pipeDelimited
+                explode: false
+          application/xml:
+            example:
+              name: big mac
+            schema:
+              type: object
+              xml:
+                name: order
+          multipart/form-data:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  const: whopper
+      responses:
+        "204":
+          description: updated
+  /orders/{orderId}:
+    delete:
+      responses:
+        "204":
+          description: deleted`
+
+func TestRequestGenerator_GenerateRequest(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerOrders))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	path := "/shops/{shopId}/orders/{orderId}"
+	pathItem := model.Model.Paths.PathItems[path]
+
+	rg := NewRequestGenerator()
+	req, err := rg.GenerateRequest(path, pathItem, pathItem.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, "https://eu.pb33f.io/api/shops/42/orders/.abc"+
+		"?toppings=cheese&toppings=pickles&filter%5Bsize%5D=large&where=%7B%22lat%22%3A1%7D", req.URL)
+	assert.Equal(t, "123-456", req.Header.Get("X-Request-Id"))
+	assert.Empty(t, req.Header.Get("Accept"))
+	assert.Len(t, req.Cookies, 1)
+	assert.Equal(t, "s3cr3t", req.Cookies[0].Value)
+	assert.Nil(t, req.Body)
+
+	rg.SetBaseURL("http://localhost:8080/")
+	req, _ = rg.GenerateRequest(path, pathItem, pathItem.Get)
+	assert.True(t, strings.HasPrefix(req.URL, "http://localhost:8080/shops/42/orders/.abc?"))
+}
+
+func TestRequestGenerator_GenerateRequest_Body(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerOrders))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	path := "/shops/{shopId}/orders/{orderId}"
+	pathItem := model.Model.Paths.PathItems[path]
+
+	rg := NewRequestGenerator()
+	rg.SetSeed(1)
+	req, err := rg.GenerateRequest(path, pathItem, pathItem.Put)
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT", req.Method)
+	assert.True(t, strings.HasPrefix(req.URL, "https://orders.pb33f.io/shops/42/orders/"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"big mac","extras":["fries","shake"]}`, string(req.Body))
+
+	rg.SetMediaType("application/x-www-form-urlencoded")
+	req, _ = rg.GenerateRequest(path, pathItem, pathItem.Put)
+	assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+	assert.Equal(t, "extras=fries|shake&name=big+mac", string(req.Body))
+
+	rg.SetMediaType("application/xml")
+	req, _ = rg.GenerateRequest(path, pathItem, pathItem.Put)
+	assert.Equal(t, "application/xml", req.Header.Get("Content-Type"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<order><name>big mac</name></order>", string(req.Body))
+
+	rg.SetMediaType("multipart/*")
+	req, _ = rg.GenerateRequest(path, pathItem, pathItem.Put)
+	assert.Equal(t, "multipart/form-data; boundary="+renderer.MultipartBoundary, req.Header.Get("Content-Type"))
+	assert.Contains(t, string(req.Body), "Content-Type: text/plain\r\n\r\nwhopper\r\n")
+
+	// unsupported media types fall back to JSON.
+	rg.SetMediaType("text/csv")
+	req, _ = rg.GenerateRequest(path, pathItem, pathItem.Put)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+}
+
+func TestRequestGenerator_GenerateRequest_Errors(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerOrders))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	rg := NewRequestGenerator()
+
+	_, err = rg.GenerateRequest("/burgers", nil, nil)
+	assert.Error(t, err)
+
+	orders := model.Model.Paths.PathItems["/orders/{orderId}"]
+	shops := model.Model.Paths.PathItems["/shops/{shopId}/orders/{orderId}"]
+	_, err = rg.GenerateRequest("/orders/{orderId}", orders, shops.Get)
+	assert.EqualError(t, err, "operation is not an operation of the path item for '/orders/{orderId}'")
+
+	_, err = rg.GenerateRequest("/orders/{orderId}", orders, orders.Delete)
+	assert.EqualError(t, err, "path parameter 'orderId' is not defined")
+
+	_, err = rg.GenerateRequest("/shops/{shopId}", shops, shops.Get)
+	assert.EqualError(t, err, "path parameter 'orderId' is not in the path '/shops/42'")
+}
+
+func TestRequestGenerator_DocumentServers(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.1.0
+info:
+  title: burgers
+  version: 1.0.0
+servers:
+  - url: /{version}
+    variables:
+      version:
+        default: v2
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: burgers`))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	pathItem := model.Model.Paths.PathItems["/burgers"]
+	rg := NewRequestGenerator()
+
+	req, err := rg.GenerateRequest("/burgers", pathItem, pathItem.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL+"/burgers", req.URL)
+
+	rg.SetDocument(&model.Model)
+	req, _ = rg.GenerateRequest("/burgers", pathItem, pathItem.Get)
+	assert.Equal(t, DefaultBaseURL+"/v2/burgers", req.URL)
+}
+
+func TestRequest_HTTPRequest(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerOrders))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	path := "/shops/{shopId}/orders/{orderId}"
+	pathItem := model.Model.Paths.PathItems[path]
+	rg := NewRequestGenerator()
+
+	req, _ := rg.GenerateRequest(path, pathItem, pathItem.Get)
+	httpReq, err := req.HTTPRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", httpReq.Method)
+	assert.Equal(t, "/api/shops/42/orders/.abc", httpReq.URL.Path)
+	assert.Equal(t, []string{"cheese", "pickles"}, httpReq.URL.Query()["toppings"])
+	assert.Equal(t, "large", httpReq.URL.Query().Get("filter[size]"))
+	assert.Equal(t, "123-456", httpReq.Header.Get("X-Request-Id"))
+	cookie, err := httpReq.Cookie("session")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", cookie.Value)
+
+	req, _ = rg.GenerateRequest(path, pathItem, pathItem.Put)
+	httpReq, _ = req.HTTPRequest()
+	var body map[string]any
+	b, _ := io.ReadAll(httpReq.Body)
+	assert.NoError(t, json.Unmarshal(b, &body))
+	assert.Equal(t, "big mac", body["name"])
+
+	_, err = (&Request{Method: "GET", URL: "://nope"}).HTTPRequest()
+	assert.Error(t, err)
+}
+
+func TestRequest_Curl(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerOrders))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	path := "/shops/{shopId}/orders/{orderId}"
+	pathItem := model.Model.Paths.PathItems[path]
+	rg := NewRequestGenerator()
+	rg.SetBaseURL("http://localhost")
+
+	req, _ := rg.GenerateRequest(path, pathItem, pathItem.Get)
+	assert.Equal(t, "curl -X GET 'http://localhost/shops/42/orders/.abc"+
+		"?toppings=cheese&toppings=pickles&filter%5Bsize%5D=large&where=%7B%22lat%22%3A1%7D'"+
+		" -H 'X-Request-Id: 123-456' --cookie 'session=s3cr3t'", req.Curl())
+
+	req = &Request{Method: "POST", URL: "http://localhost", Body: []byte(`{"name":"mac's"}`)}
+	assert.Equal(t, `curl -X POST 'http://localhost' --data-raw '{"name":"mac'\''s"}'`, req.Curl())
+}
+
+func TestRequestGenerator_BurgerShop(t *testing.T) {
+	spec, _ := os.ReadFile("../test_specs/burgershop.openapi.yaml")
+	doc, _ := libopenapi.NewDocument(spec)
+	model, _ := doc.BuildV3Model()
+
+	rg := NewRequestGenerator()
+	rg.SetSeed(1)
+	for path, pathItem := range model.Model.Paths.PathItems {
+		for _, op := range pathItem.GetOperations() {
+			req, err := rg.GenerateRequest(path, pathItem, op)
+			assert.NoError(t, err, path)
+			assert.NotContains(t, req.URL, "{")
+		}
+	}
+}
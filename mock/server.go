@@ -0,0 +1,370 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package mock provides a mock HTTP server, driven by an OpenAPI 3+ document.
+//
+// The server is an http.Handler that matches requests to the paths and operations of the document, and responds
+// with an example (or a mock rendered from the schema) of the response, so it can be used as a local stand-in for a
+// backend that does not exist yet. Clients can choose the response returned by sending an Accept header for the media
+// type, and a Prefer header for the status code and named example, for example:
+//
+//	Prefer: code=404, example=notFound
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/renderer"
+	"github.com/pb33f/libopenapi/router"
+	"gopkg.in/yaml.v3"
+)
+
+// Server is an http.Handler that responds to requests with mock responses, generated from an OpenAPI 3+ document.
+// Use NewServer to create a new Server. A Server can be used by multiple goroutines.
+type Server struct {
+	router   *router.Router
+	root     *router.Router // matches paths without the path of a server.
+	renderer *renderer.SchemaRenderer
+	encoders encoders
+	pretty   bool
+	lock     sync.Mutex
+}
+
+// NewServer creates a new mock Server from a v3 document model. Paths are matched by a router.Router, with and without
+// the path of the servers of each operation, so both /v1/burgers and /burgers will match /burgers when the server URL
+// is https://api.pb33f.io/v1. Paths that cannot be told apart (see router.NewRouter) are only matched by the first.
+func NewServer(model *libopenapi.DocumentModel[v3.Document]) *Server {
+	rt, _ := router.NewRouter(&model.Model)
+	root, _ := router.NewRouter(&v3.Document{Paths: rootPaths(model.Model.Paths)})
+	return &Server{
+		router:   rt,
+		root:     root,
+		renderer: renderer.CreateRendererUsingDefaultDictionary(),
+		encoders: newEncoders(),
+	}
+}
+
+// rootPaths returns a copy of paths without the servers of path items and operations, so every operation is served
+// from '/'.
+func rootPaths(paths *v3.Paths) *v3.Paths {
+	if paths == nil {
+		return nil
+	}
+	root := &v3.Paths{PathItems: make(map[string]*v3.PathItem, len(paths.PathItems))}
+	for template, pathItem := range paths.PathItems {
+		if pathItem == nil {
+			continue
+		}
+		pi := *pathItem
+		pi.Servers = nil
+		for _, op := range []**v3.Operation{&pi.Get, &pi.Put, &pi.Post, &pi.Delete, &pi.Options, &pi.Head, &pi.Patch,
+			&pi.Trace} {
+			if *op != nil {
+				o := **op
+				o.Servers = nil
+				*op = &o
+			}
+		}
+		root.PathItems[template] = &pi
+	}
+	return root
+}
+
+// SetSeed seeds the mocks rendered from schemas, so the same request always returns the same response.
+func (s *Server) SetSeed(seed int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.renderer.SetSeed(seed)
+}
+
+// SetPretty will render JSON and XML responses with indentation and newlines.
+func (s *Server) SetPretty() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pretty = true
+	s.encoders[renderer.XML].SetPretty()
+}
+
+// ServeHTTP responds to a request with a mock response. The response is chosen by status code (the Prefer code,
+// or the first successful response), then by media type (using the Accept header). The body is the example named
+// by Prefer, the example of the media type, or a mock rendered from the schema, in that order.
+//
+// Requests that do not match a path receive a 404, an operation that does not exist receives a 405, a response
+// without an acceptable media type receives a 406 and a Prefer code that does not exist receives a 400.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, err := s.router.FindRoute(r)
+	if err != nil {
+		// try again without the paths of the servers, a 405 is kept unless the path and method match there.
+		if rootRoute, rootErr := s.root.FindRoute(r); rootErr == nil || errors.Is(err, router.ErrPathNotFound) {
+			route, err = rootRoute, rootErr
+		}
+	}
+	if errors.Is(err, router.ErrPathNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no path matches '%s'", r.URL.Path))
+		return
+	}
+	if err != nil {
+		operations := route.PathItem.GetOperations()
+		allowed := make([]string, 0, len(operations))
+		for method := range operations {
+			allowed = append(allowed, strings.ToUpper(method))
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("method %s is not defined for path '%s'", r.Method, route.Template))
+		return
+	}
+	operation := route.Operation
+
+	prefer := parsePrefer(r.Header)
+	code, response, err := chooseResponse(operation.Responses, prefer.code)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mediaTypes := make([]string, 0, len(response.Content))
+	for mt := range response.Content {
+		mediaTypes = append(mediaTypes, mt)
+	}
+	mediaType := negotiate(mediaTypes, r.Header.Get("Accept"))
+	if len(mediaTypes) > 0 && mediaType == "" {
+		writeError(w, http.StatusNotAcceptable,
+			fmt.Sprintf("no response media type is acceptable, available types are: %s", strings.Join(mediaTypes, ", ")))
+		return
+	}
+
+	headers, body := s.render(response, mediaType, prefer.example)
+	for name, value := range headers {
+		w.Header().Set(name, value)
+	}
+	if mediaType == "" {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", contentType(mediaType))
+	w.WriteHeader(code)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+// render renders the headers and the body (for a media type) of a response. The lock is only held while rendering,
+// so a slow client does not block other requests.
+func (s *Server) render(response *v3.Response, mediaType, example string) (map[string]string, []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	headers := make(map[string]string)
+	for _, name := range sortedHeaders(response.Headers) {
+		if value := s.renderHeader(response.Headers[name]); value != "" {
+			headers[name] = value
+		}
+	}
+	if mediaType == "" {
+		return headers, nil
+	}
+	content := response.Content[mediaType]
+	return headers, s.marshal(s.renderBody(content, example), mediaType, content)
+}
+
+// chooseResponse returns the status code and response to return. If a code is preferred, then that response (or
+// the response of its range, like 4XX, or the default response) is returned. Otherwise, the lowest 2xx response is
+// returned, then the 2XX response as a 200, then the lowest response, then the lowest range (as its first code), then
+// the default response as a 200.
+func chooseResponse(responses *v3.Responses, preferred string) (int, *v3.Response, error) {
+	if responses == nil {
+		return http.StatusOK, &v3.Response{}, nil
+	}
+	if preferred != "" {
+		code, err := strconv.Atoi(preferred)
+		if err != nil || code < 100 || code > 599 {
+			return 0, nil, fmt.Errorf("preferred code '%s' is not a valid status code", preferred)
+		}
+		if response := responses.Codes[preferred]; response != nil {
+			return code, response, nil
+		}
+		if response := findRange(responses, code); response != nil {
+			return code, response, nil
+		}
+		if responses.Default != nil {
+			return code, responses.Default, nil
+		}
+		return 0, nil, fmt.Errorf("preferred code '%s' is not a response of this operation", preferred)
+	}
+
+	var codes, ranges []int
+	for c := range responses.Codes {
+		if code, err := strconv.Atoi(c); err == nil {
+			codes = append(codes, code)
+		} else if statusRange(c) > 0 {
+			ranges = append(ranges, statusRange(c))
+		}
+	}
+	sort.Ints(codes)
+	sort.Ints(ranges)
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, responses.Codes[strconv.Itoa(code)], nil
+		}
+	}
+	if response := findRange(responses, http.StatusOK); response != nil {
+		return http.StatusOK, response, nil
+	}
+	if len(codes) > 0 {
+		return codes[0], responses.Codes[strconv.Itoa(codes[0])], nil
+	}
+	if len(ranges) > 0 {
+		return ranges[0] * 100, findRange(responses, ranges[0]*100), nil
+	}
+	if responses.Default != nil {
+		return http.StatusOK, responses.Default, nil
+	}
+	return http.StatusOK, &v3.Response{}, nil
+}
+
+// statusRange returns the first digit of a status code range (like 2XX), zero if the code is not a range.
+func statusRange(code string) int {
+	if len(code) != 3 || !strings.EqualFold(code[1:], "XX") || code[0] < '1' || code[0] > '5' {
+		return 0
+	}
+	return int(code[0] - '0')
+}
+
+// findRange returns the response of the range (like 2XX) of a status code, nil if there isn't one.
+func findRange(responses *v3.Responses, status int) *v3.Response {
+	for code, response := range responses.Codes {
+		if statusRange(code) == status/100 {
+			return response
+		}
+	}
+	return nil
+}
+
+// renderBody renders the value of a response body, from the named example, the example, the first example (by
+// name) or the schema of the media type, in that order.
+func (s *Server) renderBody(mediaType *v3.MediaType, example string) any {
+	if mediaType == nil {
+		return nil
+	}
+	if exp := mediaType.Examples[example]; example != "" && exp != nil {
+		return exp.Value
+	}
+	return renderValue(s.renderer, mediaType.Example, mediaType.Examples, mediaType.Schema)
+}
+
+// renderHeader renders the value of a response header, from its examples or schema.
+func (s *Server) renderHeader(header *v3.Header) string {
+	if header == nil {
+		return ""
+	}
+	value := renderValue(s.renderer, header.Example, header.Examples, header.Schema)
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// renderValue returns the example, the first example (by name), or a value rendered from the schema, in that order.
+func renderValue(r *renderer.SchemaRenderer, example any, examples map[string]*base.Example, schema *base.SchemaProxy) any {
+	if example != nil {
+		return example
+	}
+	if exp := firstExample(examples); exp != nil {
+		return exp.Value
+	}
+	if schema != nil {
+		return r.RenderSchema(schema.Schema())
+	}
+	return nil
+}
+
+func firstExample(examples map[string]*base.Example) *base.Example {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if examples[name] != nil {
+			return examples[name]
+		}
+	}
+	return nil
+}
+
+// marshal renders a value as JSON, YAML, XML, a form or a multipart body depending on the media type, the XML objects
+// of the schema and the encoding of the media type are used. Anything else is rendered as text.
+func (s *Server) marshal(value any, contentType string, content *v3.MediaType) []byte {
+	if b, ok := s.encoders.encode(value, contentType, content); ok {
+		return b
+	}
+	switch {
+	case isYAML(contentType):
+		b, _ := yaml.Marshal(value)
+		return b
+	case isJSON(contentType):
+		if s.pretty {
+			b, _ := json.MarshalIndent(value, "", "  ")
+			return b
+		}
+		b, _ := json.Marshal(value)
+		return b
+	}
+	switch v := value.(type) {
+	case string:
+		return []byte(v)
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return b
+	}
+	return []byte(fmt.Sprint(value))
+}
+
+func sortedHeaders(headers map[string]*v3.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		// Content-Type is set by the server, as per the spec.
+		if !strings.EqualFold(name, "Content-Type") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contentType returns the Content-Type header for a media type, wildcards are replaced and multipart media types
+// have the boundary of the renderer.
+func contentType(mediaType string) string {
+	if mt, ok := mockType(mediaType); ok && mt == renderer.Multipart {
+		return strings.Split(mediaType, ";")[0] + "; boundary=" + renderer.MultipartBoundary
+	}
+	switch {
+	case mediaType == "*/*":
+		return "application/octet-stream"
+	case strings.HasSuffix(mediaType, "/*"):
+		return strings.TrimSuffix(mediaType, "*") + "octet-stream"
+	}
+	return mediaType
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	b, _ := json.Marshal(map[string]any{"code": code, "message": message})
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,421 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package mock
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/renderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const burgerShop = `openapi: 3.1.0
+info:
+  title: burgers
+  version: 1.0.0
+servers:
+  - url: https://api.pb33f.io/{version}
+    variables:
+      version:
+        default: v1
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: all the burgers
+          headers:
+            X-Total:
+              schema:
+                type: integer
+                const: 2
+            X-Cursor:
+              example: abc
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Burger'
+            application/yaml:
+              examples:
+                two:
+                  value:
+                    - name: big mac
+                    - name: whopper
+    post:
+      responses:
+        "201":
+          description: created
+        "400":
+          description: bad
+          content:
+            application/json:
+              example:
+                message: bad burger
+        default:
+          description: error
+          content:
+            text/plain:
+              example: something went wrong
+  /burgers/mine:
+    get:
+      responses:
+        "200":
+          description: my burger
+          content:
+            application/json:
+              example:
+                name: mine
+  /burgers/{burgerId}:
+    get:
+      responses:
+        "200":
+          description: a burger
+          content:
+            application/json:
+              example:
+                name: big mac
+              examples:
+                whopper:
+                  value:
+                    name: whopper
+        "404":
+          description: not found
+          content:
+            application/json:
+              examples:
+                notFound:
+                  value:
+                    message: no burger
+                gone:
+                  value:
+                    message: burger eaten
+            text/plain:
+              example: no burger
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name, patties]
+      properties:
+        name:
+          type: string
+        patties:
+          type: integer
+          minimum: 1
+          maximum: 3`
+
+func serve(s *Server, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Example(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+
+	rec := serve(s, http.MethodGet, "/burgers/123", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"big mac"}`, rec.Body.String())
+
+	// literal paths win over templates.
+	rec = serve(s, http.MethodGet, "/burgers/mine", nil)
+	assert.JSONEq(t, `{"name":"mine"}`, rec.Body.String())
+}
+
+func TestServer_Prefer(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+
+	rec := serve(s, http.MethodGet, "/burgers/123", map[string]string{"Prefer": "example=whopper"})
+	assert.JSONEq(t, `{"name":"whopper"}`, rec.Body.String())
+
+	rec = serve(s, http.MethodGet, "/burgers/123", map[string]string{"Prefer": "code=404"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"message":"burger eaten"}`, rec.Body.String())
+
+	rec = serve(s, http.MethodGet, "/burgers/123", map[string]string{"Prefer": `code=404, example="notFound"`})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"message":"no burger"}`, rec.Body.String())
+
+	// default response used for codes that are not defined.
+	rec = serve(s, http.MethodPost, "/burgers", map[string]string{"Prefer": "code=503"})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "something went wrong", rec.Body.String())
+
+	rec = serve(s, http.MethodGet, "/burgers/123", map[string]string{"Prefer": "code=503"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "not a response of this operation")
+
+	rec = serve(s, http.MethodGet, "/burgers/123", map[string]string{"Prefer": "code=burger"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServer_Accept(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+
+	rec := serve(s, http.MethodGet, "/burgers/123", map[string]string{
+		"Prefer": "code=404",
+		"Accept": "text/html;q=0.9, text/plain;q=0.8",
+	})
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no burger", rec.Body.String())
+
+	rec = serve(s, http.MethodGet, "/burgers", map[string]string{"Accept": "application/*;q=0.5, application/yaml"})
+	assert.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+	var burgers []map[string]any
+	assert.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), &burgers))
+	assert.Equal(t, "whopper", burgers[1]["name"])
+
+	rec = serve(s, http.MethodGet, "/burgers/123", map[string]string{"Accept": "image/png"})
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+}
+
+func TestServer_Schema(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+	s.SetSeed(1)
+	s.SetPretty()
+
+	rec := serve(s, http.MethodGet, "/burgers", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("X-Total"))
+	assert.Equal(t, "abc", rec.Header().Get("X-Cursor"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "[\n  {"))
+
+	var burgers []map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &burgers))
+	assert.NotEmpty(t, burgers)
+	assert.NotEmpty(t, burgers[0]["name"])
+	assert.GreaterOrEqual(t, burgers[0]["patties"], float64(1))
+	assert.LessOrEqual(t, burgers[0]["patties"], float64(3))
+
+	// seeded, so the same again.
+	s.SetSeed(1)
+	assert.Equal(t, rec.Body.String(), serve(s, http.MethodGet, "/burgers", nil).Body.String())
+}
+
+func TestServer_NoContent(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+	rec := serve(s, http.MethodPost, "/burgers", nil)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Empty(t, rec.Header().Get("Content-Type"))
+
+	rec = serve(s, http.MethodHead, "/burgers/mine", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestServer_ServerBasePath(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+	rec := serve(s, http.MethodGet, "/v1/burgers/mine", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"name":"mine"}`, rec.Body.String())
+}
+
+func TestServer_NotFound(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(burgerShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+
+	rec := serve(s, http.MethodGet, "/fries", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"code":404,"message":"no path matches '/fries'"}`, rec.Body.String())
+
+	rec = serve(s, http.MethodDelete, "/burgers", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+}
+
+func TestServer_BurgerShop(t *testing.T) {
+	spec, _ := os.ReadFile("../test_specs/burgershop.openapi.yaml")
+	doc, _ := libopenapi.NewDocument(spec)
+	model, _ := doc.BuildV3Model()
+	s := NewServer(model)
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/burgers/big-mac")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestChooseResponse(t *testing.T) {
+	code, response, err := chooseResponse(nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, response)
+
+	def := &v3.Response{Description: "default"}
+	code, response, err = chooseResponse(&v3.Responses{Default: def}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, def, response)
+
+	bad := &v3.Response{Description: "bad"}
+	code, response, _ = chooseResponse(&v3.Responses{Codes: map[string]*v3.Response{"500": bad, "4XX": def}}, "")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, bad, response)
+
+	code, _, _ = chooseResponse(&v3.Responses{}, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	ok := &v3.Response{Description: "ok"}
+	code, response, _ = chooseResponse(&v3.Responses{Codes: map[string]*v3.Response{"500": bad, "2XX": ok}}, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ok, response)
+
+	code, response, _ = chooseResponse(&v3.Responses{Codes: map[string]*v3.Response{"5XX": bad, "4xx": def}}, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, def, response)
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "application/octet-stream", contentType("*/*"))
+	assert.Equal(t, "image/octet-stream", contentType("image/*"))
+	assert.Equal(t, "application/json", contentType("application/json"))
+	assert.Equal(t, "multipart/mixed; boundary="+renderer.MultipartBoundary, contentType("multipart/mixed"))
+}
+
+const encodedShop = `openapi: 3.1.0
+info:
+  title: burgers
+  version: 1.0.0
+paths:
+  /burgers:
+    get:
+      responses:
+        2XX:
+          description: a burger
+          content:
+            application/xml:
+              example:
+                name: big mac
+                patties: 2
+              schema:
+                type: object
+                xml:
+                  name: burger
+            application/x-www-form-urlencoded:
+              example:
+                name: big mac
+                notes: extra cheese/no pickles
+              encoding:
+                notes:
+                  allowReserved: true
+            multipart/form-data:
+              example:
+                name: big mac
+              encoding:
+                name:
+                  contentType: text/html
+        4XX:
+          description: bad
+          content:
+            text/plain:
+              example: no burgers`
+
+func TestServer_EncodedResponses(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(encodedShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+
+	rec := serve(s, http.MethodGet, "/burgers", map[string]string{"Accept": "application/xml"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<burger><name>big mac</name><patties>2</patties></burger>",
+		rec.Body.String())
+
+	rec = serve(s, http.MethodGet, "/burgers", map[string]string{"Accept": "application/x-www-form-urlencoded"})
+	assert.Equal(t, "name=big+mac&notes=extra+cheese/no+pickles", rec.Body.String())
+
+	rec = serve(s, http.MethodGet, "/burgers", map[string]string{"Accept": "multipart/form-data"})
+	assert.Equal(t, "multipart/form-data; boundary="+renderer.MultipartBoundary, rec.Header().Get("Content-Type"))
+	part, err := multipart.NewReader(rec.Body, renderer.MultipartBoundary).NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "text/html", part.Header.Get("Content-Type"))
+
+	rec = serve(s, http.MethodGet, "/burgers", map[string]string{"Prefer": "code=404"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "no burgers", rec.Body.String())
+}
+
+func TestServer_OperationServers(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.1.0
+info:
+  title: burgers
+  version: 1.0.0
+servers:
+  - url: https://api.pb33f.io/v1
+paths:
+  /burgers:
+    get:
+      servers:
+        - url: /menu
+      responses:
+        "200":
+          description: the menu
+          content:
+            application/json:
+              example: [big mac]
+    post:
+      responses:
+        "201":
+          description: created`))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	s := NewServer(model)
+
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodGet, "/menu/burgers", nil).Code)
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodGet, "/burgers", nil).Code)
+	assert.Equal(t, http.StatusCreated, serve(s, http.MethodPost, "/v1/burgers", nil).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(s, http.MethodPost, "/menu/burgers", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/v1/fries", nil).Code)
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package parameters serializes Go values into parameters and deserializes them back, using the style and explode
+// values of OpenAPI 3 parameters (https://spec.openapis.org/oas/v3.1.0#style-values), and the collectionFormat of
+// Swagger 2 parameters (https://swagger.io/specification/v2/#parameterObject).
+package parameters
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Parameter styles, as defined by https://spec.openapis.org/oas/v3.1.0#style-values
+const (
+	StyleMatrix         = "matrix"
+	StyleLabel          = "label"
+	StyleForm           = "form"
+	StyleSimple         = "simple"
+	StyleSpaceDelimited = "spaceDelimited"
+	StylePipeDelimited  = "pipeDelimited"
+	StyleDeepObject     = "deepObject"
+
+	// StyleTabDelimited is not an OpenAPI 3 style, it is used for the tsv collectionFormat of Swagger 2.
+	StyleTabDelimited = "tabDelimited"
+)
+
+// Parameter locations. formData is only used by Swagger 2, it is serialized like a query parameter.
+const (
+	InPath     = "path"
+	InQuery    = "query"
+	InHeader   = "header"
+	InCookie   = "cookie"
+	InFormData = "formData"
+)
+
+// Collection formats of Swagger 2 array parameters.
+const (
+	CollectionFormatCSV   = "csv"
+	CollectionFormatSSV   = "ssv"
+	CollectionFormatTSV   = "tsv"
+	CollectionFormatPipes = "pipes"
+	CollectionFormatMulti = "multi"
+)
+
+// Codec serializes and deserializes the values of a parameter. A Codec is created for a parameter of a document
+// using NewCodec, NewHeaderCodec or NewSwaggerCodec, or built directly.
+type Codec struct {
+	Name          string       // the name of the parameter.
+	In            string       // the location of the parameter.
+	Style         string       // how the parameter is serialized, one of the Style constants.
+	Explode       bool         // arrays and objects are serialized as separate parameters (or values).
+	AllowReserved bool         // reserved characters are not escaped in query parameters.
+	Schema        *base.Schema // the schema decides the shape and type of deserialized values, nil for strings.
+}
+
+// NewCodec creates a Codec for an OpenAPI 3 parameter, using the default style and explode value of its location
+// if they are not set. Parameters that use content rather than a schema deserialize values as strings.
+func NewCodec(p *v3.Parameter) *Codec {
+	c := &Codec{Name: p.Name, In: p.In, Style: p.Style, AllowReserved: p.AllowReserved}
+	if c.Style == "" {
+		c.Style = DefaultStyle(p.In)
+	}
+	if p.Explode != nil {
+		c.Explode = *p.Explode
+	} else {
+		c.Explode = c.Style == StyleForm
+	}
+	if p.Schema != nil {
+		c.Schema = p.Schema.Schema()
+	}
+	return c
+}
+
+// NewHeaderCodec creates a Codec for a header of an OpenAPI 3 response or encoding, headers use the simple style.
+func NewHeaderCodec(name string, h *v3.Header) *Codec {
+	c := &Codec{Name: name, In: InHeader, Style: StyleSimple, Explode: h.Explode}
+	if h.Schema != nil {
+		c.Schema = h.Schema.Schema()
+	}
+	return c
+}
+
+// NewSwaggerCodec creates a Codec for a Swagger 2 parameter. The collectionFormat of the parameter is converted to
+// the equivalent style: csv (the default) to form or simple, ssv to spaceDelimited, tsv to tabDelimited, pipes to
+// pipeDelimited and multi to exploded form. The type and items of the parameter are converted to a schema. nil is
+// returned for body parameters, which are not serialized as parameters.
+func NewSwaggerCodec(p *v2.Parameter) *Codec {
+	if p.In == "body" {
+		return nil
+	}
+	c := &Codec{Name: p.Name, In: p.In, Style: DefaultStyle(p.In), Schema: swaggerSchema(p.Type, p.Format, p.Items)}
+	switch p.CollectionFormat {
+	case CollectionFormatSSV:
+		c.Style = StyleSpaceDelimited
+	case CollectionFormatTSV:
+		c.Style = StyleTabDelimited
+	case CollectionFormatPipes:
+		c.Style = StylePipeDelimited
+	case CollectionFormatMulti:
+		c.Style, c.Explode = StyleForm, true
+	}
+	return c
+}
+
+// DefaultStyle returns the default style of a parameter location, form for query, formData and cookie parameters
+// and simple for path and header parameters.
+func DefaultStyle(in string) string {
+	switch in {
+	case InQuery, InCookie, InFormData:
+		return StyleForm
+	}
+	return StyleSimple
+}
+
+// Merge merges the parameters of a path item and an operation, operation parameters override path item parameters
+// with the same name and location.
+func Merge(pathParams, operationParams []*v3.Parameter) []*v3.Parameter {
+	var params []*v3.Parameter
+	overridden := make(map[string]bool)
+	for _, p := range operationParams {
+		if p != nil {
+			overridden[p.Name+":"+p.In] = true
+		}
+	}
+	for _, p := range pathParams {
+		if p != nil && !overridden[p.Name+":"+p.In] {
+			params = append(params, p)
+		}
+	}
+	for _, p := range operationParams {
+		if p != nil {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// swaggerSchema converts the type, format and items of a Swagger 2 parameter to a schema.
+func swaggerSchema(typ, format string, items *v2.Items) *base.Schema {
+	schema := &base.Schema{Format: format}
+	if typ != "" {
+		schema.Type = []string{typ}
+	}
+	if items != nil {
+		schema.Items = &base.DynamicValue[*base.SchemaProxy, bool]{
+			A: base.CreateSchemaProxy(swaggerSchema(items.Type, items.Format, items.Items)),
+		}
+	}
+	return schema
+}
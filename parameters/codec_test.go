@@ -0,0 +1,92 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package parameters
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCodec(t *testing.T) {
+	yes, no := true, false
+	c := NewCodec(&v3.Parameter{Name: "color", In: InQuery})
+	assert.Equal(t, StyleForm, c.Style)
+	assert.True(t, c.Explode)
+
+	c = NewCodec(&v3.Parameter{Name: "color", In: InQuery, Explode: &no, AllowReserved: true})
+	assert.False(t, c.Explode)
+	assert.True(t, c.AllowReserved)
+
+	c = NewCodec(&v3.Parameter{Name: "color", In: InPath, Style: StyleLabel})
+	assert.Equal(t, StyleLabel, c.Style)
+	assert.False(t, c.Explode)
+
+	c = NewCodec(&v3.Parameter{Name: "color", In: InHeader, Explode: &yes,
+		Schema: base.CreateSchemaProxy(&base.Schema{Type: []string{"integer"}})})
+	assert.Equal(t, StyleSimple, c.Style)
+	assert.True(t, c.Explode)
+	value, err := c.Deserialize("5")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	c = NewHeaderCodec("X-Rate-Limit", &v3.Header{Explode: true})
+	assert.Equal(t, &Codec{Name: "X-Rate-Limit", In: InHeader, Style: StyleSimple, Explode: true}, c)
+}
+
+func TestNewSwaggerCodec(t *testing.T) {
+	items := &v2.Items{Type: "integer"}
+	tests := []struct {
+		format  string
+		in      string
+		style   string
+		explode bool
+		query   string
+	}{
+		{"", InQuery, StyleForm, false, "id=1,2"},
+		{CollectionFormatCSV, InPath, StyleSimple, false, "1,2"},
+		{CollectionFormatSSV, InQuery, StyleSpaceDelimited, false, "id=1%202"},
+		{CollectionFormatTSV, InHeader, StyleTabDelimited, false, "1\t2"},
+		{CollectionFormatPipes, InQuery, StylePipeDelimited, false, "id=1|2"},
+		{CollectionFormatMulti, InFormData, StyleForm, true, "id=1&id=2"},
+	}
+	for _, tc := range tests {
+		c := NewSwaggerCodec(&v2.Parameter{Name: "id", In: tc.in, Type: "array", Items: items,
+			CollectionFormat: tc.format})
+		assert.Equal(t, tc.style, c.Style, tc.format)
+		assert.Equal(t, tc.explode, c.Explode, tc.format)
+
+		serialized := c.Serialize([]int{1, 2})
+		assert.Equal(t, tc.query, serialized, tc.format)
+
+		var value any
+		var err error
+		if tc.in == InQuery || tc.in == InFormData {
+			query, _ := url.ParseQuery(serialized)
+			value, _, err = c.DeserializeQuery(query)
+		} else {
+			value, err = c.Deserialize(serialized)
+		}
+		assert.NoError(t, err, tc.format)
+		assert.Equal(t, []any{int64(1), int64(2)}, value, tc.format)
+	}
+
+	assert.Nil(t, NewSwaggerCodec(&v2.Parameter{Name: "burger", In: "body"}))
+	assert.Equal(t, []string{"boolean"}, NewSwaggerCodec(&v2.Parameter{Name: "vegan", In: InQuery,
+		Type: "boolean"}).Schema.Type)
+}
+
+func TestMerge(t *testing.T) {
+	id := &v3.Parameter{Name: "id", In: InPath}
+	limit := &v3.Parameter{Name: "limit", In: InQuery}
+	override := &v3.Parameter{Name: "limit", In: InQuery, Required: true}
+	header := &v3.Parameter{Name: "limit", In: InHeader}
+	assert.Equal(t, []*v3.Parameter{id, limit}, Merge([]*v3.Parameter{id, limit}, nil))
+	assert.Equal(t, []*v3.Parameter{id, override, header},
+		Merge([]*v3.Parameter{id, limit, nil}, []*v3.Parameter{override, header}))
+}
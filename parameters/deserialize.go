@@ -0,0 +1,226 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package parameters
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// shapes of deserialized values, decided by the schema of the parameter.
+const (
+	shapePrimitive = "primitive"
+	shapeArray     = "array"
+	shapeObject    = "object"
+)
+
+// Deserialize deserializes the value of a path, header or cookie parameter into a primitive, a []any or a
+// map[string]any, depending on the schema of the Codec. Primitives are converted to the types of the schema: int64
+// for integers, float64 for numbers and bool for booleans; values that cannot be converted are kept as strings.
+//
+// The raw value must not be escaped. Header values that are sent as multiple headers should be joined with commas.
+// An error is returned if the value is not serialized in the style of the parameter.
+func (c *Codec) Deserialize(raw string) (any, error) {
+	explode := c.Explode
+	if c.In == InCookie {
+		explode = false
+	}
+	return c.deserialize(raw, explode)
+}
+
+// DeserializeQuery deserializes a query or formData parameter from the values of a query string (or form), as with
+// Deserialize. Exploded objects are read from the values named after their properties, deepObject parameters are
+// read from the values named 'name[property]'. false is returned if the query does not have the parameter.
+func (c *Codec) DeserializeQuery(query url.Values) (any, bool, error) {
+	shape := shapeOf(c.Schema)
+	switch {
+	case c.Style == StyleDeepObject:
+		object := make(map[string]any)
+		for key, values := range query {
+			if strings.HasPrefix(key, c.Name+"[") && strings.HasSuffix(key, "]") && len(values) > 0 {
+				property := key[len(c.Name)+1 : len(key)-1]
+				object[property] = coerce(values[0], PropertySchema(c.Schema, property))
+			}
+		}
+		return object, len(object) > 0, nil
+	case c.Explode && shape == shapeObject:
+		object := make(map[string]any)
+		for property, sp := range c.Schema.Properties {
+			if values, ok := query[property]; ok && len(values) > 0 {
+				object[property] = coerce(values[0], ProxySchema(sp))
+			}
+		}
+		return object, len(object) > 0, nil
+	}
+	values, ok := query[c.Name]
+	if !ok || len(values) == 0 {
+		return nil, false, nil
+	}
+	if c.Explode && shape == shapeArray {
+		return coerceItems(values, c.Schema), true, nil
+	}
+	value, err := c.deserialize(values[0], c.Explode)
+	return value, true, err
+}
+
+// deserialize converts a serialized value into a primitive, array or object (depending on the schema) as defined by
+// https://spec.openapis.org/oas/v3.1.0#style-examples
+func (c *Codec) deserialize(raw string, explode bool) (any, error) {
+	invalid := fmt.Errorf("value '%s' is not a valid %s parameter", raw, c.Style)
+	shape := shapeOf(c.Schema)
+	separator, itemSeparator := ",", ","
+	switch c.Style {
+	case StyleMatrix:
+		if !strings.HasPrefix(raw, ";") {
+			return nil, invalid
+		}
+		raw = raw[1:]
+		if !explode || shape == shapePrimitive {
+			if raw != c.Name && !strings.HasPrefix(raw, c.Name+"=") {
+				return nil, invalid
+			}
+			raw = strings.TrimPrefix(strings.TrimPrefix(raw, c.Name), "=")
+		} else {
+			separator, itemSeparator = ";", ";"
+		}
+		if explode && shape == shapeArray {
+			items := strings.Split(raw, ";")
+			for i, item := range items {
+				if item != c.Name && !strings.HasPrefix(item, c.Name+"=") {
+					return nil, invalid
+				}
+				items[i] = strings.TrimPrefix(strings.TrimPrefix(item, c.Name), "=")
+			}
+			return coerceItems(items, c.Schema), nil
+		}
+	case StyleLabel:
+		if !strings.HasPrefix(raw, ".") {
+			return nil, invalid
+		}
+		raw = raw[1:]
+		if explode {
+			separator, itemSeparator = ".", "."
+		}
+	case StyleSpaceDelimited, StylePipeDelimited, StyleTabDelimited:
+		itemSeparator = delimiter(c.Style)
+	}
+
+	switch shape {
+	case shapeArray:
+		if raw == "" {
+			return []any{}, nil
+		}
+		return coerceItems(strings.Split(raw, itemSeparator), c.Schema), nil
+	case shapeObject:
+		object := make(map[string]any)
+		if raw == "" {
+			return object, nil
+		}
+		parts := strings.Split(raw, separator)
+		if explode {
+			for _, part := range parts {
+				k, v, ok := strings.Cut(part, "=")
+				if !ok {
+					return nil, invalid
+				}
+				object[k] = coerce(v, PropertySchema(c.Schema, k))
+			}
+			return object, nil
+		}
+		if len(parts)%2 != 0 {
+			return nil, invalid
+		}
+		for i := 0; i < len(parts); i += 2 {
+			object[parts[i]] = coerce(parts[i+1], PropertySchema(c.Schema, parts[i]))
+		}
+		return object, nil
+	}
+	return coerce(raw, c.Schema), nil
+}
+
+// shapeOf returns whether a schema describes an array, an object or a primitive.
+func shapeOf(schema *base.Schema) string {
+	if schema == nil {
+		return shapePrimitive
+	}
+	for _, t := range schema.Type {
+		if t == shapeArray || t == shapeObject {
+			return t
+		}
+	}
+	if len(schema.Type) == 0 {
+		if schema.Items != nil || len(schema.PrefixItems) > 0 {
+			return shapeArray
+		}
+		if len(schema.Properties) > 0 || schema.AdditionalProperties != nil {
+			return shapeObject
+		}
+	}
+	return shapePrimitive
+}
+
+// coerce converts a serialized primitive to the first type of its schema it can be converted to. Values that cannot
+// be converted are kept as strings.
+func coerce(raw string, schema *base.Schema) any {
+	if schema == nil {
+		return raw
+	}
+	for _, t := range schema.Type {
+		switch t {
+		case "integer":
+			if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return i
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				return f
+			}
+		case "boolean":
+			if raw == "true" || raw == "false" {
+				return raw == "true"
+			}
+		}
+	}
+	return raw
+}
+
+// coerceItems converts the items of a serialized array to the type of the items schema.
+func coerceItems(raw []string, schema *base.Schema) []any {
+	var items *base.Schema
+	if schema != nil && schema.Items != nil && schema.Items.IsA() {
+		items = ProxySchema(schema.Items.A)
+	}
+	values := make([]any, len(raw))
+	for i, item := range raw {
+		values[i] = coerce(item, items)
+	}
+	return values
+}
+
+// PropertySchema returns the schema of a property of an object, or the additional properties schema. It returns nil
+// if the schema is nil, or does not describe the property.
+func PropertySchema(schema *base.Schema, name string) *base.Schema {
+	if schema == nil {
+		return nil
+	}
+	if sp, ok := schema.Properties[name]; ok {
+		return ProxySchema(sp)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+		return ProxySchema(schema.AdditionalProperties.A)
+	}
+	return nil
+}
+
+// ProxySchema returns the schema of a schema proxy, or nil if the proxy is nil.
+func ProxySchema(sp *base.SchemaProxy) *base.Schema {
+	if sp == nil {
+		return nil
+	}
+	return sp.Schema()
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package parameters
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectations are from https://spec.openapis.org/oas/v3.1.0#style-examples
+func TestCodec_Deserialize(t *testing.T) {
+	primitive := &base.Schema{Type: []string{"integer"}}
+	array := &base.Schema{Type: []string{"array"}, Items: &base.DynamicValue[*base.SchemaProxy, bool]{
+		A: base.CreateSchemaProxy(&base.Schema{Type: []string{"string"}})}}
+	object := &base.Schema{Type: []string{"object"}, Properties: map[string]*base.SchemaProxy{
+		"R": base.CreateSchemaProxy(primitive)}}
+
+	tests := []struct {
+		raw     string
+		style   string
+		explode bool
+		schema  *base.Schema
+		want    any
+	}{
+		{"5", StyleSimple, false, primitive, int64(5)},
+		{"blue,black", StyleSimple, false, array, []any{"blue", "black"}},
+		{"R,100,G,200", StyleSimple, false, object, map[string]any{"R": int64(100), "G": "200"}},
+		{"R=100,G=200", StyleSimple, true, object, map[string]any{"R": int64(100), "G": "200"}},
+		{".5", StyleLabel, false, primitive, int64(5)},
+		{".blue,black", StyleLabel, false, array, []any{"blue", "black"}},
+		{".blue.black", StyleLabel, true, array, []any{"blue", "black"}},
+		{".R=100.G=200", StyleLabel, true, object, map[string]any{"R": int64(100), "G": "200"}},
+		{";color=5", StyleMatrix, false, primitive, int64(5)},
+		{";color=5", StyleMatrix, true, primitive, int64(5)},
+		{";color=blue,black", StyleMatrix, false, array, []any{"blue", "black"}},
+		{";color=blue;color=black", StyleMatrix, true, array, []any{"blue", "black"}},
+		{";color=R,100,G,200", StyleMatrix, false, object, map[string]any{"R": int64(100), "G": "200"}},
+		{";R=100;G=200", StyleMatrix, true, object, map[string]any{"R": int64(100), "G": "200"}},
+		{"blue black", StyleSpaceDelimited, false, array, []any{"blue", "black"}},
+		{"blue\tblack", StyleTabDelimited, false, array, []any{"blue", "black"}},
+		{"blue|black", StylePipeDelimited, false, array, []any{"blue", "black"}},
+		{"", StyleForm, false, array, []any{}},
+		{"blue", StyleSimple, false, nil, "blue"},
+	}
+	for _, tc := range tests {
+		c := &Codec{Name: "color", In: InPath, Style: tc.style, Explode: tc.explode, Schema: tc.schema}
+		value, err := c.Deserialize(tc.raw)
+		assert.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.want, value, tc.raw)
+	}
+
+	_, err := (&Codec{Name: "color", Style: StyleLabel, Schema: array}).Deserialize("blue")
+	assert.Equal(t, "value 'blue' is not a valid label parameter", err.Error())
+	_, err = (&Codec{Name: "color", Style: StyleMatrix, Schema: array}).Deserialize(";colour=blue")
+	assert.Error(t, err)
+	_, err = (&Codec{Name: "color", Style: StyleSimple, Schema: object}).Deserialize("R,100,G")
+	assert.Error(t, err)
+	_, err = (&Codec{Name: "color", Style: StyleSimple, Explode: true, Schema: object}).Deserialize("R")
+	assert.Error(t, err)
+
+	// cookies are never exploded.
+	cookie := &Codec{Name: "color", In: InCookie, Style: StyleForm, Explode: true, Schema: object}
+	value, err := cookie.Deserialize("R,100")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"R": int64(100)}, value)
+}
+
+func TestCodec_DeserializeQuery(t *testing.T) {
+	integer := &base.Schema{Type: []string{"integer"}}
+	array := &base.Schema{Type: []string{"array"}, Items: &base.DynamicValue[*base.SchemaProxy, bool]{
+		A: base.CreateSchemaProxy(integer)}}
+	object := &base.Schema{Type: []string{"object"}, Properties: map[string]*base.SchemaProxy{
+		"vegan":    base.CreateSchemaProxy(&base.Schema{Type: []string{"boolean"}}),
+		"calories": base.CreateSchemaProxy(integer),
+	}}
+	query, _ := url.ParseQuery("id=1&id=2&ids=3,4&vegan=true&calories=500&filter[vegan]=false&filter[calories]=20")
+
+	value, ok, err := (&Codec{Name: "id", In: InQuery, Style: StyleForm, Explode: true, Schema: array}).
+		DeserializeQuery(query)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(2)}, value)
+
+	value, _, _ = (&Codec{Name: "ids", In: InQuery, Style: StyleForm, Schema: array}).DeserializeQuery(query)
+	assert.Equal(t, []any{int64(3), int64(4)}, value)
+
+	value, _, _ = (&Codec{Name: "burger", In: InQuery, Style: StyleForm, Explode: true, Schema: object}).
+		DeserializeQuery(query)
+	assert.Equal(t, map[string]any{"vegan": true, "calories": int64(500)}, value)
+
+	value, _, _ = (&Codec{Name: "filter", In: InQuery, Style: StyleDeepObject, Explode: true, Schema: object}).
+		DeserializeQuery(query)
+	assert.Equal(t, map[string]any{"vegan": false, "calories": int64(20)}, value)
+
+	_, ok, _ = (&Codec{Name: "missing", In: InQuery, Style: StyleDeepObject, Schema: object}).DeserializeQuery(query)
+	assert.False(t, ok)
+	_, ok, _ = (&Codec{Name: "missing", In: InQuery, Style: StyleForm}).DeserializeQuery(query)
+	assert.False(t, ok)
+}
+
+func TestCoerce(t *testing.T) {
+	assert.Equal(t, true, coerce("true", &base.Schema{Type: []string{"boolean"}}))
+	assert.Equal(t, "yes", coerce("yes", &base.Schema{Type: []string{"boolean"}}))
+	assert.Equal(t, 1.5, coerce("1.5", &base.Schema{Type: []string{"number", "null"}}))
+	assert.Equal(t, "1.5", coerce("1.5", &base.Schema{Type: []string{"integer"}}))
+	assert.Equal(t, "1.5", coerce("1.5", &base.Schema{Type: []string{"string"}}))
+	assert.Equal(t, "1.5", coerce("1.5", nil))
+}
+
+func TestPropertySchema(t *testing.T) {
+	name := &base.Schema{Type: []string{"string"}}
+	extra := &base.Schema{Type: []string{"integer"}}
+	object := &base.Schema{Type: []string{"object"},
+		Properties:           map[string]*base.SchemaProxy{"name": base.CreateSchemaProxy(name)},
+		AdditionalProperties: &base.DynamicValue[*base.SchemaProxy, bool]{A: base.CreateSchemaProxy(extra)}}
+
+	assert.Same(t, name, PropertySchema(object, "name"))
+	assert.Same(t, extra, PropertySchema(object, "patties"))
+	assert.Nil(t, PropertySchema(&base.Schema{}, "name"))
+	assert.Nil(t, PropertySchema(nil, "name"))
+	assert.Nil(t, ProxySchema(nil))
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package parameters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Serialize serializes a value of the parameter. Values are primitives, slices and arrays, maps and structs (which
+// are serialized as objects, using their JSON field names). Nested arrays and objects are not defined by the spec,
+// they are formatted with fmt.
+//
+// Path parameters are escaped, and serialized as the value that replaces the template expression. Query and formData
+// parameters are escaped (unless AllowReserved is set), and serialized as a query string fragment, with the name of
+// the parameter: 'color=blue', or 'color=blue&color=black' if exploded. Header and cookie parameters are serialized as
+// the value of the header or cookie, cookies are never exploded.
+func (c *Codec) Serialize(value any) string {
+	switch c.In {
+	case InQuery, InFormData:
+		return c.serializeQuery(value)
+	case InPath:
+		return c.serialize(value, c.Explode, url.PathEscape)
+	case InCookie:
+		return c.serialize(value, false, noEscape)
+	}
+	return c.serialize(value, c.Explode, noEscape)
+}
+
+// serialize serializes a value that is not in a query, using the simple, label or matrix style, or a delimited style.
+func (c *Codec) serialize(value any, explode bool, escape func(string) string) string {
+	prim, items, pairs := flatten(value)
+	switch c.Style {
+	case StyleLabel:
+		switch {
+		case items != nil && explode:
+			return "." + strings.Join(escapeAll(items, escape), ".")
+		case items != nil:
+			return "." + strings.Join(escapeAll(items, escape), ",")
+		case pairs != nil:
+			return "." + joinPairs(pairs, ".", explode, escape)
+		}
+		return "." + escape(prim)
+	case StyleMatrix:
+		switch {
+		case items != nil && explode:
+			var b strings.Builder
+			for _, item := range items {
+				b.WriteString(";" + c.Name + "=" + escape(item))
+			}
+			return b.String()
+		case items != nil:
+			return ";" + c.Name + "=" + strings.Join(escapeAll(items, escape), ",")
+		case pairs != nil && explode:
+			return ";" + joinPairs(pairs, ";", true, escape)
+		case pairs != nil:
+			return ";" + c.Name + "=" + joinPairs(pairs, ",", false, escape)
+		}
+		return ";" + c.Name + "=" + escape(prim)
+	case StyleSpaceDelimited, StylePipeDelimited, StyleTabDelimited:
+		if items != nil {
+			return strings.Join(escapeAll(items, escape), escape(delimiter(c.Style)))
+		}
+	}
+	switch {
+	case items != nil:
+		return strings.Join(escapeAll(items, escape), ",")
+	case pairs != nil:
+		return joinPairs(pairs, ",", explode, escape)
+	}
+	return escape(prim)
+}
+
+// serializeQuery serializes a query parameter into a query string fragment, using the form, spaceDelimited,
+// pipeDelimited, tabDelimited or deepObject style.
+func (c *Codec) serializeQuery(value any) string {
+	escape := url.QueryEscape
+	if c.AllowReserved {
+		escape = escapeUnreserved
+	}
+	prim, items, pairs := flatten(value)
+	key := escape(c.Name)
+	switch {
+	case c.Style == StyleDeepObject && pairs != nil:
+		parts := make([]string, 0, len(pairs))
+		for _, p := range pairs {
+			parts = append(parts, key+"%5B"+escape(p.key)+"%5D="+escape(p.value))
+		}
+		return strings.Join(parts, "&")
+	case items != nil && c.Explode:
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, key+"="+escape(item))
+		}
+		return strings.Join(parts, "&")
+	case items != nil:
+		separator := ","
+		switch c.Style {
+		case StyleSpaceDelimited:
+			separator = "%20"
+		case StyleTabDelimited:
+			separator = "%09"
+		case StylePipeDelimited:
+			separator = "|"
+		}
+		return key + "=" + strings.Join(escapeAll(items, escape), separator)
+	case pairs != nil && c.Explode:
+		return joinPairs(pairs, "&", true, escape)
+	case pairs != nil:
+		return key + "=" + joinPairs(pairs, ",", false, escape)
+	}
+	return key + "=" + escape(prim)
+}
+
+// delimiter returns the separator of the items of an array in a delimited style.
+func delimiter(style string) string {
+	switch style {
+	case StyleSpaceDelimited:
+		return " "
+	case StyleTabDelimited:
+		return "\t"
+	case StylePipeDelimited:
+		return "|"
+	}
+	return ","
+}
+
+// pair is a key and value of a serialized object.
+type pair struct {
+	key   string
+	value string
+}
+
+// flatten converts a value into a primitive string, the items of an array, or the pairs of an object ordered by
+// key.
+func flatten(value any) (string, []string, []pair) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", nil, nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, primitive(rv.Index(i).Interface()))
+		}
+		return "", items, nil
+	case reflect.Map:
+		pairs := make([]pair, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, pair{key: fmt.Sprint(iter.Key().Interface()), value: primitive(iter.Value().Interface())})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+		return "", nil, pairs
+	case reflect.Struct:
+		var object map[string]any
+		if b, err := json.Marshal(rv.Interface()); err == nil && json.Unmarshal(b, &object) == nil {
+			return flatten(object)
+		}
+	case reflect.Invalid:
+		return "", nil, nil
+	}
+	return primitive(rv.Interface()), nil, nil
+}
+
+// primitive formats a primitive value, floats are formatted without exponents.
+func primitive(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
+
+// joinPairs joins the pairs of an object, either as key=value or key,value.
+func joinPairs(pairs []pair, separator string, explode bool, escape func(string) string) string {
+	parts := make([]string, 0, len(pairs)*2)
+	for _, p := range pairs {
+		if explode {
+			parts = append(parts, escape(p.key)+"="+escape(p.value))
+		} else {
+			parts = append(parts, escape(p.key), escape(p.value))
+		}
+	}
+	if explode {
+		return strings.Join(parts, separator)
+	}
+	return strings.Join(parts, ",")
+}
+
+func escapeAll(items []string, escape func(string) string) []string {
+	escaped := make([]string, len(items))
+	for i, item := range items {
+		escaped[i] = escape(item)
+	}
+	return escaped
+}
+
+func noEscape(s string) string {
+	return s
+}
+
+// escapeUnreserved escapes a query value, but leaves reserved characters (such as :/?#[]@!$&'()*+,;=) as they are,
+// used by parameters that set allowReserved.
+func escapeUnreserved(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(":/?#[]@!$&'()*+,;=", r) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(url.QueryEscape(string(r)))
+	}
+	return b.String()
+}
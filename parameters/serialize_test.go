@@ -0,0 +1,84 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package parameters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	colorPrim   = "blue"
+	colorArray  = []any{"blue", "black", "brown"}
+	colorObject = map[string]any{"R": float64(100), "G": float64(200), "B": float64(150)}
+)
+
+func serialize(in, style string, explode bool, value any) string {
+	return (&Codec{Name: "color", In: in, Style: style, Explode: explode}).Serialize(value)
+}
+
+// expectations are from https://spec.openapis.org/oas/v3.1.0#style-examples
+func TestCodec_SerializePath(t *testing.T) {
+	assert.Equal(t, "blue", serialize(InPath, StyleSimple, false, colorPrim))
+	assert.Equal(t, "blue,black,brown", serialize(InPath, StyleSimple, true, colorArray))
+	assert.Equal(t, "B,150,G,200,R,100", serialize(InPath, StyleSimple, false, colorObject))
+	assert.Equal(t, "B=150,G=200,R=100", serialize(InPath, StyleSimple, true, colorObject))
+
+	assert.Equal(t, ".blue", serialize(InPath, StyleLabel, false, colorPrim))
+	assert.Equal(t, ".blue,black,brown", serialize(InPath, StyleLabel, false, colorArray))
+	assert.Equal(t, ".blue.black.brown", serialize(InPath, StyleLabel, true, colorArray))
+	assert.Equal(t, ".B=150.G=200.R=100", serialize(InPath, StyleLabel, true, colorObject))
+
+	assert.Equal(t, ";color=blue", serialize(InPath, StyleMatrix, false, colorPrim))
+	assert.Equal(t, ";color=blue,black,brown", serialize(InPath, StyleMatrix, false, colorArray))
+	assert.Equal(t, ";color=blue;color=black;color=brown", serialize(InPath, StyleMatrix, true, colorArray))
+	assert.Equal(t, ";color=B,150,G,200,R,100", serialize(InPath, StyleMatrix, false, colorObject))
+	assert.Equal(t, ";B=150;G=200;R=100", serialize(InPath, StyleMatrix, true, colorObject))
+
+	assert.Equal(t, "big%20mac", serialize(InPath, StyleSimple, false, "big mac"))
+	assert.Equal(t, "blue%20black%20brown", serialize(InPath, StyleSpaceDelimited, false, colorArray))
+}
+
+func TestCodec_SerializeQuery(t *testing.T) {
+	assert.Equal(t, "color=blue", serialize(InQuery, StyleForm, true, colorPrim))
+	assert.Equal(t, "color=blue,black,brown", serialize(InQuery, StyleForm, false, colorArray))
+	assert.Equal(t, "color=blue&color=black&color=brown", serialize(InQuery, StyleForm, true, colorArray))
+	assert.Equal(t, "color=B,150,G,200,R,100", serialize(InQuery, StyleForm, false, colorObject))
+	assert.Equal(t, "B=150&G=200&R=100", serialize(InQuery, StyleForm, true, colorObject))
+	assert.Equal(t, "color=blue%20black%20brown", serialize(InQuery, StyleSpaceDelimited, false, colorArray))
+	assert.Equal(t, "color=blue%09black%09brown", serialize(InFormData, StyleTabDelimited, false, colorArray))
+	assert.Equal(t, "color=blue|black|brown", serialize(InQuery, StylePipeDelimited, false, colorArray))
+	assert.Equal(t, "color%5BB%5D=150&color%5BG%5D=200&color%5BR%5D=100",
+		serialize(InQuery, StyleDeepObject, true, colorObject))
+
+	q := &Codec{Name: "q", In: InQuery, Style: StyleForm, Explode: true}
+	assert.Equal(t, "q=a%2Fb", q.Serialize("a/b"))
+	q.AllowReserved = true
+	assert.Equal(t, "q=a/b+c", q.Serialize("a/b c"))
+}
+
+func TestCodec_SerializeHeaderCookie(t *testing.T) {
+	assert.Equal(t, "blue,black,brown", serialize(InCookie, StyleForm, true, colorArray))
+	assert.Equal(t, "1.5", serialize(InCookie, StyleForm, true, 1.5))
+	assert.Equal(t, "", serialize(InCookie, StyleForm, true, nil))
+
+	assert.Equal(t, "big mac", serialize(InHeader, StyleSimple, false, "big mac"))
+	assert.Equal(t, "B=150,G=200,R=100", serialize(InHeader, StyleSimple, true, colorObject))
+	assert.Equal(t, "blue|black|brown", serialize(InHeader, StylePipeDelimited, false, colorArray))
+}
+
+func TestCodec_SerializeGoValues(t *testing.T) {
+	type burger struct {
+		Name    string `json:"name"`
+		Patties int    `json:"patties"`
+	}
+	assert.Equal(t, "1,2,3", serialize(InHeader, StyleSimple, false, []int{1, 2, 3}))
+	assert.Equal(t, "a,1,b,2", serialize(InHeader, StyleSimple, false, map[string]int{"b": 2, "a": 1}))
+	assert.Equal(t, "name=big+mac&patties=2",
+		serialize(InQuery, StyleForm, true, &burger{Name: "big mac", Patties: 2}))
+	assert.Equal(t, "true", serialize(InHeader, StyleSimple, false, true))
+	var none *burger
+	assert.Equal(t, "color=", serialize(InQuery, StyleForm, true, none))
+}
@@ -0,0 +1,295 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package patch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/what-changed/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Diff will compare two yaml.Node trees and return a Patch that transforms the original tree into the updated
+// tree. The pointer is the location of the original node, use an empty string when comparing entire documents.
+//
+// Key ordering is not considered a difference, as JSON Patch has no way of expressing it.
+func Diff(original, updated *yaml.Node, pointer string) Patch {
+	var p Patch
+	diffNodes(unwrapDocument(original), unwrapDocument(updated), pointer, &p)
+	return p
+}
+
+func diffNodes(l, r *yaml.Node, pointer string, p *Patch) {
+	l = resolveAlias(l)
+	r = resolveAlias(r)
+	if l == nil && r == nil {
+		return
+	}
+	if l == nil || r == nil || l.Kind != r.Kind {
+		*p = append(*p, &Operation{Op: OpReplace, Path: pointer, Value: r})
+		return
+	}
+	switch l.Kind {
+	case yaml.MappingNode:
+		lKeys := make(map[string]*yaml.Node)
+		rKeys := make(map[string]*yaml.Node)
+		for i := 0; i < len(l.Content)-1; i += 2 {
+			lKeys[l.Content[i].Value] = l.Content[i+1]
+		}
+		for i := 0; i < len(r.Content)-1; i += 2 {
+			rKeys[r.Content[i].Value] = r.Content[i+1]
+		}
+		for i := 0; i < len(l.Content)-1; i += 2 {
+			k := l.Content[i].Value
+			if rv, ok := rKeys[k]; ok {
+				diffNodes(l.Content[i+1], rv, pointer+"/"+EscapeToken(k), p)
+				continue
+			}
+			*p = append(*p, &Operation{Op: OpRemove, Path: pointer + "/" + EscapeToken(k)})
+		}
+		for i := 0; i < len(r.Content)-1; i += 2 {
+			k := r.Content[i].Value
+			if _, ok := lKeys[k]; !ok {
+				*p = append(*p, &Operation{Op: OpAdd, Path: pointer + "/" + EscapeToken(k), Value: r.Content[i+1]})
+			}
+		}
+	case yaml.SequenceNode:
+		diffSequences(l.Content, r.Content, pointer, p)
+	default:
+		if !NodesEqual(l, r) {
+			*p = append(*p, &Operation{Op: OpReplace, Path: pointer, Value: r})
+		}
+	}
+}
+
+// diffSequences uses the longest common subsequence of two arrays to work out the smallest set of removals and
+// additions. When an element is removed and another added in the same place, the two elements are compared instead.
+func diffSequences(l, r []*yaml.Node, pointer string, p *Patch) {
+	lKeys := make([]string, len(l))
+	rKeys := make([]string, len(r))
+	for i := range l {
+		b, _ := MarshalNodeJSON(l[i])
+		lKeys[i] = string(b)
+	}
+	for i := range r {
+		b, _ := MarshalNodeJSON(r[i])
+		rKeys[i] = string(b)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of l[i:] and r[j:]
+	lcs := make([][]int, len(l)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(r)+1)
+	}
+	for i := len(l) - 1; i >= 0; i-- {
+		for j := len(r) - 1; j >= 0; j-- {
+			if lKeys[i] == rKeys[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j, k := 0, 0, 0 // k is the current index in the array being patched.
+	for i < len(l) || j < len(r) {
+		switch {
+		case i < len(l) && j < len(r) && lKeys[i] == rKeys[j]:
+			i++
+			j++
+			k++
+		case i < len(l) && j < len(r) && lcs[i+1][j] == lcs[i][j+1]:
+			// neither side is part of the common sequence, so compare the elements.
+			diffNodes(l[i], r[j], pointer+"/"+strconv.Itoa(k), p)
+			i++
+			j++
+			k++
+		case j >= len(r) || (i < len(l) && lcs[i+1][j] >= lcs[i][j+1]):
+			*p = append(*p, &Operation{Op: OpRemove, Path: pointer + "/" + strconv.Itoa(k)})
+			i++
+		default:
+			*p = append(*p, &Operation{Op: OpAdd, Path: pointer + "/" + strconv.Itoa(k), Value: r[j]})
+			j++
+			k++
+		}
+	}
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// location is the JSON Pointer and value node of a node found inside a yaml.Node tree.
+type location struct {
+	pointer string
+	node    *yaml.Node
+}
+
+// indexLocations walks a yaml.Node tree and indexes the location of every node, keyed by the address of the
+// node's Line field. This is the same address held by a model.ChangeContext, so every change can be mapped
+// back to the exact node it was created from. Key nodes are indexed as the location of their value.
+func indexLocations(node *yaml.Node, pointer string, idx map[*int]*location) {
+	if node == nil {
+		return
+	}
+	if _, ok := idx[&node.Line]; ok {
+		return // already seen (anchors / aliases)
+	}
+	idx[&node.Line] = &location{pointer: pointer, node: node}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			indexLocations(n, pointer, idx)
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			p := pointer + "/" + EscapeToken(node.Content[i].Value)
+			if _, ok := idx[&node.Content[i].Line]; !ok {
+				idx[&node.Content[i].Line] = &location{pointer: p, node: node.Content[i+1]}
+			}
+			indexLocations(node.Content[i+1], p, idx)
+		}
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			indexLocations(n, pointer+"/"+strconv.Itoa(i), idx)
+		}
+	}
+}
+
+// FromChanges will generate a Patch that transforms the original document into the updated document, using the
+// changes found by what-changed. The position of every change is used to locate the nodes that changed in each
+// document, and an operation is created for each one, renamed and moved objects are expressed as move operations.
+//
+// Not everything in a document is compared by what-changed (like unknown properties), so once the operations
+// for every change have been generated, the two documents are compared structurally and any remaining differences
+// are added to the patch. This guarantees that applying the patch to the original will produce the updated document.
+func FromChanges(changes []*model.Change, original, updated *yaml.Node) Patch {
+	lIdx := make(map[*int]*location)
+	rIdx := make(map[*int]*location)
+	indexLocations(original, "", lIdx)
+	indexLocations(updated, "", rIdx)
+
+	var moves, edits, removes, adds Patch
+	for _, c := range changes {
+		if c == nil || c.Context == nil {
+			continue
+		}
+		var l, r *location
+		if c.Context.OriginalLine != nil {
+			l = lIdx[c.Context.OriginalLine]
+		}
+		if c.Context.NewLine != nil {
+			r = rIdx[c.Context.NewLine]
+		}
+		switch c.ChangeType {
+		case model.Modified:
+			if l != nil && r != nil && l.pointer == r.pointer {
+				edits = append(edits, Diff(l.node, r.node, l.pointer)...)
+			}
+		case model.PropertyAdded, model.ObjectAdded:
+			if r != nil && l == nil {
+				if _, err := Find(original, r.pointer); err != nil {
+					adds = append(adds, &Operation{Op: OpAdd, Path: r.pointer, Value: r.node})
+				}
+			}
+		case model.PropertyRemoved, model.ObjectRemoved:
+			if l != nil && r == nil {
+				if _, err := Find(updated, l.pointer); err != nil {
+					removes = append(removes, &Operation{Op: OpRemove, Path: l.pointer})
+				}
+			}
+		case model.Renamed, model.Moved:
+			if l != nil && r != nil && l.pointer != r.pointer {
+				moves = append(moves, &Operation{Op: OpMove, From: l.pointer, Path: r.pointer})
+				edits = append(edits, Diff(l.node, r.node, r.pointer)...)
+			}
+		}
+	}
+
+	// removals are applied deepest / last first, so array indexes remain valid, additions are the opposite.
+	sort.SliceStable(removes, func(i, j int) bool {
+		return comparePointers(removes[i].Path, removes[j].Path) > 0
+	})
+	sort.SliceStable(adds, func(i, j int) bool {
+		return comparePointers(adds[i].Path, adds[j].Path) < 0
+	})
+
+	var p Patch
+	p = append(p, moves...)
+	p = append(p, edits...)
+	p = append(p, removes...)
+	p = append(p, adds...)
+	p = compact(p)
+
+	// make sure the patch works, and catch anything what-changed does not look at.
+	patched, err := p.ApplyToCopy(original)
+	if err != nil {
+		return Diff(original, updated, "")
+	}
+	return append(p, Diff(patched, updated, "")...)
+}
+
+// compact removes duplicate operations (changes to shared components are reported for every use), and
+// operations made redundant by an add, remove or replace operation on a parent.
+func compact(p Patch) Patch {
+	seen := make(map[string]bool)
+	var covering []string
+	var result Patch
+	for _, op := range p {
+		var key string
+		if op.Value != nil {
+			b, _ := MarshalNodeJSON(op.Value)
+			key = op.Op + op.From + "|" + op.Path + "|" + string(b)
+		} else {
+			key = op.Op + op.From + "|" + op.Path
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		covered := false
+		for _, c := range covering {
+			if strings.HasPrefix(op.Path, c+"/") {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+		if op.Op == OpAdd || op.Op == OpRemove || op.Op == OpReplace {
+			covering = append(covering, op.Path)
+		}
+		result = append(result, op)
+	}
+	return result
+}
+
+// comparePointers compares two JSON Pointers token by token, numeric tokens are compared as numbers.
+func comparePointers(a, b string) int {
+	at, _ := ParsePointer(a)
+	bt, _ := ParsePointer(b)
+	for i := 0; i < len(at) && i < len(bt); i++ {
+		if at[i] == bt[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(at[i])
+		bn, bErr := strconv.Atoi(bt[i])
+		if aErr == nil && bErr == nil {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(at[i], bt[i])
+	}
+	return len(at) - len(bt)
+}
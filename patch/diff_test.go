@@ -0,0 +1,144 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package patch
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/what-changed/model"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func assertPatchTransforms(t *testing.T, p Patch, original, updated *yaml.Node) {
+	patched, err := p.ApplyToCopy(original)
+	assert.NoError(t, err)
+	assert.True(t, NodesEqual(patched, updated))
+}
+
+func TestDiff(t *testing.T) {
+	l := parseYAML(t, `info:
+  title: burgers
+  version: 1.0.0
+tags: [a, b, c, d]
+servers:
+  - url: https://a.com
+  - url: https://b.com`)
+	r := parseYAML(t, `info:
+  title: burger shop
+  contact:
+    name: dave
+tags: [a, c, d, e]
+servers:
+  - url: https://a.com
+  - url: https://c.com`)
+
+	p := Diff(l, r, "")
+	b, _ := p.Render()
+	assert.Equal(t, `[
+  {
+    "op": "replace",
+    "path": "/info/title",
+    "value": "burger shop"
+  },
+  {
+    "op": "remove",
+    "path": "/info/version"
+  },
+  {
+    "op": "add",
+    "path": "/info/contact",
+    "value": {
+      "name": "dave"
+    }
+  },
+  {
+    "op": "remove",
+    "path": "/tags/1"
+  },
+  {
+    "op": "add",
+    "path": "/tags/3",
+    "value": "e"
+  },
+  {
+    "op": "replace",
+    "path": "/servers/1/url",
+    "value": "https://c.com"
+  }
+]`, string(b))
+	assertPatchTransforms(t, p, l, r)
+}
+
+func TestDiff_Equal(t *testing.T) {
+	l := parseYAML(t, `a: [1, 2, {b: c}]`)
+	assert.Empty(t, Diff(l, l, ""))
+}
+
+func TestDiff_KindChange(t *testing.T) {
+	l := parseYAML(t, `a: [1, 2]`)
+	r := parseYAML(t, `a: {b: 1}`)
+	p := Diff(l, r, "")
+	assert.Len(t, p, 1)
+	assert.Equal(t, OpReplace, p[0].Op)
+	assertPatchTransforms(t, p, l, r)
+}
+
+func TestFromChanges_UsesMoves(t *testing.T) {
+	l := parseYAML(t, `components:
+  schemas:
+    Pet:
+      type: object
+      description: a pet`)
+	r := parseYAML(t, `components:
+  schemas:
+    Animal:
+      type: object
+      description: a pet`)
+
+	lPet := l.Content[0].Content[1].Content[1].Content[1]
+	rAnimal := r.Content[0].Content[1].Content[1].Content[1]
+	changes := []*model.Change{{
+		ChangeType: model.Renamed,
+		Context:    model.CreateContext(lPet, rAnimal),
+	}}
+
+	p := FromChanges(changes, l, r)
+	assert.Len(t, p, 1)
+	assert.Equal(t, OpMove, p[0].Op)
+	assert.Equal(t, "/components/schemas/Pet", p[0].From)
+	assert.Equal(t, "/components/schemas/Animal", p[0].Path)
+	assertPatchTransforms(t, p, l, r)
+}
+
+func TestFromChanges_FillsGaps(t *testing.T) {
+	l := parseYAML(t, `info:
+  title: burgers
+x-unknown: 1`)
+	r := parseYAML(t, `info:
+  title: burger shop
+x-unknown: 2`)
+
+	title := func(n *yaml.Node) *yaml.Node {
+		return n.Content[0].Content[1].Content[1]
+	}
+	changes := []*model.Change{
+		{ChangeType: model.Modified, Context: model.CreateContext(title(l), title(r))},
+		{ChangeType: model.Modified, Context: model.CreateContext(title(l), title(r))}, // duplicates are removed.
+		nil,
+	}
+
+	p := FromChanges(changes, l, r)
+	assert.Len(t, p, 2)
+	assert.Equal(t, "/info/title", p[0].Path)
+	assert.Equal(t, "/x-unknown", p[1].Path)
+	assertPatchTransforms(t, p, l, r)
+}
+
+func TestComparePointers(t *testing.T) {
+	assert.Less(t, comparePointers("/a/2", "/a/10"), 0)
+	assert.Greater(t, comparePointers("/b", "/a/10"), 0)
+	assert.Less(t, comparePointers("/a", "/a/1"), 0)
+	assert.Equal(t, 0, comparePointers("/a/1", "/a/1"))
+}
@@ -0,0 +1,607 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package patch provides support for RFC 6902 JSON Patch documents (https://www.rfc-editor.org/rfc/rfc6902) that
+// operate directly on a yaml.Node tree.
+//
+// Applying a patch to a yaml.Node tree (rather than a decoded map) means that comments, key ordering and formatting
+// of everything not touched by the patch is preserved, which makes patches a great way of shipping reviewable changes
+// to a specification between teams.
+//
+// Patches can be generated by comparing two yaml.Node trees (Diff), or from the changes found by what-changed
+// (FromChanges).
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definitions of the operations available to a JSON Patch.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation represents a single RFC 6902 JSON Patch operation. The Path (and From for move and copy operations)
+// are RFC 6901 JSON Pointers. The Value is held as a yaml.Node, so comments and ordering are kept intact when applied.
+type Operation struct {
+	Op    string     `json:"op" yaml:"op"`
+	Path  string     `json:"path" yaml:"path"`
+	From  string     `json:"from,omitempty" yaml:"from,omitempty"`
+	Value *yaml.Node `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Patch is an ordered collection of JSON Patch operations.
+type Patch []*Operation
+
+// MarshalJSON will render the Operation as JSON, the Value is rendered with the original key ordering.
+func (o *Operation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"op":`)
+	writeJSONString(&buf, o.Op)
+	buf.WriteString(`,"path":`)
+	writeJSONString(&buf, o.Path)
+	if o.From != "" {
+		buf.WriteString(`,"from":`)
+		writeJSONString(&buf, o.From)
+	}
+	if o.Value != nil {
+		buf.WriteString(`,"value":`)
+		if err := writeNodeJSON(&buf, o.Value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON will parse a JSON Patch operation, the Value is parsed into a yaml.Node.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	op, err := parseOperation(unwrapDocument(&node))
+	if err != nil {
+		return err
+	}
+	*o = *op
+	return nil
+}
+
+// Render will render the Patch as an indented JSON document.
+func (p Patch) Render() ([]byte, error) {
+	if p == nil {
+		p = Patch{}
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParsePatch will parse a JSON Patch document. The document can be either JSON or YAML. Each operation is
+// checked to make sure it has the properties the operation requires.
+func ParsePatch(data []byte) (Patch, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("unable to parse patch: %s", err.Error())
+	}
+	root := unwrapDocument(&node)
+	if root == nil || root.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("unable to parse patch: a patch must be an array of operations")
+	}
+	p := make(Patch, 0, len(root.Content))
+	for i, n := range root.Content {
+		op, err := parseOperation(n)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse patch operation %d: %s", i, err.Error())
+		}
+		p = append(p, op)
+	}
+	return p, nil
+}
+
+func parseOperation(n *yaml.Node) (*Operation, error) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("operation must be an object")
+	}
+	op := new(Operation)
+	pathSet := false
+	fromSet := false
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		v := n.Content[i+1]
+		switch n.Content[i].Value {
+		case "op":
+			op.Op = v.Value
+		case "path":
+			op.Path = v.Value
+			pathSet = true
+		case "from":
+			op.From = v.Value
+			fromSet = true
+		case "value":
+			op.Value = v
+			clearStyle(v)
+		}
+	}
+	if !pathSet {
+		return nil, fmt.Errorf("'%s' operation is missing a 'path'", op.Op)
+	}
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		if op.Value == nil {
+			return nil, fmt.Errorf("'%s' operation is missing a 'value'", op.Op)
+		}
+	case OpMove, OpCopy:
+		if !fromSet {
+			return nil, fmt.Errorf("'%s' operation is missing 'from'", op.Op)
+		}
+	case OpRemove:
+	default:
+		return nil, fmt.Errorf("unknown operation '%s'", op.Op)
+	}
+	return op, nil
+}
+
+// clearStyle removes the quoting and flow style a value picks up when a patch is written in JSON, so patched
+// values are rendered in the same style as the rest of a YAML document. Strings that would otherwise be read
+// back as another type are still quoted when rendered.
+func clearStyle(n *yaml.Node) {
+	if n == nil {
+		return
+	}
+	n.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle | yaml.FlowStyle
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
+
+// Apply will apply every operation in the Patch (in order) to the supplied yaml.Node tree, the tree is modified
+// in place. If any operation fails, an error is returned and the tree may be partially patched, so use
+// ApplyToCopy if the original tree must be left intact.
+func (p Patch) Apply(root *yaml.Node) error {
+	doc := root
+	if doc == nil {
+		return fmt.Errorf("unable to apply patch: no document")
+	}
+	if doc.Kind != yaml.DocumentNode {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	}
+	for i, op := range p {
+		if err := applyOperation(doc, op); err != nil {
+			return fmt.Errorf("unable to apply patch operation %d (%s %s): %s", i, op.Op, op.Path, err.Error())
+		}
+	}
+	if root.Kind != yaml.DocumentNode && len(doc.Content) > 0 {
+		*root = *doc.Content[0]
+	}
+	return nil
+}
+
+// ApplyToCopy will apply the patch to a deep copy of the supplied yaml.Node tree, and return the patched copy.
+// The original tree is never modified.
+func (p Patch) ApplyToCopy(root *yaml.Node) (*yaml.Node, error) {
+	c := CopyNode(root)
+	if err := p.Apply(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func applyOperation(doc *yaml.Node, op *Operation) error {
+	switch op.Op {
+	case OpAdd:
+		return add(doc, op.Path, CopyNode(op.Value))
+	case OpRemove:
+		_, err := remove(doc, op.Path)
+		return err
+	case OpReplace:
+		parent, key, err := locateParent(doc, op.Path)
+		if err != nil {
+			return err
+		}
+		old, err := child(parent, key)
+		if err != nil {
+			return err
+		}
+		replacement := CopyNode(op.Value)
+		keepComments(old, replacement)
+		return setChild(parent, key, replacement)
+	case OpMove:
+		if op.From == op.Path {
+			return nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return fmt.Errorf("cannot move a value into one of its own children")
+		}
+		// a move inside the same object is a rename, keep the key where it is.
+		fromParent, fromKey, err := locateParent(doc, op.From)
+		if err != nil {
+			return err
+		}
+		toParent, toKey, err := locateParent(doc, op.Path)
+		if err == nil && fromParent == toParent && fromParent.Kind == yaml.MappingNode {
+			if _, e := child(toParent, toKey); e != nil {
+				for i := 0; i < len(fromParent.Content)-1; i += 2 {
+					if fromParent.Content[i].Value == fromKey {
+						fromParent.Content[i].Value = toKey
+						return nil
+					}
+				}
+			}
+		}
+		v, err := remove(doc, op.From)
+		if err != nil {
+			return err
+		}
+		return add(doc, op.Path, v)
+	case OpCopy:
+		v, err := Find(doc, op.From)
+		if err != nil {
+			return err
+		}
+		return add(doc, op.Path, CopyNode(v))
+	case OpTest:
+		v, err := Find(doc, op.Path)
+		if err != nil {
+			return err
+		}
+		if !NodesEqual(v, op.Value) {
+			return fmt.Errorf("test failed, value does not match")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation '%s'", op.Op)
+}
+
+func add(doc *yaml.Node, path string, value *yaml.Node) error {
+	if path == "" {
+		doc.Content = []*yaml.Node{value}
+		return nil
+	}
+	parent, key, err := locateParent(doc, path)
+	if err != nil {
+		return err
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(parent.Content)-1; i += 2 {
+			if parent.Content[i].Value == key {
+				keepComments(parent.Content[i+1], value)
+				parent.Content[i+1] = value
+				return nil
+			}
+		}
+		parent.Content = append(parent.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		return nil
+	case yaml.SequenceNode:
+		idx := len(parent.Content)
+		if key != "-" {
+			idx, err = arrayIndex(key, len(parent.Content))
+			if err != nil {
+				return err
+			}
+		}
+		parent.Content = append(parent.Content, nil)
+		copy(parent.Content[idx+1:], parent.Content[idx:])
+		parent.Content[idx] = value
+		return nil
+	}
+	return fmt.Errorf("cannot add to a scalar value")
+}
+
+func remove(doc *yaml.Node, path string) (*yaml.Node, error) {
+	if path == "" {
+		return nil, fmt.Errorf("cannot remove the root of a document")
+	}
+	parent, key, err := locateParent(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(parent.Content)-1; i += 2 {
+			if parent.Content[i].Value == key {
+				v := parent.Content[i+1]
+				parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("path does not exist")
+	case yaml.SequenceNode:
+		idx, e := arrayIndex(key, len(parent.Content)-1)
+		if e != nil {
+			return nil, e
+		}
+		v := parent.Content[idx]
+		parent.Content = append(parent.Content[:idx], parent.Content[idx+1:]...)
+		return v, nil
+	}
+	return nil, fmt.Errorf("path does not exist")
+}
+
+func child(parent *yaml.Node, key string) (*yaml.Node, error) {
+	switch parent.Kind {
+	case yaml.DocumentNode:
+		if len(parent.Content) > 0 {
+			return parent.Content[0], nil
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(parent.Content)-1; i += 2 {
+			if parent.Content[i].Value == key {
+				return parent.Content[i+1], nil
+			}
+		}
+	case yaml.SequenceNode:
+		idx, err := arrayIndex(key, len(parent.Content)-1)
+		if err != nil {
+			return nil, err
+		}
+		return parent.Content[idx], nil
+	case yaml.AliasNode:
+		if parent.Alias != nil {
+			return child(parent.Alias, key)
+		}
+	}
+	return nil, fmt.Errorf("path does not exist")
+}
+
+func setChild(parent *yaml.Node, key string, value *yaml.Node) error {
+	switch parent.Kind {
+	case yaml.DocumentNode:
+		parent.Content = []*yaml.Node{value}
+		return nil
+	case yaml.MappingNode:
+		for i := 0; i < len(parent.Content)-1; i += 2 {
+			if parent.Content[i].Value == key {
+				parent.Content[i+1] = value
+				return nil
+			}
+		}
+	case yaml.SequenceNode:
+		idx, err := arrayIndex(key, len(parent.Content)-1)
+		if err != nil {
+			return err
+		}
+		parent.Content[idx] = value
+		return nil
+	}
+	return fmt.Errorf("path does not exist")
+}
+
+// locateParent will find the parent container of the value the pointer points to, and return it with the
+// final (unescaped) token of the pointer. The root of a document has the document node as a parent.
+func locateParent(doc *yaml.Node, pointer string) (*yaml.Node, string, error) {
+	if pointer == "" {
+		return doc, "", nil
+	}
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	node, err := child(doc, "")
+	if err != nil {
+		return nil, "", err
+	}
+	for _, t := range tokens[:len(tokens)-1] {
+		node, err = child(node, t)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return node, tokens[len(tokens)-1], nil
+}
+
+// Find will locate the yaml.Node a JSON Pointer points to, inside the supplied yaml.Node tree.
+func Find(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	doc := root
+	if doc.Kind != yaml.DocumentNode {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	}
+	parent, key, err := locateParent(doc, pointer)
+	if err != nil {
+		return nil, err
+	}
+	return child(parent, key)
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("'%s' is not a valid array index", token)
+	}
+	if idx > max {
+		return 0, fmt.Errorf("array index %d is out of bounds", idx)
+	}
+	return idx, nil
+}
+
+// ParsePointer will parse an RFC 6901 JSON Pointer into its unescaped reference tokens.
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("'%s' is not a valid JSON pointer, it must start with a '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i := range tokens {
+		tokens[i] = UnescapeToken(tokens[i])
+	}
+	return tokens, nil
+}
+
+// BuildPointer will build an RFC 6901 JSON Pointer from a set of reference tokens, escaping each one.
+func BuildPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(EscapeToken(t))
+	}
+	return sb.String()
+}
+
+// EscapeToken escapes a JSON Pointer reference token ('~' becomes '~0' and '/' becomes '~1').
+func EscapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// UnescapeToken reverses EscapeToken.
+func UnescapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// keepComments moves any comments from a node being replaced onto the replacement, if it has none of its own.
+func keepComments(old, replacement *yaml.Node) {
+	if old == nil || replacement == nil {
+		return
+	}
+	if replacement.HeadComment == "" {
+		replacement.HeadComment = old.HeadComment
+	}
+	if replacement.LineComment == "" {
+		replacement.LineComment = old.LineComment
+	}
+	if replacement.FootComment == "" {
+		replacement.FootComment = old.FootComment
+	}
+}
+
+// CopyNode will create a deep copy of a yaml.Node tree.
+func CopyNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	c := *node
+	if node.Content != nil {
+		c.Content = make([]*yaml.Node, len(node.Content))
+		for i := range node.Content {
+			c.Content[i] = CopyNode(node.Content[i])
+		}
+	}
+	return &c
+}
+
+// NodesEqual returns true if two yaml.Node trees represent the same JSON value. The members of objects are compared
+// regardless of their order, as RFC 6902 requires of the test operation.
+func NodesEqual(l, r *yaml.Node) bool {
+	lv, lErr := nodeValue(l)
+	rv, rErr := nodeValue(r)
+	return lErr == nil && rErr == nil && reflect.DeepEqual(lv, rv)
+}
+
+// nodeValue decodes a yaml.Node tree into the value of its JSON.
+func nodeValue(node *yaml.Node) (any, error) {
+	j, err := MarshalNodeJSON(node)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err = json.Unmarshal(j, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// MarshalNodeJSON renders a yaml.Node tree as compact JSON, keeping the original ordering of keys.
+func MarshalNodeJSON(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeNodeJSON(&buf, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unwrapDocument(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	return node
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+func writeNodeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	if node == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeNodeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeNodeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteString("{")
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteString(":")
+			if err := writeNodeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case yaml.SequenceNode:
+		buf.WriteString("[")
+		for i, n := range node.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeNodeJSON(buf, n); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+	default:
+		switch node.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool", "!!int", "!!float":
+			var v any
+			if err := node.Decode(&v); err != nil {
+				return err
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				// things like .inf and .nan cannot be represented in JSON.
+				writeJSONString(buf, node.Value)
+				return nil
+			}
+			buf.Write(b)
+		default:
+			writeJSONString(buf, node.Value)
+		}
+	}
+	return nil
+}
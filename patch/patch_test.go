@@ -0,0 +1,226 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func parseYAML(t *testing.T, s string) *yaml.Node {
+	var n yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(s), &n))
+	return &n
+}
+
+func renderYAML(t *testing.T, n *yaml.Node) string {
+	b, err := yaml.Marshal(n)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestParsePatch(t *testing.T) {
+	p, err := ParsePatch([]byte(`[
+  {"op": "add", "path": "/info/title", "value": "burgers"},
+  {"op": "remove", "path": "/paths/~1burgers"},
+  {"op": "move", "from": "/a", "path": "/b"}
+]`))
+	assert.NoError(t, err)
+	assert.Len(t, p, 3)
+	assert.Equal(t, OpAdd, p[0].Op)
+	assert.Equal(t, "burgers", p[0].Value.Value)
+	assert.Equal(t, "/paths/~1burgers", p[1].Path)
+	assert.Equal(t, "/a", p[2].From)
+}
+
+func TestParsePatch_YAML(t *testing.T) {
+	p, err := ParsePatch([]byte(`- op: replace
+  path: /info
+  value:
+    title: burgers # tasty
+    version: 1.0`))
+	assert.NoError(t, err)
+	assert.Len(t, p, 1)
+	assert.Equal(t, yaml.MappingNode, p[0].Value.Kind)
+}
+
+func TestParsePatch_Errors(t *testing.T) {
+	_, err := ParsePatch([]byte(`{"op": "add"}`))
+	assert.Error(t, err)
+
+	_, err = ParsePatch([]byte(`[{"op": "add", "path": "/a"}]`))
+	assert.Equal(t, "unable to parse patch operation 0: 'add' operation is missing a 'value'", err.Error())
+
+	_, err = ParsePatch([]byte(`[{"op": "move", "path": "/a"}]`))
+	assert.Equal(t, "unable to parse patch operation 0: 'move' operation is missing 'from'", err.Error())
+
+	_, err = ParsePatch([]byte(`[{"op": "eat", "path": "/a"}]`))
+	assert.Equal(t, "unable to parse patch operation 0: unknown operation 'eat'", err.Error())
+
+	_, err = ParsePatch([]byte(`[{"op": "remove"}]`))
+	assert.Error(t, err)
+
+	_, err = ParsePatch([]byte(`[1, 2`))
+	assert.Error(t, err)
+}
+
+func TestPatch_Apply_PreservesCommentsAndOrdering(t *testing.T) {
+	doc := parseYAML(t, `# the burger shop
+openapi: 3.1.0
+info:
+  title: burgers # the title
+  version: 1.0.0
+paths:
+  /burgers:
+    get:
+      description: get burgers
+  /fries:
+    get:
+      description: get fries`)
+
+	p, _ := ParsePatch([]byte(`[
+  {"op": "replace", "path": "/info/title", "value": "burger shop"},
+  {"op": "add", "path": "/info/description", "value": "all about burgers"},
+  {"op": "add", "path": "/info/x-build", "value": "2.0"},
+  {"op": "move", "from": "/paths/~1fries", "path": "/paths/~1chips"},
+  {"op": "remove", "path": "/paths/~1burgers/get/description"},
+  {"op": "test", "path": "/openapi", "value": "3.1.0"}
+]`))
+
+	assert.NoError(t, p.Apply(doc))
+	assert.Equal(t, `# the burger shop
+openapi: 3.1.0
+info:
+    title: burger shop # the title
+    version: 1.0.0
+    description: all about burgers
+    x-build: "2.0"
+paths:
+    /burgers:
+        get: {}
+    /chips:
+        get:
+            description: get fries
+`, renderYAML(t, doc))
+}
+
+func TestPatch_Apply_Arrays(t *testing.T) {
+	doc := parseYAML(t, `tags: [a, b, c]`)
+	p, _ := ParsePatch([]byte(`[
+  {"op": "add", "path": "/tags/0", "value": "z"},
+  {"op": "add", "path": "/tags/-", "value": "y"},
+  {"op": "remove", "path": "/tags/2"},
+  {"op": "copy", "from": "/tags/0", "path": "/other"},
+  {"op": "replace", "path": "/tags/1", "value": {"name": "x"}}
+]`))
+	assert.NoError(t, p.Apply(doc))
+	j, _ := MarshalNodeJSON(doc)
+	assert.Equal(t, `{"tags":["z",{"name":"x"},"c","y"],"other":"z"}`, string(j))
+}
+
+func TestPatch_Apply_TestUnorderedObject(t *testing.T) {
+	doc := parseYAML(t, `info:
+  title: burgers
+  version: 1.0.0
+  contact:
+    name: chef
+    email: chef@pb33f.io`)
+
+	// the members of objects can be in any order.
+	p, _ := ParsePatch([]byte(`[{"op": "test", "path": "/info",
+  "value": {"contact": {"email": "chef@pb33f.io", "name": "chef"}, "version": "1.0.0", "title": "burgers"}}]`))
+	assert.NoError(t, p.Apply(doc))
+
+	p, _ = ParsePatch([]byte(`[{"op": "test", "path": "/info",
+  "value": {"contact": {"email": "chef@pb33f.io"}, "version": "1.0.0", "title": "burgers"}}]`))
+	assert.Error(t, p.Apply(doc))
+}
+
+func TestPatch_Apply_Errors(t *testing.T) {
+	doc := parseYAML(t, `tags: [a, b, c]
+info:
+  title: hello`)
+
+	tests := []struct {
+		patch string
+		err   string
+	}{
+		{`[{"op": "remove", "path": "/nope"}]`, "unable to apply patch operation 0 (remove /nope): path does not exist"},
+		{`[{"op": "remove", "path": "/tags/3"}]`, "unable to apply patch operation 0 (remove /tags/3): array index 3 is out of bounds"},
+		{`[{"op": "add", "path": "/tags/01", "value": 1}]`, "unable to apply patch operation 0 (add /tags/01): '01' is not a valid array index"},
+		{`[{"op": "add", "path": "/info/title/a", "value": 1}]`, "unable to apply patch operation 0 (add /info/title/a): cannot add to a scalar value"},
+		{`[{"op": "replace", "path": "/info/nope", "value": 1}]`, "unable to apply patch operation 0 (replace /info/nope): path does not exist"},
+		{`[{"op": "test", "path": "/info/title", "value": "bye"}]`, "unable to apply patch operation 0 (test /info/title): test failed, value does not match"},
+		{`[{"op": "move", "from": "/info", "path": "/info/child"}]`, "unable to apply patch operation 0 (move /info/child): cannot move a value into one of its own children"},
+		{`[{"op": "remove", "path": ""}]`, "unable to apply patch operation 0 (remove ): cannot remove the root of a document"},
+		{`[{"op": "remove", "path": "info"}]`, "unable to apply patch operation 0 (remove info): 'info' is not a valid JSON pointer, it must start with a '/'"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePatch([]byte(tt.patch))
+		assert.NoError(t, err)
+		_, err = p.ApplyToCopy(doc)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestPatch_Apply_NotDocumentNode(t *testing.T) {
+	doc := parseYAML(t, `a: b`)
+	p, _ := ParsePatch([]byte(`[{"op": "replace", "path": "", "value": [1, 2]}]`))
+	root := doc.Content[0]
+	assert.NoError(t, p.Apply(root))
+	assert.Equal(t, yaml.SequenceNode, root.Kind)
+	assert.Error(t, p.Apply(nil))
+}
+
+func TestPatch_Render(t *testing.T) {
+	p := Patch{
+		{Op: OpAdd, Path: "/info", Value: parseYAML(t, "title: burgers\nversion: 1.2\nfree: true\nnothing: null").Content[0]},
+		{Op: OpMove, From: "/a", Path: "/b"},
+	}
+	b, err := p.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `[
+  {
+    "op": "add",
+    "path": "/info",
+    "value": {
+      "title": "burgers",
+      "version": 1.2,
+      "free": true,
+      "nothing": null
+    }
+  },
+  {
+    "op": "move",
+    "path": "/b",
+    "from": "/a"
+  }
+]`, string(b))
+
+	// round trip
+	parsed, err := ParsePatch(b)
+	assert.NoError(t, err)
+	assert.Len(t, parsed, 2)
+
+	var empty Patch
+	b, _ = empty.Render()
+	assert.Equal(t, "[]", string(b))
+}
+
+func TestPointers(t *testing.T) {
+	assert.Equal(t, "/paths/~1pets~1{id}/get", BuildPointer("paths", "/pets/{id}", "get"))
+	tokens, err := ParsePointer("/paths/~1pets~1{id}/x~0y")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"paths", "/pets/{id}", "x~y"}, tokens)
+
+	doc := parseYAML(t, `paths:
+  /pets/{id}:
+    get:
+      tags: [a, b]`)
+	n, err := Find(doc, "/paths/~1pets~1{id}/get/tags/1")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", n.Value)
+}
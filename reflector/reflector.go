@@ -0,0 +1,325 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package reflector creates OpenAPI schemas from Go types, so code-first services can describe their types with
+// libopenapi and render them with the rest of a document.
+//
+// Struct fields are named by their json tags, and fields without omitempty are required. Embedded structs are
+// composed with allOf, time.Time is a date-time string, and types that marshal themselves are strings (when they
+// implement encoding.TextMarshaler) or any value (when they implement json.Marshaler). Struct tags set the
+// description, enum, format, pattern, minimum, maximum, minLength, maxLength, minItems and maxItems of fields.
+//
+// Named types are registered as schemas of components, and referenced with $ref.
+package reflector
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// componentSchemaPrefix is the prefix of references to the schemas of components.
+const componentSchemaPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// qualifier matches the package paths of the type arguments in the names of generic types.
+var qualifier = regexp.MustCompile(`[\w./-]*\.`)
+
+// Reflector creates schemas from Go types, registering the schemas of named types.
+type Reflector struct {
+	schemas map[string]*base.SchemaProxy
+	names   map[reflect.Type]string
+}
+
+// NewReflector creates a new Reflector, without any registered schemas.
+func NewReflector() *Reflector {
+	return &Reflector{
+		schemas: make(map[string]*base.SchemaProxy),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Reflect returns the schema of the type of a value. The schema of a named type is a reference to the schema
+// registered for the type.
+func (r *Reflector) Reflect(value any) (*base.SchemaProxy, error) {
+	if value == nil {
+		return nil, fmt.Errorf("cannot reflect a schema from a nil value")
+	}
+	return r.ReflectType(reflect.TypeOf(value))
+}
+
+// ReflectType returns the schema of a type, as Reflect does for the type of a value.
+func (r *Reflector) ReflectType(t reflect.Type) (*base.SchemaProxy, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if schema := special(t); schema != nil {
+		return base.CreateSchemaProxy(schema), nil
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		schema, err := r.build(t)
+		if err != nil {
+			return nil, err
+		}
+		return base.CreateSchemaProxy(schema), nil
+	}
+	name, ok := r.names[t]
+	if !ok {
+		// the name is registered first, so recursive types reference themselves.
+		name = r.register(t)
+		schema, err := r.build(t)
+		if err != nil {
+			delete(r.names, t)
+			delete(r.schemas, name)
+			return nil, err
+		}
+		r.schemas[name] = base.CreateSchemaProxy(schema)
+	}
+	return base.CreateSchemaProxyRef(componentSchemaPrefix + name), nil
+}
+
+// Schemas returns the schemas of the named types that have been reflected, keyed by their name, ready to be used
+// as the schemas of the components of a document.
+func (r *Reflector) Schemas() map[string]*base.SchemaProxy {
+	return r.schemas
+}
+
+// register reserves the name of the schema of a named type. Types with the same name in different packages are
+// prefixed with the name of their package, and numbered if that is used too.
+func (r *Reflector) register(t reflect.Type) string {
+	name := typeName(t.Name())
+	if r.isRegistered(name) {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = typeName(pkg) + name
+		for i, prefix := 2, name; r.isRegistered(name); i++ {
+			name = fmt.Sprintf("%s%d", prefix, i)
+		}
+	}
+	r.names[t] = name
+	r.schemas[name] = nil
+	return name
+}
+
+func (r *Reflector) isRegistered(name string) bool {
+	_, ok := r.schemas[name]
+	return ok
+}
+
+// build creates the schema of a type.
+func (r *Reflector) build(t reflect.Type) (*base.Schema, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &base.Schema{Type: []string{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16:
+		return &base.Schema{Type: []string{"integer"}}, nil
+	case reflect.Int32:
+		return &base.Schema{Type: []string{"integer"}, Format: "int32"}, nil
+	case reflect.Int64:
+		return &base.Schema{Type: []string{"integer"}, Format: "int64"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		minimum := float64(0)
+		return &base.Schema{Type: []string{"integer"}, Minimum: &minimum}, nil
+	case reflect.Float32:
+		return &base.Schema{Type: []string{"number"}, Format: "float"}, nil
+	case reflect.Float64:
+		return &base.Schema{Type: []string{"number"}, Format: "double"}, nil
+	case reflect.String:
+		return &base.Schema{Type: []string{"string"}}, nil
+	case reflect.Interface:
+		return &base.Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &base.Schema{Type: []string{"string"}, Format: "byte"}, nil
+		}
+		items, err := r.ReflectType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema := &base.Schema{Type: []string{"array"}, Items: &base.DynamicValue[*base.SchemaProxy, bool]{A: items}}
+		if t.Kind() == reflect.Array {
+			length := int64(t.Len())
+			schema.MinItems, schema.MaxItems = &length, &length
+		}
+		return schema, nil
+	case reflect.Map:
+		if !isMapKey(t.Key()) {
+			return nil, fmt.Errorf("cannot reflect a schema from type %s: map keys must be strings, integers or "+
+				"implement encoding.TextMarshaler", t)
+		}
+		values, err := r.ReflectType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &base.Schema{
+			Type:                 []string{"object"},
+			AdditionalProperties: &base.DynamicValue[*base.SchemaProxy, bool]{A: values},
+		}, nil
+	case reflect.Struct:
+		return r.structSchema(t)
+	}
+	return nil, fmt.Errorf("cannot reflect a schema from type %s: %s values cannot be encoded as JSON", t, t.Kind())
+}
+
+// structSchema creates the schema of a struct, an object with a property for every field. Embedded structs are
+// composed with allOf.
+func (r *Reflector) structSchema(t reflect.Type) (*base.Schema, error) {
+	object := &base.Schema{Type: []string{"object"}, Properties: make(map[string]*base.SchemaProxy)}
+	var allOf []*base.SchemaProxy
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			embedded, err := r.ReflectType(fieldType)
+			if err != nil {
+				return nil, fmt.Errorf("unable to reflect embedded field %s.%s: %w", t, field.Name, err)
+			}
+			allOf = append(allOf, embedded)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property, err := r.fieldSchema(field, hasOption(options, "string"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to reflect field %s.%s: %w", t, field.Name, err)
+		}
+		object.Properties[name] = property
+		if !hasOption(options, "omitempty") {
+			object.Required = append(object.Required, name)
+		}
+	}
+	if len(object.Properties) == 0 {
+		object.Properties = nil
+	}
+	if len(allOf) == 0 {
+		return object, nil
+	}
+	if object.Properties != nil {
+		allOf = append(allOf, base.CreateSchemaProxy(object))
+	}
+	return &base.Schema{AllOf: allOf}, nil
+}
+
+// fieldSchema creates the schema of a field, with the keywords of its struct tags. Fields encoded as strings (with
+// the string option of their json tag) are strings.
+func (r *Reflector) fieldSchema(field reflect.StructField, asString bool) (*base.SchemaProxy, error) {
+	fieldType := field.Type
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	var proxy *base.SchemaProxy
+	var err error
+	switch fieldType.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64:
+		if asString {
+			proxy = base.CreateSchemaProxy(&base.Schema{Type: []string{"string"}})
+			break
+		}
+		fallthrough
+	default:
+		if proxy, err = r.ReflectType(field.Type); err != nil {
+			return nil, err
+		}
+	}
+	if !hasKeywordTags(field.Tag) {
+		return proxy, nil
+	}
+
+	// keywords cannot be added to a reference, so it is wrapped by allOf.
+	var schema *base.Schema
+	if proxy.IsReference() {
+		schema = &base.Schema{AllOf: []*base.SchemaProxy{proxy}}
+	} else {
+		schema = proxy.Schema()
+	}
+	if asString {
+		fieldType = reflect.TypeOf("")
+	}
+	if err = applyTags(schema, field.Tag, fieldType); err != nil {
+		return nil, err
+	}
+	return base.CreateSchemaProxy(schema), nil
+}
+
+// special returns the schema of types that are not encoded by their kind, or nil for any other type.
+func special(t reflect.Type) *base.Schema {
+	switch {
+	case t == timeType:
+		return &base.Schema{Type: []string{"string"}, Format: "date-time"}
+	case t == durationType:
+		return &base.Schema{Type: []string{"integer"}, Format: "int64"}
+	case t == rawMessageType:
+		return &base.Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &base.Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &base.Schema{Type: []string{"string"}}
+	}
+	return nil
+}
+
+// isMapKey returns true if a type can be the key of a map encoded as JSON.
+func isMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// typeName returns the name of the schema of a type, without the packages of the type arguments of generic types,
+// or any characters that are not letters or digits.
+func typeName(name string) string {
+	name = qualifier.ReplaceAllString(name, "")
+	var b strings.Builder
+	upper := true
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
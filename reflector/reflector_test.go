@@ -0,0 +1,285 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package reflector
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type Entity struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Burger struct {
+	Entity
+	Name      string            `json:"name" description:"the name of the burger" minLength:"1" maxLength:"64"`
+	Status    string            `json:"status,omitempty" enum:"fresh,cold"`
+	Rating    *float64          `json:"rating,omitempty" minimum:"0" maximum:"5"`
+	Toppings  []string          `json:"toppings,omitempty" enum:"cheese, pickles" maxItems:"3"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Parent    *Burger           `json:"parent,omitempty" description:"the burger this is based on"`
+	Secret    string            `json:"-"`
+	Count     uint8             `json:"count,string"`
+	Image     []byte            `json:"image,omitempty"`
+	Anything  any               `json:"anything,omitempty"`
+	NoTag     bool
+	unexposed string
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Next  *string
+}
+
+type URL struct {
+	Raw string `json:"raw"`
+}
+
+type text struct{}
+
+func (text) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+func schemaOf(t *testing.T, r *Reflector, name string) *base.Schema {
+	proxy := r.Schemas()[name]
+	if !assert.NotNil(t, proxy, name) {
+		return &base.Schema{}
+	}
+	return proxy.Schema()
+}
+
+func TestReflector_Reflect(t *testing.T) {
+	r := NewReflector()
+	proxy, err := r.Reflect(&Burger{})
+	assert.NoError(t, err)
+	assert.True(t, proxy.IsReference())
+	assert.Equal(t, "#/components/schemas/Burger", proxy.GetReference())
+	assert.Len(t, r.Schemas(), 2)
+
+	entity := schemaOf(t, r, "Entity")
+	assert.Equal(t, []string{"object"}, entity.Type)
+	assert.Equal(t, []string{"id", "createdAt"}, entity.Required)
+	assert.Equal(t, "int64", entity.Properties["id"].Schema().Format)
+	assert.Equal(t, "date-time", entity.Properties["createdAt"].Schema().Format)
+
+	burger := schemaOf(t, r, "Burger")
+	assert.Len(t, burger.AllOf, 2)
+	assert.Equal(t, "#/components/schemas/Entity", burger.AllOf[0].GetReference())
+	object := burger.AllOf[1].Schema()
+	assert.Equal(t, []string{"name", "count", "NoTag"}, object.Required)
+	assert.Len(t, object.Properties, 10)
+	assert.NotContains(t, object.Properties, "Secret")
+	assert.NotContains(t, object.Properties, "unexposed")
+
+	name := object.Properties["name"].Schema()
+	assert.Equal(t, "the name of the burger", name.Description)
+	assert.Equal(t, int64(1), *name.MinLength)
+	assert.Equal(t, int64(64), *name.MaxLength)
+	assert.Equal(t, []any{"fresh", "cold"}, object.Properties["status"].Schema().Enum)
+
+	rating := object.Properties["rating"].Schema()
+	assert.Equal(t, []string{"number"}, rating.Type)
+	assert.Equal(t, 0.0, *rating.Minimum)
+	assert.Equal(t, 5.0, *rating.Maximum)
+
+	toppings := object.Properties["toppings"].Schema()
+	assert.Equal(t, int64(3), *toppings.MaxItems)
+	assert.Equal(t, []any{"cheese", "pickles"}, toppings.Items.A.Schema().Enum)
+
+	labels := object.Properties["labels"].Schema()
+	assert.Equal(t, []string{"object"}, labels.Type)
+	assert.Equal(t, []string{"string"}, labels.AdditionalProperties.A.Schema().Type)
+
+	// references with keywords are wrapped by allOf.
+	parent := object.Properties["parent"].Schema()
+	assert.Equal(t, "the burger this is based on", parent.Description)
+	assert.Equal(t, "#/components/schemas/Burger", parent.AllOf[0].GetReference())
+
+	assert.Equal(t, []string{"string"}, object.Properties["count"].Schema().Type)
+	assert.Equal(t, "byte", object.Properties["image"].Schema().Format)
+	assert.Empty(t, object.Properties["anything"].Schema().Type)
+	assert.Equal(t, []string{"boolean"}, object.Properties["NoTag"].Schema().Type)
+
+	// reflecting again uses the registered schema.
+	again, err := r.Reflect(Burger{})
+	assert.NoError(t, err)
+	assert.Equal(t, proxy.GetReference(), again.GetReference())
+	assert.Len(t, r.Schemas(), 2)
+}
+
+func TestReflector_ReflectType(t *testing.T) {
+	tests := []struct {
+		value  any
+		types  []string
+		format string
+	}{
+		{true, []string{"boolean"}, ""},
+		{1, []string{"integer"}, ""},
+		{int32(1), []string{"integer"}, "int32"},
+		{int64(1), []string{"integer"}, "int64"},
+		{float32(1), []string{"number"}, "float"},
+		{1.5, []string{"number"}, "double"},
+		{"burger", []string{"string"}, ""},
+		{time.Now(), []string{"string"}, "date-time"},
+		{time.Second, []string{"integer"}, "int64"},
+		{json.RawMessage(`{}`), nil, ""},
+		{net.IP{}, []string{"string"}, ""},
+		{text{}, []string{"string"}, ""},
+		{[]int{}, []string{"array"}, ""},
+		{map[int]bool{}, []string{"object"}, ""},
+		{struct{ A string }{}, []string{"object"}, ""},
+	}
+	for _, tc := range tests {
+		r := NewReflector()
+		proxy, err := r.Reflect(tc.value)
+		assert.NoError(t, err)
+		assert.False(t, proxy.IsReference(), reflect.TypeOf(tc.value))
+		assert.Equal(t, tc.types, proxy.Schema().Type, reflect.TypeOf(tc.value))
+		assert.Equal(t, tc.format, proxy.Schema().Format, reflect.TypeOf(tc.value))
+		assert.Empty(t, r.Schemas())
+	}
+
+	r := NewReflector()
+	proxy, err := r.ReflectType(reflect.TypeOf([2]uint{}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *proxy.Schema().MinItems)
+	assert.Equal(t, int64(2), *proxy.Schema().MaxItems)
+	assert.Equal(t, 0.0, *proxy.Schema().Items.A.Schema().Minimum)
+}
+
+func TestReflector_Names(t *testing.T) {
+	r := NewReflector()
+	_, err := r.Reflect(Page[Burger]{})
+	assert.NoError(t, err)
+	_, err = r.Reflect(URL{})
+	assert.NoError(t, err)
+	_, err = r.Reflect(url.URL{})
+	assert.NoError(t, err)
+
+	var names []string
+	for name := range r.Schemas() {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"PageBurger", "Burger", "Entity", "URL", "UrlURL", "Userinfo"}, names)
+	page := schemaOf(t, r, "PageBurger")
+	assert.Equal(t, []string{"items", "Next"}, page.Required)
+	assert.Equal(t, "#/components/schemas/Burger", page.Properties["items"].Schema().Items.A.GetReference())
+}
+
+func TestReflector_Errors(t *testing.T) {
+	r := NewReflector()
+	_, err := r.Reflect(nil)
+	assert.EqualError(t, err, "cannot reflect a schema from a nil value")
+
+	_, err = r.Reflect(make(chan int))
+	assert.EqualError(t, err, "cannot reflect a schema from type chan int: chan values cannot be encoded as JSON")
+
+	_, err = r.Reflect(map[Entity]string{})
+	assert.EqualError(t, err, "cannot reflect a schema from type map[reflector.Entity]string: map keys must be "+
+		"strings, integers or implement encoding.TextMarshaler")
+
+	type broken struct {
+		Callback func() `json:"callback"`
+	}
+	_, err = r.Reflect(broken{})
+	assert.EqualError(t, err, "unable to reflect field reflector.broken.Callback: cannot reflect a schema from "+
+		"type func(): func values cannot be encoded as JSON")
+	assert.Empty(t, r.Schemas())
+
+	type badTag struct {
+		Size int `json:"size" enum:"small"`
+	}
+	_, err = r.Reflect(badTag{})
+	assert.EqualError(t, err, "unable to reflect field reflector.badTag.Size: enum value 'small' is not an integer "+
+		"of type int")
+}
+
+type Sauce string
+
+type Meal struct {
+	Sauces []Sauce `json:"sauces" enum:"ketchup,mayo"`
+}
+
+func TestReflector_Reflect_EnumOfReferences(t *testing.T) {
+	r := NewReflector()
+	_, err := r.Reflect(Meal{})
+	assert.NoError(t, err)
+
+	// the enum is set on the items, which are wrapped by allOf as they are a reference.
+	sauces := schemaOf(t, r, "Meal").Properties["sauces"].Schema()
+	assert.Empty(t, sauces.Enum)
+	items := sauces.Items.A.Schema()
+	assert.Len(t, items.AllOf, 1)
+	assert.Equal(t, "#/components/schemas/Sauce", items.AllOf[0].GetReference())
+	assert.Equal(t, []any{"ketchup", "mayo"}, items.Enum)
+}
+
+func TestReflector_Render(t *testing.T) {
+	r := NewReflector()
+	type Topping struct {
+		Name    string   `json:"name" description:"the name of the topping"`
+		Calorie *float64 `json:"calories,omitempty" minimum:"0"`
+	}
+	type Order struct {
+		Toppings []Topping `json:"toppings" maxItems:"5"`
+		Size     string    `json:"size" enum:"small,large"`
+	}
+	proxy, err := r.Reflect(Order{})
+	assert.NoError(t, err)
+
+	doc := &v3.Document{
+		Version:    "3.1.0",
+		Info:       &base.Info{Title: "Burgers", Version: "1.0"},
+		Components: &v3.Components{Schemas: r.Schemas()},
+	}
+	rendered, err := doc.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `openapi: 3.1.0
+info:
+    title: Burgers
+    version: "1.0"
+components:
+    schemas:
+        Order:
+            type: object
+            properties:
+                size:
+                    type: string
+                    enum:
+                        - small
+                        - large
+                toppings:
+                    type: array
+                    items:
+                        $ref: '#/components/schemas/Topping'
+                    maxItems: 5
+            required:
+                - toppings
+                - size
+        Topping:
+            type: object
+            properties:
+                calories:
+                    type: number
+                    minimum: 0
+                    format: double
+                name:
+                    type: string
+                    description: the name of the topping
+            required:
+                - name`, strings.TrimSpace(string(rendered)))
+	assert.Equal(t, "#/components/schemas/Order", proxy.GetReference())
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// keywordTags are the struct tags that set the keywords of the schema of a field.
+var keywordTags = []string{
+	"description", "enum", "format", "pattern", "minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems",
+}
+
+func hasKeywordTags(tag reflect.StructTag) bool {
+	for _, key := range keywordTags {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTags sets the keywords of the schema of a field from its struct tags. Enums are comma separated values of the
+// type of the field, or the type of its items if it is a slice or array, and are set on its items (items that are a
+// reference are wrapped by allOf).
+func applyTags(schema *base.Schema, tag reflect.StructTag, fieldType reflect.Type) error {
+	if v, ok := tag.Lookup("description"); ok {
+		schema.Description = v
+	}
+	if v, ok := tag.Lookup("format"); ok {
+		schema.Format = v
+	}
+	if v, ok := tag.Lookup("pattern"); ok {
+		schema.Pattern = v
+	}
+	for _, number := range []struct {
+		key    string
+		target **float64
+	}{
+		{"minimum", &schema.Minimum},
+		{"maximum", &schema.Maximum},
+	} {
+		if v, ok := tag.Lookup(number.key); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("%s '%s' is not a number", number.key, v)
+			}
+			*number.target = &f
+		}
+	}
+	for _, count := range []struct {
+		key    string
+		target **int64
+	}{
+		{"minLength", &schema.MinLength},
+		{"maxLength", &schema.MaxLength},
+		{"minItems", &schema.MinItems},
+		{"maxItems", &schema.MaxItems},
+	} {
+		if v, ok := tag.Lookup(count.key); ok {
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || i < 0 {
+				return fmt.Errorf("%s '%s' is not a non-negative integer", count.key, v)
+			}
+			*count.target = &i
+		}
+	}
+	v, ok := tag.Lookup("enum")
+	if !ok {
+		return nil
+	}
+	target := schema
+	if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array) && schema.Items != nil &&
+		schema.Items.IsA() {
+		if schema.Items.A.IsReference() {
+			// keywords cannot be added to a reference, so the items are wrapped by allOf.
+			target = &base.Schema{AllOf: []*base.SchemaProxy{schema.Items.A}}
+			schema.Items.A = base.CreateSchemaProxy(target)
+		} else {
+			target = schema.Items.A.Schema()
+		}
+		fieldType = fieldType.Elem()
+	}
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	for _, raw := range strings.Split(v, ",") {
+		value, err := enumValue(strings.TrimSpace(raw), fieldType)
+		if err != nil {
+			return err
+		}
+		target.Enum = append(target.Enum, value)
+	}
+	return nil
+}
+
+// enumValue parses a value of an enum, as the kind of a type.
+func enumValue(raw string, t reflect.Type) (any, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("enum value '%s' is not a boolean", raw)
+		}
+		return b, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("enum value '%s' is not an integer of type %s", raw, t)
+		}
+		return i, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("enum value '%s' is not an integer of type %s", raw, t)
+		}
+		return u, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("enum value '%s' is not a number", raw)
+		}
+		return f, nil
+	}
+	return raw, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultMaxDepth is the maximum depth a SchemaRenderer will render to, unless set by SetMaxDepth.
+const DefaultMaxDepth = 100
+
+// CircularStrategy determines what a SchemaRenderer renders when it stops rendering a schema, because the schema
+// is circular (it contains itself), or because the maximum depth has been reached.
+type CircularStrategy int
+
+const (
+	// OmitCircular omits optional properties where rendering stopped. If the property is required, then an
+	// array is rendered empty and anything else is rendered as null. This is the default.
+	OmitCircular CircularStrategy = iota
+
+	// EmptyArrayCircular renders an empty array for arrays where rendering stopped, anything else is rendered
+	// as null.
+	EmptyArrayCircular
+
+	// NullCircular renders null where rendering stopped.
+	NullCircular
+)
+
+// circularCut marks a value that was not rendered because the schema is circular or too deep.
+type circularCut struct{}
+
+// SetMaxDepth sets the maximum depth of the rendered values, properties and items deeper than this are not rendered,
+// and are handled by the CircularStrategy. The default is DefaultMaxDepth.
+func (wr *SchemaRenderer) SetMaxDepth(depth int) {
+	wr.maxDepth = depth
+}
+
+// SetCircularStrategy sets what is rendered when a circular schema is found, or the maximum depth is reached.
+// A schema is circular if it's found inside itself, such as a tree Node whose children are Nodes. Schemas are
+// identified by their hash.
+func (wr *SchemaRenderer) SetCircularStrategy(strategy CircularStrategy) {
+	wr.circularStrategy = strategy
+}
+
+func (wr *SchemaRenderer) getMaxDepth() int {
+	if wr.maxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return wr.maxDepth
+}
+
+// schemaHash returns the hash of the low-level schema, false if the schema was not built from a document.
+func schemaHash(schema *base.Schema) ([32]byte, bool) {
+	if low := schema.GoLow(); low != nil {
+		return low.Hash(), true
+	}
+	return [32]byte{}, false
+}
+
+// isVisiting returns true if the schema with this hash is already being rendered (further up the tree), which means
+// it's circular.
+func (wr *SchemaRenderer) isVisiting(hash [32]byte) bool {
+	return wr.visiting[hash] > 0
+}
+
+// visit marks the schema with this hash as being rendered, the returned function un-marks it once rendering is
+// complete.
+func (wr *SchemaRenderer) visit(hash [32]byte) func() {
+	if wr.visiting == nil {
+		wr.visiting = make(map[[32]byte]int)
+	}
+	wr.visiting[hash]++
+	return func() {
+		wr.visiting[hash]--
+	}
+}
+
+// cutValue returns the value rendered in place of a required or top level value that was not rendered.
+func (wr *SchemaRenderer) cutValue(schema *base.Schema) any {
+	if wr.circularStrategy != NullCircular && schema != nil {
+		types := schema.Type
+		if len(types) == 0 {
+			types = inferTypes(schema)
+		}
+		if slices.Contains(types, arrayType) {
+			return []any{}
+		}
+	}
+	return nil
+}
+
+// cutArray returns the value rendered in place of an array whose items were not rendered.
+func (wr *SchemaRenderer) cutArray() any {
+	if wr.circularStrategy == EmptyArrayCircular {
+		return []any{}
+	}
+	return circularCut{}
+}
+
+// resolveCuts replaces (or removes) any properties of an object that were not rendered.
+func (wr *SchemaRenderer) resolveCuts(schema *base.Schema, propertyMap map[string]any, required map[string]bool) {
+	for name, value := range propertyMap {
+		if _, ok := value.(circularCut); !ok {
+			continue
+		}
+		if wr.circularStrategy == OmitCircular && !required[name] {
+			delete(propertyMap, name)
+			continue
+		}
+		var propertySchema *base.Schema
+		if proxy := schema.Properties[name]; proxy != nil {
+			propertySchema = proxy.Schema()
+		}
+		propertyMap[name] = wr.cutValue(propertySchema)
+	}
+}
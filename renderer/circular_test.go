@@ -0,0 +1,184 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const treeSpec = `openapi: 3.1.0
+info:
+  title: trees
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Node:
+      type: object
+      properties:
+        name:
+          type: string
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+    Forest:
+      type: object
+      required: [trees, largest]
+      properties:
+        trees:
+          type: array
+          items:
+            $ref: '#/components/schemas/Tree'
+        largest:
+          $ref: '#/components/schemas/Tree'
+    Tree:
+      type: object
+      required: [branches]
+      properties:
+        branches:
+          type: array
+          items:
+            $ref: '#/components/schemas/Tree'
+        parent:
+          $ref: '#/components/schemas/Tree'
+    Deep:
+      type: object
+      required: [a]
+      properties:
+        a:
+          type: object
+          required: [b]
+          properties:
+            b:
+              type: object
+              required: [c]
+              properties:
+                c:
+                  type: string`
+
+func TestRenderSchema_Circular_Omit(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(treeSpec), &datamodel.DocumentConfiguration{
+		IgnoreArrayCircularReferences:       true,
+		IgnorePolymorphicCircularReferences: true,
+	})
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Len(t, errs, 1) // the tree is infinitely circular, which is reported, but the model is still built.
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+
+	// children is optional, so it's omitted rather than rendering forever.
+	node := wr.RenderSchema(schemas["Node"].Schema()).(map[string]any)
+	assert.Len(t, node, 1)
+	assert.Contains(t, node, "name")
+
+	// branches is required, so it's empty, parent is optional so it's omitted.
+	forest := wr.RenderSchema(schemas["Forest"].Schema()).(map[string]any)
+	assert.Equal(t, map[string]any{"branches": []any{}}, forest["largest"])
+	assert.Equal(t, []any{map[string]any{"branches": []any{}}}, forest["trees"])
+}
+
+func TestRenderSchema_Circular_EmptyArray(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(treeSpec), &datamodel.DocumentConfiguration{
+		IgnoreArrayCircularReferences:       true,
+		IgnorePolymorphicCircularReferences: true,
+	})
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Len(t, errs, 1)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+	wr.SetCircularStrategy(EmptyArrayCircular)
+
+	node := wr.RenderSchema(schemas["Node"].Schema()).(map[string]any)
+	assert.Equal(t, []any{}, node["children"])
+
+	tree := wr.RenderSchema(schemas["Tree"].Schema()).(map[string]any)
+	assert.Equal(t, []any{}, tree["branches"])
+}
+
+func TestRenderSchema_Circular_Null(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(treeSpec), &datamodel.DocumentConfiguration{
+		IgnoreArrayCircularReferences:       true,
+		IgnorePolymorphicCircularReferences: true,
+	})
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Len(t, errs, 1)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+	wr.SetCircularStrategy(NullCircular)
+
+	node := wr.RenderSchema(schemas["Node"].Schema()).(map[string]any)
+	assert.Contains(t, node, "children")
+	assert.Nil(t, node["children"])
+
+	b, err := json.Marshal(node)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"children":null`)
+}
+
+func TestRenderSchema_MaxDepth(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(treeSpec), &datamodel.DocumentConfiguration{
+		IgnoreArrayCircularReferences:       true,
+		IgnorePolymorphicCircularReferences: true,
+	})
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Len(t, errs, 1)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+	wr.SetMaxDepth(1)
+
+	deep := wr.RenderSchema(schemas["Deep"].Schema()).(map[string]any)
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": nil}}, deep)
+
+	wr.SetMaxDepth(0) // back to the default.
+	deep = wr.RenderSchema(schemas["Deep"].Schema()).(map[string]any)
+	assert.IsType(t, "", deep["a"].(map[string]any)["b"].(map[string]any)["c"])
+}
+
+func TestRenderSchema_MaxDepth_Root(t *testing.T) {
+	schema := getSchema([]byte(`type: array
+items:
+  type: array
+  items:
+    type: array
+    items:
+      type: string`))
+	wr := createSchemaRenderer()
+	wr.SetMaxDepth(1)
+	assert.Equal(t, []any{}, wr.RenderSchema(schema))
+
+	wr.SetCircularStrategy(EmptyArrayCircular)
+	assert.Equal(t, []any{[]any{}}, wr.RenderSchema(schema))
+
+	wr.SetCircularStrategy(NullCircular)
+	assert.Nil(t, wr.RenderSchema(schema))
+}
+
+func TestMockGenerator_GenerateMock_Circular(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(treeSpec), &datamodel.DocumentConfiguration{
+		IgnoreArrayCircularReferences:       true,
+		IgnorePolymorphicCircularReferences: true,
+	})
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Len(t, errs, 1)
+	schemas := model.Model.Components.Schemas
+	mg := NewMockGenerator(JSON)
+	mg.SetCircularStrategy(NullCircular)
+	mg.SetMaxDepth(10)
+
+	mock, err := mg.GenerateMock(schemas["Node"].Schema(), "")
+	assert.NoError(t, err)
+	assert.Contains(t, string(mock), `"children":null`)
+}
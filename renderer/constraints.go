@@ -0,0 +1,545 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lucasjones/reggen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"golang.org/x/exp/slices"
+)
+
+// maxAttempts is the number of times the renderer will try to generate a value that satisfies a constraint
+// (such as uniqueItems, not, or a pattern with length limits) before giving up and reporting it.
+const maxAttempts = 10
+
+// UnsatisfiableConstraintError is returned by GetRenderingErrors when the constraints of a schema cannot be
+// satisfied by a generated value, for example when minLength is greater than maxLength.
+type UnsatisfiableConstraintError struct {
+	Path   string // location of the schema in the rendered value, for example '$.burgers[].name'
+	Reason string // why the constraint could not be satisfied.
+}
+
+// Error returns a description of the constraint that could not be satisfied.
+func (e *UnsatisfiableConstraintError) Error() string {
+	return fmt.Sprintf("unable to satisfy schema constraints at '%s': %s", e.Path, e.Reason)
+}
+
+func (wr *SchemaRenderer) pushPath(key string) {
+	switch key {
+	case rootType:
+		wr.path = append(wr.path, "$")
+	case itemsType:
+		wr.path = append(wr.path, "[]")
+	case allOfType, oneOfType, anyOfType:
+		wr.path = append(wr.path, "")
+	default:
+		wr.path = append(wr.path, "."+key)
+	}
+}
+
+func (wr *SchemaRenderer) popPath() {
+	if len(wr.path) > 0 {
+		wr.path = wr.path[:len(wr.path)-1]
+	}
+}
+
+func (wr *SchemaRenderer) unsatisfiable(reason string) {
+	wr.errors = append(wr.errors, &UnsatisfiableConstraintError{Path: strings.Join(wr.path, ""), Reason: reason})
+}
+
+// word returns a random word from the dictionary that is between min and max characters long, falling back to
+// random letters if the dictionary has no word of the right length.
+func (wr *SchemaRenderer) word(min, max int64) string {
+	if len(wr.words) > 0 {
+		w := wr.RandomWord(min, max, 0)
+		if l := int64(utf8.RuneCountInString(w)); l >= min && l <= max && !strings.HasPrefix(w, "no-word-found-") {
+			return w
+		}
+	}
+	length := min
+	if max > min {
+		spread := max - min
+		if spread > 10 {
+			spread = 10
+		}
+		length += wr.random().Int63n(spread + 1)
+	}
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = letterBytes[wr.random().Intn(len(letterBytes))]
+	}
+	return string(b)
+}
+
+// maxFormatLength is the longest string generated for formats that have no limit, such as uri.
+const maxFormatLength = 2048
+
+// fitsLength returns true if a string is within the minLength and maxLength of a schema.
+func fitsLength(schema *base.Schema, s string) bool {
+	l := int64(utf8.RuneCountInString(s))
+	return (schema.MinLength == nil || l >= *schema.MinLength) && (schema.MaxLength == nil || l <= *schema.MaxLength)
+}
+
+// describeLength describes the minLength and maxLength of a schema, such as 'between 3 and 10 characters'.
+func describeLength(schema *base.Schema) string {
+	switch {
+	case schema.MinLength != nil && schema.MaxLength != nil:
+		return fmt.Sprintf("between %d and %d characters", *schema.MinLength, *schema.MaxLength)
+	case schema.MinLength != nil:
+		return fmt.Sprintf("of at least %d characters", *schema.MinLength)
+	case schema.MaxLength != nil:
+		return fmt.Sprintf("of at most %d characters", *schema.MaxLength)
+	}
+	return "of any length"
+}
+
+// formatLength returns a random length for a string of a format, between the shortest and longest strings of the
+// format and within minLength and maxLength. Lengths stay close to the shortest that fits, like the word lengths.
+// If no length fits, the length of the format nearest to the bounds is returned (and reported by the caller).
+func (wr *SchemaRenderer) formatLength(minLength, maxLength, shortest, longest int64) int64 {
+	lo, hi := shortest, longest
+	if minLength > lo {
+		lo = minLength
+	}
+	if maxLength < hi {
+		hi = maxLength
+	}
+	if lo > hi {
+		if maxLength < shortest {
+			return shortest
+		}
+		return longest
+	}
+	if hi-lo > 10 {
+		hi = lo + 10
+	}
+	return lo + wr.random().Int63n(hi-lo+1)
+}
+
+// letters returns a string of random lower case letters.
+func (wr *SchemaRenderer) letters(n int64) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letterBytes[wr.random().Intn(26)]
+	}
+	return string(b)
+}
+
+// hex returns a string of random hexadecimal digits.
+func (wr *SchemaRenderer) hex(n int64) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digits[wr.random().Intn(len(digits))]
+	}
+	return string(b)
+}
+
+// sizedEmail returns an email address that is n (at least 7) characters long.
+func (wr *SchemaRenderer) sizedEmail(n int64) string {
+	local := (n - 5) / 2
+	return fmt.Sprintf("%s@%s.com", wr.letters(local), wr.letters(n-5-local))
+}
+
+// sizedHostname returns a hostname that is n (at least 5) characters long, with labels of at most 63 characters.
+func (wr *SchemaRenderer) sizedHostname(n int64) string {
+	var labels []string
+	for n -= 4; n > 63; n -= 62 {
+		labels = append(labels, wr.letters(61))
+	}
+	return strings.Join(append(labels, wr.letters(n)), ".") + ".com"
+}
+
+// sizedURI returns an https URI that is n (at least 13) characters long, with a path if it is long enough.
+func (wr *SchemaRenderer) sizedURI(n int64) string {
+	rest := n - 8 // the length of https://
+	if rest <= 15 {
+		return "https://" + wr.sizedHostname(rest)
+	}
+	return fmt.Sprintf("https://%s/%s", wr.sizedHostname(14), wr.letters(rest-15))
+}
+
+// sizedURIReference returns a relative reference that is n (at least 2) characters long.
+func (wr *SchemaRenderer) sizedURIReference(n int64) string {
+	if n < 4 {
+		return "/" + wr.letters(n-1)
+	}
+	first := (n - 2) / 2
+	return fmt.Sprintf("/%s/%s", wr.letters(first), wr.letters(n-2-first))
+}
+
+// sizedIPv4 returns an IPv4 address that is n (between 7 and 15) characters long.
+func (wr *SchemaRenderer) sizedIPv4(n int64) string {
+	octets := make([]string, 4)
+	for i, digits := range wr.spread(n-3, 4, 3) {
+		switch digits {
+		case 1:
+			octets[i] = fmt.Sprint(wr.random().Intn(10))
+		case 2:
+			octets[i] = fmt.Sprint(10 + wr.random().Intn(90))
+		default:
+			octets[i] = fmt.Sprint(100 + wr.random().Intn(156))
+		}
+	}
+	return strings.Join(octets, ".")
+}
+
+// sizedIPv6 returns an IPv6 address that is n (between 15 and 39) characters long.
+func (wr *SchemaRenderer) sizedIPv6(n int64) string {
+	groups := make([]string, 8)
+	for i, digits := range wr.spread(n-7, 8, 4) {
+		// groups do not have leading zeros, unless they are a single digit.
+		if digits == 1 {
+			groups[i] = fmt.Sprintf("%x", wr.random().Intn(16))
+		} else {
+			groups[i] = fmt.Sprintf("%x", 1<<(4*(digits-1))+wr.random().Intn(15<<(4*(digits-1))))
+		}
+	}
+	return strings.Join(groups, ":")
+}
+
+// spread randomly divides a total between a number of parts, each part being between 1 and most.
+func (wr *SchemaRenderer) spread(total int64, parts, most int) []int {
+	result := make([]int, parts)
+	for i := range result {
+		result[i] = 1
+	}
+	for extra := int(total) - parts; extra > 0; {
+		if i := wr.random().Intn(parts); result[i] < most {
+			result[i]++
+			extra--
+		}
+	}
+	return result
+}
+
+// patternString generates a string that matches a pattern. If the length is bounded, then the renderer will try a
+// few times to generate a string with a length that fits.
+func (wr *SchemaRenderer) patternString(pattern string, min, max int64, bounded bool) string {
+	gen, err := reggen.NewGenerator(pattern)
+	if err != nil {
+		wr.unsatisfiable(fmt.Sprintf("invalid pattern '%s': %s", pattern, err.Error()))
+		return ""
+	}
+	gen.SetSeed(wr.random().Int63())
+	limit := int(max)
+	if limit <= 0 {
+		limit = 1
+	}
+	var s string
+	for i := 0; i < maxAttempts; i++ {
+		s = gen.Generate(limit)
+		l := int64(utf8.RuneCountInString(s))
+		if !bounded || (l >= min && l <= max) {
+			return s
+		}
+	}
+	wr.unsatisfiable(fmt.Sprintf("unable to generate a string matching '%s' between %d and %d characters",
+		pattern, min, max))
+	return s
+}
+
+// renderNumber generates a number within the minimum and maximum (inclusive or exclusive) of a schema, that is
+// a multiple of multipleOf. The type of the value returned depends on the schema type and format.
+func (wr *SchemaRenderer) renderNumber(schema *base.Schema, integer bool) any {
+	lo, hi := 1.0, 100.0
+	loExcl, hiExcl := false, true
+	hasMin, hasMax := false, false
+
+	if schema.Minimum != nil {
+		lo, loExcl, hasMin = *schema.Minimum, false, true
+		if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A {
+			loExcl = true
+		}
+	}
+	if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() {
+		if !hasMin || schema.ExclusiveMinimum.B >= lo {
+			lo, loExcl, hasMin = schema.ExclusiveMinimum.B, true, true
+		}
+	}
+	if schema.Maximum != nil {
+		hi, hiExcl, hasMax = *schema.Maximum, false, true
+		if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A {
+			hiExcl = true
+		}
+	}
+	if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() {
+		if !hasMax || schema.ExclusiveMaximum.B <= hi {
+			hi, hiExcl, hasMax = schema.ExclusiveMaximum.B, true, true
+		}
+	}
+	// without both limits, use a sensible range next to the limit that is defined.
+	switch {
+	case hasMin && !hasMax:
+		if lo < 100 {
+			hi = 100
+		} else {
+			hi = lo + 100
+		}
+	case hasMax && !hasMin:
+		if hi > 1 {
+			lo = 1
+		} else {
+			lo = hi - 100
+		}
+	}
+
+	switch schema.Format {
+	case int32Type, "int64":
+		integer = true
+	case floatType, doubleType:
+		integer = false
+	}
+
+	step := 1.0
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		step = *schema.MultipleOf
+	}
+	intStep := integralStep(step)
+
+	if integer || (schema.Format == "" && intStep > 0) {
+		if intStep > 0 {
+			if kMin, kMax, ok := multiplesInRange(lo, hi, loExcl, hiExcl, intStep); ok {
+				v := int64(intStep) * (kMin + wr.random().Int63n(kMax-kMin+1))
+				if schema.Format == int32Type {
+					return int(v)
+				}
+				return v
+			}
+		}
+		if integer {
+			wr.unsatisfiable(fmt.Sprintf("no integer %s in range %s", multipleDescription(schema), rangeDescription(lo, hi, loExcl, hiExcl)))
+			return int64(lo)
+		}
+	}
+
+	var v float64
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		kMin, kMax, ok := multiplesInRange(lo, hi, loExcl, hiExcl, step)
+		if !ok {
+			wr.unsatisfiable(fmt.Sprintf("no number %s in range %s", multipleDescription(schema), rangeDescription(lo, hi, loExcl, hiExcl)))
+			return lo
+		}
+		v = math.Round(float64(kMin+wr.random().Int63n(kMax-kMin+1))*step*1e10) / 1e10
+	} else {
+		if hi < lo || (hi == lo && (loExcl || hiExcl)) {
+			wr.unsatisfiable(fmt.Sprintf("no number in range %s", rangeDescription(lo, hi, loExcl, hiExcl)))
+			return lo
+		}
+		v = lo + wr.random().Float64()*(hi-lo)
+		if loExcl && v == lo {
+			v = lo + (hi-lo)/2
+		}
+	}
+	if schema.Format == floatType {
+		return float32(v)
+	}
+	return v
+}
+
+// integralStep returns the smallest whole number that is a multiple of step, or zero if there isn't a small one.
+func integralStep(step float64) float64 {
+	for n := 1.0; n <= 1000; n++ {
+		if v := step * n; math.Abs(v-math.Round(v)) < 1e-9 {
+			return math.Round(v)
+		}
+	}
+	return 0
+}
+
+// multiplesInRange returns the range of k, for which k * step is within the range lo to hi.
+func multiplesInRange(lo, hi float64, loExcl, hiExcl bool, step float64) (int64, int64, bool) {
+	const epsilon = 1e-9
+	kMin := math.Ceil(lo/step - epsilon)
+	if loExcl && kMin*step <= lo {
+		kMin++
+	}
+	kMax := math.Floor(hi/step + epsilon)
+	if hiExcl && kMax*step >= hi {
+		kMax--
+	}
+	if kMin > kMax || kMax-kMin >= math.MaxInt64 {
+		return 0, 0, false
+	}
+	return int64(kMin), int64(kMax), true
+}
+
+func multipleDescription(schema *base.Schema) string {
+	if schema.MultipleOf != nil {
+		return fmt.Sprintf("that is a multiple of %v", *schema.MultipleOf)
+	}
+	return "available"
+}
+
+func rangeDescription(lo, hi float64, loExcl, hiExcl bool) string {
+	open, closing := "[", "]"
+	if loExcl {
+		open = "("
+	}
+	if hiExcl {
+		closing = ")"
+	}
+	return fmt.Sprintf("%s%v, %v%s", open, lo, hi, closing)
+}
+
+// uniqueKey returns a key that is equal for values that are equal as JSON.
+func uniqueKey(v any) string {
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// simpleSchema returns true if a schema only uses keywords that matchesSimpleSchema can evaluate. A 'not' schema
+// that uses anything else (such as format, properties, items or composition) cannot be checked, so it's ignored.
+func simpleSchema(schema *base.Schema) bool {
+	return schema.Format == "" && len(schema.AllOf) == 0 && len(schema.AnyOf) == 0 && len(schema.OneOf) == 0 &&
+		schema.Not == nil && schema.If == nil && schema.Then == nil && schema.Else == nil &&
+		len(schema.Properties) == 0 && len(schema.PatternProperties) == 0 && len(schema.DependentSchemas) == 0 &&
+		schema.AdditionalProperties == nil && schema.PropertyNames == nil && schema.UnevaluatedProperties == nil &&
+		schema.Items == nil && len(schema.PrefixItems) == 0 && schema.Contains == nil && schema.UnevaluatedItems == nil
+}
+
+// matchesSimpleSchema checks a value against the simple keywords of a schema (type, const, enum, pattern, lengths,
+// ranges, multipleOf, item and property counts, uniqueItems and required), it's used to make sure a value does not
+// match a 'not' schema.
+func matchesSimpleSchema(schema *base.Schema, v any) bool {
+	if len(schema.Type) > 0 && !slices.Contains(schema.Type, valueType(v)) &&
+		!(valueType(v) == integerType && slices.Contains(schema.Type, numberType)) {
+		return false
+	}
+	if schema.Const != nil && uniqueKey(schema.Const) != uniqueKey(v) {
+		return false
+	}
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, e := range schema.Enum {
+			if uniqueKey(e) == uniqueKey(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		if !fitsLength(schema, t) {
+			return false
+		}
+		if schema.Pattern != "" {
+			if rx, err := regexp.Compile(schema.Pattern); err == nil && !rx.MatchString(t) {
+				return false
+			}
+		}
+	case []any:
+		n := int64(len(t))
+		if (schema.MinItems != nil && n < *schema.MinItems) || (schema.MaxItems != nil && n > *schema.MaxItems) {
+			return false
+		}
+		if schema.UniqueItems != nil && *schema.UniqueItems {
+			seen := make(map[string]bool, len(t))
+			for _, item := range t {
+				if seen[uniqueKey(item)] {
+					return false
+				}
+				seen[uniqueKey(item)] = true
+			}
+		}
+	case map[string]any:
+		n := int64(len(t))
+		if (schema.MinProperties != nil && n < *schema.MinProperties) ||
+			(schema.MaxProperties != nil && n > *schema.MaxProperties) {
+			return false
+		}
+		for _, r := range schema.Required {
+			if _, found := t[r]; !found {
+				return false
+			}
+		}
+	}
+	if f, ok := numberValue(v); ok {
+		return inRange(schema, f)
+	}
+	return true
+}
+
+// inRange checks a number against the minimum, maximum, exclusiveMinimum, exclusiveMaximum and multipleOf of a
+// schema, exclusive bounds can be booleans (OpenAPI 3.0) or numbers (OpenAPI 3.1).
+func inRange(schema *base.Schema, f float64) bool {
+	loExcl := schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A
+	hiExcl := schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A
+	if schema.Minimum != nil && (f < *schema.Minimum || (f == *schema.Minimum && loExcl)) {
+		return false
+	}
+	if schema.Maximum != nil && (f > *schema.Maximum || (f == *schema.Maximum && hiExcl)) {
+		return false
+	}
+	if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() && f <= schema.ExclusiveMinimum.B {
+		return false
+	}
+	if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() && f >= schema.ExclusiveMaximum.B {
+		return false
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		q := f / *schema.MultipleOf
+		return math.Abs(q-math.Round(q)) < 1e-9
+	}
+	return true
+}
+
+// numberValue returns the value of a rendered number as a float64.
+func numberValue(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// valueType returns the JSON Schema type of a rendered value.
+func valueType(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return stringType
+	case bool:
+		return booleanType
+	case map[string]any:
+		return objectType
+	case []any:
+		return arrayType
+	case float32:
+		if float64(t) == math.Trunc(float64(t)) {
+			return integerType
+		}
+		return numberType
+	case float64:
+		if t == math.Trunc(t) {
+			return integerType
+		}
+		return numberType
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerType
+	}
+	return ""
+}
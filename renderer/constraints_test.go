@@ -0,0 +1,411 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+)
+
+// renderSeeded renders a schema many times with different seeds, so random values are tested across the range.
+func renderSeeded(t *testing.T, schema string, check func(v any)) {
+	compiled := getSchema([]byte(schema))
+	wr := createSchemaRenderer()
+	for i := int64(0); i < 50; i++ {
+		wr.SetSeed(i)
+		v := wr.RenderSchema(compiled)
+		assert.Empty(t, wr.GetRenderingErrors())
+		check(v)
+	}
+}
+
+func renderErrors(schema string) []error {
+	wr := createSchemaRenderer()
+	wr.SetSeed(1)
+	wr.RenderSchema(getSchema([]byte(schema)))
+	return wr.GetRenderingErrors()
+}
+
+func TestRenderSchema_Pattern(t *testing.T) {
+	rx := regexp.MustCompile(`^[A-Z]{3}-[0-9]{4}$`)
+	renderSeeded(t, `type: string
+pattern: ^[A-Z]{3}-[0-9]{4}$`, func(v any) {
+		assert.Regexp(t, rx, v)
+	})
+}
+
+func TestRenderSchema_PatternWithLength(t *testing.T) {
+	renderSeeded(t, `type: string
+pattern: ^[a-z]+$
+minLength: 4
+maxLength: 6`, func(v any) {
+		assert.Regexp(t, `^[a-z]{4,6}$`, v)
+	})
+}
+
+func TestRenderSchema_StringLength(t *testing.T) {
+	renderSeeded(t, `type: string
+minLength: 25`, func(v any) {
+		assert.GreaterOrEqual(t, len(v.(string)), 25)
+	})
+	renderSeeded(t, `type: string
+maxLength: 2`, func(v any) {
+		assert.LessOrEqual(t, len(v.(string)), 2)
+	})
+}
+
+func TestRenderSchema_FormatLength(t *testing.T) {
+	formats := map[string]func(s string) bool{
+		"email": func(s string) bool {
+			address, err := mail.ParseAddress(s)
+			return err == nil && address.Address == s
+		},
+		"hostname": regexp.MustCompile(`^([a-z]{1,63}\.)+com$`).MatchString,
+		"uri": func(s string) bool {
+			u, err := url.Parse(s)
+			return err == nil && u.Scheme == "https" && u.Host != ""
+		},
+		"uri-reference": regexp.MustCompile(`^/[a-z]+(/[a-z]+)?$`).MatchString,
+		"ipv4":          func(s string) bool { return net.ParseIP(s) != nil && strings.Count(s, ".") == 3 },
+		"ipv6":          func(s string) bool { return net.ParseIP(s) != nil && strings.Count(s, ":") == 7 },
+		"byte":          regexp.MustCompile(`^[0-9a-f]+$`).MatchString,
+		"binary": func(s string) bool {
+			_, err := base64.StdEncoding.DecodeString(s)
+			return err == nil
+		},
+	}
+	bounds := map[string][][2]int{
+		"email":         {{0, 12}, {20, 30}, {7, 7}},
+		"hostname":      {{0, 6}, {70, 80}},
+		"uri":           {{0, 15}, {40, 50}},
+		"uri-reference": {{0, 3}, {30, 40}},
+		"ipv4":          {{0, 8}, {14, 15}},
+		"ipv6":          {{0, 20}, {39, 39}},
+		"byte":          {{0, 2}, {30, 40}},
+		"binary":        {{0, 5}, {9, 13}},
+	}
+	for format, valid := range formats {
+		for _, b := range bounds[format] {
+			schema := fmt.Sprintf("type: string\nformat: %s\nmaxLength: %d", format, b[1])
+			if b[0] > 0 {
+				schema += fmt.Sprintf("\nminLength: %d", b[0])
+			}
+			renderSeeded(t, schema, func(v any) {
+				s := v.(string)
+				assert.True(t, valid(s), "%s is not a valid %s", s, format)
+				assert.GreaterOrEqual(t, len(s), b[0], format)
+				assert.LessOrEqual(t, len(s), b[1], format)
+			})
+		}
+	}
+}
+
+func TestRenderSchema_FormatLength_Unsatisfiable(t *testing.T) {
+	for _, schema := range []string{
+		"type: string\nformat: email\nmaxLength: 6",
+		"type: string\nformat: uri\nmaxLength: 12",
+		"type: string\nformat: uuid\nmaxLength: 10",
+		"type: string\nformat: ipv4\nminLength: 16",
+		"type: string\nformat: date\nminLength: 11",
+		"type: string\nformat: binary\nminLength: 5\nmaxLength: 7",
+	} {
+		errs := renderErrors(schema)
+		if assert.Len(t, errs, 1, schema) {
+			assert.Contains(t, errs[0].Error(), "unable to generate a '")
+		}
+	}
+	assert.EqualError(t, renderErrors("type: string\nformat: email\nmaxLength: 6")[0],
+		"unable to satisfy schema constraints at '$': unable to generate a 'email' string of at most 6 characters")
+}
+
+func TestRenderSchema_MultipleOf(t *testing.T) {
+	renderSeeded(t, `type: integer
+minimum: 10
+maximum: 1000
+multipleOf: 7`, func(v any) {
+		assert.Zero(t, v.(int64)%7)
+		assert.GreaterOrEqual(t, v.(int64), int64(10))
+		assert.LessOrEqual(t, v.(int64), int64(1000))
+	})
+	renderSeeded(t, `type: number
+format: double
+minimum: 0
+maximum: 2
+multipleOf: 0.25`, func(v any) {
+		f := v.(float64)
+		assert.Equal(t, f, math.Round(f*4)/4)
+	})
+}
+
+func TestRenderSchema_ExclusiveBounds(t *testing.T) {
+	// OpenAPI 3.0, boolean exclusive flags.
+	renderSeeded(t, `type: integer
+minimum: 1
+exclusiveMinimum: true
+maximum: 3
+exclusiveMaximum: true`, func(v any) {
+		assert.Equal(t, int64(2), v)
+	})
+	// OpenAPI 3.1, numeric exclusive bounds.
+	renderSeeded(t, `type: integer
+exclusiveMinimum: 5
+exclusiveMaximum: 7`, func(v any) {
+		assert.Equal(t, int64(6), v)
+	})
+}
+
+func TestRenderSchema_FloatRange(t *testing.T) {
+	renderSeeded(t, `type: number
+format: float
+minimum: 0.5
+maximum: 0.75`, func(v any) {
+		assert.GreaterOrEqual(t, v.(float32), float32(0.5))
+		assert.LessOrEqual(t, v.(float32), float32(0.75))
+	})
+	renderSeeded(t, `type: number
+minimum: 0.1
+maximum: 0.2`, func(v any) {
+		assert.GreaterOrEqual(t, v.(float64), 0.1)
+		assert.LessOrEqual(t, v.(float64), 0.2)
+	})
+}
+
+func TestRenderSchema_Const(t *testing.T) {
+	renderSeeded(t, `type: string
+const: burger`, func(v any) {
+		assert.Equal(t, "burger", v)
+	})
+}
+
+func TestRenderSchema_UniqueItems(t *testing.T) {
+	renderSeeded(t, `type: array
+minItems: 3
+maxItems: 3
+uniqueItems: true
+items:
+  type: integer
+  minimum: 1
+  maximum: 3`, func(v any) {
+		items := v.([]any)
+		assert.Len(t, items, 3)
+		assert.ElementsMatch(t, []any{int64(1), int64(2), int64(3)}, items)
+	})
+}
+
+func TestRenderSchema_MaxItems(t *testing.T) {
+	renderSeeded(t, `type: array
+maxItems: 0
+items:
+  type: string`, func(v any) {
+		assert.Len(t, v, 0)
+	})
+}
+
+func TestRenderSchema_PrefixItems(t *testing.T) {
+	renderSeeded(t, `type: array
+prefixItems:
+  - type: integer
+  - type: string
+    const: chips
+items: false`, func(v any) {
+		items := v.([]any)
+		assert.Len(t, items, 2)
+		assert.IsType(t, int64(0), items[0])
+		assert.Equal(t, "chips", items[1])
+	})
+}
+
+func TestRenderSchema_MinProperties(t *testing.T) {
+	renderSeeded(t, `type: object
+minProperties: 3
+properties:
+  name:
+    type: string
+patternProperties:
+  ^x-[a-z]{3}$:
+    type: integer
+additionalProperties: false`, func(v any) {
+		m := v.(map[string]any)
+		assert.Len(t, m, 3)
+		assert.Contains(t, m, "name")
+		for k, val := range m {
+			if k != "name" {
+				assert.Regexp(t, `^x-[a-z]{3}$`, k)
+				assert.IsType(t, int64(0), val)
+			}
+		}
+	})
+	renderSeeded(t, `type: object
+minProperties: 2
+additionalProperties:
+  type: boolean
+propertyNames:
+  pattern: ^[a-z]{5}$`, func(v any) {
+		m := v.(map[string]any)
+		assert.Len(t, m, 2)
+		for k, val := range m {
+			assert.Regexp(t, `^[a-z]{5}$`, k)
+			assert.IsType(t, true, val)
+		}
+	})
+}
+
+func TestRenderSchema_MaxProperties(t *testing.T) {
+	renderSeeded(t, `type: object
+maxProperties: 1
+properties:
+  a:
+    type: string
+  b:
+    type: string`, func(v any) {
+		assert.Len(t, v, 1)
+	})
+}
+
+func TestRenderSchema_Not(t *testing.T) {
+	renderSeeded(t, `type: string
+enum: [burger, fries]
+not:
+  const: fries`, func(v any) {
+		assert.Equal(t, "burger", v)
+	})
+}
+
+func TestRenderSchema_NotKeywords(t *testing.T) {
+	assert.Empty(t, renderErrors(`type: string
+maxLength: 3
+not:
+  minLength: 5`))
+	assert.Empty(t, renderErrors(`type: integer
+minimum: 1
+maximum: 5
+not:
+  minimum: 10`))
+	assert.Empty(t, renderErrors(`type: string
+not:
+  format: email`))
+
+	renderSeeded(t, `type: integer
+enum: [1, 20]
+not:
+  maximum: 10`, func(v any) {
+		assert.EqualValues(t, 20, v)
+	})
+}
+
+func TestRenderSchema_Unsatisfiable(t *testing.T) {
+	errs := renderErrors(`type: object
+properties:
+  name:
+    type: string
+    minLength: 10
+    maxLength: 5
+  age:
+    type: integer
+    minimum: 10
+    maximum: 5
+  tags:
+    type: array
+    minItems: 5
+    maxItems: 2
+    items:
+      type: string
+  bad:
+    type: string
+    pattern: "[a-z"
+  never:
+    not: {}`)
+	assert.Len(t, errs, 5)
+
+	var uce *UnsatisfiableConstraintError
+	paths := make(map[string]bool)
+	for _, err := range errs {
+		assert.True(t, errors.As(err, &uce))
+		paths[uce.Path] = true
+	}
+	assert.Equal(t, map[string]bool{"$.name": true, "$.age": true, "$.tags": true, "$.bad": true, "$.never": true}, paths)
+	assert.Contains(t, errs[0].Error(), "unable to satisfy schema constraints at '$.age'")
+}
+
+func TestRenderSchema_Unsatisfiable_Objects(t *testing.T) {
+	errs := renderErrors(`type: object
+required: [a, b]
+maxProperties: 1
+properties:
+  a:
+    type: string
+  b:
+    type: string`)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "maxProperties is 1")
+
+	errs = renderErrors(`type: object
+minProperties: 2
+additionalProperties: false
+properties:
+  a:
+    type: string`)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "minProperties is 2")
+
+	errs = renderErrors(`type: array
+minItems: 3
+prefixItems:
+  - type: string
+items: false`)
+	assert.Len(t, errs, 1)
+
+	errs = renderErrors(`type: array
+minItems: 3
+uniqueItems: true
+items:
+  type: boolean`)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "unique items")
+}
+
+func TestRenderSchema_ErrorsReset(t *testing.T) {
+	wr := createSchemaRenderer()
+	wr.RenderSchema(getSchema([]byte(`type: integer
+minimum: 10
+maximum: 5`)))
+	assert.Len(t, wr.GetRenderingErrors(), 1)
+	wr.RenderSchema(getSchema([]byte(`type: integer`)))
+	assert.Empty(t, wr.GetRenderingErrors())
+}
+
+func TestMatchesSimpleSchema(t *testing.T) {
+	five, half, unique := int64(5), 0.5, true
+	assert.True(t, matchesSimpleSchema(&highbase.Schema{Type: []string{"number"}}, int64(1)))
+	assert.True(t, matchesSimpleSchema(&highbase.Schema{Type: []string{"integer"}}, 2.0))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{Type: []string{"integer"}}, 2.5))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{Pattern: "^a"}, "burger"))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{Required: []string{"a"}}, map[string]any{}))
+	assert.True(t, matchesSimpleSchema(&highbase.Schema{Enum: []any{"a", "b"}}, "b"))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{MinLength: &five}, "abc"))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{MinItems: &five}, []any{1}))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{UniqueItems: &unique}, []any{1, 1}))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{MinProperties: &five}, map[string]any{}))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{MultipleOf: &half}, 0.3))
+	assert.True(t, matchesSimpleSchema(&highbase.Schema{MultipleOf: &half}, 1.5))
+	assert.False(t, matchesSimpleSchema(&highbase.Schema{
+		ExclusiveMinimum: &highbase.DynamicValue[bool, float64]{N: 1, B: 5}}, 5))
+	assert.False(t, simpleSchema(&highbase.Schema{Format: "email"}))
+	assert.Equal(t, "null", valueType(nil))
+	assert.Equal(t, "boolean", valueType(true))
+	assert.Equal(t, "array", valueType([]any{}))
+	assert.Equal(t, "number", valueType(float32(0.5)))
+	assert.Equal(t, "", valueType(struct{}{}))
+}
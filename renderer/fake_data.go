@@ -0,0 +1,222 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// FakerExtension is the schema extension that names the faker used to generate a value, for example:
+//
+//	x-faker: firstName
+const FakerExtension = "x-faker"
+
+// Generator generates a fake value for a schema. Generators must only use the source of randomness provided, so
+// seeded renderers are still reproducible.
+type Generator func(r *rand.Rand, schema *base.Schema) any
+
+// fakers are the built-in generators of realistic values, by faker name.
+var fakers = map[string]Generator{
+	"email": func(r *rand.Rand, _ *base.Schema) any {
+		return fmt.Sprintf("%s.%s@%s", strings.ToLower(pick(r, firstNames)), strings.ToLower(pick(r, lastNames)),
+			pick(r, emailDomains))
+	},
+	"firstName": func(r *rand.Rand, _ *base.Schema) any { return pick(r, firstNames) },
+	"lastName":  func(r *rand.Rand, _ *base.Schema) any { return pick(r, lastNames) },
+	"fullName": func(r *rand.Rand, _ *base.Schema) any {
+		return pick(r, firstNames) + " " + pick(r, lastNames)
+	},
+	"username": func(r *rand.Rand, _ *base.Schema) any {
+		return fmt.Sprintf("%s%d", strings.ToLower(pick(r, firstNames)), r.Intn(100))
+	},
+	"city":        func(r *rand.Rand, _ *base.Schema) any { return pick(r, cities) },
+	"country":     func(r *rand.Rand, _ *base.Schema) any { return countries[r.Intn(len(countries))][1] },
+	"countryCode": func(r *rand.Rand, _ *base.Schema) any { return countries[r.Intn(len(countries))][0] },
+	"phone": func(r *rand.Rand, _ *base.Schema) any {
+		return fmt.Sprintf("+1-%03d-555-%04d", 200+r.Intn(800), r.Intn(10000))
+	},
+	"url": func(r *rand.Rand, _ *base.Schema) any {
+		return fmt.Sprintf("https://www.%s.com", strings.ToLower(strings.ReplaceAll(pick(r, companies), " ", "")))
+	},
+	"street": func(r *rand.Rand, _ *base.Schema) any {
+		return fmt.Sprintf("%d %s", 1+r.Intn(999), pick(r, streets))
+	},
+	"postcode": func(r *rand.Rand, _ *base.Schema) any { return fmt.Sprintf("%05d", r.Intn(100000)) },
+	"company":  func(r *rand.Rand, _ *base.Schema) any { return pick(r, companies) },
+	"currency": func(r *rand.Rand, _ *base.Schema) any { return pick(r, currencies) },
+	"dateTime": func(r *rand.Rand, _ *base.Schema) any {
+		return seededTime.Add(time.Duration(r.Int63n(365*24*60*60)) * time.Second).Format(time.RFC3339)
+	},
+	"price": func(r *rand.Rand, schema *base.Schema) any {
+		if isIntegerSchema(schema) {
+			return int64(1 + r.Intn(500))
+		}
+		return float64(100+r.Intn(50000)) / 100
+	},
+	"id": func(r *rand.Rand, schema *base.Schema) any {
+		if isIntegerSchema(schema) {
+			return int64(1 + r.Intn(100000))
+		}
+		return pseudoUUID(r)
+	},
+	"uuid":      func(r *rand.Rand, _ *base.Schema) any { return pseudoUUID(r) },
+	"latitude":  func(r *rand.Rand, _ *base.Schema) any { return math.Round((r.Float64()*180-90)*1e6) / 1e6 },
+	"longitude": func(r *rand.Rand, _ *base.Schema) any { return math.Round((r.Float64()*360-180)*1e6) / 1e6 },
+}
+
+// propertyFakers maps common property names (lower case, without separators) to the faker that generates them.
+var propertyFakers = map[string]string{
+	"email": "email", "emailaddress": "email",
+	"firstname": "firstName", "givenname": "firstName", "forename": "firstName",
+	"lastname": "lastName", "surname": "lastName", "familyname": "lastName",
+	"fullname": "fullName", "displayname": "fullName",
+	"username": "username", "login": "username",
+	"city": "city", "town": "city",
+	"country": "country", "countryname": "country", "countrycode": "countryCode",
+	"phone": "phone", "phonenumber": "phone", "telephone": "phone", "mobile": "phone",
+	"url": "url", "website": "url", "homepage": "url",
+	"street": "street", "streetaddress": "street", "address": "street", "addressline1": "street",
+	"zip": "postcode", "zipcode": "postcode", "postcode": "postcode", "postalcode": "postcode",
+	"company": "company", "companyname": "company", "organization": "company",
+	"currency": "currency", "currencycode": "currency",
+	"createdat": "dateTime", "updatedat": "dateTime", "deletedat": "dateTime", "modifiedat": "dateTime",
+	"timestamp": "dateTime",
+	"price":     "price", "amount": "price", "cost": "price", "total": "price",
+	"id": "id", "uuid": "uuid",
+	"latitude": "latitude", "lat": "latitude", "longitude": "longitude", "lng": "longitude", "lon": "longitude",
+}
+
+var (
+	firstNames = []string{"Alice", "Ben", "Chloe", "Daniel", "Emma", "Felix", "Grace", "Henry", "Isla", "Jack",
+		"Katie", "Liam", "Maya", "Noah", "Olivia", "Priya", "Quinn", "Ruby", "Samuel", "Tara", "Umar", "Violet",
+		"William", "Yuki", "Zoe"}
+	lastNames = []string{"Anderson", "Brown", "Clarke", "Davies", "Evans", "Fischer", "Garcia", "Hughes", "Ito",
+		"Johnson", "Kowalski", "Lopez", "Martin", "Nguyen", "O'Brien", "Patel", "Rossi", "Smith", "Taylor", "Walker",
+		"Young"}
+	emailDomains = []string{"example.com", "example.org", "example.net"}
+	cities       = []string{"Amsterdam", "Austin", "Berlin", "Boston", "Dublin", "Lisbon", "London", "Madrid", "Melbourne",
+		"Montreal", "Nairobi", "Oslo", "Paris", "Seattle", "Seoul", "Singapore", "Tokyo", "Toronto", "Vienna"}
+	countries = [][2]string{{"AU", "Australia"}, {"BR", "Brazil"}, {"CA", "Canada"}, {"DE", "Germany"},
+		{"ES", "Spain"}, {"FR", "France"}, {"GB", "United Kingdom"}, {"IE", "Ireland"}, {"IN", "India"},
+		{"IT", "Italy"}, {"JP", "Japan"}, {"KE", "Kenya"}, {"MX", "Mexico"}, {"NL", "Netherlands"},
+		{"NO", "Norway"}, {"NZ", "New Zealand"}, {"SG", "Singapore"}, {"US", "United States"}}
+	streets = []string{"Main Street", "High Street", "Oak Avenue", "Maple Drive", "Park Lane", "Church Road",
+		"Elm Street", "Station Road", "Mill Lane", "River Road"}
+	companies = []string{"Acme Corp", "Blue Sky Labs", "Cobalt Systems", "Evergreen Foods", "Globex", "Initech",
+		"Northwind Traders", "Pied Piper", "Stark Industries", "Umbrella Logistics", "Wayne Enterprises"}
+	currencies = []string{"AUD", "CAD", "CHF", "EUR", "GBP", "JPY", "NZD", "USD"}
+)
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+func isIntegerSchema(schema *base.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	for _, t := range schema.Type {
+		if t == integerType {
+			return true
+		}
+	}
+	return false
+}
+
+func pseudoUUID(r *rand.Rand) string {
+	b := make([]byte, 16)
+	_, _ = r.Read(b)
+	return strings.ToLower(fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
+}
+
+// RegisterFormatGenerator registers a generator for a string format, such as 'iban' or 'credit-card'. A registered
+// generator replaces the built-in generator of a format.
+func (wr *SchemaRenderer) RegisterFormatGenerator(format string, generator Generator) {
+	if wr.formatGenerators == nil {
+		wr.formatGenerators = make(map[string]Generator)
+	}
+	wr.formatGenerators[format] = generator
+}
+
+// RegisterFakerGenerator registers a generator for schemas that name it with the FakerExtension (x-faker). A
+// registered generator replaces the built-in faker of the same name. The built-in fakers are: email, firstName,
+// lastName, fullName, username, city, country, countryCode, phone, url, street, postcode, company, currency,
+// dateTime, price, id, uuid, latitude and longitude.
+func (wr *SchemaRenderer) RegisterFakerGenerator(name string, generator Generator) {
+	if wr.fakerGenerators == nil {
+		wr.fakerGenerators = make(map[string]Generator)
+	}
+	wr.fakerGenerators[name] = generator
+}
+
+// fake generates a fake value for a schema. The faker named by the x-faker extension is used first, then a
+// registered format generator, then a faker recognised from the property name (such as 'email' or 'createdAt').
+// Values from property names are only used if they satisfy the schema, false is returned if there is no fake value.
+func (wr *SchemaRenderer) fake(schema *base.Schema, key string) (any, bool) {
+	if name, ok := schema.Extensions[FakerExtension].(string); ok {
+		if generator := wr.faker(name); generator != nil {
+			return generator(wr.random(), schema), true
+		}
+	}
+	if generator := wr.formatGenerators[schema.Format]; schema.Format != "" && generator != nil {
+		return generator(wr.random(), schema), true
+	}
+	if schema.Format != "" {
+		return nil, false
+	}
+	if generator := wr.faker(propertyFakers[normalizePropertyName(key)]); generator != nil {
+		// the random source is used either way, so rendering is the same whether the value fits or not.
+		if v := generator(wr.random(), schema); fitsSchema(schema, v) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// faker returns the registered or built-in faker with a name, nil if there isn't one.
+func (wr *SchemaRenderer) faker(name string) Generator {
+	if name == "" {
+		return nil
+	}
+	if generator := wr.fakerGenerators[name]; generator != nil {
+		return generator
+	}
+	return fakers[name]
+}
+
+// normalizePropertyName lower cases a property name and removes separators, so 'first_name', 'first-name' and
+// 'firstName' are the same. Names that end with 'Id' or 'At' (like 'customerId' and 'publishedAt') are normalized to
+// 'id' and 'createdat'.
+func normalizePropertyName(name string) string {
+	switch name {
+	case rootType, itemsType, allOfType, oneOfType, anyOfType:
+		return ""
+	}
+	if len(name) > 2 && (strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "_id")) {
+		return "id"
+	}
+	if len(name) > 2 && (strings.HasSuffix(name, "At") || strings.HasSuffix(name, "_at")) {
+		return "createdat"
+	}
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+}
+
+// fitsSchema returns true if a fake value satisfies the type, const, enum, pattern, length and range of a schema.
+func fitsSchema(schema *base.Schema, v any) bool {
+	if len(schema.Type) == 0 || !matchesSimpleSchema(schema, v) {
+		return false
+	}
+	if _, ok := numberValue(v); ok {
+		// fakers don't know about multiples or numeric exclusive bounds, so don't trust them with those.
+		return schema.MultipleOf == nil && (schema.ExclusiveMinimum == nil || schema.ExclusiveMinimum.IsA()) &&
+			(schema.ExclusiveMaximum == nil || schema.ExclusiveMaximum.IsA())
+	}
+	return true
+}
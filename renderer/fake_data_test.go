@@ -0,0 +1,149 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+	"time"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+)
+
+var customerSchema = `type: object
+required: [id, customerId, email, firstName, last_name, city, country, phone, website, createdAt, price, quantity, nickname]
+properties:
+  id:
+    type: integer
+  customerId:
+    type: string
+  email:
+    type: string
+  firstName:
+    type: string
+  last_name:
+    type: string
+  city:
+    type: string
+  country:
+    type: string
+  phone:
+    type: string
+  website:
+    type: string
+  createdAt:
+    type: string
+  price:
+    type: number
+  quantity:
+    type: integer
+    minimum: 1
+    maximum: 5
+  nickname:
+    type: string
+    x-faker: firstName`
+
+func TestSchemaRenderer_Fakers(t *testing.T) {
+	wr := CreateRendererUsingDefaultDictionary()
+	wr.SetSeed(1)
+	customer := wr.RenderSchema(getSchema([]byte(customerSchema))).(map[string]any)
+
+	assert.IsType(t, int64(0), customer["id"])
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`, customer["customerId"])
+	assert.Regexp(t, `^[a-z']+\.[a-z']+@example\.(com|org|net)$`, customer["email"])
+	assert.Contains(t, firstNames, customer["firstName"])
+	assert.Contains(t, lastNames, customer["last_name"])
+	assert.Contains(t, cities, customer["city"])
+	assert.Regexp(t, `^\+1-\d{3}-555-\d{4}$`, customer["phone"])
+	assert.Regexp(t, `^https://www\.[a-z]+\.com$`, customer["website"])
+	_, err := time.Parse(time.RFC3339, customer["createdAt"].(string))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\d+(\.\d{1,2})?$`, customer["price"])
+	assert.Contains(t, firstNames, customer["nickname"])
+}
+
+func TestSchemaRenderer_Fakers_RespectConstraints(t *testing.T) {
+	wr := CreateRendererUsingDefaultDictionary()
+	wr.SetSeed(1)
+
+	// fake values that do not satisfy the schema are not used.
+	email := wr.RenderSchema(getSchema([]byte(`type: object
+required: [email, city, price, id]
+properties:
+  email:
+    type: string
+    maxLength: 5
+  city:
+    type: string
+    pattern: '^[0-9]{3}$'
+  price:
+    type: integer
+    minimum: 1000
+    maximum: 2000
+  id:
+    type: boolean`))).(map[string]any)
+	assert.LessOrEqual(t, len(email["email"].(string)), 5)
+	assert.Regexp(t, `^[0-9]{3}$`, email["city"])
+	assert.GreaterOrEqual(t, email["price"], int64(1000))
+	assert.IsType(t, true, email["id"])
+}
+
+func TestSchemaRenderer_RegisterGenerators(t *testing.T) {
+	wr := CreateRendererUsingDefaultDictionary()
+	wr.RegisterFormatGenerator("iban", func(r *rand.Rand, _ *highbase.Schema) any {
+		return "GB82WEST12345698765432"
+	})
+	wr.RegisterFakerGenerator("firstName", func(r *rand.Rand, _ *highbase.Schema) any {
+		return "pb33f"
+	})
+	rendered := wr.RenderSchema(getSchema([]byte(`type: object
+required: [account, name, firstName]
+properties:
+  account:
+    type: string
+    format: iban
+  name:
+    type: string
+    x-faker: firstName
+  firstName:
+    type: string`))).(map[string]any)
+	assert.Equal(t, "GB82WEST12345698765432", rendered["account"])
+	assert.Equal(t, "pb33f", rendered["name"])
+	assert.Equal(t, "pb33f", rendered["firstName"])
+}
+
+func TestSchemaRenderer_Fakers_Seeded(t *testing.T) {
+	wr := CreateRendererUsingDefaultDictionary()
+	wr.SetSeed(7)
+	first := wr.RenderSchema(getSchema([]byte(customerSchema)))
+	wr.SetSeed(7)
+	assert.Equal(t, first, wr.RenderSchema(getSchema([]byte(customerSchema))))
+}
+
+func TestSchemaRenderer_Formats_Realistic(t *testing.T) {
+	wr := CreateRendererUsingDefaultDictionary()
+	assert.Regexp(t, `@example\.`, wr.RenderSchema(getSchema([]byte("type: string\nformat: email"))))
+	assert.Regexp(t, `^https://www\.`, wr.RenderSchema(getSchema([]byte("type: string\nformat: uri"))))
+}
+
+func TestNormalizePropertyName(t *testing.T) {
+	assert.Equal(t, "firstname", normalizePropertyName("first_name"))
+	assert.Equal(t, "firstname", normalizePropertyName("First-Name"))
+	assert.Equal(t, "id", normalizePropertyName("orderId"))
+	assert.Equal(t, "id", normalizePropertyName("order_id"))
+	assert.Equal(t, "createdat", normalizePropertyName("publishedAt"))
+	assert.Equal(t, "format", normalizePropertyName("format"))
+	assert.Equal(t, "", normalizePropertyName(rootType))
+}
+
+func TestDefaultDictionary(t *testing.T) {
+	words := DefaultDictionary()
+	assert.Greater(t, len(words), 400)
+	for _, w := range words {
+		assert.Regexp(t, regexp.MustCompile(`^[a-z]+$`), w)
+	}
+	assert.Equal(t, words, CreateRendererUsingDictionary("/do/not/exist").words)
+}
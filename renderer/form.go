@@ -0,0 +1,184 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/parameters"
+	"gopkg.in/yaml.v3"
+)
+
+// MultipartBoundary is the boundary between the parts of Multipart mocks. The boundary is always the same, so mocks
+// are reproducible, use it to build the Content-Type of a mock: 'multipart/form-data; boundary=' + MultipartBoundary.
+const MultipartBoundary = "libopenapi-mock-boundary"
+
+// renderForm renders the properties of an object as an application/x-www-form-urlencoded body. Each property is
+// serialized by a parameters.Codec, using the style, explode and allowReserved values of its encoding, like a query
+// parameter. Properties with an encoding content type (and no style) are serialized using the content type instead.
+func renderForm(value any, schema *base.Schema, encoding map[string]*v3.Encoding) []byte {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return []byte(xmlText(value))
+	}
+	var parts []string
+	for _, k := range sortedKeys(object) {
+		enc := encoding[k]
+		if enc != nil && enc.ContentType != "" && enc.Style == "" {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(
+				string(renderPart(object[k], propertySchema(schema, k), enc.ContentType))))
+			continue
+		}
+		parts = append(parts, formCodec(k, enc).Serialize(formValue(object[k])))
+	}
+	return []byte(strings.Join(parts, "&"))
+}
+
+// formCodec returns the codec of a form property, the form style and exploded unless its encoding says otherwise.
+func formCodec(name string, enc *v3.Encoding) *parameters.Codec {
+	c := &parameters.Codec{Name: name, In: parameters.InQuery, Style: parameters.StyleForm, Explode: true}
+	if enc == nil {
+		return c
+	}
+	if enc.Style != "" {
+		c.Style = enc.Style
+	}
+	c.Explode = c.Style == parameters.StyleForm
+	if enc.Explode != nil {
+		c.Explode = *enc.Explode
+	}
+	c.AllowReserved = enc.AllowReserved
+	return c
+}
+
+// formValue replaces the nested arrays and objects of a form property with their JSON text, the codec only
+// serializes primitives inside of arrays and objects.
+func formValue(value any) any {
+	switch v := value.(type) {
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = fieldText(item)
+		}
+		return items
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for k, item := range v {
+			object[k] = fieldText(item)
+		}
+		return object
+	}
+	return fieldText(value)
+}
+
+// renderMultipart renders the properties of an object as a multipart/form-data body, using MultipartBoundary. Each
+// property is a part, arrays are a part for each item. The content type of a part is the content type of its encoding,
+// otherwise primitives are text/plain, binary strings are application/octet-stream and anything else is JSON. Headers
+// of the encoding are rendered for each part.
+func (mg *MockGenerator) renderMultipart(value any, schema *base.Schema, encoding map[string]*v3.Encoding) []byte {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return []byte(xmlText(value))
+	}
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.SetBoundary(MultipartBoundary)
+	for _, k := range sortedKeys(object) {
+		property := propertySchema(schema, k)
+		values := []any{object[k]}
+		if items, ok := object[k].([]any); ok {
+			values = items
+			if property != nil && property.Items != nil && property.Items.IsA() && property.Items.A != nil {
+				property = property.Items.A.Schema()
+			}
+		}
+		for _, item := range values {
+			contentType := partContentType(item, property)
+			header := textproto.MIMEHeader{}
+			if enc := encoding[k]; enc != nil {
+				if enc.ContentType != "" {
+					// the encoding may list several content types, the first is used.
+					contentType = strings.TrimSpace(strings.Split(enc.ContentType, ",")[0])
+				}
+				for _, name := range sortedKeys(enc.Headers) {
+					if strings.EqualFold(name, "Content-Type") || enc.Headers[name] == nil {
+						continue
+					}
+					h := enc.Headers[name]
+					header.Set(name, fieldText(mg.renderValue(h.Example, h.Examples, h.Schema)))
+				}
+			}
+			disposition := fmt.Sprintf(`form-data; name="%s"`, k)
+			if contentType == "application/octet-stream" {
+				disposition += fmt.Sprintf(`; filename="%s"`, k)
+			}
+			header.Set("Content-Disposition", disposition)
+			header.Set("Content-Type", contentType)
+			part, _ := w.CreatePart(header)
+			_, _ = part.Write(renderPart(item, property, contentType))
+		}
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+// renderValue returns the example, the first example (by name), or a value rendered from the schema.
+func (mg *MockGenerator) renderValue(example any, examples map[string]*base.Example, schema *base.SchemaProxy) any {
+	if example != nil {
+		return example
+	}
+	for _, name := range sortedKeys(examples) {
+		if examples[name] != nil {
+			return examples[name].Value
+		}
+	}
+	if schema != nil {
+		return mg.renderer.RenderSchema(schema.Schema())
+	}
+	return nil
+}
+
+// partContentType returns the default content type of a multipart part.
+func partContentType(value any, schema *base.Schema) string {
+	switch {
+	case schema != nil && schema.Format == "binary":
+		return "application/octet-stream"
+	case isPrimitive(value):
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+// renderPart renders a value for the content type of a part (or an encoded form property).
+func renderPart(value any, schema *base.Schema, contentType string) []byte {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		b, _ := json.Marshal(value)
+		return b
+	case strings.HasSuffix(mediaType, "/yaml") || strings.HasSuffix(mediaType, "+yaml"):
+		b, _ := yaml.Marshal(value)
+		return b
+	case strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"):
+		return renderXML(value, schema, false)
+	}
+	return []byte(fieldText(value))
+}
+
+// fieldText returns the text of a form field, objects and arrays are JSON.
+func fieldText(value any) string {
+	if isPrimitive(value) || value == nil {
+		return xmlText(value)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockGenerator_GenerateFormMock(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petStore))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	content := model.Model.Paths.PathItems["/pets"].Get.Responses.Codes["200"].Content
+	mg := NewMockGenerator(FormURLEncoded)
+	mock, err := mg.GenerateMock(content["application/x-www-form-urlencoded"], "")
+	assert.NoError(t, err)
+	assert.Equal(t, "id=1&name=fluffy+%26+friends&owner=%7B%22name%22%3A%22dave%22%7D&tags=cute|small", string(mock))
+
+	values, err := url.ParseQuery(string(mock))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"dave"}`, values.Get("owner"))
+}
+
+func TestMockGenerator_GenerateMultipartMock(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petStore))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	content := model.Model.Paths.PathItems["/pets"].Get.Responses.Codes["200"].Content
+	mg := NewMockGenerator(Multipart)
+	mock, err := mg.GenerateMock(content["multipart/form-data"], "")
+	assert.NoError(t, err)
+
+	type part struct {
+		name, filename, contentType, rateLimit, body string
+	}
+	var parts []part
+	reader := multipart.NewReader(bytes.NewReader(mock), MultipartBoundary)
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(p)
+		parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"),
+			p.Header.Get("X-Rate-Limit"), string(body)})
+	}
+	assert.Equal(t, []part{
+		{"name", "", "text/plain", "", "fluffy"},
+		{"owner", "", "application/xml", "10", `<?xml version="1.0" encoding="UTF-8"?>` + "\n<Owner><name>dave</name></Owner>"},
+		{"photo", "photo", "application/octet-stream", "", "abc"},
+		{"tags", "", "text/plain", "", "a"},
+		{"tags", "", "text/plain", "", "b"},
+	}, parts)
+}
+
+func TestRenderForm_Styles(t *testing.T) {
+	object := map[string]any{"R": 100, "G": 200}
+	explode, noExplode := true, false
+	render := func(value any, enc *v3.Encoding) string {
+		return string(renderForm(map[string]any{"c": value}, nil, map[string]*v3.Encoding{"c": enc}))
+	}
+	assert.Equal(t, "c=a&c=b", render([]any{"a", "b"}, nil))
+	assert.Equal(t, "c=a,b", render([]any{"a", "b"}, &v3.Encoding{Explode: &noExplode}))
+	assert.Equal(t, "c=a%20b", render([]any{"a", "b"}, &v3.Encoding{Style: "spaceDelimited"}))
+	assert.Equal(t, "G=200&R=100", render(object, nil))
+	assert.Equal(t, "c=G,200,R,100", render(object, &v3.Encoding{Explode: &noExplode}))
+	assert.Equal(t, "c%5BG%5D=200&c%5BR%5D=100", render(object, &v3.Encoding{Style: "deepObject", Explode: &explode}))
+	assert.Equal(t, "c=a+b", render("a b", nil))
+	assert.Equal(t, "c=%5B1%2C2%5D&c=x", render([]any{[]any{1, 2}, "x"}, nil))
+}
+
+func TestRenderForm_AllowReserved(t *testing.T) {
+	mock := renderForm(map[string]any{"note": "hello big world", "path": "/a/b?c=d"}, nil,
+		map[string]*v3.Encoding{"note": {AllowReserved: true}, "path": {AllowReserved: true}})
+	assert.Equal(t, "note=hello+big+world&path=/a/b?c=d", string(mock))
+
+	values, err := url.ParseQuery(string(mock))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello big world", values.Get("note"))
+	assert.Equal(t, "/a/b?c=d", values.Get("path"))
+}
+
+func TestRenderForm_NotAnObject(t *testing.T) {
+	assert.Equal(t, "fluffy", string(renderForm("fluffy", nil, nil)))
+	mg := NewMockGenerator(Multipart)
+	assert.Equal(t, "1", string(mg.renderMultipart(1, nil, nil)))
+}
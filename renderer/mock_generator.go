@@ -5,10 +5,13 @@ package renderer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"gopkg.in/yaml.v3"
 	"reflect"
+	"sort"
 )
 
 const Example = "Example"
@@ -17,11 +20,23 @@ const Schema = "Schema"
 
 type MockType int
 
+// MockVariant is a mock generated by GenerateMockVariants, named by the variant (or example) it was generated from.
+type MockVariant struct {
+	Name string
+	Mock []byte
+}
+
 const (
 	JSON MockType = iota
 	YAML
+	XML            // XML, using the XML object of each schema for names, namespaces, attributes and wrapping.
+	FormURLEncoded // application/x-www-form-urlencoded, using the Encoding of the mockable struct for each property.
+	Multipart      // multipart/form-data, using the Encoding of the mockable struct for each part, see MultipartBoundary.
 )
 
+// Encoding is the name of the field of a mockable struct that holds the encoding of form and multipart properties.
+const Encoding = "Encoding"
+
 // MockGenerator is used to generate mocks for high-level mockable structs or *base.Schema pointers.
 // The mock generator will attempt to generate a mock from a struct using the following fields:
 //   - Example: any type, this is the default example to use if no examples are present.
@@ -29,7 +44,11 @@ const (
 //   - Schema: *base.SchemaProxy, this is the schema to use if no examples are present.
 //
 // The mock generator will attempt to generate a mock from a *base.Schema pointer.
-// Use NewMockGenerator or NewMockGeneratorWithDictionary to create a new mock generator.
+//
+// FormURLEncoded and Multipart mocks also use the Encoding field (map[string]*v3.Encoding) of a mockable struct,
+// such as a *v3.MediaType, if it has one.
+// Use NewMockGenerator or NewMockGeneratorWithDictionary to create a new mock generator. Like a SchemaRenderer, a mock
+// generator must not be used by more than one goroutine at a time.
 type MockGenerator struct {
 	renderer *SchemaRenderer
 	mockType MockType
@@ -38,13 +57,16 @@ type MockGenerator struct {
 
 // NewMockGeneratorWithDictionary creates a new mock generator using a custom dictionary. This is useful if you want to
 // use a custom dictionary to generate mocks. The location of a text file with one word per line is expected.
+// The generator must not be used by more than one goroutine at a time.
 func NewMockGeneratorWithDictionary(dictionaryLocation string, mockType MockType) *MockGenerator {
 	renderer := CreateRendererUsingDictionary(dictionaryLocation)
 	return &MockGenerator{renderer: renderer, mockType: mockType}
 }
 
-// NewMockGenerator creates a new mock generator using the default dictionary. The default is located at /usr/share/dict/words
-// on most systems. Windows users will need to use NewMockGeneratorWithDictionary to specify a custom dictionary.
+// NewMockGenerator creates a new mock generator using the default dictionary, which is embedded in the library.
+// Use NewMockGeneratorWithDictionary to specify a custom dictionary.
+// Mocks are random by default, use SetSeed on the returned generator to make them reproducible.
+// The generator must not be used by more than one goroutine at a time.
 func NewMockGenerator(mockType MockType) *MockGenerator {
 	renderer := CreateRendererUsingDefaultDictionary()
 	return &MockGenerator{renderer: renderer, mockType: mockType}
@@ -52,45 +74,73 @@ func NewMockGenerator(mockType MockType) *MockGenerator {
 
 // SetPretty sets the pretty flag on the mock generator. If true, the mock will be rendered with indentation and newlines.
 // If false, the mock will be rendered as a single line which is good for API responses. False is the default.
-// This option only effects JSON mocks, there is no concept of pretty printing YAML.
+// This option only effects JSON and XML mocks, there is no concept of pretty printing YAML.
 func (mg *MockGenerator) SetPretty() {
 	mg.pretty = true
 }
 
+// SetVariantStrategy sets how the mock generator chooses which schema of a oneOf or anyOf to render.
+// FirstVariant is the default.
+func (mg *MockGenerator) SetVariantStrategy(strategy VariantStrategy) {
+	mg.renderer.SetVariantStrategy(strategy)
+}
+
+// SetVariantName sets the name of the oneOf or anyOf variant to render, such as a discriminator mapping key or the
+// name of a referenced schema.
+func (mg *MockGenerator) SetVariantName(name string) {
+	mg.renderer.SetVariantName(name)
+}
+
+// SetMaxDepth sets the maximum depth of mocks generated from a schema, the default is DefaultMaxDepth.
+func (mg *MockGenerator) SetMaxDepth(depth int) {
+	mg.renderer.SetMaxDepth(depth)
+}
+
+// SetCircularStrategy sets what is rendered in a mock where a circular schema is found, or the maximum depth is
+// reached. OmitCircular is the default.
+func (mg *MockGenerator) SetCircularStrategy(strategy CircularStrategy) {
+	mg.renderer.SetCircularStrategy(strategy)
+}
+
+// RegisterFormatGenerator registers a generator for values of a string format, see
+// SchemaRenderer.RegisterFormatGenerator.
+func (mg *MockGenerator) RegisterFormatGenerator(format string, generator Generator) {
+	mg.renderer.RegisterFormatGenerator(format, generator)
+}
+
+// RegisterFakerGenerator registers a generator for schemas that name it with the x-faker extension, see
+// SchemaRenderer.RegisterFakerGenerator.
+func (mg *MockGenerator) RegisterFakerGenerator(name string, generator Generator) {
+	mg.renderer.RegisterFakerGenerator(name, generator)
+}
+
+// SetSeed seeds the mock generator, so the same mockable struct or schema will always generate the same mock.
+// Without a seed, mocks generated from a schema are different every time. Use this for reproducible (snapshot) tests.
+func (mg *MockGenerator) SetSeed(seed int64) {
+	mg.renderer.SetSeed(seed)
+}
+
 // GenerateMock generates a mock for a given high-level mockable struct. The mockable struct must contain the following fields:
 // Example: any type, this is the default example to use if no examples are present.
 // Examples: map[string]*base.Example, this is a map of examples keyed by name.
 // Schema: *base.SchemaProxy, this is the schema to use if no examples are present.
 // The name parameter is optional, if provided, the mock generator will attempt to find an example with the given name.
-// If no name is provided, the first example will be used.
+// If no name is provided, the first example (ordered by name) will be used.
+//
+// When a mock is generated from a schema with constraints that cannot be satisfied, the mock is returned along with
+// an error that joins every UnsatisfiableConstraintError found, the mock will not validate against the schema.
 func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 	v := reflect.ValueOf(mock).Elem()
-	num := v.NumField()
-	fieldCount := 0
-	for i := 0; i < num; i++ {
-		fieldName := v.Type().Field(i).Name
-		switch fieldName {
-		case Example:
-			fieldCount++
-		case Examples:
-			fieldCount++
-		}
-	}
-	mockReady := false
-	// check if all fields are present, if so, we can generate a mock
-	if fieldCount == 2 {
-		mockReady = true
-	}
-	if !mockReady {
-		return nil, fmt.Errorf("mockable struct only contains %d of the required "+
-			"fields (%s, %s)", fieldCount, Example, Examples)
+	if err := checkMockable(v); err != nil {
+		return nil, err
 	}
+	schemaValue, encoding := mockSchema(mock, v), mockEncoding(v)
 
 	// if the value has an example, try and render it out as is.
 	exampleValue := v.FieldByName(Example).Interface()
 	if exampleValue != nil {
 		// try and serialize the example value
-		return mg.renderMock(exampleValue), nil
+		return mg.renderMock(exampleValue, schemaValue, encoding), nil
 	}
 
 	// if there is no example, but there are multi-examples.
@@ -102,50 +152,146 @@ func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 		examplesMap := examplesValue.(map[string]*highbase.Example)
 
 		// if the name is not empty, try and find the example by name
-		for k, exp := range examplesMap {
-			if k == name {
-				return mg.renderMock(exp.Value), nil
-			}
+		if exp, ok := examplesMap[name]; ok && exp != nil {
+			return mg.renderMock(exp.Value, schemaValue, encoding), nil
 		}
 
-		// if the name is empty, just return the first example
-		for _, exp := range examplesMap {
-			return mg.renderMock(exp.Value), nil
+		// if the name is empty, just return the first example, by name so the choice is always the same.
+		names := make([]string, 0, len(examplesMap))
+		for k := range examplesMap {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			if examplesMap[k] != nil {
+				return mg.renderMock(examplesMap[k].Value, schemaValue, encoding), nil
+			}
 		}
 	}
 
 	// no examples? no problem, we can try and generate a mock from the schema.
+	if schemaValue != nil {
+		renderMap := mg.renderer.RenderSchema(schemaValue)
+		if renderMap != nil {
+			return mg.renderMock(renderMap, schemaValue, encoding), errors.Join(mg.renderer.GetRenderingErrors()...)
+		}
+	}
+	return nil, nil
+}
+
+// GenerateMockVariants generates a mock for every variant of the oneOf and anyOf schemas in a high-level mockable
+// struct or *base.Schema, so every branch of a polymorphic payload can be tested. Discriminator properties are set
+// to the name of each variant. The name of each MockVariant is the name of the variant rendered for the first oneOf
+// or anyOf in the schema (see SchemaRenderer.RenderSchemaVariants).
+//
+// If the mockable struct has examples, then a mock is returned for each example instead, named by the example name.
+func (mg *MockGenerator) GenerateMockVariants(mock any) ([]*MockVariant, error) {
+	v := reflect.ValueOf(mock).Elem()
+	if err := checkMockable(v); err != nil {
+		return nil, err
+	}
+	schemaValue, encoding := mockSchema(mock, v), mockEncoding(v)
+	if ex := v.FieldByName(Example); ex.IsValid() && ex.Interface() != nil {
+		return []*MockVariant{{Mock: mg.renderMock(ex.Interface(), schemaValue, encoding)}}, nil
+	}
+	if examples, ok := v.FieldByName(Examples).Interface().(map[string]*highbase.Example); ok && len(examples) > 0 {
+		names := make([]string, 0, len(examples))
+		for k := range examples {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		var variants []*MockVariant
+		for _, k := range names {
+			if examples[k] != nil {
+				variants = append(variants, &MockVariant{Name: k, Mock: mg.renderMock(examples[k].Value, schemaValue, encoding)})
+			}
+		}
+		return variants, nil
+	}
+
+	if schemaValue == nil {
+		return nil, nil
+	}
+	var variants []*MockVariant
+	var errs []error
+	for _, rendered := range mg.renderer.RenderSchemaVariants(schemaValue) {
+		errs = append(errs, mg.renderer.GetRenderingErrors()...)
+		variants = append(variants, &MockVariant{Name: rendered.Name, Mock: mg.renderMock(rendered.Value, schemaValue, encoding)})
+	}
+	return variants, errors.Join(errs...)
+}
+
+// mockSchema returns the schema of a mockable struct, or the schema itself, nil if there isn't one.
+func mockSchema(mock any, v reflect.Value) *highbase.Schema {
 	// check if this is a SchemaProxy, if not, then see if it has a Schema, if not, then we can't generate a mock.
 	var schemaValue *highbase.Schema
 	switch reflect.TypeOf(mock) {
 	case reflect.TypeOf(&highbase.Schema{}):
 		schemaValue = mock.(*highbase.Schema)
 	default:
-		if sv, ok := v.FieldByName(Schema).Interface().(*highbase.Schema); ok {
+		field := v.FieldByName(Schema)
+		if !field.IsValid() {
+			return nil
+		}
+		if sv, ok := field.Interface().(*highbase.Schema); ok {
 			if sv != nil {
 				schemaValue = sv
 			}
 		}
-		if sv, ok := v.FieldByName(Schema).Interface().(*highbase.SchemaProxy); ok {
+		if sv, ok := field.Interface().(*highbase.SchemaProxy); ok {
 			if sv != nil {
 				schemaValue = sv.Schema()
 			}
 		}
 	}
+	return schemaValue
+}
 
-	if schemaValue != nil {
-		renderMap := mg.renderer.RenderSchema(schemaValue)
-		if renderMap != nil {
-			return mg.renderMock(renderMap), nil
+// mockEncoding returns the encoding of a mockable struct, nil if there isn't one.
+func mockEncoding(v reflect.Value) map[string]*v3.Encoding {
+	if field := v.FieldByName(Encoding); field.IsValid() {
+		if encoding, ok := field.Interface().(map[string]*v3.Encoding); ok {
+			return encoding
 		}
 	}
-	return nil, nil
+	return nil
+}
+
+// checkMockable checks a mockable struct contains the fields required to generate a mock.
+func checkMockable(v reflect.Value) error {
+	num := v.NumField()
+	fieldCount := 0
+	for i := 0; i < num; i++ {
+		fieldName := v.Type().Field(i).Name
+		switch fieldName {
+		case Example:
+			fieldCount++
+		case Examples:
+			fieldCount++
+		}
+	}
+	mockReady := false
+	// check if all fields are present, if so, we can generate a mock
+	if fieldCount == 2 {
+		mockReady = true
+	}
+	if !mockReady {
+		return fmt.Errorf("mockable struct only contains %d of the required "+
+			"fields (%s, %s)", fieldCount, Example, Examples)
+	}
+	return nil
 }
 
-func (mg *MockGenerator) renderMock(v any) []byte {
+func (mg *MockGenerator) renderMock(v any, schema *highbase.Schema, encoding map[string]*v3.Encoding) []byte {
 	switch {
 	case mg.mockType == YAML:
 		return mg.renderMockYAML(v)
+	case mg.mockType == XML:
+		return renderXML(v, schema, mg.pretty)
+	case mg.mockType == FormURLEncoded:
+		return renderForm(v, schema, encoding)
+	case mg.mockType == Multipart:
+		return mg.renderMultipart(v, schema, encoding)
 	default:
 		return mg.renderMockJSON(v)
 	}
@@ -5,6 +5,7 @@ package renderer
 
 import (
 	"encoding/json"
+	"errors"
 	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/low"
 	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
@@ -277,3 +278,55 @@ func TestMockGenerator_GenerateJSONMock_Object_RawSchema(t *testing.T) {
 	assert.GreaterOrEqual(t, m["herbs"].(int), 350)
 	assert.LessOrEqual(t, m["herbs"].(int), 400)
 }
+
+func TestMockGenerator_GenerateJSONMock_Seeded(t *testing.T) {
+	fake := createFakeMock(objectFakeMockSchema, nil, nil)
+
+	generate := func() string {
+		mg := NewMockGenerator(JSON)
+		mg.SetSeed(1234)
+		mock, err := mg.GenerateMock(fake, "")
+		assert.NoError(t, err)
+		return string(mock)
+	}
+	first := generate()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, generate())
+	}
+}
+
+func TestMockGenerator_GenerateJSONMock_MultiExamples_NoName_Stable(t *testing.T) {
+	fakeExample := map[string]any{
+		"c": "third",
+		"a": "first",
+		"b": "second",
+	}
+	fake := createFakeMock(simpleFakeMockSchema, fakeExample, nil)
+	mg := NewMockGenerator(JSON)
+	for i := 0; i < 10; i++ {
+		mock, err := mg.GenerateMock(fake, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", string(mock))
+	}
+	mock, _ := mg.GenerateMock(fake, "b")
+	assert.Equal(t, "second", string(mock))
+}
+
+func TestMockGenerator_GenerateJSONMock_Unsatisfiable(t *testing.T) {
+
+	fake := createFakeMock(`type: object
+required: [name]
+properties:
+  name:
+    type: string
+    minLength: 10
+    maxLength: 2`, nil, nil)
+	mg := NewMockGenerator(JSON)
+	mock, err := mg.GenerateMock(fake, "")
+	assert.NotEmpty(t, mock)
+	assert.Error(t, err)
+
+	var uce *UnsatisfiableConstraintError
+	assert.True(t, errors.As(err, &uce))
+	assert.Equal(t, "$.name", uce.Path)
+}
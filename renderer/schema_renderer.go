@@ -4,16 +4,16 @@
 package renderer
 
 import (
-	cryptoRand "crypto/rand"
+	_ "embed"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/lucasjones/reggen"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"golang.org/x/exp/slices"
 )
@@ -49,45 +49,131 @@ const itemsType = "items"
 // used to generate random words if there is no dictionary applied.
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
-func init() {
-	// create a new random seed
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-}
+// embeddedWords is the default dictionary, embedded so readable words are rendered everywhere, not just on systems
+// with a dictionary installed.
+//
+//go:embed words.txt
+var embeddedWords string
+
+// seededTime is the date used to generate dates and times when a seed is set, so they are reproducible.
+var seededTime = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
 
 // SchemaRenderer is a renderer that will generate random words, numbers and values based on a dictionary file.
 // The dictionary is just a slice of strings that is used to generate random words.
+//
+// Every renderer has its own source of randomness. By default, the source is seeded from the current time, so each
+// render is different. Use SetSeed to make the output of a renderer reproducible.
+//
+// A SchemaRenderer holds the state of the schema it is rendering (and its source of randomness is not safe for
+// concurrent use), so it must not be used by more than one goroutine at a time. Create a renderer for each goroutine.
 type SchemaRenderer struct {
-	words []string
+	words  []string
+	rand   *rand.Rand
+	seeded bool
+	path   []string
+	errors []error
+
+	variantStrategy VariantStrategy
+	variantName     string
+	enumerating     bool
+	variantIndex    int
+	variantCount    int
+	variantLabel    string
+
+	maxDepth         int
+	circularStrategy CircularStrategy
+	visiting         map[[32]byte]int
+
+	formatGenerators map[string]Generator
+	fakerGenerators  map[string]Generator
 }
 
 // CreateRendererUsingDictionary will create a new SchemaRenderer using a custom dictionary file.
-// The location of a text file with one word per line is expected.
+// The location of a text file with one word per line is expected. If the file cannot be read, then the embedded
+// default dictionary is used. Use SetSeed on the returned renderer to make rendering reproducible.
+// The renderer must not be used by more than one goroutine at a time.
 func CreateRendererUsingDictionary(dictionaryLocation string) *SchemaRenderer {
 	// try and read in the dictionary file
 	words := ReadDictionary(dictionaryLocation)
-	return &SchemaRenderer{words: words}
+	if len(words) == 0 {
+		words = DefaultDictionary()
+	}
+	return &SchemaRenderer{words: words, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
 }
 
-// CreateRendererUsingDefaultDictionary will create a new SchemaRenderer using the default dictionary file.
-// The default dictionary is located at /usr/share/dict/words on most systems.
-// Windows users will need to use CreateRendererUsingDictionary to specify a custom dictionary.
+// CreateRendererUsingDefaultDictionary will create a new SchemaRenderer using the default dictionary, a list of
+// common words embedded in the library, so it works the same on every system (including containers without
+// /usr/share/dict/words). Use CreateRendererUsingDictionary to specify a custom dictionary.
+// The renderer must not be used by more than one goroutine at a time.
 func CreateRendererUsingDefaultDictionary() *SchemaRenderer {
 	wr := new(SchemaRenderer)
-	wr.words = ReadDictionary("/usr/share/dict/words")
+	wr.words = DefaultDictionary()
+	wr.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
 	return wr
 }
 
+// DefaultDictionary returns the words of the default dictionary, embedded in the library.
+func DefaultDictionary() []string {
+	return strings.Fields(embeddedWords)
+}
+
+// SetSeed will seed the renderer's source of randomness, rendering the same schema with the same seed will always
+// produce the same output. This is useful for snapshot tests. Dates and times are also generated from the seed,
+// rather than from the current time.
+func (wr *SchemaRenderer) SetSeed(seed int64) {
+	wr.rand = rand.New(rand.NewSource(seed))
+	wr.seeded = true
+}
+
+// random returns the renderer's source of randomness, creating one if the renderer was not created by a constructor.
+func (wr *SchemaRenderer) random() *rand.Rand {
+	if wr.rand == nil {
+		wr.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return wr.rand
+}
+
+// now returns the current time, or a time generated from the seed if one has been set.
+func (wr *SchemaRenderer) now() time.Time {
+	if wr.seeded {
+		return seededTime.Add(time.Duration(wr.random().Int63n(365*24*60*60)) * time.Second)
+	}
+	return time.Now()
+}
+
 // RenderSchema takes a schema and renders it into an interface, ready to be converted to JSON or YAML.
+// Any constraints in the schema that could not be satisfied are available from GetRenderingErrors.
 func (wr *SchemaRenderer) RenderSchema(schema *base.Schema) any {
+	wr.errors = nil
+	wr.path = nil
+	wr.visiting = nil
 	// dive into the schema and render it
 	structure := make(map[string]any)
 	wr.DiveIntoSchema(schema, rootType, structure, 0)
-	return structure[rootType].(any)
+	if _, ok := structure[rootType].(circularCut); ok {
+		return wr.cutValue(schema)
+	}
+	return structure[rootType]
+}
+
+// GetRenderingErrors returns an UnsatisfiableConstraintError for every constraint that could not be satisfied
+// during the last call to RenderSchema. The rendered value will not validate against the schema if there are errors.
+func (wr *SchemaRenderer) GetRenderingErrors() []error {
+	return wr.errors
 }
 
 // DiveIntoSchema will dive into a schema and inject values from examples into a map. If there are no examples in
 // the schema, then the renderer will attempt to generate a value based on the schema type, format and pattern.
+//
+// Generated values respect the constraints defined by the schema (const, enum, lengths, patterns, ranges,
+// multipleOf, item and property counts, uniqueItems, prefixItems, additionalProperties, patternProperties and not),
+// so the rendered value will validate against the schema.
 func (wr *SchemaRenderer) DiveIntoSchema(schema *base.Schema, key string, structure map[string]any, depth int) {
+	if schema == nil {
+		return
+	}
+	wr.pushPath(key)
+	defer wr.popPath()
 
 	// got an example? use it, we're done here.
 	if schema.Example != nil {
@@ -95,227 +181,465 @@ func (wr *SchemaRenderer) DiveIntoSchema(schema *base.Schema, key string, struct
 		return
 	}
 
-	// emergency break to prevent stack overflow from ever occurring
-	if depth > 100 {
-		structure[key] = "to deep to continue rendering..."
+	// stop rendering circular schemas, and anything too deep (to prevent stack overflow from ever occurring).
+	// whatever is rendered in place depends on the circular strategy. hashing covers the whole schema, so only once.
+	hash, hashed := schemaHash(schema)
+	if depth > wr.getMaxDepth() || (hashed && wr.isVisiting(hash)) {
+		structure[key] = circularCut{}
 		return
 	}
+	if hashed {
+		defer wr.visit(hash)()
+	}
 
-	// render out a string.
-	if slices.Contains(schema.Type, stringType) {
-		// check for an enum, if there is one, then pick a random value from it.
-		if schema.Enum != nil && len(schema.Enum) > 0 {
-			structure[key] = schema.Enum[rand.Int()%len(schema.Enum)]
-		} else {
+	// a value must not match the 'not' schema, so keep trying until one doesn't. a 'not' schema that can't be
+	// evaluated is left alone, rather than reported as unsatisfiable.
+	var notSchema *base.Schema
+	if schema.Not != nil {
+		if ns := schema.Not.Schema(); ns != nil && simpleSchema(ns) {
+			notSchema = ns
+		}
+	}
+	for i := 0; i < maxAttempts; i++ {
+		wr.renderValue(schema, key, structure, depth)
+		if notSchema == nil || !matchesSimpleSchema(notSchema, structure[key]) {
+			return
+		}
+	}
+	wr.unsatisfiable("unable to generate a value that does not match the 'not' schema")
+}
 
-			// generate a random value based on the schema format, pattern and length values.
-			var minLength int64 = 3
-			var maxLength int64 = 10
+// renderValue renders a value for a schema, based on the schema type.
+func (wr *SchemaRenderer) renderValue(schema *base.Schema, key string, structure map[string]any, depth int) {
+	if schema.Const != nil {
+		structure[key] = schema.Const
+		return
+	}
+	// check for an enum, if there is one, then pick a random value from it.
+	if len(schema.Enum) > 0 {
+		structure[key] = schema.Enum[wr.random().Int()%len(schema.Enum)]
+		return
+	}
+	// fakers and custom generators produce realistic values, such as names and email addresses.
+	if v, ok := wr.fake(schema, key); ok {
+		structure[key] = v
+		return
+	}
 
-			if schema.MinLength != nil {
-				minLength = *schema.MinLength
+	types := schema.Type
+	if len(types) == 0 {
+		// a oneOf or anyOf without a type could be anything, so render the variant as it is.
+		if len(schema.Properties) == 0 && len(schema.AllOf) == 0 {
+			if len(schema.OneOf) > 0 {
+				structure[key] = wr.renderVariant(schema, schema.OneOf, oneOfType, depth)
+				return
 			}
-			if schema.MaxLength != nil {
-				maxLength = *schema.MaxLength
+			if len(schema.AnyOf) > 0 {
+				structure[key] = wr.renderVariant(schema, schema.AnyOf, anyOfType, depth)
+				return
 			}
+		}
+		types = inferTypes(schema)
+	}
 
-			switch schema.Format {
-			case dateTimeType:
-				structure[key] = time.Now().Format(time.RFC3339)
-			case dateType:
-				structure[key] = time.Now().Format("2006-01-02")
-			case timeType:
-				structure[key] = time.Now().Format("15:04:05")
-			case emailType:
-				structure[key] = fmt.Sprintf("%s@%s.com",
-					wr.RandomWord(minLength, maxLength, 0),
-					wr.RandomWord(minLength, maxLength, 0))
-			case hostnameType:
-				structure[key] = fmt.Sprintf("%s.com", wr.RandomWord(minLength, maxLength, 0))
-			case ipv4Type:
-				structure[key] = fmt.Sprintf("%d.%d.%d.%d",
-					rand.Int()%255, rand.Int()%255, rand.Int()%255, rand.Int()%255)
-			case ipv6Type:
-				structure[key] = fmt.Sprintf("%04x:%04x:%04x:%04x:%04x:%04x:%04x:%04x",
-					rand.Intn(65535), rand.Intn(65535), rand.Intn(65535), rand.Intn(65535),
-					rand.Intn(65535), rand.Intn(65535), rand.Intn(65535), rand.Intn(65535),
-				)
-			case uriType:
-				structure[key] = fmt.Sprintf("https://%s-%s-%s.com/%s",
-					wr.RandomWord(minLength, maxLength, 0),
-					wr.RandomWord(minLength, maxLength, 0),
-					wr.RandomWord(minLength, maxLength, 0),
-					wr.RandomWord(minLength, maxLength, 0))
-			case uriReferenceType:
-				structure[key] = fmt.Sprintf("/%s/%s",
-					wr.RandomWord(minLength, maxLength, 0),
-					wr.RandomWord(minLength, maxLength, 0))
-			case uuidType:
-				structure[key] = wr.PseudoUUID()
-			case byteType:
-				structure[key] = fmt.Sprintf("%x", wr.RandomWord(minLength, maxLength, 0))
-			case passwordType:
-				structure[key] = fmt.Sprintf("%s", wr.RandomWord(minLength, maxLength, 0))
-			case binaryType:
-				structure[key] = fmt.Sprintf("%s",
-					base64.StdEncoding.EncodeToString([]byte(wr.RandomWord(minLength, maxLength, 0))))
-			default:
-				// if there is a pattern supplied, then try and generate a string from it.
-				if schema.Pattern != "" {
-					str, err := reggen.Generate(schema.Pattern, int(maxLength))
-					if err == nil {
-						structure[key] = str
-					}
-				} else {
-					structure[key] = wr.RandomWord(minLength, maxLength, 0)
-				}
-			}
+	switch {
+	case slices.Contains(types, stringType):
+		structure[key] = wr.renderString(schema)
+	case slices.Contains(types, numberType), slices.Contains(types, integerType):
+		structure[key] = wr.renderNumber(schema, slices.Contains(types, integerType) && !slices.Contains(types, numberType))
+	case slices.Contains(types, booleanType):
+		structure[key] = wr.random().Intn(2) == 1
+	case slices.Contains(types, objectType):
+		structure[key] = wr.renderObject(schema, depth)
+	case slices.Contains(types, arrayType):
+		if items := wr.renderArray(schema, depth); items != nil {
+			structure[key] = items
 		}
-		return
 	}
+}
 
-	// handle numbers
-	if slices.Contains(schema.Type, numberType) || slices.Contains(schema.Type, integerType) {
+// inferTypes works out the type of schema that does not define one, from the keywords it uses.
+func inferTypes(schema *base.Schema) []string {
+	switch {
+	case len(schema.Properties) > 0, len(schema.PatternProperties) > 0, len(schema.Required) > 0,
+		schema.AdditionalProperties != nil, schema.MinProperties != nil:
+		return []string{objectType}
+	case schema.Items != nil, len(schema.PrefixItems) > 0, schema.MinItems != nil:
+		return []string{arrayType}
+	case schema.Minimum != nil, schema.Maximum != nil, schema.MultipleOf != nil:
+		return []string{numberType}
+	case schema.Pattern != "", schema.MinLength != nil, schema.MaxLength != nil, schema.Format != "":
+		return []string{stringType}
+	case len(schema.AllOf) > 0, len(schema.OneOf) > 0, len(schema.AnyOf) > 0:
+		return []string{objectType}
+	}
+	return nil
+}
 
-		if schema.Enum != nil && len(schema.Enum) > 0 {
-			structure[key] = schema.Enum[rand.Int()%len(schema.Enum)]
-		} else {
+func (wr *SchemaRenderer) renderString(schema *base.Schema) any {
+	// generate a random value based on the schema format, pattern and length values.
+	var minLength int64 = 3
+	var maxLength int64 = 10
 
-			var minimum int64 = 1
-			var maximum int64 = 100
+	if schema.MinLength != nil {
+		minLength = *schema.MinLength
+		if schema.MaxLength == nil && minLength > maxLength {
+			maxLength = minLength + 10
+		}
+	}
+	if schema.MaxLength != nil {
+		maxLength = *schema.MaxLength
+		if schema.MinLength == nil && maxLength < minLength {
+			minLength = maxLength
+		}
+	}
+	satisfiable := minLength <= maxLength
+	if !satisfiable {
+		wr.unsatisfiable(fmt.Sprintf("minLength (%d) is greater than maxLength (%d)", minLength, maxLength))
+		maxLength = minLength
+	}
 
-			if schema.Minimum != nil {
-				minimum = int64(*schema.Minimum)
-			}
-			if schema.Maximum != nil {
-				maximum = int64(*schema.Maximum)
-			}
+	if v, ok := wr.formatString(schema, minLength, maxLength); ok {
+		if satisfiable && !fitsLength(schema, v) {
+			wr.unsatisfiable(fmt.Sprintf("unable to generate a '%s' string %s", schema.Format, describeLength(schema)))
+		}
+		return v
+	}
+	// if there is a pattern supplied, then try and generate a string from it.
+	if schema.Pattern != "" {
+		return wr.patternString(schema.Pattern, minLength, maxLength, schema.MinLength != nil || schema.MaxLength != nil)
+	}
+	return wr.word(minLength, maxLength)
+}
 
-			switch schema.Format {
-			case floatType:
-				structure[key] = rand.Float32()
-			case doubleType:
-				structure[key] = rand.Float64()
-			case int32Type:
-				structure[key] = int(wr.RandomInt(minimum, maximum))
-			default:
-				structure[key] = wr.RandomInt(minimum, maximum)
+// formatString generates a string of the format of a schema, false is returned if the format is not known. Strings of
+// formats with a variable length are generated to fit the minLength and maxLength of the schema, if it has them.
+func (wr *SchemaRenderer) formatString(schema *base.Schema, minLength, maxLength int64) (string, bool) {
+	bounded := schema.MinLength != nil || schema.MaxLength != nil
+	switch schema.Format {
+	case dateTimeType:
+		return wr.now().Format(time.RFC3339), true
+	case dateType:
+		return wr.now().Format("2006-01-02"), true
+	case timeType:
+		return wr.now().Format("15:04:05"), true
+	case emailType:
+		if v, ok := fakers["email"](wr.random(), schema).(string); ok && fitsSchema(schema, v) {
+			return v, true
+		}
+		if bounded {
+			return wr.sizedEmail(wr.formatLength(minLength, maxLength, 7, 254)), true
+		}
+		return fmt.Sprintf("%s@%s.com",
+			wr.RandomWord(minLength, maxLength, 0),
+			wr.RandomWord(minLength, maxLength, 0)), true
+	case hostnameType:
+		if bounded {
+			return wr.sizedHostname(wr.formatLength(minLength, maxLength, 5, 253)), true
+		}
+		return fmt.Sprintf("%s.com", wr.RandomWord(minLength, maxLength, 0)), true
+	case ipv4Type:
+		if bounded {
+			return wr.sizedIPv4(wr.formatLength(minLength, maxLength, 7, 15)), true
+		}
+		return fmt.Sprintf("%d.%d.%d.%d",
+			wr.random().Int()%255, wr.random().Int()%255, wr.random().Int()%255, wr.random().Int()%255), true
+	case ipv6Type:
+		if bounded {
+			return wr.sizedIPv6(wr.formatLength(minLength, maxLength, 15, 39)), true
+		}
+		return fmt.Sprintf("%04x:%04x:%04x:%04x:%04x:%04x:%04x:%04x",
+			wr.random().Intn(65535), wr.random().Intn(65535), wr.random().Intn(65535), wr.random().Intn(65535),
+			wr.random().Intn(65535), wr.random().Intn(65535), wr.random().Intn(65535), wr.random().Intn(65535),
+		), true
+	case uriType:
+		if v, ok := fakers["url"](wr.random(), schema).(string); ok && fitsSchema(schema, v) {
+			return v, true
+		}
+		if bounded {
+			return wr.sizedURI(wr.formatLength(minLength, maxLength, 13, maxFormatLength)), true
+		}
+		return fmt.Sprintf("https://%s-%s-%s.com/%s",
+			wr.RandomWord(minLength, maxLength, 0),
+			wr.RandomWord(minLength, maxLength, 0),
+			wr.RandomWord(minLength, maxLength, 0),
+			wr.RandomWord(minLength, maxLength, 0)), true
+	case uriReferenceType:
+		if bounded {
+			return wr.sizedURIReference(wr.formatLength(minLength, maxLength, 2, maxFormatLength)), true
+		}
+		return fmt.Sprintf("/%s/%s",
+			wr.RandomWord(minLength, maxLength, 0),
+			wr.RandomWord(minLength, maxLength, 0)), true
+	case uuidType:
+		return wr.PseudoUUID(), true
+	case byteType:
+		if bounded {
+			return wr.hex(wr.formatLength(minLength, maxLength, 1, maxFormatLength)), true
+		}
+		return fmt.Sprintf("%x", wr.RandomWord(minLength, maxLength, 0)), true
+	case passwordType:
+		return wr.word(minLength, maxLength), true
+	case binaryType:
+		if bounded {
+			// base64 is encoded in blocks of four characters, for every three bytes.
+			n := wr.formatLength(minLength, maxLength, 4, maxFormatLength)
+			if n%4 != 0 {
+				if n-n%4 >= minLength && n-n%4 >= 4 {
+					n -= n % 4
+				} else {
+					n += 4 - n%4
+				}
 			}
+			return base64.StdEncoding.EncodeToString([]byte(wr.letters(n / 4 * 3))), true
 		}
-		return
+		return base64.StdEncoding.EncodeToString([]byte(wr.RandomWord(minLength, maxLength, 0))), true
 	}
+	return "", false
+}
 
-	// handle booleans
-	if slices.Contains(schema.Type, booleanType) {
-		structure[key] = true
+func (wr *SchemaRenderer) renderObject(schema *base.Schema, depth int) map[string]any {
+	properties := schema.Properties
+	propertyMap := make(map[string]any)
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
 	}
 
-	// handle objects
-	if slices.Contains(schema.Type, objectType) {
-		properties := schema.Properties
-		propertyMap := make(map[string]any)
-
-		if properties != nil {
-			// check if this schema has required properties, if so, then only render required props, if not
-			// render everything in the schema.
-			checkProps := make(map[string]*base.SchemaProxy)
-			if len(schema.Required) > 0 {
-				for _, requiredProp := range schema.Required {
+	if properties != nil {
+		// check if this schema has required properties, if so, then only render required props, if not
+		// render everything in the schema.
+		checkProps := make(map[string]*base.SchemaProxy)
+		if len(schema.Required) > 0 {
+			for _, requiredProp := range schema.Required {
+				if properties[requiredProp] != nil {
 					checkProps[requiredProp] = properties[requiredProp]
 				}
-			} else {
-				checkProps = properties
-			}
-			for propName, propValue := range checkProps {
-				// render property
-				propertySchema := propValue.Schema()
-				wr.DiveIntoSchema(propertySchema, propName, propertyMap, depth+1)
 			}
+		} else {
+			checkProps = properties
+		}
+		// properties are rendered in name order, so the output is reproducible when a seed is set.
+		for _, propName := range sortedKeys(checkProps) {
+			// render property
+			propertySchema := checkProps[propName].Schema()
+			wr.DiveIntoSchema(propertySchema, propName, propertyMap, depth+1)
 		}
+	}
 
-		// handle allOf
-		allOf := schema.AllOf
-		if allOf != nil {
-			allOfMap := make(map[string]any)
-			for _, allOfSchema := range allOf {
-				allOfCompiled := allOfSchema.Schema()
-				wr.DiveIntoSchema(allOfCompiled, allOfType, allOfMap, depth+1)
-				for k, v := range allOfMap[allOfType].(map[string]any) {
-					propertyMap[k] = v
-				}
+	// handle allOf
+	for _, allOfSchema := range schema.AllOf {
+		allOfMap := make(map[string]any)
+		wr.DiveIntoSchema(allOfSchema.Schema(), allOfType, allOfMap, depth+1)
+		mergeProperties(propertyMap, allOfMap[allOfType])
+	}
+
+	// a schema that inherits a discriminator (via allOf) is named by the mapping or its own name.
+	if ref := schemaReference(schema); ref != "" {
+		for _, allOfSchema := range schema.AllOf {
+			if s := allOfSchema.Schema(); s != nil && s.Discriminator != nil && s.Discriminator.PropertyName != "" {
+				propertyMap[s.Discriminator.PropertyName] = discriminatorValue(s.Discriminator, ref)
 			}
 		}
+	}
 
-		// handle dependentSchemas
-		dependentSchemas := schema.DependentSchemas
-		if dependentSchemas != nil {
+	// handle dependentSchemas
+	for _, k := range sortedKeys(schema.DependentSchemas) {
+		// only map if the property exists
+		if propertyMap[k] != nil {
 			dependentSchemasMap := make(map[string]any)
-			for k, dependentSchema := range dependentSchemas {
-				// only map if the property exists
-				if propertyMap[k] != nil {
-					dependentSchemaCompiled := dependentSchema.Schema()
-					wr.DiveIntoSchema(dependentSchemaCompiled, k, dependentSchemasMap, depth+1)
-					for i, v := range dependentSchemasMap[k].(map[string]any) {
-						propertyMap[k].(map[string]any)[i] = v
-					}
-				}
+			wr.DiveIntoSchema(schema.DependentSchemas[k].Schema(), k, dependentSchemasMap, depth+1)
+			if existing, ok := propertyMap[k].(map[string]any); ok {
+				mergeProperties(existing, dependentSchemasMap[k])
 			}
 		}
+	}
+
+	// handle oneOf, the variant rendered depends on the variant strategy.
+	if len(schema.OneOf) > 0 {
+		mergeProperties(propertyMap, wr.renderVariant(schema, schema.OneOf, oneOfType, depth))
+	}
+
+	// handle anyOf
+	if len(schema.AnyOf) > 0 {
+		mergeProperties(propertyMap, wr.renderVariant(schema, schema.AnyOf, anyOfType, depth))
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := propertyMap[name]; !ok && wr.additionalAllowed(schema) {
+			wr.renderAdditionalProperty(schema, name, propertyMap, depth)
+		}
+	}
 
-		// handle oneOf
-		oneOf := schema.OneOf
-		if len(oneOf) > 0 {
-			oneOfMap := make(map[string]any)
-			oneOfCompiled := oneOf[0].Schema()
-			wr.DiveIntoSchema(oneOfCompiled, oneOfType, oneOfMap, depth+1)
-			for k, v := range oneOfMap[oneOfType].(map[string]any) {
-				propertyMap[k] = v
+	wr.resolveCuts(schema, propertyMap, required)
+	if schema.MinProperties != nil && int64(len(propertyMap)) < *schema.MinProperties {
+		wr.fillProperties(schema, propertyMap, *schema.MinProperties, depth)
+		wr.resolveCuts(schema, propertyMap, required)
+	}
+	if schema.MaxProperties != nil && int64(len(propertyMap)) > *schema.MaxProperties {
+		// remove optional properties, last first.
+		names := make([]string, 0, len(propertyMap))
+		for k := range propertyMap {
+			names = append(names, k)
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(names)))
+		for _, name := range names {
+			if int64(len(propertyMap)) <= *schema.MaxProperties {
+				break
 			}
+			if !required[name] {
+				delete(propertyMap, name)
+			}
+		}
+		if int64(len(propertyMap)) > *schema.MaxProperties {
+			wr.unsatisfiable(fmt.Sprintf("%d properties are required, but maxProperties is %d",
+				len(propertyMap), *schema.MaxProperties))
 		}
+	}
+	return propertyMap
+}
 
-		// handle anyOf
-		anyOf := schema.AnyOf
-		if len(anyOf) > 0 {
-			anyOfMap := make(map[string]any)
-			anyOfCompiled := anyOf[0].Schema()
-			wr.DiveIntoSchema(anyOfCompiled, anyOfType, anyOfMap, depth+1)
-			for k, v := range anyOfMap[anyOfType].(map[string]any) {
-				propertyMap[k] = v
+// fillProperties adds optional properties, then pattern properties, then additional properties to an object
+// until it has the minimum number of properties.
+func (wr *SchemaRenderer) fillProperties(schema *base.Schema, propertyMap map[string]any, min int64, depth int) {
+	for _, name := range sortedKeys(schema.Properties) {
+		if int64(len(propertyMap)) >= min {
+			return
+		}
+		if _, ok := propertyMap[name]; !ok {
+			wr.DiveIntoSchema(schema.Properties[name].Schema(), name, propertyMap, depth+1)
+		}
+	}
+	patterns := sortedKeys(schema.PatternProperties)
+	for i := 0; i < maxAttempts*len(patterns) && int64(len(propertyMap)) < min; i++ {
+		pattern := patterns[i%len(patterns)]
+		name := wr.patternString(pattern, 1, 20, false)
+		if _, ok := propertyMap[name]; !ok && name != "" {
+			wr.DiveIntoSchema(schema.PatternProperties[pattern].Schema(), name, propertyMap, depth+1)
+		}
+	}
+	if wr.additionalAllowed(schema) {
+		for i := 0; i < maxAttempts*int(min) && int64(len(propertyMap)) < min; i++ {
+			name := wr.propertyName(schema, depth)
+			if _, ok := propertyMap[name]; !ok {
+				wr.renderAdditionalProperty(schema, name, propertyMap, depth)
 			}
 		}
-		structure[key] = propertyMap
+	}
+	if int64(len(propertyMap)) < min {
+		wr.unsatisfiable(fmt.Sprintf("minProperties is %d, but only %d properties are allowed", min, len(propertyMap)))
+	}
+}
+
+// additionalAllowed returns true if properties not defined by properties or patternProperties are allowed.
+func (wr *SchemaRenderer) additionalAllowed(schema *base.Schema) bool {
+	ap := schema.AdditionalProperties
+	return ap == nil || (ap.IsA() && ap.A != nil) || (ap.IsB() && ap.B)
+}
+
+func (wr *SchemaRenderer) renderAdditionalProperty(schema *base.Schema, name string, propertyMap map[string]any, depth int) {
+	if ap := schema.AdditionalProperties; ap != nil && ap.IsA() && ap.A != nil {
+		wr.DiveIntoSchema(ap.A.Schema(), name, propertyMap, depth+1)
 		return
 	}
+	propertyMap[name] = wr.word(3, 10)
+}
 
-	if slices.Contains(schema.Type, arrayType) {
+// propertyName generates the name of an additional property, using propertyNames if it's defined.
+func (wr *SchemaRenderer) propertyName(schema *base.Schema, depth int) string {
+	if schema.PropertyNames != nil {
+		names := make(map[string]any)
+		wr.DiveIntoSchema(schema.PropertyNames.Schema(), "propertyNames", names, depth+1)
+		if name, ok := names["propertyNames"].(string); ok {
+			return name
+		}
+	}
+	return wr.word(3, 10)
+}
 
-		// an array needs an items schema
-		itemsSchema := schema.Items
-		if itemsSchema != nil {
+func mergeProperties(into map[string]any, from any) {
+	if m, ok := from.(map[string]any); ok {
+		for k, v := range m {
+			into[k] = v
+		}
+	}
+}
 
-			// otherwise the items value is a schema, so we need to dive into it
-			if itemsSchema.IsA() {
+func (wr *SchemaRenderer) renderArray(schema *base.Schema, depth int) any {
+	items := schema.Items
+	prefix := schema.PrefixItems
 
-				// check if the schema contains a minItems value and render up to that number.
-				var minItems int64 = 1
-				if schema.MinItems != nil {
-					minItems = *schema.MinItems
-				}
+	// an array needs an items schema
+	if items == nil && len(prefix) == 0 {
+		return nil
+	}
 
-				var renderedItems []any
-				// build up the array
-				for i := int64(0); i < minItems; i++ {
-					itemMap := make(map[string]any)
-					itemsSchemaCompiled := itemsSchema.A.Schema()
-					wr.DiveIntoSchema(itemsSchemaCompiled, itemsType, itemMap, depth+1)
-					renderedItems = append(renderedItems, itemMap[itemsType])
+	// check if the schema contains a minItems value and render up to that number.
+	var minItems int64 = 1
+	if schema.MinItems != nil {
+		minItems = *schema.MinItems
+	}
+	count := minItems
+	if int64(len(prefix)) > count {
+		count = int64(len(prefix))
+	}
+	if schema.MaxItems != nil && count > *schema.MaxItems {
+		if schema.MinItems != nil && minItems > *schema.MaxItems {
+			wr.unsatisfiable(fmt.Sprintf("minItems (%d) is greater than maxItems (%d)", minItems, *schema.MaxItems))
+		} else {
+			count = *schema.MaxItems
+		}
+	}
+	moreItems := items == nil || items.IsA() || items.B
+	if !moreItems && count > int64(len(prefix)) {
+		wr.unsatisfiable(fmt.Sprintf("%d items are required, but only %d prefixItems are allowed", count, len(prefix)))
+		count = int64(len(prefix))
+	}
+	unique := schema.UniqueItems != nil && *schema.UniqueItems
+
+	renderedItems := make([]any, 0, count)
+	seen := make(map[string]bool)
+	// build up the array
+	for i := int64(0); i < count; i++ {
+		var itemsSchema *base.Schema
+		switch {
+		case i < int64(len(prefix)):
+			itemsSchema = prefix[i].Schema()
+		case items != nil && items.IsA() && items.A != nil:
+			itemsSchema = items.A.Schema()
+		}
+		var item any
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			itemMap := make(map[string]any)
+			if itemsSchema != nil {
+				wr.DiveIntoSchema(itemsSchema, itemsType, itemMap, depth+1)
+				item = itemMap[itemsType]
+				if _, ok := item.(circularCut); ok {
+					return wr.cutArray()
 				}
-				structure[key] = renderedItems
-				return
+			} else {
+				item = wr.word(3, 10)
+			}
+			if !unique || !seen[uniqueKey(item)] {
+				break
+			}
+			if attempt == maxAttempts-1 {
+				wr.unsatisfiable(fmt.Sprintf("unable to generate %d unique items", count))
 			}
 		}
+		seen[uniqueKey(item)] = true
+		renderedItems = append(renderedItems, item)
 	}
+	return renderedItems
+}
 
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func readFile(file io.Reader) []string {
@@ -352,12 +676,12 @@ func (wr *SchemaRenderer) RandomWord(min, max int64, depth int) string {
 		}
 		b := make([]byte, min)
 		for i := range b {
-			b[i] = letterBytes[rand.Intn(len(letterBytes))]
+			b[i] = letterBytes[wr.random().Intn(len(letterBytes))]
 		}
 		return string(b)
 	}
 
-	word := wr.words[rand.Int()%len(wr.words)]
+	word := wr.words[wr.random().Int()%len(wr.words)]
 	if min == 0 && max == 0 {
 		return word
 	}
@@ -369,17 +693,15 @@ func (wr *SchemaRenderer) RandomWord(min, max int64, depth int) string {
 
 // RandomInt will return a random int between the min and max values.
 func (wr *SchemaRenderer) RandomInt(min, max int64) int64 {
-	return rand.Int63n(max-min) + min
+	return wr.random().Int63n(max-min) + min
 }
 
 // RandomFloat64 will return a random float64 between 0 and 1.
 func (wr *SchemaRenderer) RandomFloat64() float64 {
-	return rand.Float64()
+	return wr.random().Float64()
 }
 
 // PseudoUUID will return a random UUID, it's not a real UUID, but it's good enough for mock /example data.
 func (wr *SchemaRenderer) PseudoUUID() string {
-	b := make([]byte, 16)
-	_, _ = cryptoRand.Read(b)
-	return strings.ToLower(fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
+	return pseudoUUID(wr.random())
 }
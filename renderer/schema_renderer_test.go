@@ -510,12 +510,17 @@ func TestRenderExample_Boolean_WithoutExample(t *testing.T) {
 
 	compiled := getSchema([]byte(testObject))
 
-	journeyMap := make(map[string]any)
+	// booleans are random, so both values should show up.
+	seen := make(map[bool]bool)
 	wr := createSchemaRenderer()
-	wr.DiveIntoSchema(compiled, "pb33f", journeyMap, 0)
-
-	assert.NotNil(t, journeyMap["pb33f"])
-	assert.True(t, journeyMap["pb33f"].(bool))
+	for i := int64(0); i < 20; i++ {
+		journeyMap := make(map[string]any)
+		wr.SetSeed(i)
+		wr.DiveIntoSchema(compiled, "pb33f", journeyMap, 0)
+		assert.IsType(t, true, journeyMap["pb33f"])
+		seen[journeyMap["pb33f"].(bool)] = true
+	}
+	assert.Len(t, seen, 2)
 }
 
 func TestRenderExample_Array_String(t *testing.T) {
@@ -664,7 +669,7 @@ dependentSchemas:
 	assert.NotNil(t, journeyMap["pb33f"])
 	assert.Len(t, journeyMap["pb33f"], 1)
 	assert.Greater(t, journeyMap["pb33f"].(map[string]interface{})["fishCake"].(map[string]interface{})["cream"].(float64), float64(0))
-	assert.True(t, journeyMap["pb33f"].(map[string]interface{})["fishCake"].(map[string]interface{})["bones"].(bool))
+	assert.IsType(t, true, journeyMap["pb33f"].(map[string]interface{})["fishCake"].(map[string]interface{})["bones"])
 }
 
 func TestRenderExample_Object_OneOf(t *testing.T) {
@@ -931,7 +936,7 @@ properties:
 	assert.NotEmpty(t, burger["name"].(string))
 	assert.NotZero(t, burger["weight"].(int64))
 	assert.NotEmpty(t, burger["patty"].(string))
-	assert.True(t, burger["frozen"].(bool))
+	assert.IsType(t, true, burger["frozen"])
 }
 
 func TestRenderExample_Test_RequiredRendered(t *testing.T) {
@@ -1096,9 +1101,9 @@ func TestRenderSchema_NestedDeep(t *testing.T) {
 	var dive func(mapNode map[string]any, level int)
 	// count the levels to validate the recursion hard limit.
 	dive = func(mapNode map[string]any, level int) {
-		child := mapNode["child"]
+		child, ok := mapNode["child"]
 		if level >= 100 {
-			assert.Equal(t, "to deep to continue rendering...", child)
+			assert.False(t, ok) // optional, so omitted.
 			journeyLevel = level
 			return
 		}
@@ -1117,19 +1122,19 @@ func TestCreateRendererUsingDictionary(t *testing.T) {
 
 func createNestedStructure() *highbase.SchemaProxy {
 
-	schema := `type: [object]
+	// every level is a different schema, so the structure is deep, but not circular.
+	buildSchema := func(level int) *highbase.SchemaProxy {
+		schema := fmt.Sprintf(`type: [object]
 properties:
   name:
     type: string
-    example: pb33f`
+    example: pb33f-%d`, level)
 
-	var compNode yaml.Node
-	e := yaml.Unmarshal([]byte(schema), &compNode)
-	if e != nil {
-		panic(e)
-	}
-
-	buildSchema := func() *highbase.SchemaProxy {
+		var compNode yaml.Node
+		e := yaml.Unmarshal([]byte(schema), &compNode)
+		if e != nil {
+			panic(e)
+		}
 		sp := new(lowbase.SchemaProxy)
 		_ = sp.Build(nil, compNode.Content[0], nil)
 		lp := low.NodeReference[*lowbase.SchemaProxy]{
@@ -1142,7 +1147,7 @@ properties:
 	var loopMe func(parent *highbase.SchemaProxy, level int)
 
 	loopMe = func(parent *highbase.SchemaProxy, level int) {
-		schemaProxy := buildSchema()
+		schemaProxy := buildSchema(level + 1)
 		if parent != nil {
 			parent.Schema().Properties["child"] = schemaProxy
 		}
@@ -1150,7 +1155,72 @@ properties:
 			loopMe(schemaProxy, level+1)
 		}
 	}
-	root := buildSchema()
+	root := buildSchema(0)
 	loopMe(root, 0)
 	return root
 }
+
+func TestRenderSchema_Seeded(t *testing.T) {
+	testObject := `type: object
+properties:
+  name:
+    type: string
+  code:
+    type: string
+    pattern: "^[A-Z]{3}-[0-9]{4}$"
+  born:
+    type: string
+    format: date-time
+  id:
+    type: string
+    format: uuid
+  address:
+    type: string
+    format: ipv4
+  count:
+    type: integer
+  weight:
+    type: number
+    format: double
+  tags:
+    type: array
+    minItems: 3
+    items:
+      type: string
+      enum: [a, b, c, d, e, f]`
+
+	compiled := getSchema([]byte(testObject))
+
+	render := func(seed int64) string {
+		wr := createSchemaRenderer()
+		wr.SetSeed(seed)
+		rendered, _ := json.Marshal(wr.RenderSchema(compiled))
+		return string(rendered)
+	}
+
+	first := render(42)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, render(42))
+	}
+	assert.NotEqual(t, first, render(43))
+
+	// dates are generated from the seed, not the current time.
+	var rendered map[string]any
+	_ = json.Unmarshal([]byte(first), &rendered)
+	born, err := time.Parse(time.RFC3339, rendered["born"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, 2023, born.Year())
+	assert.Regexp(t, "^[A-Z]{3}-[0-9]{4}$", rendered["code"])
+}
+
+func TestRenderSchema_Seeded_NoConstructor(t *testing.T) {
+	compiled := getSchema([]byte(`type: string`))
+	wr := &SchemaRenderer{}
+	wr.SetSeed(1)
+	first := wr.RenderSchema(compiled)
+	wr.SetSeed(1)
+	assert.Equal(t, first, wr.RenderSchema(compiled))
+
+	// a renderer that was not created by a constructor still works without a seed.
+	assert.NotEmpty(t, (&SchemaRenderer{}).RenderSchema(compiled))
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// VariantStrategy determines which schema of a oneOf or anyOf is rendered by a SchemaRenderer.
+type VariantStrategy int
+
+const (
+	// FirstVariant renders the first schema of a oneOf or anyOf, this is the default.
+	FirstVariant VariantStrategy = iota
+
+	// RandomVariant renders a random schema of a oneOf or anyOf.
+	RandomVariant
+
+	// NamedVariant renders the schema of a oneOf or anyOf with the name set by SetVariantName. If there is
+	// no variant with that name, the first schema is rendered.
+	NamedVariant
+)
+
+// Variant is a value rendered by RenderSchemaVariants, the Name is the name of the variant rendered for the first
+// oneOf or anyOf found in the schema.
+type Variant struct {
+	Name  string
+	Value any
+}
+
+// SetVariantStrategy sets the strategy used to choose which schema of a oneOf or anyOf is rendered.
+func (wr *SchemaRenderer) SetVariantStrategy(strategy VariantStrategy) {
+	wr.variantStrategy = strategy
+}
+
+// SetVariantName will render the oneOf or anyOf variant with the given name, and sets the strategy to
+// NamedVariant. A variant is named by its discriminator mapping key, the name of the schema it references, or
+// its title, in that order.
+func (wr *SchemaRenderer) SetVariantName(name string) {
+	wr.variantStrategy = NamedVariant
+	wr.variantName = name
+}
+
+// RenderSchemaVariants renders a value for every variant of the oneOf and anyOf schemas found in the schema, so
+// every branch of a polymorphic schema can be tested. The first value renders the first variant of every oneOf and
+// anyOf, the second value renders the second variant, and so on, until every variant has been rendered at least once.
+// A schema without a oneOf or anyOf renders a single value, with an empty name.
+func (wr *SchemaRenderer) RenderSchemaVariants(schema *base.Schema) []*Variant {
+	var variants []*Variant
+	wr.enumerating = true
+	defer func() {
+		wr.enumerating = false
+	}()
+	for i := 0; i == 0 || i < wr.variantCount; i++ {
+		wr.variantIndex = i
+		wr.variantCount = 0
+		wr.variantLabel = ""
+		value := wr.RenderSchema(schema)
+		variants = append(variants, &Variant{Name: wr.variantLabel, Value: value})
+	}
+	return variants
+}
+
+// pickVariant chooses which of the variants of a oneOf or anyOf to render, returning its index and name.
+func (wr *SchemaRenderer) pickVariant(schema *base.Schema, variants []*base.SchemaProxy) (int, string) {
+	index := 0
+	switch {
+	case wr.enumerating:
+		if len(variants) > wr.variantCount {
+			wr.variantCount = len(variants)
+		}
+		index = wr.variantIndex % len(variants)
+	case wr.variantStrategy == RandomVariant:
+		index = wr.random().Intn(len(variants))
+	case wr.variantStrategy == NamedVariant:
+		for i := range variants {
+			if variantName(schema, variants[i], i) == wr.variantName {
+				index = i
+				break
+			}
+		}
+	}
+	name := variantName(schema, variants[index], index)
+	if wr.enumerating && wr.variantLabel == "" {
+		wr.variantLabel = name
+	}
+	return index, name
+}
+
+// renderVariant renders the chosen variant of a oneOf or anyOf. If the schema has a discriminator, the
+// discriminator property of the rendered value is set to the name of the variant.
+func (wr *SchemaRenderer) renderVariant(schema *base.Schema, variants []*base.SchemaProxy, key string, depth int) any {
+	index, name := wr.pickVariant(schema, variants)
+	variantMap := make(map[string]any)
+	wr.DiveIntoSchema(variants[index].Schema(), key, variantMap, depth+1)
+	value := variantMap[key]
+	if m, ok := value.(map[string]any); ok && schema.Discriminator != nil &&
+		schema.Discriminator.PropertyName != "" && variants[index].IsReference() {
+		m[schema.Discriminator.PropertyName] = name
+	}
+	return value
+}
+
+// variantName returns the name of a variant, which is the key of the discriminator mapping for the variant, the
+// name of the schema it references, its title, or its position.
+func variantName(schema *base.Schema, variant *base.SchemaProxy, index int) string {
+	if variant.IsReference() {
+		return discriminatorValue(schema.Discriminator, variant.GetReference())
+	}
+	if s := variant.Schema(); s != nil && s.Title != "" {
+		return s.Title
+	}
+	return strconv.Itoa(index)
+}
+
+// discriminatorValue returns the value of a discriminator property for a referenced schema. If the schema is not
+// in the mapping, then the name of the schema is used (as per the spec).
+func discriminatorValue(discriminator *base.Discriminator, ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if discriminator != nil {
+		keys := make([]string, 0, len(discriminator.Mapping))
+		for k := range discriminator.Mapping {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if v := discriminator.Mapping[k]; v == ref || v[strings.LastIndex(v, "/")+1:] == name {
+				return k
+			}
+		}
+	}
+	return name
+}
+
+// schemaReference returns the reference used to reach a schema, or the name of the schema if it was not reached by a
+// reference (such as a schema rendered directly from components), an empty string if there isn't one.
+func schemaReference(schema *base.Schema) string {
+	proxy := schema.ParentProxy
+	if proxy == nil {
+		return ""
+	}
+	if proxy.IsReference() {
+		return proxy.GetReference()
+	}
+	if low := proxy.GoLow(); low != nil && low.GetKeyNode() != nil {
+		return low.GetKeyNode().Value
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petShop = `openapi: 3.1.0
+info:
+  title: pets
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Lizard'
+      discriminator:
+        propertyName: petType
+        mapping:
+          kitty: '#/components/schemas/Cat'
+          doggo: Dog
+    Cat:
+      type: object
+      required: [petType, meow]
+      properties:
+        petType:
+          type: string
+        meow:
+          type: boolean
+    Dog:
+      type: object
+      required: [petType, bark]
+      properties:
+        petType:
+          type: string
+        bark:
+          type: string
+    Lizard:
+      type: object
+      required: [petType, scales]
+      properties:
+        petType:
+          type: string
+        scales:
+          type: integer
+    Animal:
+      type: object
+      required: [kind]
+      properties:
+        kind:
+          type: string
+      discriminator:
+        propertyName: kind
+        mapping:
+          big-cat: '#/components/schemas/Lion'
+    Lion:
+      allOf:
+        - $ref: '#/components/schemas/Animal'
+        - type: object
+          required: [roar]
+          properties:
+            roar:
+              type: boolean
+    Owner:
+      type: object
+      required: [pets, contact]
+      properties:
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+        contact:
+          anyOf:
+            - title: email
+              type: string
+              format: email
+            - title: phone
+              type: integer`
+
+func TestSchemaRenderer_Variants_First(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+	pet := wr.RenderSchema(schemas["Pet"].Schema()).(map[string]any)
+	assert.Equal(t, "kitty", pet["petType"])
+	assert.Contains(t, pet, "meow")
+}
+
+func TestSchemaRenderer_Variants_Named(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+
+	wr.SetVariantName("doggo")
+	pet := wr.RenderSchema(schemas["Pet"].Schema()).(map[string]any)
+	assert.Equal(t, "doggo", pet["petType"])
+	assert.Contains(t, pet, "bark")
+
+	// not in the mapping, so the schema name is used.
+	wr.SetVariantName("Lizard")
+	pet = wr.RenderSchema(schemas["Pet"].Schema()).(map[string]any)
+	assert.Equal(t, "Lizard", pet["petType"])
+	assert.Contains(t, pet, "scales")
+
+	// unknown names render the first variant.
+	wr.SetVariantName("Unicorn")
+	pet = wr.RenderSchema(schemas["Pet"].Schema()).(map[string]any)
+	assert.Equal(t, "kitty", pet["petType"])
+}
+
+func TestSchemaRenderer_Variants_Random(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+	wr.SetVariantStrategy(RandomVariant)
+
+	seen := make(map[any]bool)
+	for i := int64(0); i < 30; i++ {
+		wr.SetSeed(i)
+		pet := wr.RenderSchema(schemas["Pet"].Schema()).(map[string]any)
+		seen[pet["petType"]] = true
+	}
+	assert.Equal(t, map[any]bool{"kitty": true, "doggo": true, "Lizard": true}, seen)
+}
+
+func TestSchemaRenderer_RenderSchemaVariants(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+
+	variants := wr.RenderSchemaVariants(schemas["Pet"].Schema())
+	assert.Len(t, variants, 3)
+	assert.Equal(t, "kitty", variants[0].Name)
+	assert.Equal(t, "doggo", variants[1].Name)
+	assert.Equal(t, "Lizard", variants[2].Name)
+	for _, v := range variants {
+		assert.Equal(t, v.Name, v.Value.(map[string]any)["petType"])
+	}
+
+	// nested variants are all rendered, the name is from the first variant found.
+	variants = wr.RenderSchemaVariants(schemas["Owner"].Schema())
+	assert.Len(t, variants, 3)
+	contacts := make(map[string]bool)
+	for _, v := range variants {
+		owner := v.Value.(map[string]any)
+		contacts[valueType(owner["contact"])] = true
+	}
+	assert.Equal(t, map[string]bool{"string": true, "integer": true}, contacts)
+
+	// no variants, a single value.
+	variants = wr.RenderSchemaVariants(schemas["Cat"].Schema())
+	assert.Len(t, variants, 1)
+	assert.Empty(t, variants[0].Name)
+}
+
+func TestSchemaRenderer_Variants_InheritedDiscriminator(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+	wr := createSchemaRenderer()
+	lion := wr.RenderSchema(schemas["Lion"].Schema()).(map[string]any)
+	assert.Equal(t, "big-cat", lion["kind"])
+	assert.Contains(t, lion, "roar")
+}
+
+func TestVariantName(t *testing.T) {
+	parent := &highbase.Schema{}
+	assert.Equal(t, "email", variantName(parent, highbase.CreateSchemaProxy(&highbase.Schema{Title: "email"}), 0))
+	assert.Equal(t, "1", variantName(parent, highbase.CreateSchemaProxy(&highbase.Schema{}), 1))
+	assert.Equal(t, "Cat", discriminatorValue(nil, "#/components/schemas/Cat"))
+}
+
+func TestMockGenerator_GenerateMockVariants(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petShop))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+	mg := NewMockGenerator(JSON)
+
+	mocks, err := mg.GenerateMockVariants(schemas["Pet"].Schema())
+	assert.NoError(t, err)
+	assert.Len(t, mocks, 3)
+	for _, mock := range mocks {
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(mock.Mock, &m))
+		assert.Equal(t, mock.Name, m["petType"])
+	}
+
+	mg.SetVariantName("doggo")
+	mock, err := mg.GenerateMock(schemas["Pet"].Schema(), "")
+	assert.NoError(t, err)
+	assert.Contains(t, string(mock), `"petType":"doggo"`)
+
+	mg.SetVariantStrategy(FirstVariant)
+	mock, err = mg.GenerateMock(schemas["Pet"].Schema(), "")
+	assert.NoError(t, err)
+	assert.Contains(t, string(mock), `"petType":"kitty"`)
+}
+
+func TestMockGenerator_GenerateMockVariants_Examples(t *testing.T) {
+	mg := NewMockGenerator(JSON)
+
+	mocks, err := mg.GenerateMockVariants(createFakeMock(simpleFakeMockSchema, map[string]any{
+		"fries":  "chips",
+		"burger": "big mac",
+	}, nil))
+	assert.NoError(t, err)
+	assert.Len(t, mocks, 2)
+	assert.Equal(t, "burger", mocks[0].Name)
+	assert.Equal(t, "big mac", string(mocks[0].Mock))
+	assert.Equal(t, "fries", mocks[1].Name)
+
+	mocks, err = mg.GenerateMockVariants(createFakeMock(simpleFakeMockSchema, nil, "pizza"))
+	assert.NoError(t, err)
+	assert.Len(t, mocks, 1)
+	assert.Equal(t, "pizza", string(mocks[0].Mock))
+
+	_, err = mg.GenerateMockVariants(&struct{ Thing string }{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// xmlRootName is the name of the root element of an XML mock, when the schema has no XML name and is not a reference.
+const xmlRootName = "root"
+
+// xmlWriter renders values as XML, using the XML objects of the schemas the values were rendered from.
+type xmlWriter struct {
+	b      strings.Builder
+	pretty bool
+}
+
+// renderXML renders a value as an XML document. The name of the root element is the XML name of the schema, or the
+// name of the referenced schema, or 'root'.
+func renderXML(value any, schema *base.Schema, pretty bool) []byte {
+	w := &xmlWriter{pretty: pretty}
+	w.b.WriteString(xml.Header)
+	name := xmlRootName
+	if schema != nil && schema.ParentProxy != nil && schema.ParentProxy.IsReference() {
+		ref := schema.ParentProxy.GetReference()
+		name = ref[strings.LastIndex(ref, "/")+1:]
+	}
+	name = xmlName(name, schema)
+	if items, ok := value.([]any); ok {
+		// a document only has a single root element, so root arrays are always wrapped.
+		w.open(name, namespace(schema), 0)
+		w.items(name, schema, items, 1)
+		w.close(name, 0)
+	} else {
+		w.element(name, schema, value, 0)
+	}
+	return []byte(strings.TrimSuffix(w.b.String(), "\n"))
+}
+
+// element writes a value as an element, with attributes and child elements for objects.
+func (w *xmlWriter) element(name string, schema *base.Schema, value any, depth int) {
+	attributes := namespace(schema)
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var children []string
+		for _, k := range keys {
+			property := propertySchema(schema, k)
+			if property != nil && property.XML != nil && property.XML.Attribute && isPrimitive(v[k]) {
+				attributes = append(attributes, xmlName(k, property)+"=\""+escapeXML(xmlText(v[k]))+"\"")
+				continue
+			}
+			children = append(children, k)
+		}
+		if len(children) == 0 {
+			w.empty(name, attributes, depth)
+			return
+		}
+		w.open(name, attributes, depth)
+		for _, k := range children {
+			property := propertySchema(schema, k)
+			if items, ok := v[k].([]any); ok {
+				w.array(xmlName(k, property), property, items, depth+1)
+				continue
+			}
+			w.element(xmlName(k, property), property, v[k], depth+1)
+		}
+		w.close(name, depth)
+	case []any:
+		w.array(name, schema, v, depth)
+	case nil:
+		w.empty(name, attributes, depth)
+	default:
+		w.indent(depth)
+		w.b.WriteString("<" + strings.Join(append([]string{name}, attributes...), " ") + ">")
+		w.b.WriteString(escapeXML(xmlText(v)))
+		w.b.WriteString("</" + name + ">")
+		w.newline()
+	}
+}
+
+// array writes the items of an array, inside a wrapping element if the schema is wrapped.
+func (w *xmlWriter) array(name string, schema *base.Schema, items []any, depth int) {
+	if schema != nil && schema.XML != nil && schema.XML.Wrapped {
+		w.open(name, namespace(schema), depth)
+		w.items(name, schema, items, depth+1)
+		w.close(name, depth)
+		return
+	}
+	w.items(name, schema, items, depth)
+}
+
+// items writes each item of an array as an element, named by the XML name of the items schema, or the array name.
+func (w *xmlWriter) items(name string, schema *base.Schema, items []any, depth int) {
+	var itemSchema *base.Schema
+	if schema != nil && schema.Items != nil && schema.Items.IsA() && schema.Items.A != nil {
+		itemSchema = schema.Items.A.Schema()
+	}
+	itemName := name
+	if itemSchema != nil && itemSchema.XML != nil && itemSchema.XML.Name != "" {
+		itemName = xmlName(itemSchema.XML.Name, itemSchema)
+	}
+	for _, item := range items {
+		w.element(itemName, itemSchema, item, depth)
+	}
+}
+
+func (w *xmlWriter) open(name string, attributes []string, depth int) {
+	w.indent(depth)
+	w.b.WriteString("<" + strings.Join(append([]string{name}, attributes...), " ") + ">")
+	w.newline()
+}
+
+func (w *xmlWriter) close(name string, depth int) {
+	w.indent(depth)
+	w.b.WriteString("</" + name + ">")
+	w.newline()
+}
+
+func (w *xmlWriter) empty(name string, attributes []string, depth int) {
+	w.indent(depth)
+	w.b.WriteString("<" + strings.Join(append([]string{name}, attributes...), " ") + "/>")
+	w.newline()
+}
+
+func (w *xmlWriter) indent(depth int) {
+	if w.pretty {
+		w.b.WriteString(strings.Repeat("  ", depth))
+	}
+}
+
+func (w *xmlWriter) newline() {
+	if w.pretty {
+		w.b.WriteString("\n")
+	}
+}
+
+// xmlName returns the (prefixed) name of an element or attribute, the XML name of the schema replaces the name.
+func xmlName(name string, schema *base.Schema) string {
+	if schema == nil || schema.XML == nil {
+		return name
+	}
+	if schema.XML.Name != "" {
+		name = schema.XML.Name
+	}
+	if schema.XML.Prefix != "" {
+		name = schema.XML.Prefix + ":" + name
+	}
+	return name
+}
+
+// namespace returns the namespace declaration of a schema, as an attribute.
+func namespace(schema *base.Schema) []string {
+	if schema == nil || schema.XML == nil || schema.XML.Namespace == "" {
+		return nil
+	}
+	if schema.XML.Prefix != "" {
+		return []string{"xmlns:" + schema.XML.Prefix + "=\"" + escapeXML(schema.XML.Namespace) + "\""}
+	}
+	return []string{"xmlns=\"" + escapeXML(schema.XML.Namespace) + "\""}
+}
+
+// propertySchema returns the schema of a property, looking in the properties of the schema, then in allOf, oneOf
+// and anyOf schemas, then additional properties. nil is returned if the property has no schema.
+func propertySchema(schema *base.Schema, name string) *base.Schema {
+	if schema == nil {
+		return nil
+	}
+	if proxy := schema.Properties[name]; proxy != nil {
+		return proxy.Schema()
+	}
+	for _, group := range [][]*base.SchemaProxy{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, proxy := range group {
+			if proxy == nil {
+				continue
+			}
+			if property := propertySchema(proxy.Schema(), name); property != nil {
+				return property
+			}
+		}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() && schema.AdditionalProperties.A != nil {
+		return schema.AdditionalProperties.A.Schema()
+	}
+	return nil
+}
+
+func isPrimitive(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any, nil:
+		return false
+	}
+	return true
+}
+
+// xmlText returns the text of a primitive value.
+func xmlText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
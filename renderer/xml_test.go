@@ -0,0 +1,135 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package renderer
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petStore = `openapi: 3.1.0
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/xml:
+              schema:
+                $ref: '#/components/schemas/Pet'
+            application/x-www-form-urlencoded:
+              schema:
+                $ref: '#/components/schemas/Pet'
+              encoding:
+                tags:
+                  style: pipeDelimited
+                  explode: false
+                owner:
+                  contentType: application/json
+            multipart/form-data:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+                    const: fluffy
+                  photo:
+                    type: string
+                    format: binary
+                    const: abc
+                  owner:
+                    $ref: '#/components/schemas/Owner'
+                  tags:
+                    type: array
+                    items:
+                      type: string
+                    const: [a, b]
+              encoding:
+                owner:
+                  contentType: application/xml
+                  headers:
+                    X-Rate-Limit:
+                      schema:
+                        type: integer
+                        const: 10
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name, tags, owner]
+      xml:
+        name: pet
+        namespace: https://pb33f.io/pets
+        prefix: pb
+      properties:
+        id:
+          type: integer
+          const: 1
+          xml:
+            attribute: true
+        name:
+          type: string
+          const: fluffy & friends
+        tags:
+          type: array
+          const: [cute, small]
+          xml:
+            name: tags
+            wrapped: true
+          items:
+            type: string
+            xml:
+              name: tag
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          const: dave`
+
+func TestMockGenerator_GenerateXMLMock(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(petStore))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	content := model.Model.Paths.PathItems["/pets"].Get.Responses.Codes["200"].Content
+	mg := NewMockGenerator(XML)
+	mock, err := mg.GenerateMock(content["application/xml"], "")
+	assert.NoError(t, err)
+	assert.Equal(t, xml.Header+`<pb:pet xmlns:pb="https://pb33f.io/pets" id="1"><name>fluffy &amp; friends</name>`+
+		`<owner><name>dave</name></owner><tags><tag>cute</tag><tag>small</tag></tags></pb:pet>`, string(mock))
+
+	mg.SetPretty()
+	mock, _ = mg.GenerateMock(content["application/xml"], "")
+	assert.Equal(t, xml.Header+`<pb:pet xmlns:pb="https://pb33f.io/pets" id="1">
+  <name>fluffy &amp; friends</name>
+  <owner>
+    <name>dave</name>
+  </owner>
+  <tags>
+    <tag>cute</tag>
+    <tag>small</tag>
+  </tags>
+</pb:pet>`, string(mock))
+}
+
+func TestRenderXML(t *testing.T) {
+	// no schema, so the root and items are named 'root'.
+	assert.Equal(t, xml.Header+"<root><root>1</root><root>a</root></root>",
+		string(renderXML([]any{1, "a"}, nil, false)))
+	assert.Equal(t, xml.Header+"<root><a>1.5</a><b/><c>1</c><c>2</c></root>",
+		string(renderXML(map[string]any{"a": 1.5, "b": nil, "c": []any{1, 2}}, nil, false)))
+	assert.Equal(t, xml.Header+"<root>&lt;tag&gt;</root>", string(renderXML("<tag>", nil, false)))
+}
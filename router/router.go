@@ -0,0 +1,240 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package router matches HTTP requests to the paths and operations of an OpenAPI 3 or Swagger 2 document.
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+var (
+	// ErrPathNotFound is returned when no path of the document matches a request.
+	ErrPathNotFound = errors.New("path not found")
+
+	// ErrMethodNotAllowed is returned when a path matches a request, but the path has no operation for the method.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+// Route is the path and operation of a document that a request matches.
+type Route struct {
+	Template        string            // the path template that matched, as written in the document.
+	Method          string            // the method of the operation, in lower case (like PathItem.GetOperations).
+	PathParams      map[string]string // the unescaped values of the path parameters, by name.
+	Server          string            // the URL of the server (or the base path) that matched.
+	ServerVariables map[string]string // the values of the server variables, defaults unless they are in the path.
+
+	// the path item and operation of an OpenAPI 3 document.
+	PathItem  *v3.PathItem
+	Operation *v3.Operation
+
+	// the path item and operation of a Swagger 2 document.
+	SwaggerPathItem  *v2.PathItem
+	SwaggerOperation *v2.Operation
+}
+
+// route is a path of a document, with the operations of the path item.
+type route struct {
+	*pathTemplate
+	pathItem        *v3.PathItem
+	swaggerPathItem *v2.PathItem
+	operations      map[string]*operation
+}
+
+// operation is an operation of a path item, with the servers it is served from.
+type operation struct {
+	operation        *v3.Operation
+	swaggerOperation *v2.Operation
+	servers          map[*serverPrefix]bool
+}
+
+// Router matches HTTP requests to the paths and operations of a document.
+type Router struct {
+	routes []*route
+	paths  []*prefixPath // the paths of every server, the longest first.
+}
+
+// NewRouter creates a Router for the paths of an OpenAPI 3 document. Operations are served from their own servers,
+// then the servers of their path item, then the servers of the document, or '/' if there are none. Only the paths of
+// the URLs of a server (see servers.Enumerate) are matched, so requests are matched regardless of the host they are
+// sent to.
+//
+// The router is always created. Errors are returned for paths that cannot be told apart, such as /burgers/{id} and
+// /burgers/{burgerId}, only the first of these paths (by name) is matched.
+func NewRouter(document *v3.Document) (*Router, []error) {
+	r := &Router{}
+	if document == nil || document.Paths == nil {
+		return r, nil
+	}
+	known := make(map[string]*serverPrefix)
+	// prefixes returns the prefixes of the first list of servers that is not empty.
+	prefixes := func(s ...[]*v3.Server) map[*serverPrefix]bool {
+		for _, list := range s {
+			if len(list) == 0 {
+				continue
+			}
+			m := make(map[*serverPrefix]bool)
+			for _, server := range list {
+				if server == nil {
+					continue
+				}
+				sp, ok := known[server.URL]
+				if !ok {
+					sp = newServerPrefix(server)
+					known[server.URL] = sp
+				}
+				m[sp] = true
+			}
+			return m
+		}
+		return nil
+	}
+	lines := make(map[string]int)
+	if low := document.Paths.GoLow(); low != nil {
+		for k := range low.PathItems {
+			if k.KeyNode != nil {
+				lines[k.Value] = k.KeyNode.Line
+			}
+		}
+	}
+	root := &v3.Server{URL: "/"}
+	for template, pathItem := range document.Paths.PathItems {
+		if pathItem == nil {
+			continue
+		}
+		rt := &route{pathTemplate: newPathTemplate(template, lines[template]), pathItem: pathItem,
+			operations: make(map[string]*operation)}
+		for method, op := range pathItem.GetOperations() {
+			rt.operations[method] = &operation{operation: op,
+				servers: prefixes(op.Servers, pathItem.Servers, document.Servers, []*v3.Server{root})}
+		}
+		r.routes = append(r.routes, rt)
+	}
+	for _, sp := range known {
+		r.paths = append(r.paths, sp.paths...)
+	}
+	return r, r.sort()
+}
+
+// NewSwaggerRouter creates a Router for the paths of a Swagger 2 document, every path is served from the base path
+// of the document. Errors are returned for paths that cannot be told apart, as with NewRouter.
+func NewSwaggerRouter(swagger *v2.Swagger) (*Router, []error) {
+	r := &Router{}
+	if swagger == nil || swagger.Paths == nil {
+		return r, nil
+	}
+	basePath := swagger.BasePath
+	if basePath == "" {
+		basePath = "/"
+	}
+	sp := newServerPrefix(&v3.Server{URL: basePath})
+	r.paths = sp.paths
+	lines := make(map[string]int)
+	if low := swagger.Paths.GoLow(); low != nil {
+		for k := range low.PathItems {
+			if k.KeyNode != nil {
+				lines[k.Value] = k.KeyNode.Line
+			}
+		}
+	}
+	for template, pathItem := range swagger.Paths.PathItems {
+		if pathItem == nil {
+			continue
+		}
+		rt := &route{pathTemplate: newPathTemplate(template, lines[template]), swaggerPathItem: pathItem,
+			operations: make(map[string]*operation)}
+		for method, op := range pathItem.GetOperations() {
+			rt.operations[method] = &operation{swaggerOperation: op, servers: map[*serverPrefix]bool{sp: true}}
+		}
+		r.routes = append(r.routes, rt)
+	}
+	return r, r.sort()
+}
+
+// sort orders the routes by precedence and the server paths by their length, so the most specific are matched
+// first. An error is returned for every route that has the same shape as a route before it.
+func (r *Router) sort() []error {
+	sort.Slice(r.routes, func(i, j int) bool { return r.routes[i].precedes(r.routes[j].pathTemplate) })
+	sort.SliceStable(r.paths, func(i, j int) bool {
+		if len(r.paths[i].path) != len(r.paths[j].path) {
+			return len(r.paths[i].path) > len(r.paths[j].path)
+		}
+		return r.paths[i].server.url < r.paths[j].server.url
+	})
+	var errs []error
+	shapes := make(map[string]*route)
+	for _, rt := range r.routes {
+		shape := rt.shape()
+		if other, ok := shapes[shape]; ok {
+			errs = append(errs, fmt.Errorf("path '%s' (line %d) is ambiguous with path '%s' (line %d), "+
+				"only '%s' is matched", rt.template, rt.line, other.template, other.line, other.template))
+			continue
+		}
+		shapes[shape] = rt
+	}
+	return errs
+}
+
+// FindRoute finds the path and operation of the document that a request matches, and extracts the values of the
+// path parameters. Paths are matched before methods: if the most specific path that matches the request has no
+// operation for the method, the route of the path item (without an operation) is returned with ErrMethodNotAllowed.
+// ErrPathNotFound is returned if no path matches the request.
+func (r *Router) FindRoute(request *http.Request) (*Route, error) {
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	method := strings.ToLower(request.Method)
+	for _, pp := range r.paths {
+		rest, variables, ok := pp.match(path)
+		if !ok {
+			continue
+		}
+		sp := pp.server
+		for _, rt := range r.routes {
+			params, ok := rt.match(rest)
+			if !ok || !rt.served(sp) {
+				continue
+			}
+			found := &Route{
+				Template:        rt.template,
+				Method:          method,
+				PathParams:      params,
+				Server:          sp.url,
+				ServerVariables: variables,
+				PathItem:        rt.pathItem,
+				SwaggerPathItem: rt.swaggerPathItem,
+			}
+			op := rt.operations[method]
+			if op == nil || !op.servers[sp] {
+				return found, fmt.Errorf("%w: %s is not defined for path '%s'", ErrMethodNotAllowed,
+					request.Method, rt.template)
+			}
+			found.Operation = op.operation
+			found.SwaggerOperation = op.swaggerOperation
+			return found, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no path matches '%s'", ErrPathNotFound, path)
+}
+
+// served returns true if any operation of the route is served from a server. A path item without operations is
+// served from every server.
+func (rt *route) served(sp *serverPrefix) bool {
+	if len(rt.operations) == 0 {
+		return true
+	}
+	for _, op := range rt.operations {
+		if op.servers[sp] {
+			return true
+		}
+	}
+	return false
+}
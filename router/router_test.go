@@ -0,0 +1,205 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package router
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var routerSpec = `openapi: 3.1.0
+info:
+  title: Burgers
+  version: 1.0.0
+servers:
+  - url: https://{region}.pb33f.io/{version}/api
+    variables:
+      region:
+        default: eu
+      version:
+        default: v1
+        enum: [v1, v2]
+  - url: http://localhost:8080
+paths:
+  /burgers/{burgerId}:
+    get:
+      operationId: getBurger
+    delete:
+      operationId: deleteBurger
+  /burgers/mine:
+    get:
+      operationId: getMyBurgers
+  /burgers:
+    post:
+      operationId: createBurger
+  /{entity}/latest:
+    get:
+      operationId: getLatest
+  /files/{name}.{ext}:
+    get:
+      operationId: getFile
+  /admin/reports:
+    get:
+      operationId: getReports
+      servers:
+        - url: https://admin.pb33f.io/internal
+  /burgers/{id}:
+    put:
+      operationId: replaceBurger`
+
+func TestRouter_FindRoute(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(routerSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	r, _ := NewRouter(&model.Model)
+
+	route, err := r.FindRoute(httptest.NewRequest("GET", "/v1/api/burgers/big%20mac", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "/burgers/{burgerId}", route.Template)
+	assert.Equal(t, "get", route.Method)
+	assert.Equal(t, "getBurger", route.Operation.OperationId)
+	assert.Equal(t, map[string]string{"burgerId": "big mac"}, route.PathParams)
+	assert.Equal(t, "https://{region}.pb33f.io/{version}/api", route.Server)
+	assert.Equal(t, map[string]string{"region": "eu", "version": "v1"}, route.ServerVariables)
+
+	route, err = r.FindRoute(httptest.NewRequest("GET", "/v2/api/burgers/mine", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "getMyBurgers", route.Operation.OperationId)
+	assert.Equal(t, "v2", route.ServerVariables["version"])
+
+	// the second server is served from the root.
+	route, err = r.FindRoute(httptest.NewRequest("POST", "http://localhost:8080/burgers", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "createBurger", route.Operation.OperationId)
+	assert.Equal(t, "http://localhost:8080", route.Server)
+
+	// concrete segments are matched before templated segments.
+	route, err = r.FindRoute(httptest.NewRequest("GET", "/burgers/latest", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "getBurger", route.Operation.OperationId)
+	route, err = r.FindRoute(httptest.NewRequest("GET", "/fries/latest", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"entity": "fries"}, route.PathParams)
+
+	route, err = r.FindRoute(httptest.NewRequest("GET", "/files/menu.tar.gz", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "menu.tar", "ext": "gz"}, route.PathParams)
+
+	// v3 is not a value of the version variable, so the path is not stripped.
+	_, err = r.FindRoute(httptest.NewRequest("GET", "/v3/api/burgers/mine", nil))
+	assert.True(t, errors.Is(err, ErrPathNotFound))
+	assert.Equal(t, "path not found: no path matches '/v3/api/burgers/mine'", err.Error())
+
+	_, err = r.FindRoute(httptest.NewRequest("GET", "/burgers/mine/extra", nil))
+	assert.True(t, errors.Is(err, ErrPathNotFound))
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(routerSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	r, _ := NewRouter(&model.Model)
+	route, err := r.FindRoute(httptest.NewRequest("PATCH", "/burgers/1", nil))
+	assert.True(t, errors.Is(err, ErrMethodNotAllowed))
+	assert.Equal(t, "method not allowed: PATCH is not defined for path '/burgers/{burgerId}'", err.Error())
+	assert.Nil(t, route.Operation)
+	assert.Len(t, route.PathItem.GetOperations(), 2)
+}
+
+func TestRouter_OperationServers(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(routerSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	r, _ := NewRouter(&model.Model)
+	route, err := r.FindRoute(httptest.NewRequest("GET", "/internal/admin/reports", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "getReports", route.Operation.OperationId)
+	assert.Equal(t, "https://admin.pb33f.io/internal", route.Server)
+
+	// the operation is not served from the document servers.
+	_, err = r.FindRoute(httptest.NewRequest("GET", "/admin/reports", nil))
+	assert.True(t, errors.Is(err, ErrPathNotFound))
+}
+
+func TestRouter_Ambiguous(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(routerSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	r, errs := NewRouter(&model.Model)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "path '/burgers/{id}' (line 37) is ambiguous with path '/burgers/{burgerId}' (line 15), "+
+		"only '/burgers/{burgerId}' is matched", errs[0].Error())
+
+	_, err = r.FindRoute(httptest.NewRequest("PUT", "/burgers/1", nil))
+	assert.True(t, errors.Is(err, ErrMethodNotAllowed))
+}
+
+func TestNewRouter_NoServers(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.0.3
+info:
+  title: Burgers
+  version: 1.0.0
+paths:
+  /:
+    get:
+      operationId: root`))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	r, errs := NewRouter(&model.Model)
+	assert.Empty(t, errs)
+	route, err := r.FindRoute(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "root", route.Operation.OperationId)
+	assert.Equal(t, "/", route.Server)
+
+	r, errs = NewRouter(nil)
+	assert.Empty(t, errs)
+	_, err = r.FindRoute(httptest.NewRequest("GET", "/", nil))
+	assert.True(t, errors.Is(err, ErrPathNotFound))
+}
+
+func TestNewSwaggerRouter(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`swagger: "2.0"
+info:
+  title: Burgers
+  version: 1.0.0
+basePath: /api/v1
+paths:
+  /burgers/{burgerId}:
+    get:
+      operationId: getBurger
+  /burgers/mine:
+    get:
+      operationId: getMyBurgers`))
+	require.NoError(t, err)
+	model, errs := doc.BuildV2Model()
+	require.Empty(t, errs)
+	r, rerrs := NewSwaggerRouter(&model.Model)
+	assert.Empty(t, rerrs)
+
+	route, err := r.FindRoute(httptest.NewRequest("GET", "/api/v1/burgers/42", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "getBurger", route.SwaggerOperation.OperationId)
+	assert.Equal(t, map[string]string{"burgerId": "42"}, route.PathParams)
+	assert.Nil(t, route.Operation)
+
+	route, err = r.FindRoute(httptest.NewRequest("GET", "/api/v1/burgers/mine", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "getMyBurgers", route.SwaggerOperation.OperationId)
+
+	_, err = r.FindRoute(httptest.NewRequest("GET", "/burgers/mine", nil))
+	assert.True(t, errors.Is(err, ErrPathNotFound))
+	_, err = r.FindRoute(httptest.NewRequest("POST", "/api/v1/burgers/mine", nil))
+	assert.True(t, errors.Is(err, ErrMethodNotAllowed))
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package router
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/servers"
+)
+
+// templateParam matches a parameter (or server variable) of a template.
+var templateParam = regexp.MustCompile(`{([^{}/]+)}`)
+
+// kinds of path template segments, in order of precedence.
+const (
+	concreteSegment = iota // a segment without parameters, like 'burgers'.
+	mixedSegment           // a segment with parameters and literal text, like '{name}.{ext}'.
+	paramSegment           // a segment that is a single parameter, like '{burgerId}'.
+)
+
+// segment is a single segment of a path template.
+type segment struct {
+	kind    int
+	literal string         // the text of a concrete segment, or the literal text of a mixed segment.
+	pattern *regexp.Regexp // matches a mixed segment.
+	params  []string
+}
+
+// pathTemplate is a path from a document, split into segments.
+type pathTemplate struct {
+	template string
+	line     int
+	segments []segment
+}
+
+func newPathTemplate(template string, line int) *pathTemplate {
+	pt := &pathTemplate{template: template, line: line}
+	for _, s := range strings.Split(strings.TrimPrefix(template, "/"), "/") {
+		matches := templateParam.FindAllStringSubmatchIndex(s, -1)
+		switch {
+		case len(matches) == 0:
+			pt.segments = append(pt.segments, segment{kind: concreteSegment, literal: s})
+		case len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s):
+			pt.segments = append(pt.segments, segment{kind: paramSegment, params: []string{s[1 : len(s)-1]}})
+		default:
+			seg := segment{kind: mixedSegment, literal: templateParam.ReplaceAllString(s, "")}
+			var b strings.Builder
+			last := 0
+			for _, m := range matches {
+				b.WriteString(regexp.QuoteMeta(s[last:m[0]]))
+				b.WriteString("(.+)")
+				seg.params = append(seg.params, s[m[2]:m[3]])
+				last = m[1]
+			}
+			b.WriteString(regexp.QuoteMeta(s[last:]))
+			seg.pattern = regexp.MustCompile("^" + b.String() + "$")
+			pt.segments = append(pt.segments, seg)
+		}
+	}
+	return pt
+}
+
+// match matches an escaped path against the template, returning the (unescaped) values of the path parameters.
+func (pt *pathTemplate) match(path string) (map[string]string, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) != len(pt.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, s := range pt.segments {
+		switch s.kind {
+		case concreteSegment:
+			if unescape(parts[i]) != s.literal {
+				return nil, false
+			}
+		case paramSegment:
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[s.params[0]] = unescape(parts[i])
+		default:
+			values := s.pattern.FindStringSubmatch(parts[i])
+			if values == nil {
+				return nil, false
+			}
+			for j, name := range s.params {
+				params[name] = unescape(values[j+1])
+			}
+		}
+	}
+	return params, true
+}
+
+// shape returns the template with every parameter name removed, templates with the same shape match the same paths.
+func (pt *pathTemplate) shape() string {
+	return templateParam.ReplaceAllString(pt.template, "{}")
+}
+
+// precedes returns true if the template is matched before another template. Segments are compared from the left,
+// concrete segments are matched before mixed segments, which are matched before parameter segments.
+func (pt *pathTemplate) precedes(other *pathTemplate) bool {
+	if len(pt.segments) != len(other.segments) {
+		return len(pt.segments) < len(other.segments)
+	}
+	for i, s := range pt.segments {
+		o := other.segments[i]
+		if s.kind != o.kind {
+			return s.kind < o.kind
+		}
+		if s.kind == mixedSegment && len(s.literal) != len(o.literal) {
+			return len(s.literal) > len(o.literal)
+		}
+	}
+	return pt.template < other.template
+}
+
+// serverPrefix is a server of a document, with the paths of every concrete URL of the server.
+type serverPrefix struct {
+	url   string
+	paths []*prefixPath
+}
+
+// prefixPath is the path of a concrete URL of a server, with the values of the server variables.
+type prefixPath struct {
+	server    *serverPrefix
+	path      string
+	variables map[string]string
+}
+
+// newServerPrefix creates the prefix of a server, with a path for every URL enumerated by servers.Enumerate: server
+// variables in the path match one of the values of their enum, or their default. Variables that are not in the path
+// have their default value. A server with an undefined variable is matched by the path of its URL as written.
+func newServerPrefix(server *v3.Server) *serverPrefix {
+	sp := &serverPrefix{url: server.URL}
+	defaults := make(map[string]string)
+	for name, variable := range server.Variables {
+		if variable != nil {
+			defaults[name] = variable.Default
+		}
+	}
+	inPath := servers.Variables(&v3.Server{URL: serverPath(server.URL)})
+	combinations, err := servers.EnumerateValues(server)
+	if err != nil {
+		sp.paths = []*prefixPath{{server: sp, path: serverPath(server.URL), variables: defaults}}
+		return sp
+	}
+	seen := make(map[string]bool)
+	for _, values := range combinations {
+		u, err := servers.Expand(server, values)
+		if err != nil || seen[serverPath(u)] {
+			continue
+		}
+		seen[serverPath(u)] = true
+		variables := make(map[string]string, len(defaults))
+		for name, value := range defaults {
+			variables[name] = value
+		}
+		for _, name := range inPath {
+			variables[name] = values[name]
+		}
+		sp.paths = append(sp.paths, &prefixPath{server: sp, path: serverPath(u), variables: variables})
+	}
+	return sp
+}
+
+// match strips the path from an escaped request path, returning the rest of the path and the values of the server
+// variables.
+func (pp *prefixPath) match(path string) (string, map[string]string, bool) {
+	if !strings.HasPrefix(path, pp.path) || (len(path) > len(pp.path) && path[len(pp.path)] != '/') {
+		return "", nil, false
+	}
+	variables := make(map[string]string, len(pp.variables))
+	for name, value := range pp.variables {
+		variables[name] = value
+	}
+	rest := path[len(pp.path):]
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, variables, true
+}
+
+// serverPath returns the path of a server URL, without a trailing slash. Server variables are kept.
+func serverPath(serverURL string) string {
+	path := serverURL
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+		if j := strings.Index(path, "/"); j >= 0 {
+			path = path[j:]
+		} else {
+			path = ""
+		}
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+func unescape(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package router
+
+import (
+	"sort"
+	"testing"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathTemplate_Match(t *testing.T) {
+	pt := newPathTemplate("/burgers/{burgerId}/reviews/{reviewId}", 0)
+	params, ok := pt.match("/burgers/1/reviews/a%2Fb")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"burgerId": "1", "reviewId": "a/b"}, params)
+
+	_, ok = pt.match("/burgers/1/reviews/")
+	assert.False(t, ok)
+	_, ok = pt.match("/burgers/1/comments/2")
+	assert.False(t, ok)
+
+	pt = newPathTemplate("/reports/report-{year}.{format}", 0)
+	params, ok = pt.match("/reports/report-2023.csv")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"year": "2023", "format": "csv"}, params)
+	_, ok = pt.match("/reports/2023.csv")
+	assert.False(t, ok)
+
+	params, ok = newPathTemplate("/", 0).match("/")
+	assert.True(t, ok)
+	assert.Empty(t, params)
+}
+
+func TestPathTemplate_Precedes(t *testing.T) {
+	templates := []*pathTemplate{
+		newPathTemplate("/{entity}/{id}", 0),
+		newPathTemplate("/burgers/{id}", 0),
+		newPathTemplate("/burgers/{id}.{format}", 0),
+		newPathTemplate("/burgers/{id}.json", 0),
+		newPathTemplate("/burgers/mine", 0),
+		newPathTemplate("/{entity}/mine", 0),
+		newPathTemplate("/burgers", 0),
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].precedes(templates[j]) })
+	var order []string
+	for _, pt := range templates {
+		order = append(order, pt.template)
+	}
+	assert.Equal(t, []string{"/burgers", "/burgers/mine", "/burgers/{id}.json", "/burgers/{id}.{format}",
+		"/burgers/{id}", "/{entity}/mine", "/{entity}/{id}"}, order)
+
+	assert.Equal(t, "/burgers/{}", newPathTemplate("/burgers/{burgerId}", 0).shape())
+}
+
+func TestServerPrefix_Match(t *testing.T) {
+	sp := newServerPrefix(&v3.Server{URL: "https://{region}.pb33f.io:{port}/api/{version}/", Variables: map[string]*v3.ServerVariable{
+		"region":  {Default: "eu", Enum: []string{"eu", "us"}},
+		"port":    {Default: "443"},
+		"version": {Default: "v1", Enum: []string{"v1", "v2"}},
+	}})
+	assert.Len(t, sp.paths, 2)
+	rest, variables, ok := sp.paths[1].match("/api/v2/burgers")
+	assert.True(t, ok)
+	assert.Equal(t, "/burgers", rest)
+	assert.Equal(t, map[string]string{"region": "eu", "port": "443", "version": "v2"}, variables)
+
+	rest, _, ok = sp.paths[1].match("/api/v2")
+	assert.True(t, ok)
+	assert.Equal(t, "/", rest)
+
+	_, _, ok = sp.paths[1].match("/api/v2s/burgers")
+	assert.False(t, ok)
+
+	// only the default of a variable without an enum is matched.
+	sp = newServerPrefix(&v3.Server{URL: "/{version}", Variables: map[string]*v3.ServerVariable{"version": {Default: "v1"}}})
+	assert.Len(t, sp.paths, 1)
+	_, _, ok = sp.paths[0].match("/v2/burgers")
+	assert.False(t, ok)
+
+	assert.Equal(t, "", serverPath("https://pb33f.io"))
+	assert.Equal(t, "/v1", serverPath("v1/"))
+	assert.Equal(t, "/api", serverPath("http://localhost:8080/api?debug=true"))
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthorized is wrapped by the errors of Check, when a request does not satisfy any requirement.
+var ErrUnauthorized = errors.New("request does not satisfy any security requirement")
+
+// Check checks a request carries the credentials of at least one of the requirements of an operation, and returns
+// the first requirement that is satisfied. A request always satisfies an operation without requirements, and the
+// returned requirement is nil.
+//
+// Only the presence of credentials (and the form of the Authorization header) is checked, keys, tokens and scopes
+// are not verified.
+func Check(requirements []*Requirement, request *http.Request) (*Requirement, error) {
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+	var reasons []string
+	for _, requirement := range requirements {
+		var missing []string
+		for _, credential := range requirement.Credentials {
+			if err := credential.Check(request); err != nil {
+				missing = append(missing, err.Error())
+			}
+		}
+		if len(missing) == 0 {
+			return requirement, nil
+		}
+		reasons = append(reasons, strings.Join(missing, " and "))
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnauthorized, strings.Join(reasons, "; or "))
+}
+
+// Check returns an error if a request does not carry a credential.
+func (c *Credential) Check(request *http.Request) error {
+	switch c.Type {
+	case TypeAPIKey:
+		switch c.In {
+		case InHeader:
+			if request.Header.Get(c.Name) == "" {
+				return fmt.Errorf("%s: header '%s' is not set", c.Scheme, c.Name)
+			}
+		case InQuery:
+			if request.URL.Query().Get(c.Name) == "" {
+				return fmt.Errorf("%s: query parameter '%s' is not set", c.Scheme, c.Name)
+			}
+		case InCookie:
+			if cookie, err := request.Cookie(c.Name); err != nil || cookie.Value == "" {
+				return fmt.Errorf("%s: cookie '%s' is not set", c.Scheme, c.Name)
+			}
+		default:
+			return fmt.Errorf("%s: API keys cannot be in '%s'", c.Scheme, c.In)
+		}
+		return nil
+	case TypeHTTP:
+		return c.checkAuthorization(request, c.HTTPScheme)
+	case TypeOAuth2, TypeOpenIDConnect:
+		return c.checkAuthorization(request, "bearer")
+	case TypeMutualTLS:
+		if request.TLS == nil || len(request.TLS.PeerCertificates) == 0 {
+			return fmt.Errorf("%s: no client certificate was presented", c.Scheme)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: unknown security scheme type '%s'", c.Scheme, c.Type)
+}
+
+// checkAuthorization checks the Authorization header of a request uses a scheme, and has credentials.
+func (c *Credential) checkAuthorization(request *http.Request, scheme string) error {
+	authorization := request.Header.Get("Authorization")
+	if authorization == "" {
+		return fmt.Errorf("%s: header 'Authorization' is not set", c.Scheme)
+	}
+	name, credentials, _ := strings.Cut(authorization, " ")
+	credentials = strings.TrimSpace(credentials)
+	if !strings.EqualFold(name, scheme) || credentials == "" {
+		return fmt.Errorf("%s: header 'Authorization' does not have %s credentials", c.Scheme, scheme)
+	}
+	if strings.EqualFold(scheme, "basic") {
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil || !strings.Contains(string(decoded), ":") {
+			return fmt.Errorf("%s: header 'Authorization' does not have valid basic credentials", c.Scheme)
+		}
+	}
+	return nil
+}
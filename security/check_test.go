@@ -0,0 +1,118 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredential_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential *Credential
+		setup      func(r *http.Request)
+		want       string
+	}{
+		{"header key", &Credential{Scheme: "key", Type: TypeAPIKey, In: InHeader, Name: "X-API-Key"},
+			func(r *http.Request) { r.Header.Set("X-API-Key", "secret") }, ""},
+		{"missing header key", &Credential{Scheme: "key", Type: TypeAPIKey, In: InHeader, Name: "X-API-Key"},
+			nil, "key: header 'X-API-Key' is not set"},
+		{"query key", &Credential{Scheme: "key", Type: TypeAPIKey, In: InQuery, Name: "key"},
+			func(r *http.Request) { r.URL.RawQuery = "key=secret" }, ""},
+		{"missing query key", &Credential{Scheme: "key", Type: TypeAPIKey, In: InQuery, Name: "key"},
+			nil, "key: query parameter 'key' is not set"},
+		{"cookie key", &Credential{Scheme: "key", Type: TypeAPIKey, In: InCookie, Name: "session"},
+			func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: "secret"}) }, ""},
+		{"missing cookie key", &Credential{Scheme: "key", Type: TypeAPIKey, In: InCookie, Name: "session"},
+			nil, "key: cookie 'session' is not set"},
+		{"bad key location", &Credential{Scheme: "key", Type: TypeAPIKey, In: "body", Name: "key"},
+			nil, "key: API keys cannot be in 'body'"},
+		{"bearer", &Credential{Scheme: "jwt", Type: TypeHTTP, HTTPScheme: "bearer"},
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer abc.def.ghi") }, ""},
+		{"missing authorization", &Credential{Scheme: "jwt", Type: TypeHTTP, HTTPScheme: "bearer"},
+			nil, "jwt: header 'Authorization' is not set"},
+		{"wrong scheme", &Credential{Scheme: "jwt", Type: TypeHTTP, HTTPScheme: "bearer"},
+			func(r *http.Request) { r.Header.Set("Authorization", "Basic Zm9vOmJhcg==") },
+			"jwt: header 'Authorization' does not have bearer credentials"},
+		{"empty token", &Credential{Scheme: "jwt", Type: TypeHTTP, HTTPScheme: "bearer"},
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") },
+			"jwt: header 'Authorization' does not have bearer credentials"},
+		{"basic", &Credential{Scheme: "basic", Type: TypeHTTP, HTTPScheme: "basic"},
+			func(r *http.Request) { r.SetBasicAuth("foo", "bar") }, ""},
+		{"bad basic", &Credential{Scheme: "basic", Type: TypeHTTP, HTTPScheme: "basic"},
+			func(r *http.Request) { r.Header.Set("Authorization", "Basic not-base64") },
+			"basic: header 'Authorization' does not have valid basic credentials"},
+		{"oauth2", &Credential{Scheme: "oauth", Type: TypeOAuth2},
+			func(r *http.Request) { r.Header.Set("Authorization", "bearer token") }, ""},
+		{"openid", &Credential{Scheme: "oidc", Type: TypeOpenIDConnect}, nil,
+			"oidc: header 'Authorization' is not set"},
+		{"mutual tls", &Credential{Scheme: "mtls", Type: TypeMutualTLS},
+			func(r *http.Request) {
+				r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}}
+			}, ""},
+		{"missing mutual tls", &Credential{Scheme: "mtls", Type: TypeMutualTLS},
+			nil, "mtls: no client certificate was presented"},
+		{"unknown", &Credential{Scheme: "magic", Type: "magic"},
+			nil, "magic: unknown security scheme type 'magic'"},
+	}
+	for _, tc := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/burgers", nil)
+		if tc.setup != nil {
+			tc.setup(request)
+		}
+		err := tc.credential.Check(request)
+		if tc.want == "" {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.EqualError(t, err, tc.want, tc.name)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(securitySpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	burgers := model.Model.Paths.PathItems["/burgers"]
+	requirements, _ := Requirements(&model.Model, burgers.Post)
+	secured := requirements[:2]
+
+	request := httptest.NewRequest(http.MethodPost, "/burgers", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	request.Header.Set("X-API-Key", "secret")
+	requirement, err := Check(secured, request)
+	assert.NoError(t, err)
+	assert.Same(t, secured[0], requirement)
+
+	request.Header.Del("X-API-Key")
+	requirement, err = Check(secured, request)
+	assert.NoError(t, err)
+	assert.Same(t, secured[1], requirement)
+
+	request = httptest.NewRequest(http.MethodPost, "/burgers", nil)
+	_, err = Check(secured, request)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.EqualError(t, err, "request does not satisfy any security requirement: apiKey: header 'X-API-Key' is "+
+		"not set and oauth: header 'Authorization' is not set; or bearer: header 'Authorization' is not set")
+
+	// the optional requirement is satisfied by any request.
+	requirement, err = Check(requirements, request)
+	assert.NoError(t, err)
+	assert.True(t, requirement.IsAnonymous())
+
+	// operations without requirements are not secured.
+	requirement, err = Check(nil, request)
+	assert.NoError(t, err)
+	assert.Nil(t, requirement)
+}
@@ -0,0 +1,237 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package security evaluates the security requirements of operations, joining them with the security schemes of a
+// document to describe the credentials that satisfy an operation, and checks requests carry those credentials.
+//
+// An operation is satisfied by any one of its requirements, and a requirement is satisfied when all of its
+// credentials are present. Swagger 2 security definitions are converted to the same credentials as OpenAPI 3
+// security schemes, so both can be enforced the same way.
+package security
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Security scheme types.
+const (
+	TypeAPIKey        = "apiKey"
+	TypeHTTP          = "http"
+	TypeOAuth2        = "oauth2"
+	TypeOpenIDConnect = "openIdConnect"
+	TypeMutualTLS     = "mutualTLS"
+)
+
+// Locations of API keys.
+const (
+	InHeader = "header"
+	InQuery  = "query"
+	InCookie = "cookie"
+)
+
+// OAuth2 flows, Swagger 2 flows use the same names as OpenAPI 3 (application is clientCredentials and accessCode
+// is authorizationCode).
+const (
+	FlowImplicit          = "implicit"
+	FlowPassword          = "password"
+	FlowClientCredentials = "clientCredentials"
+	FlowAuthorizationCode = "authorizationCode"
+)
+
+// Requirement is one way of satisfying an operation, every one of its credentials must be present. A requirement
+// without credentials is anonymous, it is satisfied by any request.
+type Requirement struct {
+	Credentials []*Credential
+}
+
+// IsAnonymous returns true if the requirement does not need any credentials.
+func (r *Requirement) IsAnonymous() bool {
+	return len(r.Credentials) == 0
+}
+
+// Credential describes the credential a security scheme needs, and the scopes a requirement needs from it.
+type Credential struct {
+	// Scheme is the name of the security scheme.
+	Scheme string
+
+	// Type is one of apiKey, http, oauth2, openIdConnect or mutualTLS.
+	Type string
+
+	// In is the location of an API key (header, query or cookie), and Name is its name.
+	In   string
+	Name string
+
+	// HTTPScheme is the lower-cased scheme of the Authorization header of an HTTP credential, such as bearer or
+	// basic, and BearerFormat is a hint of the format of a bearer token.
+	HTTPScheme   string
+	BearerFormat string
+
+	// Scopes are the scopes (or roles) the requirement needs.
+	Scopes []string
+
+	// Flows are the OAuth2 flows that can be used to obtain the credential.
+	Flows []*Flow
+
+	// OpenIDConnectURL is the URL used to discover the OpenID Connect configuration.
+	OpenIDConnectURL string
+}
+
+// Flow is an OAuth2 flow of a credential.
+type Flow struct {
+	Name             string
+	AuthorizationURL string
+	TokenURL         string
+	RefreshURL       string
+
+	// Scopes are the scopes that are available from the flow, with their descriptions.
+	Scopes map[string]string
+}
+
+// FlowsWithScopes returns the flows of a credential that provide every scope the requirement needs.
+func (c *Credential) FlowsWithScopes() []*Flow {
+	var flows []*Flow
+	for _, flow := range c.Flows {
+		provided := true
+		for _, scope := range c.Scopes {
+			if _, ok := flow.Scopes[scope]; !ok {
+				provided = false
+				break
+			}
+		}
+		if provided {
+			flows = append(flows, flow)
+		}
+	}
+	return flows
+}
+
+// Requirements returns the requirements of an operation of an OpenAPI 3 document, any one of which satisfies the
+// operation. The security of the operation overrides the security of the document, and an operation that declares
+// an empty security removes it. No requirements means the operation is not secured.
+//
+// An error is returned if a requirement uses a security scheme that is not defined by the components of the
+// document.
+func Requirements(document *v3.Document, operation *v3.Operation) ([]*Requirement, error) {
+	security := operation.Security
+	if security == nil && document != nil {
+		security = document.Security
+	}
+	schemes := make(map[string]*Credential)
+	if document != nil && document.Components != nil {
+		for name, scheme := range document.Components.SecuritySchemes {
+			schemes[name] = newCredential(name, scheme)
+		}
+	}
+	return join(security, schemes)
+}
+
+// SwaggerRequirements returns the requirements of an operation of a Swagger 2 document, as Requirements does for
+// OpenAPI 3, using the security definitions of the document.
+func SwaggerRequirements(swagger *v2.Swagger, operation *v2.Operation) ([]*Requirement, error) {
+	security := operation.Security
+	if security == nil && swagger != nil {
+		security = swagger.Security
+	}
+	schemes := make(map[string]*Credential)
+	if swagger != nil && swagger.SecurityDefinitions != nil {
+		for name, scheme := range swagger.SecurityDefinitions.Definitions {
+			schemes[name] = newSwaggerCredential(name, scheme)
+		}
+	}
+	return join(security, schemes)
+}
+
+// join creates the requirements of security requirements, with the credentials of the schemes they use, sorted by
+// the name of their scheme.
+func join(security []*base.SecurityRequirement, schemes map[string]*Credential) ([]*Requirement, error) {
+	requirements := make([]*Requirement, 0, len(security))
+	for _, sr := range security {
+		requirement := new(Requirement)
+		for name, scopes := range sr.Requirements {
+			scheme := schemes[name]
+			if scheme == nil {
+				return nil, fmt.Errorf("security requirement uses the security scheme '%s', which is not defined",
+					name)
+			}
+			credential := *scheme
+			credential.Scopes = scopes
+			requirement.Credentials = append(requirement.Credentials, &credential)
+		}
+		sort.Slice(requirement.Credentials, func(i, j int) bool {
+			return requirement.Credentials[i].Scheme < requirement.Credentials[j].Scheme
+		})
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+func newCredential(name string, scheme *v3.SecurityScheme) *Credential {
+	c := &Credential{
+		Scheme:           name,
+		Type:             scheme.Type,
+		In:               scheme.In,
+		HTTPScheme:       strings.ToLower(scheme.Scheme),
+		BearerFormat:     scheme.BearerFormat,
+		OpenIDConnectURL: scheme.OpenIdConnectUrl,
+	}
+	if scheme.Type == TypeAPIKey {
+		c.Name = scheme.Name
+	}
+	if flows := scheme.Flows; flows != nil {
+		for _, f := range []struct {
+			name string
+			flow *v3.OAuthFlow
+		}{
+			{FlowImplicit, flows.Implicit},
+			{FlowPassword, flows.Password},
+			{FlowClientCredentials, flows.ClientCredentials},
+			{FlowAuthorizationCode, flows.AuthorizationCode},
+		} {
+			if f.flow != nil {
+				c.Flows = append(c.Flows, &Flow{
+					Name:             f.name,
+					AuthorizationURL: f.flow.AuthorizationUrl,
+					TokenURL:         f.flow.TokenUrl,
+					RefreshURL:       f.flow.RefreshUrl,
+					Scopes:           f.flow.Scopes,
+				})
+			}
+		}
+	}
+	return c
+}
+
+func newSwaggerCredential(name string, scheme *v2.SecurityScheme) *Credential {
+	c := &Credential{Scheme: name, Type: scheme.Type}
+	switch scheme.Type {
+	case "basic":
+		c.Type = TypeHTTP
+		c.HTTPScheme = "basic"
+	case TypeAPIKey:
+		c.In = scheme.In
+		c.Name = scheme.Name
+	case TypeOAuth2:
+		flow := &Flow{
+			Name:             scheme.Flow,
+			AuthorizationURL: scheme.AuthorizationUrl,
+			TokenURL:         scheme.TokenUrl,
+		}
+		switch scheme.Flow {
+		case "application":
+			flow.Name = FlowClientCredentials
+		case "accessCode":
+			flow.Name = FlowAuthorizationCode
+		}
+		if scheme.Scopes != nil {
+			flow.Scopes = scheme.Scopes.Values
+		}
+		c.Flows = []*Flow{flow}
+	}
+	return c
+}
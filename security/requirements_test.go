@@ -0,0 +1,215 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var securitySpec = `openapi: 3.1.0
+security:
+  - apiKey: []
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: ok
+    post:
+      security:
+        - oauth: [write:burgers]
+          apiKey: []
+        - bearer: []
+        - {}
+      responses:
+        "200":
+          description: ok
+    delete:
+      security: []
+      responses:
+        "200":
+          description: ok
+    patch:
+      security:
+        - missing: []
+      responses:
+        "200":
+          description: ok
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    bearer:
+      type: http
+      scheme: Bearer
+      bearerFormat: JWT
+    basic:
+      type: http
+      scheme: basic
+    oauth:
+      type: oauth2
+      flows:
+        implicit:
+          authorizationUrl: https://pb33f.io/oauth/authorize
+          scopes:
+            read:burgers: read burgers
+        authorizationCode:
+          authorizationUrl: https://pb33f.io/oauth/authorize
+          tokenUrl: https://pb33f.io/oauth/token
+          refreshUrl: https://pb33f.io/oauth/refresh
+          scopes:
+            read:burgers: read burgers
+            write:burgers: write burgers
+    openId:
+      type: openIdConnect
+      openIdConnectUrl: https://pb33f.io/.well-known/openid-configuration`
+
+func TestRequirements(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(securitySpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	burgers := model.Model.Paths.PathItems["/burgers"]
+
+	// the document security.
+	requirements, err := Requirements(&model.Model, burgers.Get)
+	assert.NoError(t, err)
+	assert.Len(t, requirements, 1)
+	assert.Equal(t, &Credential{Scheme: "apiKey", Type: TypeAPIKey, In: InHeader, Name: "X-API-Key"},
+		requirements[0].Credentials[0])
+
+	// the operation security, with an optional requirement.
+	requirements, err = Requirements(&model.Model, burgers.Post)
+	assert.NoError(t, err)
+	assert.Len(t, requirements, 3)
+	assert.Len(t, requirements[0].Credentials, 2)
+	assert.Equal(t, "apiKey", requirements[0].Credentials[0].Scheme)
+	oauth := requirements[0].Credentials[1]
+	assert.Equal(t, TypeOAuth2, oauth.Type)
+	assert.Equal(t, []string{"write:burgers"}, oauth.Scopes)
+	assert.Len(t, oauth.Flows, 2)
+	assert.Equal(t, FlowImplicit, oauth.Flows[0].Name)
+	flows := oauth.FlowsWithScopes()
+	assert.Len(t, flows, 1)
+	assert.Equal(t, FlowAuthorizationCode, flows[0].Name)
+	assert.Equal(t, "https://pb33f.io/oauth/token", flows[0].TokenURL)
+	assert.Equal(t, "https://pb33f.io/oauth/refresh", flows[0].RefreshURL)
+	assert.Equal(t, &Credential{Scheme: "bearer", Type: TypeHTTP, HTTPScheme: "bearer", BearerFormat: "JWT"},
+		requirements[1].Credentials[0])
+	assert.False(t, requirements[1].IsAnonymous())
+	assert.True(t, requirements[2].IsAnonymous())
+
+	// the operation removes security.
+	requirements, err = Requirements(&model.Model, burgers.Delete)
+	assert.NoError(t, err)
+	assert.Empty(t, requirements)
+
+	_, err = Requirements(&model.Model, burgers.Patch)
+	assert.EqualError(t, err, "security requirement uses the security scheme 'missing', which is not defined")
+
+	// schemes are not shared between requirements.
+	requirements, _ = Requirements(&model.Model, burgers.Get)
+	requirements[0].Credentials[0].Scopes = []string{"changed"}
+	requirements, _ = Requirements(&model.Model, burgers.Get)
+	assert.Empty(t, requirements[0].Credentials[0].Scopes)
+}
+
+func TestRequirements_NoDocumentSecurity(t *testing.T) {
+	requirements, err := Requirements(&v3.Document{}, &v3.Operation{})
+	assert.NoError(t, err)
+	assert.Empty(t, requirements)
+
+	requirements, err = Requirements(nil, &v3.Operation{})
+	assert.NoError(t, err)
+	assert.Empty(t, requirements)
+}
+
+func TestRequirements_OpenIDConnect(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(securitySpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	credential := newCredential("openId", model.Model.Components.SecuritySchemes["openId"])
+	assert.Equal(t, TypeOpenIDConnect, credential.Type)
+	assert.Equal(t, "https://pb33f.io/.well-known/openid-configuration", credential.OpenIDConnectURL)
+}
+
+var swaggerSecuritySpec = `swagger: "2.0"
+security:
+  - basic: []
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: ok
+    post:
+      security:
+        - oauth: [write:burgers]
+        - key: []
+      responses:
+        "200":
+          description: ok
+    delete:
+      security: []
+      responses:
+        "200":
+          description: ok
+securityDefinitions:
+  basic:
+    type: basic
+  key:
+    type: apiKey
+    in: query
+    name: key
+  oauth:
+    type: oauth2
+    flow: accessCode
+    authorizationUrl: https://pb33f.io/oauth/authorize
+    tokenUrl: https://pb33f.io/oauth/token
+    scopes:
+      write:burgers: write burgers`
+
+func TestSwaggerRequirements(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(swaggerSecuritySpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV2Model()
+	require.Empty(t, errs)
+	burgers := model.Model.Paths.PathItems["/burgers"]
+
+	requirements, err := SwaggerRequirements(&model.Model, burgers.Get)
+	assert.NoError(t, err)
+	assert.Len(t, requirements, 1)
+	assert.Equal(t, &Credential{Scheme: "basic", Type: TypeHTTP, HTTPScheme: "basic"},
+		requirements[0].Credentials[0])
+
+	requirements, err = SwaggerRequirements(&model.Model, burgers.Post)
+	assert.NoError(t, err)
+	assert.Len(t, requirements, 2)
+	oauth := requirements[0].Credentials[0]
+	assert.Equal(t, TypeOAuth2, oauth.Type)
+	assert.Equal(t, []*Flow{{
+		Name:             FlowAuthorizationCode,
+		AuthorizationURL: "https://pb33f.io/oauth/authorize",
+		TokenURL:         "https://pb33f.io/oauth/token",
+		Scopes:           map[string]string{"write:burgers": "write burgers"},
+	}}, oauth.FlowsWithScopes())
+	assert.Equal(t, &Credential{Scheme: "key", Type: TypeAPIKey, In: InQuery, Name: "key"},
+		requirements[1].Credentials[0])
+
+	requirements, err = SwaggerRequirements(&model.Model, burgers.Delete)
+	assert.NoError(t, err)
+	assert.Empty(t, requirements)
+
+	_, err = SwaggerRequirements(&v2.Swagger{}, burgers.Post)
+	assert.EqualError(t, err, "security requirement uses the security scheme 'oauth', which is not defined")
+}
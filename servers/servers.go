@@ -0,0 +1,159 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package servers expands the URL templates of servers into concrete URLs, finds the servers of an operation and
+// resolves relative server URLs, as defined by https://spec.openapis.org/oas/v3.1.0#server-object
+//
+// Swagger 2 documents describe their server with host, basePath and schemes, which are converted to an OpenAPI 3
+// server so they can be used the same way.
+package servers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// variablePattern matches the variables of a server URL.
+var variablePattern = regexp.MustCompile(`{([^{}]+)}`)
+
+// Variables returns the names of the variables used in the URL of a server, in the order they first appear.
+func Variables(server *v3.Server) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range variablePattern.FindAllStringSubmatch(server.URL, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Expand expands the URL of a server, replacing each variable with its value, or its default if there is no value.
+// A variable without a default uses the first value of its enum. An error is returned if a value is not one of the
+// enum of its variable, if a value is supplied for a variable the server does not have, or if the URL uses a
+// variable that is not defined.
+func Expand(server *v3.Server, values map[string]string) (string, error) {
+	for name := range values {
+		if server.Variables[name] == nil {
+			return "", fmt.Errorf("'%s' is not a variable of server '%s'", name, server.URL)
+		}
+	}
+	resolved := make(map[string]string)
+	for _, name := range Variables(server) {
+		variable := server.Variables[name]
+		if variable == nil {
+			return "", fmt.Errorf("server variable '%s' of server '%s' is not defined", name, server.URL)
+		}
+		value, ok := values[name]
+		if !ok {
+			value = defaultValue(variable)
+		}
+		if len(variable.Enum) > 0 && !contains(variable.Enum, value) {
+			return "", fmt.Errorf("value '%s' of server variable '%s' is not one of %s", value, name,
+				strings.Join(variable.Enum, ", "))
+		}
+		resolved[name] = value
+	}
+	return variablePattern.ReplaceAllStringFunc(server.URL, func(v string) string {
+		return resolved[v[1:len(v)-1]]
+	}), nil
+}
+
+// Enumerate returns every concrete URL of a server, combining every value of the enums of its variables. Variables
+// without an enum only use their default. URLs are ordered by the values of the variables, in the order the variables
+// appear in the URL and the order of their enums.
+func Enumerate(server *v3.Server) ([]string, error) {
+	combinations, err := EnumerateValues(server)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(combinations))
+	for _, values := range combinations {
+		u, err := Expand(server, values)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// EnumerateValues returns the values of the variables of every concrete URL of a server, in the same order as
+// Enumerate. Each combination has a value for every variable used in the URL of the server.
+func EnumerateValues(server *v3.Server) ([]map[string]string, error) {
+	combinations := []map[string]string{{}}
+	for _, name := range Variables(server) {
+		variable := server.Variables[name]
+		if variable == nil {
+			return nil, fmt.Errorf("server variable '%s' of server '%s' is not defined", name, server.URL)
+		}
+		options := variable.Enum
+		if len(options) == 0 {
+			options = []string{variable.Default}
+		}
+		next := make([]map[string]string, 0, len(combinations)*len(options))
+		for _, combination := range combinations {
+			for _, option := range options {
+				values := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					values[k] = v
+				}
+				values[name] = option
+				next = append(next, values)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+// Effective returns the servers an operation is served from: the servers of the operation, then the servers of its
+// path item, then the servers of the document. A document without servers is served from '/'. Any of the arguments
+// may be nil.
+func Effective(document *v3.Document, pathItem *v3.PathItem, operation *v3.Operation) []*v3.Server {
+	switch {
+	case operation != nil && len(operation.Servers) > 0:
+		return operation.Servers
+	case pathItem != nil && len(pathItem.Servers) > 0:
+		return pathItem.Servers
+	case document != nil && len(document.Servers) > 0:
+		return document.Servers
+	}
+	return []*v3.Server{{URL: "/"}}
+}
+
+// Resolve resolves a (expanded) server URL that is relative against the URL of the document it was read from, as
+// server URLs are relative to the location of the document. Absolute URLs, and any URL when the document URL is
+// nil, are returned as they are.
+func Resolve(serverURL string, documentURL *url.URL) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve server URL '%s': %w", serverURL, err)
+	}
+	if u.IsAbs() || documentURL == nil {
+		return serverURL, nil
+	}
+	return documentURL.ResolveReference(u).String(), nil
+}
+
+// defaultValue returns the default of a variable, or the first value of its enum if it has no default.
+func defaultValue(variable *v3.ServerVariable) string {
+	if variable.Default == "" && len(variable.Enum) > 0 {
+		return variable.Enum[0]
+	}
+	return variable.Default
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
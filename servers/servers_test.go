@@ -0,0 +1,115 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package servers
+
+import (
+	"net/url"
+	"testing"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func regionServer() *v3.Server {
+	return &v3.Server{
+		URL: "https://{region}.{domain}/{version}/{region}",
+		Variables: map[string]*v3.ServerVariable{
+			"region":  {Enum: []string{"eu", "us"}},
+			"domain":  {Default: "pb33f.io"},
+			"version": {Default: "v2", Enum: []string{"v1", "v2"}},
+		},
+	}
+}
+
+func TestVariables(t *testing.T) {
+	assert.Equal(t, []string{"region", "domain", "version"}, Variables(regionServer()))
+	assert.Empty(t, Variables(&v3.Server{URL: "https://pb33f.io"}))
+}
+
+func TestExpand(t *testing.T) {
+	u, err := Expand(regionServer(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://eu.pb33f.io/v2/eu", u)
+
+	u, err = Expand(regionServer(), map[string]string{"region": "us", "domain": "example.com", "version": "v1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://us.example.com/v1/us", u)
+
+	u, err = Expand(&v3.Server{URL: "/api"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api", u)
+}
+
+func TestExpand_Errors(t *testing.T) {
+	_, err := Expand(regionServer(), map[string]string{"region": "asia"})
+	assert.EqualError(t, err, "value 'asia' of server variable 'region' is not one of eu, us")
+
+	_, err = Expand(regionServer(), map[string]string{"port": "8080"})
+	assert.EqualError(t, err, "'port' is not a variable of server 'https://{region}.{domain}/{version}/{region}'")
+
+	_, err = Expand(&v3.Server{URL: "https://{host}/api"}, nil)
+	assert.EqualError(t, err, "server variable 'host' of server 'https://{host}/api' is not defined")
+}
+
+func TestEnumerate(t *testing.T) {
+	urls, err := Enumerate(regionServer())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://eu.pb33f.io/v1/eu",
+		"https://eu.pb33f.io/v2/eu",
+		"https://us.pb33f.io/v1/us",
+		"https://us.pb33f.io/v2/us",
+	}, urls)
+
+	urls, err = Enumerate(&v3.Server{URL: "https://pb33f.io"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://pb33f.io"}, urls)
+
+	_, err = Enumerate(&v3.Server{URL: "https://{host}"})
+	assert.Error(t, err)
+
+	values, err := EnumerateValues(regionServer())
+	assert.NoError(t, err)
+	assert.Len(t, values, 4)
+	assert.Equal(t, map[string]string{"region": "us", "domain": "pb33f.io", "version": "v1"}, values[2])
+}
+
+func TestEffective(t *testing.T) {
+	root := []*v3.Server{{URL: "https://root"}}
+	item := []*v3.Server{{URL: "https://item"}}
+	op := []*v3.Server{{URL: "https://op"}}
+	doc := &v3.Document{Servers: root}
+
+	assert.Equal(t, op, Effective(doc, &v3.PathItem{Servers: item}, &v3.Operation{Servers: op}))
+	assert.Equal(t, item, Effective(doc, &v3.PathItem{Servers: item}, &v3.Operation{}))
+	assert.Equal(t, root, Effective(doc, &v3.PathItem{}, nil))
+	assert.Equal(t, []*v3.Server{{URL: "/"}}, Effective(&v3.Document{}, nil, nil))
+	assert.Equal(t, []*v3.Server{{URL: "/"}}, Effective(nil, nil, nil))
+}
+
+func TestResolve(t *testing.T) {
+	doc, _ := url.Parse("https://pb33f.io/specs/openapi.yaml")
+	tests := []struct {
+		server string
+		want   string
+	}{
+		{"https://api.pb33f.io/v1", "https://api.pb33f.io/v1"},
+		{"/v1", "https://pb33f.io/v1"},
+		{"v1", "https://pb33f.io/specs/v1"},
+		{"//api.pb33f.io/v1", "https://api.pb33f.io/v1"},
+		{"/", "https://pb33f.io/"},
+	}
+	for _, tc := range tests {
+		u, err := Resolve(tc.server, doc)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, u, tc.server)
+	}
+
+	u, err := Resolve("/v1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1", u)
+
+	_, err = Resolve("http://[::1", doc)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package servers
+
+import (
+	"strings"
+
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// SchemeVariable is the name of the server variable of the schemes of a Swagger 2 document.
+const SchemeVariable = "scheme"
+
+// SwaggerServers returns the server of a Swagger 2 document, built from its host, basePath and schemes. A document
+// with several schemes has a 'scheme' variable, with the schemes as its enum and the first scheme as its default.
+//
+// A document without a host is served from the host serving the document, so its URL is the base path, and a
+// document without schemes uses the scheme of the document, so its URL starts with '//'. Both can be made absolute
+// with Resolve.
+func SwaggerServers(swagger *v2.Swagger) []*v3.Server {
+	if swagger == nil {
+		return []*v3.Server{{URL: "/"}}
+	}
+	return []*v3.Server{swaggerServer(swagger.Host, swagger.BasePath, swagger.Schemes)}
+}
+
+// SwaggerOperationServers returns the server of an operation of a Swagger 2 document, the schemes of the operation
+// override the schemes of the document.
+func SwaggerOperationServers(swagger *v2.Swagger, operation *v2.Operation) []*v3.Server {
+	if operation == nil || len(operation.Schemes) == 0 || swagger == nil {
+		return SwaggerServers(swagger)
+	}
+	return []*v3.Server{swaggerServer(swagger.Host, swagger.BasePath, operation.Schemes)}
+}
+
+func swaggerServer(host, basePath string, schemes []string) *v3.Server {
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	if host == "" {
+		if basePath == "" {
+			basePath = "/"
+		}
+		return &v3.Server{URL: basePath}
+	}
+	switch len(schemes) {
+	case 0:
+		return &v3.Server{URL: "//" + host + basePath}
+	case 1:
+		return &v3.Server{URL: schemes[0] + "://" + host + basePath}
+	}
+	return &v3.Server{
+		URL: "{" + SchemeVariable + "}://" + host + basePath,
+		Variables: map[string]*v3.ServerVariable{
+			SchemeVariable: {Default: schemes[0], Enum: schemes},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package servers
+
+import (
+	"testing"
+
+	v2 "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwaggerServers(t *testing.T) {
+	tests := []struct {
+		swagger *v2.Swagger
+		want    string
+	}{
+		{&v2.Swagger{Host: "pb33f.io", BasePath: "/v1", Schemes: []string{"https"}}, "https://pb33f.io/v1"},
+		{&v2.Swagger{Host: "pb33f.io", BasePath: "v1"}, "//pb33f.io/v1"},
+		{&v2.Swagger{Host: "pb33f.io:8080"}, "//pb33f.io:8080"},
+		{&v2.Swagger{BasePath: "/v1", Schemes: []string{"https"}}, "/v1"},
+		{&v2.Swagger{}, "/"},
+		{nil, "/"},
+	}
+	for _, tc := range tests {
+		servers := SwaggerServers(tc.swagger)
+		assert.Len(t, servers, 1)
+		assert.Equal(t, tc.want, servers[0].URL)
+		assert.Empty(t, servers[0].Variables)
+	}
+}
+
+func TestSwaggerServers_Schemes(t *testing.T) {
+	swagger := &v2.Swagger{Host: "pb33f.io", BasePath: "/v1", Schemes: []string{"https", "http"}}
+	servers := SwaggerServers(swagger)
+	assert.Equal(t, "{scheme}://pb33f.io/v1", servers[0].URL)
+
+	u, err := Expand(servers[0], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://pb33f.io/v1", u)
+
+	urls, err := Enumerate(servers[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://pb33f.io/v1", "http://pb33f.io/v1"}, urls)
+
+	_, err = Expand(servers[0], map[string]string{SchemeVariable: "ws"})
+	assert.EqualError(t, err, "value 'ws' of server variable 'scheme' is not one of https, http")
+}
+
+func TestSwaggerOperationServers(t *testing.T) {
+	swagger := &v2.Swagger{Host: "pb33f.io", BasePath: "/v1", Schemes: []string{"https", "http"}}
+
+	servers := SwaggerOperationServers(swagger, &v2.Operation{Schemes: []string{"wss"}})
+	assert.Equal(t, []*v3.Server{{URL: "wss://pb33f.io/v1"}}, servers)
+
+	servers = SwaggerOperationServers(swagger, &v2.Operation{})
+	assert.Equal(t, "{scheme}://pb33f.io/v1", servers[0].URL)
+
+	servers = SwaggerOperationServers(swagger, nil)
+	assert.Equal(t, "{scheme}://pb33f.io/v1", servers[0].URL)
+}
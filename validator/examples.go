@@ -0,0 +1,321 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/parameters"
+	"gopkg.in/yaml.v3"
+)
+
+// ExampleError is an example in a document that does not validate against its schema.
+type ExampleError struct {
+	Pointer string             // JSON pointer to the example in the document.
+	Line    int                // line of the example in the document, zero if it is not known.
+	Column  int                // column of the example in the document, zero if it is not known.
+	Errors  []*ValidationError // every constraint of the schema that the example does not satisfy.
+	Err     error              // set when the example cannot be read, such as a missing externalValue file.
+}
+
+// Error returns a description of the example error.
+func (e *ExampleError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("example at '%s' (line %d, column %d) cannot be read: %s", e.Pointer, e.Line, e.Column, e.Err)
+	}
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("example at '%s' (line %d, column %d) does not match its schema: %s",
+		e.Pointer, e.Line, e.Column, strings.Join(messages, "; "))
+}
+
+// operationMethods are the methods of a path item, in the order they are walked.
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// exampleWalker walks a document, validating every example it finds.
+type exampleWalker struct {
+	config  *datamodel.DocumentConfiguration
+	errors  []*ExampleError
+	checked map[string]bool
+	schemas map[*base.Schema]bool
+}
+
+// ValidateExamples validates every example in a document against the schema it describes. This includes the
+// example and examples of schemas, parameters, headers and media types, and the components/examples they
+// reference. Examples with an externalValue are only read from a file when the configuration allows file references,
+// relative to the configuration BasePath. The configuration may be nil.
+//
+// An ExampleError is returned for every example that does not validate, with the JSON pointer and line of the
+// example. Components are checked first, so an example used in several places is reported where it is defined.
+func ValidateExamples(document *v3.Document, config *datamodel.DocumentConfiguration) []*ExampleError {
+	w := &exampleWalker{config: config, checked: make(map[string]bool), schemas: make(map[*base.Schema]bool)}
+	if document == nil {
+		return nil
+	}
+	if c := document.Components; c != nil {
+		for _, name := range sortedKeys(c.Schemas) {
+			w.walkSchema(pointer("/components/schemas", name), parameters.ProxySchema(c.Schemas[name]))
+		}
+		for _, name := range sortedKeys(c.Parameters) {
+			w.walkParameter(pointer("/components/parameters", name), c.Parameters[name])
+		}
+		for _, name := range sortedKeys(c.Headers) {
+			w.walkHeader(pointer("/components/headers", name), c.Headers[name])
+		}
+		for _, name := range sortedKeys(c.RequestBodies) {
+			if rb := c.RequestBodies[name]; rb != nil {
+				w.walkContent(pointer("/components/requestBodies", name), rb.Content)
+			}
+		}
+		for _, name := range sortedKeys(c.Responses) {
+			w.walkResponse(pointer("/components/responses", name), c.Responses[name])
+		}
+	}
+	if document.Paths != nil {
+		for _, path := range sortedKeys(document.Paths.PathItems) {
+			w.walkPathItem(pointer("/paths", path), document.Paths.PathItems[path])
+		}
+	}
+	return w.errors
+}
+
+func (w *exampleWalker) walkPathItem(ptr string, pathItem *v3.PathItem) {
+	if pathItem == nil {
+		return
+	}
+	for i, p := range pathItem.Parameters {
+		w.walkParameter(pointer(ptr+"/parameters", fmt.Sprint(i)), p)
+	}
+	operations := pathItem.GetOperations()
+	for _, method := range operationMethods {
+		op := operations[method]
+		if op == nil {
+			continue
+		}
+		opPtr := pointer(ptr, method)
+		for i, p := range op.Parameters {
+			w.walkParameter(pointer(opPtr+"/parameters", fmt.Sprint(i)), p)
+		}
+		if op.RequestBody != nil {
+			w.walkContent(opPtr+"/requestBody", op.RequestBody.Content)
+		}
+		if op.Responses != nil {
+			for _, code := range sortedKeys(op.Responses.Codes) {
+				w.walkResponse(pointer(opPtr+"/responses", code), op.Responses.Codes[code])
+			}
+			w.walkResponse(opPtr+"/responses/default", op.Responses.Default)
+		}
+	}
+}
+
+func (w *exampleWalker) walkResponse(ptr string, response *v3.Response) {
+	if response == nil {
+		return
+	}
+	for _, name := range sortedKeys(response.Headers) {
+		w.walkHeader(pointer(ptr+"/headers", name), response.Headers[name])
+	}
+	w.walkContent(ptr, response.Content)
+}
+
+func (w *exampleWalker) walkContent(ptr string, content map[string]*v3.MediaType) {
+	for _, mt := range sortedKeys(content) {
+		mediaType := content[mt]
+		if mediaType == nil {
+			continue
+		}
+		mtPtr := pointer(ptr+"/content", mt)
+		schema := parameters.ProxySchema(mediaType.Schema)
+		if low := mediaType.GoLow(); low != nil {
+			w.check(mtPtr+"/example", schema, low.Example.ValueNode)
+		}
+		w.checkExamples(mtPtr+"/examples", schema, mediaType.Examples)
+		w.walkProxy(mtPtr+"/schema", mediaType.Schema)
+	}
+}
+
+func (w *exampleWalker) walkParameter(ptr string, param *v3.Parameter) {
+	if param == nil {
+		return
+	}
+	schema := parameters.ProxySchema(param.Schema)
+	if low := param.GoLow(); low != nil {
+		w.check(ptr+"/example", schema, low.Example.ValueNode)
+	}
+	w.checkExamples(ptr+"/examples", schema, param.Examples)
+	w.walkProxy(ptr+"/schema", param.Schema)
+	w.walkContent(ptr, param.Content)
+}
+
+func (w *exampleWalker) walkHeader(ptr string, header *v3.Header) {
+	if header == nil {
+		return
+	}
+	schema := parameters.ProxySchema(header.Schema)
+	if low := header.GoLow(); low != nil {
+		w.check(ptr+"/example", schema, low.Example.ValueNode)
+	}
+	w.checkExamples(ptr+"/examples", schema, header.Examples)
+	w.walkProxy(ptr+"/schema", header.Schema)
+	w.walkContent(ptr, header.Content)
+}
+
+// walkProxy walks an inline schema, referenced schemas are checked where they are defined, in components.
+func (w *exampleWalker) walkProxy(ptr string, sp *base.SchemaProxy) {
+	if sp != nil && !sp.IsReference() {
+		w.walkSchema(ptr, sp.Schema())
+	}
+}
+
+// walkSchema checks the example and examples of a schema, and every inline schema it contains. Referenced schemas
+// are checked where they are defined, in components.
+func (w *exampleWalker) walkSchema(ptr string, schema *base.Schema) {
+	if schema == nil || w.schemas[schema] {
+		return
+	}
+	w.schemas[schema] = true
+	if low := schema.GoLow(); low != nil {
+		w.check(ptr+"/example", schema, low.Example.ValueNode)
+		for i, example := range low.Examples.Value {
+			w.check(pointer(ptr+"/examples", fmt.Sprint(i)), schema, example.ValueNode)
+		}
+	}
+	for _, name := range sortedKeys(schema.Properties) {
+		w.walkProxy(pointer(ptr+"/properties", name), schema.Properties[name])
+	}
+	for i, sp := range schema.AllOf {
+		w.walkProxy(pointer(ptr+"/allOf", fmt.Sprint(i)), sp)
+	}
+	for i, sp := range schema.OneOf {
+		w.walkProxy(pointer(ptr+"/oneOf", fmt.Sprint(i)), sp)
+	}
+	for i, sp := range schema.AnyOf {
+		w.walkProxy(pointer(ptr+"/anyOf", fmt.Sprint(i)), sp)
+	}
+	if schema.Items != nil && schema.Items.IsA() {
+		w.walkProxy(ptr+"/items", schema.Items.A)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+		w.walkProxy(ptr+"/additionalProperties", schema.AdditionalProperties.A)
+	}
+	w.walkProxy(ptr+"/not", schema.Not)
+}
+
+// checkExamples checks the named examples of a parameter, header or media type, reading external values if allowed.
+func (w *exampleWalker) checkExamples(ptr string, schema *base.Schema, examples map[string]*base.Example) {
+	for _, name := range sortedKeys(examples) {
+		example := examples[name]
+		if example == nil || example.GoLow() == nil {
+			continue
+		}
+		exPtr := pointer(ptr, name)
+		low := example.GoLow()
+		if low.Value.ValueNode != nil {
+			w.check(exPtr+"/value", schema, low.Value.ValueNode)
+			continue
+		}
+		if example.ExternalValue == "" || schema == nil || w.config == nil || !w.config.AllowFileReferences {
+			continue
+		}
+		node := low.ExternalValue.ValueNode
+		if w.checked[key(node, schema)] {
+			continue
+		}
+		w.checked[key(node, schema)] = true
+		value, err := w.readExternalValue(example.ExternalValue)
+		if err != nil {
+			w.errors = append(w.errors, &ExampleError{Pointer: exPtr + "/externalValue", Line: line(node),
+				Column: column(node), Err: err})
+			continue
+		}
+		if errs := Validate(schema, value); len(errs) > 0 {
+			w.errors = append(w.errors, &ExampleError{Pointer: exPtr + "/externalValue", Line: line(node),
+				Column: column(node), Errors: errs})
+		}
+	}
+}
+
+// check validates the example in a node against a schema, an example is only checked once against each schema.
+func (w *exampleWalker) check(ptr string, schema *base.Schema, node *yaml.Node) {
+	if node == nil || schema == nil || w.checked[key(node, schema)] {
+		return
+	}
+	w.checked[key(node, schema)] = true
+	// decode the node, rather than use the model value, as the model keeps scalar examples as strings.
+	var value any
+	if err := node.Decode(&value); err != nil {
+		w.errors = append(w.errors, &ExampleError{Pointer: ptr, Line: node.Line, Column: node.Column, Err: err})
+		return
+	}
+	if errs := Validate(schema, value); len(errs) > 0 {
+		w.errors = append(w.errors, &ExampleError{Pointer: ptr, Line: node.Line, Column: node.Column, Errors: errs})
+	}
+}
+
+// readExternalValue reads the value of an example from a file, relative to the base path of the configuration.
+// Remote values are not supported.
+func (w *exampleWalker) readExternalValue(location string) (any, error) {
+	location = strings.TrimPrefix(location, "file://")
+	if strings.Contains(location, "://") {
+		return nil, fmt.Errorf("remote external value '%s' is not supported", location)
+	}
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(w.config.BasePath, location)
+	}
+	b, err := os.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err = yaml.Unmarshal(b, &value); err != nil {
+		return nil, fmt.Errorf("unable to parse external value '%s': %w", location, err)
+	}
+	return value, nil
+}
+
+// key identifies an example checked against a schema, schemas are identified by hash as each reference to a schema
+// builds a new one.
+func key(node *yaml.Node, schema *base.Schema) string {
+	if low := schema.GoLow(); low != nil {
+		return fmt.Sprintf("%p:%x", node, low.Hash())
+	}
+	return fmt.Sprintf("%p:%p", node, schema)
+}
+
+func line(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	return node.Line
+}
+
+func column(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	return node.Column
+}
+
+// pointer appends an escaped segment to a JSON pointer.
+func pointer(ptr, segment string) string {
+	return ptr + "/" + escapePointer(segment)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const examplesSpec = `openapi: 3.1.0
+info:
+  title: examples
+  version: 1.0.0
+paths:
+  /burgers/{burgerId}:
+    parameters:
+      - name: burgerId
+        in: path
+        required: true
+        schema:
+          type: integer
+        example: big-mac
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 10
+          examples:
+            ok:
+              value: 5
+            tooMany:
+              value: 11
+      responses:
+        "200":
+          description: a burger
+          headers:
+            X-Rate-Limit:
+              schema:
+                type: integer
+              example: 100
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+              examples:
+                good:
+                  $ref: '#/components/examples/Good'
+                bad:
+                  $ref: '#/components/examples/Bad'
+                external:
+                  externalValue: burger.json
+        default:
+          description: error
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  code:
+                    type: integer
+                    example: "500"
+              example:
+                code: 500
+components:
+  examples:
+    Good:
+      value:
+        name: big mac
+        patties: 2
+    Bad:
+      value:
+        name: whopper
+        patties: 5
+  schemas:
+    Burger:
+      type: object
+      required: [name]
+      example:
+        patties: 1
+      properties:
+        name:
+          type: string
+        patties:
+          type: integer
+          maximum: 3
+          examples: [1, 4]`
+
+func pointers(errs []*ExampleError) []string {
+	var p []string
+	for _, e := range errs {
+		p = append(p, e.Pointer)
+	}
+	return p
+}
+
+func TestValidateExamples(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(examplesSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+
+	errs := ValidateExamples(&model.Model, nil)
+	assert.Equal(t, []string{
+		"/components/schemas/Burger/example",
+		"/components/schemas/Burger/properties/patties/examples/1",
+		"/paths/~1burgers~1{burgerId}/parameters/0/example",
+		"/paths/~1burgers~1{burgerId}/get/parameters/0/examples/tooMany/value",
+		"/paths/~1burgers~1{burgerId}/get/responses/200/content/application~1json/examples/bad/value",
+		"/paths/~1burgers~1{burgerId}/get/responses/default/content/application~1json/schema/properties/code/example",
+	}, pointers(errs))
+
+	// the referenced example is reported with the line it is defined on.
+	bad := errs[4]
+	assert.Equal(t, 65, bad.Line)
+	assert.Equal(t, []string{"/patties:maximum"}, keywords(bad.Errors))
+	assert.Equal(t, "example at '/components/schemas/Burger/example' (line 72, column 9) does not match its schema: "+
+		"validation failed at '/' (required): missing required property 'name'", errs[0].Error())
+
+	// scalar examples are checked with their real type.
+	assert.Equal(t, []string{":type"}, keywords(errs[2].Errors))
+	assert.Equal(t, 13, errs[2].Line)
+}
+
+func TestValidateExamples_ExternalValue(t *testing.T) {
+	dir := t.TempDir()
+	config := &datamodel.DocumentConfiguration{AllowFileReferences: true, BasePath: dir}
+	doc, err := libopenapi.NewDocument([]byte(examplesSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+
+	errs := ValidateExamples(&model.Model, config)
+	assert.Len(t, errs, 7)
+	external := errs[5]
+	assert.Equal(t, "/paths/~1burgers~1{burgerId}/get/responses/200/content/application~1json/examples/external/externalValue",
+		external.Pointer)
+	assert.Error(t, external.Err)
+	assert.Contains(t, external.Error(), "cannot be read")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "burger.json"), []byte(`{"patties": 1}`), 0o600))
+	errs = ValidateExamples(&model.Model, config)
+	assert.Len(t, errs, 7)
+	assert.Nil(t, errs[5].Err)
+	assert.Equal(t, []string{":required"}, keywords(errs[5].Errors))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "burger.json"), []byte(`{"name": "fries"}`), 0o600))
+	assert.Len(t, ValidateExamples(&model.Model, config), 6)
+}
+
+func TestValidateExamples_BurgerShop(t *testing.T) {
+	spec, _ := os.ReadFile("../test_specs/burgershop.openapi.yaml")
+	doc, _ := libopenapi.NewDocument(spec)
+	model, _ := doc.BuildV3Model()
+	for _, err := range ValidateExamples(&model.Model, nil) {
+		assert.NotEmpty(t, err.Pointer)
+		assert.Greater(t, err.Line, 0)
+	}
+	assert.Nil(t, ValidateExamples(nil, nil))
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"encoding/base64"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FormatChecker checks a value is valid for a format. Values are normalized, so numbers are float64. A checker
+// should return true for values of types the format does not apply to, for example a string format and a number.
+type FormatChecker func(value any) bool
+
+var (
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*\.?$`)
+	uuidPattern     = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	durationPattern = regexp.MustCompile(`^P(\d+W|(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?)$`)
+)
+
+// formats are the built-in format checkers, copied into every SchemaValidator. Formats without a checker are not
+// checked.
+var formats = map[string]FormatChecker{
+	"date-time": stringFormat(func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	}),
+	"date": stringFormat(func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	}),
+	"time": stringFormat(func(s string) bool {
+		_, err := time.Parse("15:04:05.999999999Z07:00", s)
+		return err == nil
+	}),
+	"duration": stringFormat(func(s string) bool {
+		return s != "P" && !strings.HasSuffix(s, "T") && durationPattern.MatchString(s)
+	}),
+	"email": stringFormat(func(s string) bool {
+		address, err := mail.ParseAddress(s)
+		return err == nil && address.Address == s
+	}),
+	"hostname": stringFormat(func(s string) bool {
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	}),
+	"ipv4": stringFormat(func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	}),
+	"ipv6": stringFormat(func(s string) bool {
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	}),
+	"uri": stringFormat(func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	}),
+	"uri-reference": stringFormat(func(s string) bool {
+		_, err := url.Parse(s)
+		return err == nil
+	}),
+	"uuid": stringFormat(uuidPattern.MatchString),
+	"regex": stringFormat(func(s string) bool {
+		_, err := regexp.Compile(s)
+		return err == nil
+	}),
+	"byte": stringFormat(func(s string) bool {
+		_, err := base64.StdEncoding.DecodeString(s)
+		return err == nil
+	}),
+	"int32": integerFormat(math.MinInt32, math.MaxInt32),
+	"int64": integerFormat(math.MinInt64, math.MaxInt64),
+}
+
+// stringFormat creates a FormatChecker for a format of strings.
+func stringFormat(check func(s string) bool) FormatChecker {
+	return func(value any) bool {
+		s, ok := value.(string)
+		return !ok || check(s)
+	}
+}
+
+// integerFormat creates a FormatChecker for a format of integers within a range.
+func integerFormat(minimum, maximum float64) FormatChecker {
+	return func(value any) bool {
+		n, ok := value.(float64)
+		return !ok || (n == math.Trunc(n) && n >= minimum && n <= maximum)
+	}
+}
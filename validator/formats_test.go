@@ -0,0 +1,61 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormats(t *testing.T) {
+	valid := map[string][]any{
+		"date-time":     {"2023-06-01T12:00:00Z", "2023-06-01T12:00:00.123+02:00"},
+		"date":          {"2023-06-01"},
+		"time":          {"12:00:00Z", "12:00:00.5+02:00"},
+		"duration":      {"P1D", "PT1H30M", "P2W"},
+		"email":         {"burgers@pb33f.io"},
+		"hostname":      {"pb33f.io", "localhost"},
+		"ipv4":          {"127.0.0.1"},
+		"ipv6":          {"::1", "2001:db8::68"},
+		"uri":           {"https://pb33f.io/burgers?id=1"},
+		"uri-reference": {"/burgers/1"},
+		"uuid":          {"e8a3bdfc-8a7b-4e1a-9f1e-2a1d1c3b4a5f"},
+		"regex":         {"^[a-z]+$"},
+		"byte":          {"cGIzM2Y="},
+		"int32":         {2147483647.0, -5.0},
+		"int64":         {9007199254740991.0},
+	}
+	invalid := map[string][]any{
+		"date-time":     {"2023-06-01", "2023-06-01 12:00:00"},
+		"date":          {"01/06/2023"},
+		"time":          {"noon"},
+		"duration":      {"P", "PT", "1D"},
+		"email":         {"burgers", "Burgers <burgers@pb33f.io>"},
+		"hostname":      {"-pb33f.io", "pb33f..io"},
+		"ipv4":          {"256.0.0.1", "::1"},
+		"ipv6":          {"127.0.0.1"},
+		"uri":           {"/burgers/1"},
+		"uri-reference": {"%zz"},
+		"uuid":          {"burger"},
+		"regex":         {"["},
+		"byte":          {"not base64!"},
+		"int32":         {2147483648.0, 1.5},
+		"int64":         {1.5},
+	}
+	for format, values := range valid {
+		for _, value := range values {
+			assert.True(t, formats[format](value), "%s should be a valid %s", value, format)
+		}
+	}
+	for format, values := range invalid {
+		for _, value := range values {
+			assert.False(t, formats[format](value), "%s should not be a valid %s", value, format)
+		}
+	}
+
+	// formats only apply to values of their type.
+	assert.True(t, formats["email"](1.0))
+	assert.True(t, formats["int32"]("a"))
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/parameters"
+)
+
+// readParameter reads the value of a parameter from a request and deserializes it using the style and explode
+// values of the parameter, converting primitives to the types of the schema. false is returned if the request does
+// not have the parameter.
+func readParameter(request *http.Request, query url.Values, pathParams map[string]string,
+	p *v3.Parameter) (any, bool, error) {
+	codec := parameters.NewCodec(p)
+	switch p.In {
+	case parameters.InPath:
+		raw, ok := pathParams[p.Name]
+		if !ok {
+			return nil, false, nil
+		}
+		value, err := codec.Deserialize(raw)
+		return value, true, err
+	case parameters.InQuery:
+		return codec.DeserializeQuery(query)
+	case parameters.InHeader:
+		values := request.Header.Values(p.Name)
+		if len(values) == 0 {
+			return nil, false, nil
+		}
+		value, err := codec.Deserialize(strings.Join(values, ","))
+		return value, true, err
+	case parameters.InCookie:
+		cookie, err := request.Cookie(p.Name)
+		if err != nil {
+			return nil, false, nil
+		}
+		value, err := codec.Deserialize(cookie.Value)
+		return value, true, err
+	}
+	return nil, false, fmt.Errorf("parameter location '%s' is not supported", p.In)
+}
+
+// formObject converts form fields into an object, the fields are deserialized as exploded form parameters, so
+// fields of array properties are arrays.
+func formObject(values url.Values, schema *base.Schema) map[string]any {
+	object := make(map[string]any, len(values))
+	for name := range values {
+		codec := &parameters.Codec{Name: name, In: parameters.InFormData, Style: parameters.StyleForm, Explode: true,
+			Schema: parameters.PropertySchema(schema, name)}
+		if value, ok, err := codec.DeserializeQuery(values); ok && err == nil {
+			object[name] = value
+		}
+	}
+	return object
+}
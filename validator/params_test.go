@@ -0,0 +1,60 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadParameter(t *testing.T) {
+	integer := base.CreateSchemaProxy(&base.Schema{Type: []string{integerType}})
+	r := httptest.NewRequest(http.MethodGet, "/burgers/1?limit=10", nil)
+	r.Header.Add("X-Ids", "1")
+	r.Header.Add("X-Ids", "2")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abcd"})
+	query := r.URL.Query()
+	pathParams := map[string]string{"burgerId": "1"}
+
+	read := func(p *v3.Parameter) any {
+		value, ok, err := readParameter(r, query, pathParams, p)
+		assert.True(t, ok, p.Name)
+		assert.NoError(t, err, p.Name)
+		return value
+	}
+	assert.Equal(t, int64(1), read(&v3.Parameter{Name: "burgerId", In: "path", Schema: integer}))
+	assert.Equal(t, int64(10), read(&v3.Parameter{Name: "limit", In: "query", Schema: integer}))
+	integers := base.CreateSchemaProxy(&base.Schema{Type: []string{arrayType},
+		Items: &base.DynamicValue[*base.SchemaProxy, bool]{A: integer}})
+	assert.Equal(t, []any{int64(1), int64(2)}, read(&v3.Parameter{Name: "X-Ids", In: "header", Schema: integers}))
+	assert.Equal(t, "abcd", read(&v3.Parameter{Name: "session", In: "cookie"}))
+
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		_, ok, err := readParameter(r, query, pathParams, &v3.Parameter{Name: "missing", In: in})
+		assert.False(t, ok, in)
+		assert.NoError(t, err, in)
+	}
+	_, _, err := readParameter(r, query, pathParams, &v3.Parameter{Name: "burger", In: "body"})
+	assert.Equal(t, "parameter location 'body' is not supported", err.Error())
+}
+
+func TestFormObject(t *testing.T) {
+	schema := getSchema(t, `type: object
+properties:
+  patties:
+    type: integer
+  toppings:
+    type: array
+    items:
+      type: string`)
+	values, _ := url.ParseQuery("name=big+mac&patties=2&toppings=cheese&toppings=onion")
+	assert.Equal(t, map[string]any{"name": "big mac", "patties": int64(2), "toppings": []any{"cheese", "onion"}},
+		formObject(values, schema))
+}
@@ -0,0 +1,326 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/parameters"
+	"github.com/pb33f/libopenapi/router"
+	"gopkg.in/yaml.v3"
+)
+
+// locations of request errors that are not parameters.
+const (
+	inRequest = "request"
+	inBody    = "body"
+)
+
+// RequestError is a part of a request that does not match its operation.
+type RequestError struct {
+	In      string             // path, query, header or cookie for parameters, body, or request for the route.
+	Name    string             // the name of the parameter, empty for the body and the route.
+	Message string             // describes the error.
+	Line    int                // line of the parameter, request body or media type in the document, zero if not known.
+	Column  int                // column of the parameter, request body or media type in the document.
+	Errors  []*ValidationError // the constraints of the schema the value does not satisfy.
+	Err     error              // the cause of the error, such as router.ErrPathNotFound.
+}
+
+// Error returns a description of the request error.
+func (e *RequestError) Error() string {
+	var b strings.Builder
+	switch {
+	case e.Name != "":
+		fmt.Fprintf(&b, "%s parameter '%s' is invalid: %s", e.In, e.Name, e.Message)
+	case e.In == inBody:
+		fmt.Fprintf(&b, "request body is invalid: %s", e.Message)
+	default:
+		b.WriteString(e.Message)
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " (line %d, column %d)", e.Line, e.Column)
+	}
+	for _, err := range e.Errors {
+		b.WriteString("; " + err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the cause of the error.
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestValidator validates HTTP requests against the operations of an OpenAPI 3 document. A RequestValidator is
+// safe to use from multiple goroutines.
+type RequestValidator struct {
+	router     *router.Router
+	validators sync.Map
+}
+
+// NewRequestValidator creates a RequestValidator for a document. Errors are returned for the paths of the document
+// that are ambiguous, as with router.NewRouter.
+func NewRequestValidator(document *v3.Document) (*RequestValidator, []error) {
+	r, errs := router.NewRouter(document)
+	return &RequestValidator{router: r}, errs
+}
+
+// ValidateRequest finds the operation of a request and validates the request against it. An error wrapping
+// router.ErrPathNotFound or router.ErrMethodNotAllowed is returned if the request does not match an operation.
+func (rv *RequestValidator) ValidateRequest(request *http.Request) []*RequestError {
+	route, err := rv.router.FindRoute(request)
+	if err != nil {
+		return []*RequestError{{In: inRequest, Message: err.Error(), Err: err}}
+	}
+	return rv.ValidateRoute(request, route)
+}
+
+// ValidateRoute validates a request against the operation of the route it matches. Parameters of the path item and
+// the operation are deserialized using their style and explode values, then validated against their schemas. The
+// body must be present if it is required, the Content-Type must be one of the media types of the request body, and
+// the body must be valid against the schema of the media type, where readOnly properties must not appear. JSON,
+// YAML, form-urlencoded, multipart/form-data and text bodies are validated, other bodies are only checked for their
+// Content-Type.
+//
+// The body of the request is read, and replaced so it can be read again. An empty slice is returned if the request
+// is valid.
+func (rv *RequestValidator) ValidateRoute(request *http.Request, route *router.Route) []*RequestError {
+	if route == nil || route.Operation == nil {
+		return []*RequestError{{In: inRequest, Message: "the request does not match an operation"}}
+	}
+	var errs []*RequestError
+	var pathParams []*v3.Parameter
+	if route.PathItem != nil {
+		pathParams = route.PathItem.Parameters
+	}
+	query := request.URL.Query()
+	for _, p := range parameters.Merge(pathParams, route.Operation.Parameters) {
+		if err := rv.validateParameter(request, query, route.PathParams, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := rv.validateBody(request, route.Operation.RequestBody); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ignoredHeaders are header parameters that are ignored, as defined by
+// https://spec.openapis.org/oas/v3.1.0#fixed-fields-9
+var ignoredHeaders = []string{"Accept", "Content-Type", "Authorization"}
+
+func (rv *RequestValidator) validateParameter(request *http.Request, query url.Values, pathParams map[string]string,
+	p *v3.Parameter) *RequestError {
+	if p.In == parameters.InHeader {
+		for _, h := range ignoredHeaders {
+			if strings.EqualFold(p.Name, h) {
+				return nil
+			}
+		}
+	}
+	fail := func(err error, errs []*ValidationError, format string, args ...any) *RequestError {
+		e := &RequestError{In: p.In, Name: p.Name, Message: fmt.Sprintf(format, args...), Errors: errs, Err: err}
+		if low := p.GoLow(); low != nil && low.Name.KeyNode != nil {
+			e.Line, e.Column = low.Name.KeyNode.Line, low.Name.KeyNode.Column
+		}
+		return e
+	}
+
+	schema := parameters.ProxySchema(p.Schema)
+	mediaType := ""
+	if schema == nil {
+		// a parameter with content has a single media type, with the schema.
+		for _, mt := range sortedKeys(p.Content) {
+			if p.Content[mt] != nil {
+				mediaType, schema = mt, parameters.ProxySchema(p.Content[mt].Schema)
+				break
+			}
+		}
+	}
+	value, ok, err := readParameter(request, query, pathParams, p)
+	if mediaType != "" && ok && err == nil {
+		value, err = decodeBody([]byte(fmt.Sprint(value)), mediaType, schema)
+	}
+	switch {
+	case !ok && p.Required:
+		return fail(nil, nil, "the parameter is required")
+	case !ok:
+		return nil
+	case err != nil:
+		return fail(err, nil, "%s", err)
+	}
+	if errs := rv.validator(schema).Validate(value); len(errs) > 0 {
+		return fail(nil, errs, "the value does not match the schema")
+	}
+	return nil
+}
+
+func (rv *RequestValidator) validateBody(request *http.Request, requestBody *v3.RequestBody) *RequestError {
+	if requestBody == nil {
+		return nil
+	}
+	fail := func(err error, errs []*ValidationError, format string, args ...any) *RequestError {
+		e := &RequestError{In: inBody, Message: fmt.Sprintf(format, args...), Errors: errs, Err: err}
+		if low := requestBody.GoLow(); low != nil {
+			if node := low.Content.KeyNode; node != nil {
+				e.Line, e.Column = node.Line, node.Column
+			}
+		}
+		return e
+	}
+
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return fail(err, nil, "the body cannot be read: %s", err)
+		}
+	}
+	if len(body) == 0 {
+		if requestBody.Required != nil && *requestBody.Required {
+			e := fail(nil, nil, "the body is required")
+			if low := requestBody.GoLow(); low != nil && low.Required.KeyNode != nil {
+				e.Line, e.Column = low.Required.KeyNode.Line, low.Required.KeyNode.Column
+			}
+			return e
+		}
+		return nil
+	}
+
+	contentType := request.Header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	name, mt := findMediaType(requestBody.Content, mediaType)
+	if mt == nil {
+		return fail(nil, nil, "content type '%s' is not one of %s", contentType,
+			strings.Join(sortedKeys(requestBody.Content), ", "))
+	}
+	schema := parameters.ProxySchema(mt.Schema)
+	if schema == nil {
+		return nil
+	}
+	if boundary, ok := params["boundary"]; ok && mediaType == "multipart/form-data" {
+		mediaType += "; boundary=" + boundary
+	}
+	value, err := decodeBody(body, mediaType, schema)
+	if err != nil {
+		if err == errUnsupportedMediaType {
+			return nil
+		}
+		return fail(err, nil, "the body cannot be decoded as '%s': %s", name, err)
+	}
+	if errs := rv.validator(schema).Validate(value); len(errs) > 0 {
+		e := fail(nil, errs, "the body does not match the schema of '%s'", name)
+		if low := mt.GoLow(); low != nil && low.Schema.KeyNode != nil {
+			e.Line, e.Column = low.Schema.KeyNode.Line, low.Schema.KeyNode.Column
+		}
+		return e
+	}
+	return nil
+}
+
+// validator returns the SchemaValidator of a schema, which does not allow readOnly values. Validators are created once
+// for each schema.
+func (rv *RequestValidator) validator(schema *base.Schema) *SchemaValidator {
+	return cachedValidator(&rv.validators, schema, AccessWrite)
+}
+
+// cachedValidator returns the SchemaValidator of a schema stored in a cache, creating it with an access if the
+// cache does not have one.
+func cachedValidator(cache *sync.Map, schema *base.Schema, access Access) *SchemaValidator {
+	if sv, ok := cache.Load(schema); ok {
+		return sv.(*SchemaValidator)
+	}
+	sv := NewSchemaValidator(schema)
+	sv.SetAccess(access)
+	stored, _ := cache.LoadOrStore(schema, sv)
+	return stored.(*SchemaValidator)
+}
+
+// findMediaType finds the media type of content that matches a media type, trying the media type, then a media
+// range of its type (like image/*), then */*.
+func findMediaType(content map[string]*v3.MediaType, mediaType string) (string, *v3.MediaType) {
+	mediaType = strings.ToLower(mediaType)
+	candidates := []string{mediaType}
+	if i := strings.Index(mediaType, "/"); i > 0 {
+		candidates = append(candidates, mediaType[:i]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+	for _, candidate := range candidates {
+		for _, name := range sortedKeys(content) {
+			parsed, _, err := mime.ParseMediaType(name)
+			if err != nil {
+				parsed = name
+			}
+			if strings.EqualFold(parsed, candidate) {
+				return name, content[name]
+			}
+		}
+	}
+	return "", nil
+}
+
+// errUnsupportedMediaType is returned when a body cannot be decoded, because its media type is not supported.
+var errUnsupportedMediaType = fmt.Errorf("media type is not supported")
+
+// decodeBody decodes a body of a media type, using the schema to convert form fields.
+func decodeBody(body []byte, mediaType string, schema *base.Schema) (any, error) {
+	essence, params, _ := mime.ParseMediaType(mediaType)
+	switch {
+	case essence == "application/json" || strings.HasSuffix(essence, "+json"):
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value any
+		err := decoder.Decode(&value)
+		return value, err
+	case strings.HasSuffix(essence, "/yaml") || strings.HasSuffix(essence, "+yaml"):
+		var value any
+		err := yaml.Unmarshal(body, &value)
+		return value, err
+	case essence == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		return formObject(values, schema), nil
+	case essence == "multipart/form-data":
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(int64(len(body)))
+		if err != nil {
+			return nil, err
+		}
+		values := url.Values(form.Value)
+		for name, files := range form.File {
+			for _, file := range files {
+				f, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				b, _ := io.ReadAll(f)
+				_ = f.Close()
+				values[name] = append(values[name], string(b))
+			}
+		}
+		return formObject(values, schema), nil
+	case strings.HasPrefix(essence, "text/"):
+		return (&parameters.Codec{Style: parameters.StyleSimple, Schema: schema}).Deserialize(string(body))
+	}
+	return nil, errUnsupportedMediaType
+}
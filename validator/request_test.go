@@ -0,0 +1,232 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var requestSpec = `openapi: 3.1.0
+info:
+  title: Burgers
+  version: 1.0.0
+paths:
+  /burgers/{burgerId}:
+    parameters:
+      - name: burgerId
+        in: path
+        required: true
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      parameters:
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+            maxItems: 2
+        - name: filter
+          in: query
+          style: deepObject
+          schema:
+            type: object
+            properties:
+              vegan:
+                type: boolean
+              calories:
+                type: integer
+                maximum: 1000
+        - name: X-Trace-Id
+          in: header
+          required: true
+          schema:
+            type: string
+            format: uuid
+        - name: session
+          in: cookie
+          required: true
+          schema:
+            type: string
+            minLength: 4
+        - name: where
+          in: query
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [lat]
+    put:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+          application/x-www-form-urlencoded:
+            schema:
+              $ref: '#/components/schemas/Burger'
+          multipart/form-data:
+            schema:
+              $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        patties:
+          type: integer
+          minimum: 1
+        toppings:
+          type: array
+          items:
+            type: string
+        id:
+          type: integer
+          readOnly: true`
+
+func getBurgerRequest(target string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Header.Set("X-Trace-Id", "e8a3bdfc-8a7b-4e1a-9f1e-2a1d1c3b4a5f")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abcd"})
+	return r
+}
+
+func TestRequestValidator_Parameters(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(requestSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	rv, rerrs := NewRequestValidator(&model.Model)
+	require.Empty(t, rerrs)
+
+	r := getBurgerRequest(`/burgers/1?tags=cheese&tags=bacon&filter[vegan]=true&filter[calories]=500&where={"lat":1}`)
+	assert.Empty(t, rv.ValidateRequest(r))
+
+	errs := rv.ValidateRequest(getBurgerRequest("/burgers/0?tags=a&tags=b&tags=c&filter[calories]=2000"))
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "path", errs[0].In)
+	assert.Equal(t, "burgerId", errs[0].Name)
+	assert.Equal(t, 8, errs[0].Line)
+	assert.Equal(t, "path parameter 'burgerId' is invalid: the value does not match the schema (line 8, column 9); "+
+		"validation failed at '/' (minimum): value must be >= 1, but is 0", errs[0].Error())
+	assert.Equal(t, []string{":maxItems"}, keywords(errs[1].Errors))
+	assert.Equal(t, []string{"/calories:maximum"}, keywords(errs[2].Errors))
+	assert.Equal(t, 33, errs[2].Errors[0].Line)
+
+	r = httptest.NewRequest(http.MethodGet, "/burgers/abc?where={}", nil)
+	r.Header.Set("X-Trace-Id", "nope")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "a"})
+	errs = rv.ValidateRequest(r)
+	assert.Len(t, errs, 4)
+	assert.Equal(t, "validation failed at '/' (type): expected integer, but got string", errs[0].Errors[0].Error())
+	assert.Equal(t, []string{":format"}, keywords(errs[1].Errors))
+	assert.Equal(t, []string{":minLength"}, keywords(errs[2].Errors))
+	assert.Equal(t, "where", errs[3].Name)
+	assert.Equal(t, []string{":required"}, keywords(errs[3].Errors))
+
+	errs = rv.ValidateRequest(httptest.NewRequest(http.MethodGet, "/burgers/1", nil))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "header parameter 'X-Trace-Id' is invalid: the parameter is required (line 34, column 11)",
+		errs[0].Error())
+	assert.Equal(t, "session", errs[1].Name)
+
+	errs = rv.ValidateRequest(getBurgerRequest(`/burgers/1?where={`))
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs[0].Err)
+}
+
+func TestRequestValidator_Body(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(requestSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	rv, rerrs := NewRequestValidator(&model.Model)
+	require.Empty(t, rerrs)
+
+	put := func(contentType, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, "/burgers/1", strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		return r
+	}
+
+	r := put("application/json; charset=utf-8", `{"name": "big mac", "patties": 2}`)
+	assert.Empty(t, rv.ValidateRequest(r))
+	// the body can be read again.
+	b, _ := io.ReadAll(r.Body)
+	assert.Equal(t, `{"name": "big mac", "patties": 2}`, string(b))
+
+	errs := rv.ValidateRequest(put("application/json", `{"patties": 0}`))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "body", errs[0].In)
+	assert.Equal(t, "the body does not match the schema of 'application/json'", errs[0].Message)
+	assert.Equal(t, 58, errs[0].Line)
+	assert.Equal(t, []string{":required", "/patties:minimum"}, keywords(errs[0].Errors))
+	assert.Equal(t, "#/components/schemas/Burger/required", errs[0].Errors[0].SchemaLocation)
+
+	// readOnly properties must not be sent.
+	errs = rv.ValidateRequest(put("application/json", `{"name": "big mac", "id": 1}`))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []string{"/id:readOnly"}, keywords(errs[0].Errors))
+
+	errs = rv.ValidateRequest(put("", ""))
+	assert.Equal(t, "request body is invalid: the body is required (line 55, column 9)", errs[0].Error())
+
+	errs = rv.ValidateRequest(put("text/csv", "name\nbig mac"))
+	assert.Equal(t, "content type 'text/csv' is not one of application/json, application/x-www-form-urlencoded, "+
+		"multipart/form-data", errs[0].Message)
+
+	errs = rv.ValidateRequest(put("application/json", `{"name": `))
+	assert.Contains(t, errs[0].Message, "the body cannot be decoded as 'application/json'")
+
+	assert.Empty(t, rv.ValidateRequest(put("application/x-www-form-urlencoded",
+		"name=big+mac&patties=2&toppings=cheese&toppings=onion")))
+	errs = rv.ValidateRequest(put("application/x-www-form-urlencoded", "name=big+mac&patties=none"))
+	assert.Equal(t, []string{"/patties:type"}, keywords(errs[0].Errors))
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("name", "big mac")
+	_ = w.WriteField("patties", "0")
+	_ = w.Close()
+	errs = rv.ValidateRequest(put(w.FormDataContentType(), buf.String()))
+	assert.Equal(t, []string{"/patties:minimum"}, keywords(errs[0].Errors))
+}
+
+func TestRequestValidator_Route(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(requestSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	rv, rerrs := NewRequestValidator(&model.Model)
+	require.Empty(t, rerrs)
+	errs := rv.ValidateRequest(httptest.NewRequest(http.MethodGet, "/fries", nil))
+	assert.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], router.ErrPathNotFound))
+
+	errs = rv.ValidateRequest(httptest.NewRequest(http.MethodPost, "/burgers/1", nil))
+	assert.True(t, errors.Is(errs[0], router.ErrMethodNotAllowed))
+
+	errs = rv.ValidateRoute(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	assert.Equal(t, "the request does not match an operation", errs[0].Error())
+}
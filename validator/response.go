@@ -0,0 +1,249 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/parameters"
+)
+
+// inStatus is the location of a response error for a status code that is not declared.
+const inStatus = "status"
+
+// ResponseError is a part of a response that does not match its operation.
+type ResponseError struct {
+	In      string             // status, header or body.
+	Name    string             // the name of the header, empty for the status and the body.
+	Message string             // describes the error.
+	Line    int                // line of the responses, header, content or media type in the document, zero if not known.
+	Column  int                // column of the responses, header, content or media type in the document.
+	Errors  []*ValidationError // the constraints of the schema the value does not satisfy.
+	Err     error              // the cause of the error, such as a body that cannot be decoded.
+}
+
+// Error returns a description of the response error.
+func (e *ResponseError) Error() string {
+	var b strings.Builder
+	switch e.In {
+	case parameters.InHeader:
+		fmt.Fprintf(&b, "response header '%s' is invalid: %s", e.Name, e.Message)
+	case inBody:
+		fmt.Fprintf(&b, "response body is invalid: %s", e.Message)
+	default:
+		b.WriteString(e.Message)
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " (line %d, column %d)", e.Line, e.Column)
+	}
+	for _, err := range e.Errors {
+		b.WriteString("; " + err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the cause of the error.
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseValidator validates HTTP responses against the operations of an OpenAPI 3 document, to check that a
+// service does not drift from its contract. A ResponseValidator is safe to use from multiple goroutines.
+type ResponseValidator struct {
+	validators sync.Map
+}
+
+// NewResponseValidator creates a ResponseValidator.
+func NewResponseValidator() *ResponseValidator {
+	return &ResponseValidator{}
+}
+
+// ValidateResponse validates a response to a request against an operation, as with ValidateRecorded. The body of
+// the response is read, and replaced so it can be read again. The request may be nil.
+func (rv *ResponseValidator) ValidateResponse(operation *v3.Operation, request *http.Request,
+	response *http.Response) []*ResponseError {
+	var body []byte
+	if response.Body != nil {
+		var err error
+		body, err = io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return []*ResponseError{{In: inBody, Message: fmt.Sprintf("the body cannot be read: %s", err), Err: err}}
+		}
+	}
+	return rv.ValidateRecorded(operation, request, response.StatusCode, response.Header, body)
+}
+
+// ValidateRecorded validates the status code, headers and body of a response to a request against an operation.
+// The status code must be declared by the responses of the operation, or match a range (like 2XX) or the default
+// response. Required headers must be present, and headers must be valid against their schemas. The Content-Type must
+// be one of the media types of the response, and the body must be valid against the schema of the media type, where
+// writeOnly properties must not appear. Bodies of responses to HEAD requests and empty bodies are not validated.
+//
+// An empty slice is returned if the response is valid.
+func (rv *ResponseValidator) ValidateRecorded(operation *v3.Operation, request *http.Request, status int,
+	header http.Header, body []byte) []*ResponseError {
+	if operation == nil || operation.Responses == nil {
+		return nil
+	}
+	response := findResponse(operation.Responses, status)
+	if response == nil {
+		e := &ResponseError{In: inStatus, Message: fmt.Sprintf("status code %d is not one of %s", status,
+			strings.Join(responseCodes(operation.Responses), ", "))}
+		if low := operation.GoLow(); low != nil && low.Responses.KeyNode != nil {
+			e.Line, e.Column = low.Responses.KeyNode.Line, low.Responses.KeyNode.Column
+		}
+		return []*ResponseError{e}
+	}
+
+	var errs []*ResponseError
+	for _, name := range sortedKeys(response.Headers) {
+		if err := rv.validateHeader(response, name, header); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if (request == nil || request.Method != http.MethodHead) && len(body) > 0 {
+		if err := rv.validateBody(response, header.Get("Content-Type"), body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (rv *ResponseValidator) validateHeader(response *v3.Response, name string, header http.Header) *ResponseError {
+	h := response.Headers[name]
+	// the Content-Type header is described by the content of the response.
+	if h == nil || strings.EqualFold(name, "Content-Type") {
+		return nil
+	}
+	fail := func(err error, errs []*ValidationError, format string, args ...any) *ResponseError {
+		e := &ResponseError{In: parameters.InHeader, Name: name, Message: fmt.Sprintf(format, args...), Errors: errs, Err: err}
+		if low := response.GoLow(); low != nil {
+			for key := range low.Headers.Value {
+				if key.Value == name && key.KeyNode != nil {
+					e.Line, e.Column = key.KeyNode.Line, key.KeyNode.Column
+				}
+			}
+		}
+		return e
+	}
+
+	values := header.Values(name)
+	if len(values) == 0 {
+		if h.Required {
+			return fail(nil, nil, "the header is required")
+		}
+		return nil
+	}
+	schema := parameters.ProxySchema(h.Schema)
+	mediaType := ""
+	if schema == nil {
+		// a header with content has a single media type, with the schema.
+		for _, mt := range sortedKeys(h.Content) {
+			if h.Content[mt] != nil {
+				mediaType, schema = mt, parameters.ProxySchema(h.Content[mt].Schema)
+				break
+			}
+		}
+	}
+	raw := strings.Join(values, ",")
+	var value any
+	var err error
+	if mediaType != "" {
+		value, err = decodeBody([]byte(raw), mediaType, schema)
+	} else {
+		value, err = parameters.NewHeaderCodec(name, h).Deserialize(raw)
+	}
+	if err != nil {
+		return fail(err, nil, "%s", err)
+	}
+	if errs := rv.validator(schema).Validate(value); len(errs) > 0 {
+		return fail(nil, errs, "the value does not match the schema")
+	}
+	return nil
+}
+
+func (rv *ResponseValidator) validateBody(response *v3.Response, contentType string, body []byte) *ResponseError {
+	if len(response.Content) == 0 {
+		return nil
+	}
+	fail := func(err error, errs []*ValidationError, format string, args ...any) *ResponseError {
+		e := &ResponseError{In: inBody, Message: fmt.Sprintf(format, args...), Errors: errs, Err: err}
+		if low := response.GoLow(); low != nil && low.Content.KeyNode != nil {
+			e.Line, e.Column = low.Content.KeyNode.Line, low.Content.KeyNode.Column
+		}
+		return e
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	name, mt := findMediaType(response.Content, mediaType)
+	if mt == nil {
+		return fail(nil, nil, "content type '%s' is not one of %s", contentType,
+			strings.Join(sortedKeys(response.Content), ", "))
+	}
+	schema := parameters.ProxySchema(mt.Schema)
+	if schema == nil {
+		return nil
+	}
+	if boundary, ok := params["boundary"]; ok && mediaType == "multipart/form-data" {
+		mediaType += "; boundary=" + boundary
+	}
+	value, err := decodeBody(body, mediaType, schema)
+	if err != nil {
+		if err == errUnsupportedMediaType {
+			return nil
+		}
+		return fail(err, nil, "the body cannot be decoded as '%s': %s", name, err)
+	}
+	if errs := rv.validator(schema).Validate(value); len(errs) > 0 {
+		e := fail(nil, errs, "the body does not match the schema of '%s'", name)
+		if low := mt.GoLow(); low != nil && low.Schema.KeyNode != nil {
+			e.Line, e.Column = low.Schema.KeyNode.Line, low.Schema.KeyNode.Column
+		}
+		return e
+	}
+	return nil
+}
+
+// validator returns the SchemaValidator of a schema, which does not allow writeOnly values.
+func (rv *ResponseValidator) validator(schema *base.Schema) *SchemaValidator {
+	return cachedValidator(&rv.validators, schema, AccessRead)
+}
+
+// findResponse finds the response of a status code, trying the code, then its range (like 2XX), then the default
+// response.
+func findResponse(responses *v3.Responses, status int) *v3.Response {
+	if r := responses.FindResponseByCode(status); r != nil {
+		return r
+	}
+	statusRange := strconv.Itoa(status/100) + "XX"
+	for code, r := range responses.Codes {
+		if strings.EqualFold(code, statusRange) {
+			return r
+		}
+	}
+	return responses.Default
+}
+
+// responseCodes returns the declared status codes of responses, followed by default.
+func responseCodes(responses *v3.Responses) []string {
+	codes := sortedKeys(responses.Codes)
+	if responses.Default != nil {
+		codes = append(codes, "default")
+	}
+	return codes
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var responseSpec = `openapi: 3.1.0
+info:
+  title: Burgers
+  version: 1.0.0
+paths:
+  /burgers/{burgerId}:
+    get:
+      responses:
+        "200":
+          description: a burger
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema:
+                type: integer
+                maximum: 100
+            X-Tags:
+              schema:
+                type: array
+                items:
+                  type: string
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+        4XX:
+          description: client error
+          content:
+            text/plain:
+              schema:
+                type: string
+        default:
+          description: error
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        secret:
+          type: string
+          writeOnly: true`
+
+func TestResponseValidator_ValidateResponse(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(responseSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	op := model.Model.Paths.PathItems["/burgers/{burgerId}"].Get
+	rv := NewResponseValidator()
+	request := httptest.NewRequest(http.MethodGet, "/burgers/1", nil)
+
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Rate-Limit", "10")
+	w.Header().Set("X-Tags", "cheese,bacon")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.WriteString(`{"name": "big mac"}`)
+	response := w.Result()
+	assert.Empty(t, rv.ValidateResponse(op, request, response))
+	// the body can be read again.
+	b, _ := io.ReadAll(response.Body)
+	assert.Equal(t, `{"name": "big mac"}`, string(b))
+
+	header := http.Header{"Content-Type": {"application/json"}, "X-Rate-Limit": {"500"}}
+	errs := rv.ValidateRecorded(op, request, http.StatusOK, header, []byte(`{"secret": "ketchup"}`))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "response header 'X-Rate-Limit' is invalid: the value does not match the schema "+
+		"(line 12, column 13); validation failed at '/' (maximum): value must be <= 100, but is 500", errs[0].Error())
+	assert.Equal(t, "body", errs[1].In)
+	assert.Equal(t, 24, errs[1].Line)
+	assert.Equal(t, []string{":required", "/secret:writeOnly"}, keywords(errs[1].Errors))
+	assert.Equal(t, "#/components/schemas/Burger/properties/secret/writeOnly", errs[1].Errors[1].SchemaLocation)
+
+	// the body of a HEAD request is not validated.
+	head := httptest.NewRequest(http.MethodHead, "/burgers/1", nil)
+	errs = rv.ValidateRecorded(op, head, http.StatusOK, http.Header{}, []byte(`{}`))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "response header 'X-Rate-Limit' is invalid: the header is required (line 12, column 13)",
+		errs[0].Error())
+}
+
+func TestResponseValidator_Status(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(responseSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	op := model.Model.Paths.PathItems["/burgers/{burgerId}"].Get
+	rv := NewResponseValidator()
+
+	header := http.Header{"Content-Type": {"text/plain"}}
+	assert.Empty(t, rv.ValidateRecorded(op, nil, http.StatusNotFound, header, []byte("not found")))
+	errs := rv.ValidateRecorded(op, nil, http.StatusTeapot, http.Header{"Content-Type": {"application/json"}},
+		[]byte("{}"))
+	assert.Equal(t, "response body is invalid: content type 'application/json' is not one of text/plain "+
+		"(line 28, column 11)", errs[0].Error())
+
+	// the default response has no content.
+	assert.Empty(t, rv.ValidateRecorded(op, nil, http.StatusInternalServerError, header, []byte("oops")))
+
+	op.Responses.Default = nil
+	errs = rv.ValidateRecorded(op, nil, http.StatusInternalServerError, header, nil)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "status", errs[0].In)
+	assert.Equal(t, "status code 500 is not one of 200, 4XX (line 8, column 7)", errs[0].Error())
+
+	assert.Empty(t, rv.ValidateRecorded(nil, nil, http.StatusOK, header, nil))
+}
+
+func TestResponseValidator_Body(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(responseSpec))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	op := model.Model.Paths.PathItems["/burgers/{burgerId}"].Get
+	rv := NewResponseValidator()
+	header := http.Header{"Content-Type": {"application/json; charset=utf-8"}, "X-Rate-Limit": {"1"}}
+
+	errs := rv.ValidateRecorded(op, nil, http.StatusOK, header, []byte(`{"name": `))
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "the body cannot be decoded as 'application/json'")
+	assert.Error(t, errs[0].Err)
+
+	// empty bodies are not validated.
+	assert.Empty(t, rv.ValidateRecorded(op, nil, http.StatusOK, header, nil))
+}
@@ -0,0 +1,887 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package validator validates values against the schemas of an OpenAPI document, and checks the examples of a
+// document are valid against the schemas they describe.
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	nullType    = "null"
+	booleanType = "boolean"
+	stringType  = "string"
+	numberType  = "number"
+	integerType = "integer"
+	arrayType   = "array"
+	objectType  = "object"
+)
+
+// rootLocation is the schema location of a schema that is not a reference.
+const rootLocation = "#"
+
+// Dialect is the flavor of JSON Schema used to validate values.
+type Dialect int
+
+const (
+	// DialectDetect detects the dialect from the version of the document the schema was read from. If the version
+	// is not known, the keywords of both dialects are used.
+	DialectDetect Dialect = iota
+
+	// DialectOpenAPI30 is the schema object of OpenAPI 3.0 and Swagger 2. nullable is supported, exclusiveMinimum and
+	// exclusiveMaximum are booleans and the keywords added by JSON Schema 2020-12 are ignored.
+	DialectOpenAPI30
+
+	// DialectJSONSchema202012 is JSON Schema 2020-12, used by OpenAPI 3.1. nullable is ignored, exclusiveMinimum and
+	// exclusiveMaximum are numbers.
+	DialectJSONSchema202012
+)
+
+// Access is the direction a value is sent in, which decides whether readOnly and writeOnly properties may appear.
+type Access int
+
+const (
+	// AccessAny treats readOnly and writeOnly as annotations, any property may appear.
+	AccessAny Access = iota
+
+	// AccessRead validates a value read from an API, such as the body of a response. writeOnly values must not
+	// appear.
+	AccessRead
+
+	// AccessWrite validates a value written to an API, such as the body of a request. readOnly values must not
+	// appear.
+	AccessWrite
+)
+
+// ValidationError is a constraint of a schema that a value does not satisfy.
+type ValidationError struct {
+	InstancePath   string // JSON pointer to the invalid value, empty for the value itself.
+	SchemaLocation string // location of the keyword, '#' (or the reference of the schema) followed by a JSON pointer.
+	Keyword        string // the schema keyword that failed, such as 'type' or 'required'.
+	Message        string
+	Line           int // line of the keyword in the document, zero if it is not known.
+	Column         int // column of the keyword in the document, zero if it is not known.
+}
+
+// Error returns a description of the validation error.
+func (e *ValidationError) Error() string {
+	path := e.InstancePath
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("validation failed at '%s' (%s): %s", path, e.Keyword, e.Message)
+}
+
+// SchemaValidator validates values against a schema. A SchemaValidator is safe to use from multiple goroutines, once
+// the dialect and formats are set.
+type SchemaValidator struct {
+	schema   *base.Schema
+	location string
+	dialect  Dialect
+	access   Access
+	formats  map[string]FormatChecker
+	patterns sync.Map
+}
+
+// NewSchemaValidator creates a SchemaValidator for a schema, using the built-in format checkers. Referenced schemas
+// are built as they are needed, use Compile to build every schema up front.
+func NewSchemaValidator(schema *base.Schema) *SchemaValidator {
+	sv := &SchemaValidator{schema: schema, location: rootLocation, formats: make(map[string]FormatChecker)}
+	if schema != nil && schema.ParentProxy != nil && schema.ParentProxy.IsReference() {
+		sv.location = schema.ParentProxy.GetReference()
+	}
+	for name, checker := range formats {
+		sv.formats[name] = checker
+	}
+	return sv
+}
+
+// Compile builds a schema and every schema it contains or references, resolving references through the index of
+// the document, and compiles every pattern. An error is returned if a schema cannot be built or a pattern is not
+// a valid regular expression.
+func Compile(proxy *base.SchemaProxy) (*SchemaValidator, error) {
+	if proxy == nil {
+		return nil, fmt.Errorf("unable to compile schema: no schema")
+	}
+	schema, err := proxy.BuildSchema()
+	if err != nil || schema == nil {
+		return nil, fmt.Errorf("unable to compile schema: %w", err)
+	}
+	sv := NewSchemaValidator(schema)
+	if err = sv.compile(schema, sv.location, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return sv, nil
+}
+
+// SetDialect sets the dialect used to validate values, the default detects the dialect from the document.
+func (sv *SchemaValidator) SetDialect(dialect Dialect) {
+	sv.dialect = dialect
+}
+
+// SetAccess sets the direction values are sent in, the default allows readOnly and writeOnly values to appear.
+func (sv *SchemaValidator) SetAccess(access Access) {
+	sv.access = access
+}
+
+// RegisterFormat registers a checker for a format, replacing any built-in checker. A nil checker stops the format
+// from being checked.
+func (sv *SchemaValidator) RegisterFormat(format string, checker FormatChecker) {
+	if checker == nil {
+		delete(sv.formats, format)
+		return
+	}
+	sv.formats[format] = checker
+}
+
+// Validate validates a value against the schema, returning an error for every constraint the value does not
+// satisfy. Values are any Go value decoded from JSON or YAML (maps, slices, strings, numbers, booleans and nil), or
+// built in Go from the same kinds. An empty slice is returned if the value is valid.
+func (sv *SchemaValidator) Validate(value any) []*ValidationError {
+	v := &validation{validator: sv, dialect: sv.dialect, location: sv.location}
+	if v.dialect == DialectDetect {
+		v.dialect = detectDialect(sv.schema)
+	}
+	v.validate(sv.schema, normalize(value))
+	return v.errors
+}
+
+// ValidateJSON decodes a JSON document and validates it against the schema. An error is returned if the document
+// is not valid JSON.
+func (sv *SchemaValidator) ValidateJSON(data []byte) ([]*ValidationError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON: %w", err)
+	}
+	return sv.Validate(value), nil
+}
+
+// Validate validates a value against a schema, returning an error for every constraint the value does not satisfy.
+// It is the same as using a SchemaValidator with the default dialect and formats.
+func Validate(schema *base.Schema, value any) []*ValidationError {
+	return NewSchemaValidator(schema).Validate(value)
+}
+
+// compile builds every schema contained in a schema, each reference is only followed once.
+func (sv *SchemaValidator) compile(schema *base.Schema, location string, seen map[string]bool) error {
+	if schema.Pattern != "" {
+		if _, err := sv.pattern(schema.Pattern); err != nil {
+			return fmt.Errorf("unable to compile schema: pattern at '%s/pattern' is not valid: %w", location, err)
+		}
+	}
+	for pattern := range schema.PatternProperties {
+		if _, err := sv.pattern(pattern); err != nil {
+			return fmt.Errorf("unable to compile schema: pattern property at '%s/patternProperties' is not valid: %w",
+				location, err)
+		}
+	}
+	for _, child := range children(schema) {
+		childLocation := location + child.segments
+		if child.proxy.IsReference() {
+			childLocation = child.proxy.GetReference()
+			if seen[childLocation] {
+				continue
+			}
+			seen[childLocation] = true
+		}
+		s, err := child.proxy.BuildSchema()
+		if err != nil || s == nil {
+			return fmt.Errorf("unable to compile schema: schema at '%s' cannot be built: %w", childLocation, err)
+		}
+		if err = sv.compile(s, childLocation, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pattern returns a compiled regular expression, patterns are only compiled once.
+func (sv *SchemaValidator) pattern(pattern string) (*regexp.Regexp, error) {
+	if rx, ok := sv.patterns.Load(pattern); ok {
+		return rx.(*regexp.Regexp), nil
+	}
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sv.patterns.Store(pattern, rx)
+	return rx, nil
+}
+
+// subschema is a schema contained in another schema, with the segments of its location.
+type subschema struct {
+	segments string
+	proxy    *base.SchemaProxy
+}
+
+// children returns every schema contained in a schema.
+func children(schema *base.Schema) []subschema {
+	var c []subschema
+	add := func(sp *base.SchemaProxy, segments ...string) {
+		if sp != nil {
+			c = append(c, subschema{segments: locationOf(segments...), proxy: sp})
+		}
+	}
+	for _, group := range []struct {
+		keyword string
+		proxies []*base.SchemaProxy
+	}{{"allOf", schema.AllOf}, {"anyOf", schema.AnyOf}, {"oneOf", schema.OneOf}, {"prefixItems", schema.PrefixItems}} {
+		for i, sp := range group.proxies {
+			add(sp, group.keyword, fmt.Sprint(i))
+		}
+	}
+	for _, group := range []struct {
+		keyword string
+		schemas map[string]*base.SchemaProxy
+	}{{"properties", schema.Properties}, {"patternProperties", schema.PatternProperties},
+		{"dependentSchemas", schema.DependentSchemas}} {
+		for _, name := range sortedKeys(group.schemas) {
+			add(group.schemas[name], group.keyword, name)
+		}
+	}
+	if schema.Items != nil && schema.Items.IsA() {
+		add(schema.Items.A, "items")
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+		add(schema.AdditionalProperties.A, "additionalProperties")
+	}
+	if schema.UnevaluatedProperties != nil && schema.UnevaluatedProperties.IsA() {
+		add(schema.UnevaluatedProperties.A, "unevaluatedProperties")
+	}
+	add(schema.Not, "not")
+	add(schema.Contains, "contains")
+	add(schema.If, "if")
+	add(schema.Then, "then")
+	add(schema.Else, "else")
+	add(schema.PropertyNames, "propertyNames")
+	add(schema.UnevaluatedItems, "unevaluatedItems")
+	return c
+}
+
+// evaluated are the properties and items of a value evaluated by a schema and the schemas it applies, used by
+// unevaluatedProperties and unevaluatedItems.
+type evaluated struct {
+	properties map[string]bool
+	items      int // the number of leading items evaluated.
+	allItems   bool
+	indices    map[int]bool
+}
+
+func newEvaluated() *evaluated {
+	return &evaluated{properties: make(map[string]bool), indices: make(map[int]bool)}
+}
+
+func (e *evaluated) merge(other *evaluated) {
+	for name := range other.properties {
+		e.properties[name] = true
+	}
+	for i := range other.indices {
+		e.indices[i] = true
+	}
+	if other.items > e.items {
+		e.items = other.items
+	}
+	e.allItems = e.allItems || other.allItems
+}
+
+func (e *evaluated) item(i int) bool {
+	return e.allItems || i < e.items || e.indices[i]
+}
+
+// validation holds the state of validating a single value.
+type validation struct {
+	validator *SchemaValidator
+	dialect   Dialect
+	errors    []*ValidationError
+	path      []string
+	location  string
+}
+
+func (v *validation) fail(schema *base.Schema, keyword, format string, args ...any) {
+	line, column := keywordPosition(schema, keyword)
+	v.errors = append(v.errors, &ValidationError{
+		InstancePath:   strings.Join(v.path, ""),
+		SchemaLocation: v.location + locationOf(keyword),
+		Keyword:        keyword,
+		Message:        fmt.Sprintf(format, args...),
+		Line:           line,
+		Column:         column,
+	})
+}
+
+// push adds a property name or array index to the instance path, returning a func to remove it.
+func (v *validation) push(segment string) func() {
+	v.path = append(v.path, "/"+escapePointer(segment))
+	return func() { v.path = v.path[:len(v.path)-1] }
+}
+
+// subschema validates a value against a schema contained in the current schema, at the location of the segments.
+// The location of a referenced schema is its reference.
+func (v *validation) subschema(sp *base.SchemaProxy, value any, segments ...string) *evaluated {
+	if sp == nil {
+		return newEvaluated()
+	}
+	location := v.location
+	if sp.IsReference() {
+		v.location = sp.GetReference()
+	} else {
+		v.location += locationOf(segments...)
+	}
+	defer func() { v.location = location }()
+	return v.validate(sp.Schema(), value)
+}
+
+// matches returns true if a value is valid against a schema, without recording any errors.
+func (v *validation) matches(sp *base.SchemaProxy, value any, segments ...string) (bool, *evaluated) {
+	sub := &validation{validator: v.validator, dialect: v.dialect, path: v.path, location: v.location}
+	e := sub.subschema(sp, value, segments...)
+	return len(sub.errors) == 0, e
+}
+
+// modern returns true if the keywords added by JSON Schema 2020-12 are used.
+func (v *validation) modern() bool {
+	return v.dialect != DialectOpenAPI30
+}
+
+func (v *validation) validate(schema *base.Schema, value any) *evaluated {
+	e := newEvaluated()
+	if schema == nil {
+		return e
+	}
+	if value == nil && v.dialect != DialectJSONSchema202012 && schema.Nullable != nil && *schema.Nullable {
+		return e
+	}
+	switch {
+	case schema.WriteOnly && v.validator.access == AccessRead:
+		v.fail(schema, "writeOnly", "value is write-only and must not be read")
+	case schema.ReadOnly && v.validator.access == AccessWrite:
+		v.fail(schema, "readOnly", "value is read-only and must not be written")
+	}
+	if len(schema.Type) > 0 && !matchesType(schema.Type, value) {
+		v.fail(schema, "type", "expected %s, but got %s", strings.Join(schema.Type, " or "), typeOf(value))
+		return e
+	}
+	if c, ok := constValue(schema); ok && !equal(c, value) {
+		v.fail(schema, "const", "value must be %s", display(c))
+	}
+	if enum := enumValues(schema); len(enum) > 0 {
+		found := false
+		for _, item := range enum {
+			if equal(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values := make([]string, len(enum))
+			for i, item := range enum {
+				values[i] = display(item)
+			}
+			v.fail(schema, "enum", "value must be one of %s", strings.Join(values, ", "))
+		}
+	}
+	if checker := v.validator.formats[schema.Format]; checker != nil && !checker(value) {
+		v.fail(schema, "format", "value %s is not a valid '%s'", display(value), schema.Format)
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(schema, val)
+	case float64:
+		v.validateNumber(schema, val)
+	case []any:
+		v.validateArray(schema, val, e)
+	case map[string]any:
+		v.validateObject(schema, val, e)
+	}
+
+	for i, sp := range schema.AllOf {
+		e.merge(v.subschema(sp, value, "allOf", fmt.Sprint(i)))
+	}
+	if len(schema.AnyOf) > 0 {
+		found := false
+		for i, sp := range schema.AnyOf {
+			// every schema is checked, as the properties and items evaluated by each match are collected.
+			if ok, evaluated := v.matches(sp, value, "anyOf", fmt.Sprint(i)); ok {
+				found = true
+				e.merge(evaluated)
+			}
+		}
+		if !found {
+			v.fail(schema, "anyOf", "value does not match any of the anyOf schemas")
+		}
+	}
+	if len(schema.OneOf) > 0 {
+		count := 0
+		for i, sp := range schema.OneOf {
+			if ok, evaluated := v.matches(sp, value, "oneOf", fmt.Sprint(i)); ok {
+				count++
+				e.merge(evaluated)
+			}
+		}
+		if count != 1 {
+			v.fail(schema, "oneOf", "value must match exactly one oneOf schema, but matches %d", count)
+		}
+	}
+	if schema.Not != nil {
+		if ok, _ := v.matches(schema.Not, value, "not"); ok {
+			v.fail(schema, "not", "value must not match the 'not' schema")
+		}
+	}
+	if v.modern() && schema.If != nil {
+		if ok, evaluated := v.matches(schema.If, value, "if"); ok {
+			e.merge(evaluated)
+			if schema.Then != nil {
+				e.merge(v.subschema(schema.Then, value, "then"))
+			}
+		} else if schema.Else != nil {
+			e.merge(v.subschema(schema.Else, value, "else"))
+		}
+	}
+
+	// unevaluated keywords are applied last, once every other keyword has evaluated the value.
+	if v.modern() {
+		switch val := value.(type) {
+		case []any:
+			v.validateUnevaluatedItems(schema, val, e)
+		case map[string]any:
+			v.validateUnevaluatedProperties(schema, val, e)
+		}
+	}
+	return e
+}
+
+func (v *validation) validateString(schema *base.Schema, value string) {
+	length := int64(utf8.RuneCountInString(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(schema, "minLength", "length must be at least %d, but is %d", *schema.MinLength, length)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(schema, "maxLength", "length must be at most %d, but is %d", *schema.MaxLength, length)
+	}
+	if schema.Pattern != "" {
+		rx, err := v.validator.pattern(schema.Pattern)
+		if err != nil {
+			v.fail(schema, "pattern", "pattern '%s' is not a valid regular expression", schema.Pattern)
+		} else if !rx.MatchString(value) {
+			v.fail(schema, "pattern", "value '%s' does not match pattern '%s'", value, schema.Pattern)
+		}
+	}
+}
+
+// validateNumber checks the range of a number. OpenAPI 3.0 exclusive minimums and maximums are booleans, modifying
+// the minimum and maximum, JSON Schema 2020-12 exclusive minimums and maximums are numbers.
+func (v *validation) validateNumber(schema *base.Schema, value float64) {
+	legacy := v.dialect != DialectJSONSchema202012
+	if schema.Minimum != nil {
+		exclusive := legacy && schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() &&
+			schema.ExclusiveMinimum.A
+		if value < *schema.Minimum || (exclusive && value == *schema.Minimum) {
+			v.fail(schema, "minimum", "value must be %s %v, but is %v", comparison(">", exclusive),
+				*schema.Minimum, value)
+		}
+	}
+	if v.modern() && schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() &&
+		value <= schema.ExclusiveMinimum.B {
+		v.fail(schema, "exclusiveMinimum", "value must be > %v, but is %v", schema.ExclusiveMinimum.B, value)
+	}
+	if schema.Maximum != nil {
+		exclusive := legacy && schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() &&
+			schema.ExclusiveMaximum.A
+		if value > *schema.Maximum || (exclusive && value == *schema.Maximum) {
+			v.fail(schema, "maximum", "value must be %s %v, but is %v", comparison("<", exclusive),
+				*schema.Maximum, value)
+		}
+	}
+	if v.modern() && schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() &&
+		value >= schema.ExclusiveMaximum.B {
+		v.fail(schema, "exclusiveMaximum", "value must be < %v, but is %v", schema.ExclusiveMaximum.B, value)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		q := value / *schema.MultipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(schema, "multipleOf", "value must be a multiple of %v, but is %v", *schema.MultipleOf, value)
+		}
+	}
+}
+
+func comparison(op string, exclusive bool) string {
+	if exclusive {
+		return op
+	}
+	return op + "="
+}
+
+func (v *validation) validateArray(schema *base.Schema, value []any, e *evaluated) {
+	count := int64(len(value))
+	if schema.MinItems != nil && count < *schema.MinItems {
+		v.fail(schema, "minItems", "array must have at least %d items, but has %d", *schema.MinItems, count)
+	}
+	if schema.MaxItems != nil && count > *schema.MaxItems {
+		v.fail(schema, "maxItems", "array must have at most %d items, but has %d", *schema.MaxItems, count)
+	}
+	if schema.UniqueItems != nil && *schema.UniqueItems {
+		seen := make(map[string]int)
+		for i, item := range value {
+			key := display(item)
+			if j, ok := seen[key]; ok {
+				v.fail(schema, "uniqueItems", "items %d and %d are equal", j, i)
+				break
+			}
+			seen[key] = i
+		}
+	}
+
+	// items only applies to the items after prefixItems.
+	start := 0
+	if v.modern() {
+		for i, sp := range schema.PrefixItems {
+			if i >= len(value) {
+				break
+			}
+			pop := v.push(fmt.Sprint(i))
+			v.subschema(sp, value[i], "prefixItems", fmt.Sprint(i))
+			pop()
+			start = i + 1
+		}
+		e.items = start
+	}
+	if schema.Items != nil {
+		if schema.Items.IsA() {
+			for i := start; i < len(value); i++ {
+				pop := v.push(fmt.Sprint(i))
+				v.subschema(schema.Items.A, value[i], "items")
+				pop()
+			}
+			e.allItems = true
+		} else if !schema.Items.B && len(value) > start {
+			if start == 0 {
+				v.fail(schema, "items", "array must be empty")
+			} else {
+				v.fail(schema, "items", "array must have at most %d items, but has %d", start, count)
+			}
+		}
+	}
+
+	if v.modern() && schema.Contains != nil {
+		matched := int64(0)
+		for i, item := range value {
+			pop := v.push(fmt.Sprint(i))
+			if ok, _ := v.matches(schema.Contains, item, "contains"); ok {
+				matched++
+				e.indices[i] = true
+			}
+			pop()
+		}
+		minimum := int64(1)
+		if schema.MinContains != nil {
+			minimum = *schema.MinContains
+		}
+		if matched < minimum {
+			v.fail(schema, "contains", "array must contain at least %d matching items, but has %d", minimum, matched)
+		}
+		if schema.MaxContains != nil && matched > *schema.MaxContains {
+			v.fail(schema, "maxContains", "array must contain at most %d matching items, but has %d",
+				*schema.MaxContains, matched)
+		}
+	}
+}
+
+func (v *validation) validateUnevaluatedItems(schema *base.Schema, value []any, e *evaluated) {
+	if schema.UnevaluatedItems == nil {
+		return
+	}
+	for i, item := range value {
+		if e.item(i) {
+			continue
+		}
+		pop := v.push(fmt.Sprint(i))
+		v.subschema(schema.UnevaluatedItems, item, "unevaluatedItems")
+		pop()
+	}
+	e.allItems = true
+}
+
+func (v *validation) validateObject(schema *base.Schema, value map[string]any, e *evaluated) {
+	count := int64(len(value))
+	if schema.MinProperties != nil && count < *schema.MinProperties {
+		v.fail(schema, "minProperties", "object must have at least %d properties, but has %d",
+			*schema.MinProperties, count)
+	}
+	if schema.MaxProperties != nil && count > *schema.MaxProperties {
+		v.fail(schema, "maxProperties", "object must have at most %d properties, but has %d",
+			*schema.MaxProperties, count)
+	}
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			v.fail(schema, "required", "missing required property '%s'", name)
+		}
+	}
+
+	for _, name := range sortedKeys(value) {
+		pop := v.push(name)
+		if v.modern() && schema.PropertyNames != nil {
+			v.subschema(schema.PropertyNames, name, "propertyNames")
+		}
+		if sp, ok := schema.Properties[name]; ok {
+			e.properties[name] = true
+			v.subschema(sp, value[name], "properties", name)
+		}
+		for _, pattern := range sortedKeys(schema.PatternProperties) {
+			if rx, err := v.validator.pattern(pattern); err == nil && rx.MatchString(name) {
+				e.properties[name] = true
+				v.subschema(schema.PatternProperties[pattern], value[name], "patternProperties", pattern)
+			}
+		}
+		if !e.properties[name] && schema.AdditionalProperties != nil {
+			e.properties[name] = true
+			if schema.AdditionalProperties.IsA() {
+				v.subschema(schema.AdditionalProperties.A, value[name], "additionalProperties")
+			} else if !schema.AdditionalProperties.B {
+				pop()
+				v.fail(schema, "additionalProperties", "property '%s' is not allowed", name)
+				continue
+			}
+		}
+		pop()
+	}
+
+	if v.modern() {
+		for _, name := range sortedKeys(schema.DependentSchemas) {
+			if _, ok := value[name]; ok {
+				e.merge(v.subschema(schema.DependentSchemas[name], value, "dependentSchemas", name))
+			}
+		}
+	}
+}
+
+func (v *validation) validateUnevaluatedProperties(schema *base.Schema, value map[string]any, e *evaluated) {
+	if schema.UnevaluatedProperties == nil {
+		return
+	}
+	for _, name := range sortedKeys(value) {
+		if e.properties[name] {
+			continue
+		}
+		e.properties[name] = true
+		if schema.UnevaluatedProperties.IsA() {
+			pop := v.push(name)
+			v.subschema(schema.UnevaluatedProperties.A, value[name], "unevaluatedProperties")
+			pop()
+		} else if !schema.UnevaluatedProperties.B {
+			v.fail(schema, "unevaluatedProperties", "property '%s' is not allowed", name)
+		}
+	}
+}
+
+// detectDialect returns the dialect of the document a schema was read from, or DialectDetect if it is not known.
+func detectDialect(schema *base.Schema) Dialect {
+	if schema == nil || schema.GoLow() == nil {
+		return DialectDetect
+	}
+	if strings.Contains(schema.SchemaTypeRef, "2020-12") {
+		return DialectJSONSchema202012
+	}
+	idx := schema.GoLow().Index
+	if idx == nil || idx.GetConfig() == nil || idx.GetConfig().SpecInfo == nil {
+		return DialectDetect
+	}
+	info := idx.GetConfig().SpecInfo
+	switch {
+	case info.SpecFormat == datamodel.OAS2 || strings.HasPrefix(info.Version, "3.0"):
+		return DialectOpenAPI30
+	case strings.HasPrefix(info.Version, "3.1"):
+		return DialectJSONSchema202012
+	}
+	return DialectDetect
+}
+
+// keywordPosition returns the line and column of a keyword of a schema in the document, or the line and column of
+// the schema if the keyword cannot be found.
+func keywordPosition(schema *base.Schema, keyword string) (int, int) {
+	low := schema.GoLow()
+	if low == nil {
+		return 0, 0
+	}
+	// the fields of the low-level schema are named after the keywords.
+	field := reflect.ValueOf(low).Elem().FieldByName(strings.ToUpper(keyword[:1]) + keyword[1:])
+	if field.IsValid() && field.Kind() == reflect.Struct {
+		if key := field.FieldByName("KeyNode"); key.IsValid() {
+			if node, ok := key.Interface().(*yaml.Node); ok && node != nil {
+				return node.Line, node.Column
+			}
+		}
+	}
+	if low.ParentProxy != nil {
+		if node := low.ParentProxy.GetValueNode(); node != nil {
+			return node.Line, node.Column
+		}
+	}
+	return 0, 0
+}
+
+// constValue returns the const of a schema, decoded from the document when possible, because the model only keeps
+// scalar values.
+func constValue(schema *base.Schema) (any, bool) {
+	if low := schema.GoLow(); low != nil && low.Const.ValueNode != nil {
+		var c any
+		if err := low.Const.ValueNode.Decode(&c); err == nil {
+			return normalize(c), true
+		}
+	}
+	if schema.Const != nil {
+		return normalize(schema.Const), true
+	}
+	return nil, false
+}
+
+// enumValues returns the enum of a schema, decoded from the document when possible, because the model only keeps
+// scalar values.
+func enumValues(schema *base.Schema) []any {
+	if low := schema.GoLow(); low != nil && len(low.Enum.Value) == len(schema.Enum) {
+		values := make([]any, len(schema.Enum))
+		for i, e := range low.Enum.Value {
+			values[i] = normalize(schema.Enum[i])
+			if e.ValueNode != nil {
+				var decoded any
+				if err := e.ValueNode.Decode(&decoded); err == nil {
+					values[i] = normalize(decoded)
+				}
+			}
+		}
+		return values
+	}
+	values := make([]any, len(schema.Enum))
+	for i, e := range schema.Enum {
+		values[i] = normalize(e)
+	}
+	return values
+}
+
+// matchesType returns true if a value is one of the types, integers are also numbers.
+func matchesType(types []string, value any) bool {
+	t := typeOf(value)
+	for _, allowed := range types {
+		if allowed == t || (allowed == numberType && t == integerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a normalized value.
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return nullType
+	case bool:
+		return booleanType
+	case string:
+		return stringType
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return integerType
+		}
+		return numberType
+	case []any:
+		return arrayType
+	case map[string]any:
+		return objectType
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// normalize converts a value decoded from JSON, YAML or built in Go into the types used by validation: numbers
+// become float64, slices become []any and maps become map[string]any.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, float64:
+		return v
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalize(item)
+		}
+		return items
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = normalize(item)
+		}
+		return m
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = normalize(rv.Index(i).Interface())
+		}
+		return items
+	case reflect.Map:
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = normalize(iter.Value().Interface())
+		}
+		return m
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	}
+	return value
+}
+
+func equal(a, b any) bool {
+	return display(a) == display(b)
+}
+
+// display renders a value as JSON, for messages and comparing values.
+func display(value any) string {
+	b, err := json.Marshal(normalize(value))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// locationOf returns the JSON pointer of the segments of a schema location, to append to a location.
+func locationOf(segments ...string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteString("/" + escapePointer(s))
+	}
+	return b.String()
+}
+
+// escapePointer escapes a JSON pointer segment.
+func escapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
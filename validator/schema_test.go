@@ -0,0 +1,419 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func getSchema(t *testing.T, schema string) *base.Schema {
+	var node yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(schema), &node))
+	sp := new(lowbase.SchemaProxy)
+	assert.NoError(t, sp.Build(nil, node.Content[0], nil))
+	return base.NewSchemaProxy(&low.NodeReference[*lowbase.SchemaProxy]{Value: sp}).Schema()
+}
+
+func keywords(errs []*ValidationError) []string {
+	var k []string
+	for _, e := range errs {
+		k = append(k, e.InstancePath+":"+e.Keyword)
+	}
+	return k
+}
+
+func TestValidate_Types(t *testing.T) {
+	schema := getSchema(t, `type: integer`)
+	assert.Empty(t, Validate(schema, 1))
+	assert.Empty(t, Validate(schema, int64(1)))
+	assert.Empty(t, Validate(schema, 2.0))
+	assert.Equal(t, []string{":type"}, keywords(Validate(schema, 1.5)))
+	assert.Equal(t, "validation failed at '/' (type): expected integer, but got string", Validate(schema, "1")[0].Error())
+
+	assert.Empty(t, Validate(getSchema(t, `type: number`), 1))
+	assert.Empty(t, Validate(getSchema(t, `type: [string, "null"]`), nil))
+	assert.Empty(t, Validate(getSchema(t, "type: string\nnullable: true"), nil))
+	assert.NotEmpty(t, Validate(getSchema(t, `type: string`), nil))
+	assert.Empty(t, Validate(getSchema(t, `type: boolean`), true))
+	assert.Empty(t, Validate(getSchema(t, `type: array`), []string{"a"}))
+	assert.Empty(t, Validate(getSchema(t, `type: object`), map[string]int{"a": 1}))
+}
+
+func TestValidate_EnumConst(t *testing.T) {
+	schema := getSchema(t, `enum: [1, two, {three: 3}]`)
+	assert.Empty(t, Validate(schema, 1.0))
+	assert.Empty(t, Validate(schema, "two"))
+	assert.Empty(t, Validate(schema, map[string]any{"three": 3}))
+	errs := Validate(schema, "four")
+	assert.Equal(t, `value must be one of 1, "two", {"three":3}`, errs[0].Message)
+
+	assert.Empty(t, Validate(getSchema(t, `const: pb33f`), "pb33f"))
+	assert.Equal(t, []string{":const"}, keywords(Validate(getSchema(t, `const: pb33f`), "pb33g")))
+}
+
+func TestValidate_String(t *testing.T) {
+	schema := getSchema(t, `type: string
+minLength: 2
+maxLength: 4
+pattern: '^[a-z]+$'`)
+	assert.Empty(t, Validate(schema, "abc"))
+	assert.Equal(t, []string{":minLength", ":pattern"}, keywords(Validate(schema, "A")))
+	assert.Equal(t, []string{":maxLength"}, keywords(Validate(schema, "abcde")))
+	assert.Equal(t, []string{":pattern"}, keywords(Validate(getSchema(t, `pattern: '['`), "a")))
+}
+
+func TestValidate_Number(t *testing.T) {
+	schema := getSchema(t, `type: number
+minimum: 1
+maximum: 10
+multipleOf: 0.5`)
+	assert.Empty(t, Validate(schema, 1))
+	assert.Empty(t, Validate(schema, 9.5))
+	assert.Equal(t, []string{":minimum"}, keywords(Validate(schema, 0.5)))
+	assert.Equal(t, []string{":maximum"}, keywords(Validate(schema, 10.5)))
+	assert.Equal(t, []string{":multipleOf"}, keywords(Validate(schema, 1.2)))
+
+	// OpenAPI 3.0 boolean exclusives.
+	schema = getSchema(t, `minimum: 1
+exclusiveMinimum: true
+maximum: 10
+exclusiveMaximum: true`)
+	assert.Equal(t, []string{":minimum"}, keywords(Validate(schema, 1)))
+	assert.Equal(t, []string{":maximum"}, keywords(Validate(schema, 10)))
+	assert.Equal(t, "value must be > 1, but is 1", Validate(schema, 1)[0].Message)
+
+	// OpenAPI 3.1 numeric exclusives.
+	schema = getSchema(t, `exclusiveMinimum: 1
+exclusiveMaximum: 10`)
+	assert.Empty(t, Validate(schema, 5))
+	assert.Equal(t, []string{":exclusiveMinimum"}, keywords(Validate(schema, 1)))
+	assert.Equal(t, []string{":exclusiveMaximum"}, keywords(Validate(schema, 10)))
+}
+
+func TestValidate_Array(t *testing.T) {
+	schema := getSchema(t, `type: array
+minItems: 1
+maxItems: 3
+uniqueItems: true
+items:
+  type: integer`)
+	assert.Empty(t, Validate(schema, []any{1, 2}))
+	assert.Equal(t, []string{":minItems"}, keywords(Validate(schema, []any{})))
+	assert.Equal(t, []string{":maxItems"}, keywords(Validate(schema, []any{1, 2, 3, 4})))
+	assert.Equal(t, []string{":uniqueItems"}, keywords(Validate(schema, []any{1, 1.0})))
+	assert.Equal(t, []string{"/1:type"}, keywords(Validate(schema, []any{1, "a"})))
+	assert.Equal(t, []string{":items"}, keywords(Validate(getSchema(t, `items: false`), []any{1})))
+}
+
+func TestValidate_Object(t *testing.T) {
+	schema := getSchema(t, `type: object
+required: [name]
+minProperties: 1
+maxProperties: 3
+properties:
+  name:
+    type: string
+  tags/all:
+    type: array
+    items:
+      type: string
+patternProperties:
+  '^x-':
+    type: integer
+additionalProperties: false`)
+	assert.Empty(t, Validate(schema, map[string]any{"name": "pb33f", "x-rank": 1}))
+	assert.Equal(t, []string{":minProperties", ":required"}, keywords(Validate(schema, map[string]any{})))
+	assert.Equal(t, []string{"/name:type", "/tags~1all/0:type", "/x-rank:type"},
+		keywords(Validate(schema, map[string]any{"name": 1, "tags/all": []any{1}, "x-rank": "a"})))
+	errs := Validate(schema, map[string]any{"name": "a", "other": 1})
+	assert.Equal(t, []string{":additionalProperties"}, keywords(errs))
+	assert.Equal(t, "property 'other' is not allowed", errs[0].Message)
+	assert.Equal(t, []string{":maxProperties"},
+		keywords(Validate(schema, map[string]any{"name": "a", "x-a": 1, "x-b": 2, "x-c": 3})))
+
+	schema = getSchema(t, `additionalProperties:
+  type: integer`)
+	assert.Equal(t, []string{"/a:type"}, keywords(Validate(schema, map[string]any{"a": "b"})))
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	schema := getSchema(t, `allOf:
+  - required: [a]
+  - required: [b]`)
+	assert.Empty(t, Validate(schema, map[string]any{"a": 1, "b": 2}))
+	assert.Equal(t, []string{":required"}, keywords(Validate(schema, map[string]any{"a": 1})))
+
+	schema = getSchema(t, `anyOf:
+  - type: string
+  - type: integer`)
+	assert.Empty(t, Validate(schema, 1))
+	assert.Equal(t, []string{":anyOf"}, keywords(Validate(schema, true)))
+
+	schema = getSchema(t, `oneOf:
+  - type: number
+  - type: integer`)
+	assert.Empty(t, Validate(schema, 1.5))
+	errs := Validate(schema, 1)
+	assert.Equal(t, "value must match exactly one oneOf schema, but matches 2", errs[0].Message)
+
+	assert.Equal(t, []string{":not"}, keywords(Validate(getSchema(t, "not:\n  type: string"), "a")))
+	assert.Empty(t, Validate(nil, "anything"))
+}
+
+func TestValidate_Format(t *testing.T) {
+	schema := getSchema(t, `type: string
+format: date-time`)
+	assert.Empty(t, Validate(schema, "2023-06-01T12:00:00Z"))
+	errs := Validate(schema, "yesterday")
+	assert.Equal(t, []string{":format"}, keywords(errs))
+	assert.Equal(t, `value "yesterday" is not a valid 'date-time'`, errs[0].Message)
+	assert.Empty(t, Validate(getSchema(t, `format: made-up`), "anything"))
+}
+
+func TestSchemaValidator_RegisterFormat(t *testing.T) {
+	sv := NewSchemaValidator(getSchema(t, `format: burger`))
+	sv.RegisterFormat("burger", func(value any) bool { return value == "big-mac" })
+	assert.Empty(t, sv.Validate("big-mac"))
+	assert.Equal(t, []string{":format"}, keywords(sv.Validate("whopper")))
+
+	sv = NewSchemaValidator(getSchema(t, `format: email`))
+	sv.RegisterFormat("email", nil)
+	assert.Empty(t, sv.Validate("not an email"))
+}
+
+func TestSchemaValidator_Dialect(t *testing.T) {
+	schema := getSchema(t, `type: string
+nullable: true
+minimum: 1
+exclusiveMinimum: true
+prefixItems:
+  - type: string`)
+	sv := NewSchemaValidator(schema)
+	sv.SetDialect(DialectOpenAPI30)
+	assert.Empty(t, sv.Validate(nil))
+	sv.SetDialect(DialectJSONSchema202012)
+	assert.Equal(t, []string{":type"}, keywords(sv.Validate(nil)))
+
+	schema = getSchema(t, `minimum: 1
+exclusiveMinimum: true
+exclusiveMaximum: 10
+prefixItems:
+  - type: string`)
+	sv = NewSchemaValidator(schema)
+	sv.SetDialect(DialectOpenAPI30)
+	assert.Equal(t, []string{":minimum"}, keywords(sv.Validate(1)))
+	assert.Empty(t, sv.Validate(10))
+	assert.Empty(t, sv.Validate([]any{1}))
+	sv.SetDialect(DialectJSONSchema202012)
+	assert.Empty(t, sv.Validate(1))
+	assert.Equal(t, []string{":exclusiveMaximum"}, keywords(sv.Validate(10)))
+	assert.Equal(t, []string{"/0:type"}, keywords(sv.Validate([]any{1})))
+}
+
+func TestSchemaValidator_Access(t *testing.T) {
+	schema := getSchema(t, `type: object
+properties:
+  id:
+    type: integer
+    readOnly: true
+  password:
+    type: string
+    writeOnly: true`)
+	value := map[string]any{"id": 1, "password": "secret"}
+	sv := NewSchemaValidator(schema)
+	assert.Empty(t, sv.Validate(value))
+
+	sv.SetAccess(AccessRead)
+	errs := sv.Validate(value)
+	assert.Equal(t, []string{"/password:writeOnly"}, keywords(errs))
+	assert.Equal(t, "#/properties/password/writeOnly", errs[0].SchemaLocation)
+	assert.Equal(t, "validation failed at '/password' (writeOnly): value is write-only and must not be read",
+		errs[0].Error())
+	assert.Equal(t, 8, errs[0].Line)
+
+	sv.SetAccess(AccessWrite)
+	assert.Equal(t, []string{"/id:readOnly"}, keywords(sv.Validate(value)))
+}
+
+func TestValidate_PrefixItemsContains(t *testing.T) {
+	schema := getSchema(t, `prefixItems:
+  - type: string
+  - type: integer
+items: false`)
+	assert.Empty(t, Validate(schema, []any{"a", 1}))
+	assert.Equal(t, []string{"/1:type"}, keywords(Validate(schema, []any{"a", "b"})))
+	errs := Validate(schema, []any{"a", 1, true})
+	assert.Equal(t, "array must have at most 2 items, but has 3", errs[0].Message)
+
+	schema = getSchema(t, `contains:
+  type: integer
+maxContains: 2`)
+	assert.Empty(t, Validate(schema, []any{"a", 1}))
+	assert.Equal(t, []string{":contains"}, keywords(Validate(schema, []any{"a"})))
+	assert.Equal(t, []string{":maxContains"}, keywords(Validate(schema, []any{1, 2, 3})))
+	assert.Empty(t, Validate(getSchema(t, "contains:\n  type: integer\nminContains: 0"), []any{"a"}))
+
+	schema = getSchema(t, `prefixItems:
+  - type: string
+contains:
+  type: integer
+unevaluatedItems:
+  type: string`)
+	assert.Empty(t, Validate(schema, []any{"a", 1, 2}))
+	errs = Validate(schema, []any{"a", true, 1})
+	assert.Equal(t, []string{"/1:type"}, keywords(errs))
+	assert.Equal(t, "#/unevaluatedItems/type", errs[0].SchemaLocation)
+}
+
+func TestValidate_Conditionals(t *testing.T) {
+	schema := getSchema(t, `if:
+  properties:
+    kind:
+      const: burger
+  required: [kind]
+then:
+  required: [patties]
+else:
+  required: [size]
+dependentSchemas:
+  sauce:
+    required: [sauceType]
+propertyNames:
+  maxLength: 9`)
+	assert.Empty(t, Validate(schema, map[string]any{"kind": "burger", "patties": 2}))
+	assert.Equal(t, []string{":required"}, keywords(Validate(schema, map[string]any{"kind": "burger"})))
+	assert.Equal(t, []string{":required"}, keywords(Validate(schema, map[string]any{"kind": "fries"})))
+	assert.Equal(t, "missing required property 'sauceType'",
+		Validate(schema, map[string]any{"size": 1, "sauce": "ketchup"})[0].Message)
+	assert.Equal(t, []string{"/doubleSauce:maxLength"},
+		keywords(Validate(schema, map[string]any{"size": 1, "doubleSauce": true})))
+}
+
+func TestValidate_UnevaluatedProperties(t *testing.T) {
+	schema := getSchema(t, `allOf:
+  - properties:
+      name:
+        type: string
+anyOf:
+  - properties:
+      patties:
+        type: integer
+  - properties:
+      fries:
+        type: boolean
+dependentSchemas:
+  sauce:
+    properties:
+      sauceType:
+        type: string
+properties:
+  sauce:
+    type: boolean
+unevaluatedProperties: false`)
+	assert.Empty(t, Validate(schema, map[string]any{"name": "big", "patties": 2, "sauce": true, "sauceType": "bbq"}))
+	errs := Validate(schema, map[string]any{"name": "big", "sauceType": "bbq", "pickles": true})
+	assert.Equal(t, []string{":unevaluatedProperties", ":unevaluatedProperties"}, keywords(errs))
+	assert.Equal(t, "property 'pickles' is not allowed", errs[0].Message)
+	assert.Equal(t, "property 'sauceType' is not allowed", errs[1].Message)
+
+	schema = getSchema(t, `unevaluatedProperties:
+  type: integer`)
+	assert.Equal(t, []string{"/a:type"}, keywords(Validate(schema, map[string]any{"a": "b"})))
+}
+
+func TestValidate_SchemaLocation(t *testing.T) {
+	schema := getSchema(t, `type: object
+properties:
+  name:
+    type: string
+    minLength: 3
+required: [name]`)
+	errs := Validate(schema, map[string]any{"name": "a"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/name", errs[0].InstancePath)
+	assert.Equal(t, "#/properties/name/minLength", errs[0].SchemaLocation)
+	assert.Equal(t, 5, errs[0].Line)
+	assert.Equal(t, 5, errs[0].Column)
+
+	errs = Validate(schema, map[string]any{})
+	assert.Equal(t, "#/required", errs[0].SchemaLocation)
+	assert.Equal(t, 6, errs[0].Line)
+}
+
+var compileSpec = `openapi: 3.1.0
+info:
+  title: Burgers
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          minLength: 3
+        fries:
+          $ref: '#/components/schemas/Fries'
+        related:
+          type: array
+          items:
+            $ref: '#/components/schemas/Burger'
+    Fries:
+      type: object
+      properties:
+        size:
+          type: string
+          enum: [small, large]
+        price:
+          type: number
+          exclusiveMinimum: 0
+    Broken:
+      properties:
+        name:
+          pattern: '['`
+
+func TestCompile(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(compileSpec))
+	assert.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	assert.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+
+	sv, err := Compile(schemas["Burger"])
+	assert.NoError(t, err)
+
+	assert.Empty(t, sv.Validate(map[string]any{"name": "big mac", "related": []any{map[string]any{"name": "whopper"}}}))
+
+	verrs := sv.Validate(map[string]any{"name": "big mac", "fries": map[string]any{"size": "medium", "price": 0}})
+	assert.Equal(t, []string{"/fries/price:exclusiveMinimum", "/fries/size:enum"}, keywords(verrs))
+	assert.Equal(t, "#/components/schemas/Fries/properties/price/exclusiveMinimum", verrs[0].SchemaLocation)
+	assert.Equal(t, 29, verrs[0].Line)
+	assert.Equal(t, "#/components/schemas/Fries/properties/size/enum", verrs[1].SchemaLocation)
+	assert.Equal(t, 26, verrs[1].Line)
+
+	verrs, err = sv.ValidateJSON([]byte(`{"related": [{"name": "a"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{":required", "/related/0/name:minLength"}, keywords(verrs))
+	assert.Equal(t, "#/components/schemas/Burger/properties/name/minLength", verrs[1].SchemaLocation)
+	assert.Equal(t, 14, verrs[1].Line)
+
+	_, err = sv.ValidateJSON([]byte(`{"name": `))
+	assert.Error(t, err)
+
+	_, err = Compile(schemas["Broken"])
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "'#/properties/name/pattern'")
+
+	_, err = Compile(nil)
+	assert.Error(t, err)
+}
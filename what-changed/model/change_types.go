@@ -24,6 +24,14 @@ const (
 
 	// PropertyRemoved means that a property of an object was removed
 	PropertyRemoved
+
+	// Renamed means that an object was removed from a parent object and re-added under a different name,
+	// with identical or near-identical content (for example a schema renamed from 'Pet' to 'Animal')
+	Renamed
+
+	// Moved means that an object was removed from one location and re-added at another location, with identical
+	// or near-identical content (for example a path moved from '/pets/{id}' to '/pets/{petId}')
+	Moved
 )
 
 // WhatChanged is a summary object that contains a high level summary of everything changed.
@@ -90,6 +98,9 @@ type Change struct {
 
 	// NewObject represents the new object that has been modified.
 	NewObject any `json:"-" yaml:"-"`
+
+	// equivalentReference is set when a schema is swapped between a $ref and an inline schema, and both are the same.
+	equivalentReference bool
 }
 
 // PropertyChanges holds a slice of Change pointers
@@ -0,0 +1,212 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/low/v3"
+)
+
+// ComparisonOptions controls which changes are reported when comparing documents. By default, every change
+// is reported. Each option removes a class of purely cosmetic changes from the results, which is useful when specs
+// are generated and churn on every build, hiding the real changes.
+type ComparisonOptions struct {
+
+	// IgnoreDescriptions will ignore any changes made to descriptions and summaries.
+	IgnoreDescriptions bool
+
+	// IgnoreDescriptionWhitespace will only ignore changes made to descriptions and summaries if the change is
+	// whitespace only (re-wrapping lines, trailing spaces, indentation etc.). Has no effect if IgnoreDescriptions is set.
+	IgnoreDescriptionWhitespace bool
+
+	// IgnoreExamples will ignore any changes made to example values (example, examples and components/examples).
+	IgnoreExamples bool
+
+	// IgnoreExtensions will ignore changes made to any extension with a name matching the pattern,
+	// for example `^x-generated-`. If nil, no extensions are ignored.
+	IgnoreExtensions *regexp.Regexp
+
+	// IgnoreEquivalentReferences will ignore a schema being swapped from a $ref to an inline schema (or vice versa)
+	// when the referenced schema is identical to the inline schema.
+	IgnoreEquivalentReferences bool
+}
+
+// IgnoreCosmeticChanges returns ComparisonOptions that will ignore all cosmetic changes. Changes to descriptions and
+// summaries, examples, equivalent references and any extension matching extensionPattern are ignored.
+// If extensionPattern is empty, all extensions are compared.
+func IgnoreCosmeticChanges(extensionPattern string) (*ComparisonOptions, error) {
+	opts := &ComparisonOptions{
+		IgnoreDescriptions:         true,
+		IgnoreExamples:             true,
+		IgnoreEquivalentReferences: true,
+	}
+	if extensionPattern != "" {
+		rx, err := regexp.Compile(extensionPattern)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile extension pattern '%s': %s", extensionPattern, err.Error())
+		}
+		opts.IgnoreExtensions = rx
+	}
+	return opts, nil
+}
+
+// CompareDocumentsWithOptions will compare any two OpenAPI documents (either Swagger or OpenAPI) and return a
+// pointer to DocumentChanges that outlines everything that was found to have changed, minus anything the supplied
+// ComparisonOptions has been configured to ignore. Returns nil if nothing changed.
+func CompareDocumentsWithOptions(l, r any, options *ComparisonOptions) *DocumentChanges {
+	dc := CompareDocuments(l, r)
+	if dc == nil || options == nil {
+		return dc
+	}
+	if options.FilterChanges(dc) {
+		return nil
+	}
+	return dc
+}
+
+// FilterChanges will walk an entire tree of changes (for example DocumentChanges or SchemaChanges) and remove
+// every change that these options have been configured to ignore. Any change objects left empty are removed from
+// the tree. Returns true if there is nothing left in the tree.
+func (o *ComparisonOptions) FilterChanges(changes any) bool {
+	return o.filterValue(reflect.ValueOf(changes))
+}
+
+// filterValue walks a value that represents a change object (or a collection of change objects), filters the
+// changes inside, and returns true if the object is now empty and can be pruned.
+func (o *ComparisonOptions) filterValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+		switch ch := v.Interface().(type) {
+		case *PropertyChanges:
+			ch.Changes = o.filterChangeSlice(ch.Changes, false)
+			return len(ch.Changes) == 0
+		case *ExtensionChanges:
+			if ch.PropertyChanges == nil {
+				return true
+			}
+			ch.Changes = o.filterChangeSlice(ch.Changes, true)
+			return len(ch.Changes) == 0
+		case *ExampleChanges, *ExamplesChanges:
+			if o.IgnoreExamples {
+				return true
+			}
+		}
+		if v.Elem().Kind() != reflect.Struct {
+			return false
+		}
+		empty := true
+		s := v.Elem()
+		for i := 0; i < s.NumField(); i++ {
+			f := s.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			switch f.Kind() {
+			case reflect.Ptr, reflect.Map, reflect.Slice:
+				if o.filterValue(f) {
+					f.Set(reflect.Zero(f.Type()))
+					if s.Type().Field(i).Anonymous {
+						// keep the embedded property changes, so totals can still be calculated.
+						f.Set(reflect.ValueOf(NewPropertyChanges(nil)))
+					}
+				} else {
+					empty = false
+				}
+			}
+		}
+		return empty
+	case reflect.Map:
+		if v.IsNil() {
+			return true
+		}
+		for _, k := range v.MapKeys() {
+			if o.filterValue(v.MapIndex(k)) {
+				v.SetMapIndex(k, reflect.Value{})
+			}
+		}
+		return v.Len() == 0
+	case reflect.Slice:
+		if v.IsNil() {
+			return true
+		}
+		if v.Type().Elem().Kind() != reflect.Ptr {
+			return v.Len() == 0
+		}
+		kept := reflect.MakeSlice(v.Type(), 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if !o.filterValue(v.Index(i)) {
+				kept = reflect.Append(kept, v.Index(i))
+			}
+		}
+		if v.CanSet() {
+			if kept.Len() == 0 {
+				v.Set(reflect.Zero(v.Type()))
+			} else {
+				v.Set(kept)
+			}
+		}
+		return kept.Len() == 0
+	}
+	return false
+}
+
+func (o *ComparisonOptions) filterChangeSlice(changes []*Change, extensions bool) []*Change {
+	var kept []*Change
+	for _, c := range changes {
+		if !o.IsIgnored(c, extensions) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// IsIgnored returns true if a change should be ignored according to these options. The extension flag
+// indicates the change belongs to an extension.
+func (o *ComparisonOptions) IsIgnored(change *Change, extension bool) bool {
+	if change == nil {
+		return true
+	}
+	if extension {
+		return o.IgnoreExtensions != nil && o.IgnoreExtensions.MatchString(change.Property)
+	}
+	switch change.Property {
+	case v3.DescriptionLabel, v3.SummaryLabel:
+		if o.IgnoreDescriptions {
+			return true
+		}
+		if o.IgnoreDescriptionWhitespace && change.ChangeType == Modified {
+			return strings.Join(strings.Fields(change.Original), " ") == strings.Join(strings.Fields(change.New), " ")
+		}
+	case v3.ExampleLabel, v3.ExamplesLabel:
+		return o.IgnoreExamples
+	case v3.RefLabel:
+		if o.IgnoreEquivalentReferences && change.ChangeType == Modified {
+			return change.equivalentReference
+		}
+	}
+	return false
+}
+
+// equivalentSchemaReferences returns true if one schema is a reference and the other is inline, and both
+// render exactly the same schema.
+func equivalentSchemaReferences(l, r *base.SchemaProxy) bool {
+	if l.IsSchemaReference() == r.IsSchemaReference() {
+		return false
+	}
+	lSchema := l.Schema()
+	rSchema := r.Schema()
+	if lSchema == nil || rSchema == nil {
+		return false
+	}
+	return low.AreEqual(lSchema, rSchema)
+}
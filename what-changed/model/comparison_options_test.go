@@ -0,0 +1,267 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func compareTestDocs(t *testing.T, left, right string, opts *ComparisonOptions) *DocumentChanges {
+	lInfo, _ := datamodel.ExtractSpecInfo([]byte(left))
+	rInfo, _ := datamodel.ExtractSpecInfo([]byte(right))
+	lDoc, _ := v3.CreateDocument(lInfo)
+	rDoc, _ := v3.CreateDocument(rInfo)
+	return CompareDocumentsWithOptions(lDoc, rDoc, opts)
+}
+
+func TestCompareDocumentsWithOptions_NoOptions(t *testing.T) {
+	left := `openapi: 3.1.0
+info:
+  description: a spec`
+	right := `openapi: 3.1.0
+info:
+  description: a changed spec`
+
+	changes := compareTestDocs(t, left, right, nil)
+	assert.Equal(t, 1, changes.TotalChanges())
+}
+
+func TestCompareDocumentsWithOptions_IgnoreDescriptions(t *testing.T) {
+	left := `openapi: 3.1.0
+info:
+  description: a spec
+paths:
+  /burgers:
+    get:
+      summary: eat a burger
+      description: tasty burgers`
+	right := `openapi: 3.1.0
+info:
+  description: a changed spec
+paths:
+  /burgers:
+    get:
+      summary: eat a bigger burger
+      description: even tastier burgers`
+
+	changes := compareTestDocs(t, left, right, &ComparisonOptions{IgnoreDescriptions: true})
+	assert.Nil(t, changes)
+}
+
+func TestCompareDocumentsWithOptions_IgnoreDescriptionWhitespace(t *testing.T) {
+	left := `openapi: 3.1.0
+info:
+  description: a spec
+paths:
+  /burgers:
+    get:
+      summary: eat a burger
+      description: tasty burgers`
+	right := `openapi: 3.1.0
+info:
+  description: "a    spec  "
+paths:
+  /burgers:
+    get:
+      summary: eat a  burger
+      description: tastier burgers`
+
+	changes := compareTestDocs(t, left, right, &ComparisonOptions{IgnoreDescriptionWhitespace: true})
+	assert.Equal(t, 1, changes.TotalChanges())
+	assert.Nil(t, changes.InfoChanges)
+	assert.Equal(t, "tastier burgers", changes.GetAllChanges()[0].New)
+}
+
+func TestCompareDocumentsWithOptions_IgnoreExamples(t *testing.T) {
+	left := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              example: a burger
+              examples:
+                tasty:
+                  value: a tasty burger
+              schema:
+                type: string
+                example: cheese`
+	right := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              example: a different burger
+              examples:
+                tasty:
+                  value: an even tastier burger
+              schema:
+                type: string
+                example: pickles`
+
+	changes := compareTestDocs(t, left, right, nil)
+	assert.Equal(t, 3, changes.TotalChanges())
+
+	changes = compareTestDocs(t, left, right, &ComparisonOptions{IgnoreExamples: true})
+	assert.Nil(t, changes)
+}
+
+func TestCompareDocumentsWithOptions_IgnoreExtensions(t *testing.T) {
+	left := `openapi: 3.1.0
+x-generated-at: monday
+x-team: burgers
+info:
+  title: burgers
+  x-generated-by: robots`
+	right := `openapi: 3.1.0
+x-generated-at: tuesday
+x-team: chips
+info:
+  title: burgers
+  x-generated-by: other robots`
+
+	changes := compareTestDocs(t, left, right, &ComparisonOptions{
+		IgnoreExtensions: regexp.MustCompile(`^x-generated-`),
+	})
+	assert.Equal(t, 1, changes.TotalChanges())
+	assert.Nil(t, changes.InfoChanges)
+	assert.Equal(t, "x-team", changes.ExtensionChanges.Changes[0].Property)
+}
+
+func TestCompareDocumentsWithOptions_IgnoreEquivalentReferences(t *testing.T) {
+	left := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string`
+	right := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string`
+
+	changes := compareTestDocs(t, left, right, nil)
+	assert.Equal(t, 1, changes.TotalChanges())
+	change := changes.GetAllChanges()[0]
+	assert.Equal(t, v3.RefLabel, change.Property)
+	assert.Equal(t, "#/components/schemas/Burger", change.OriginalObject)
+	assert.IsType(t, &base.SchemaProxy{}, change.NewObject)
+
+	changes = compareTestDocs(t, left, right, &ComparisonOptions{IgnoreEquivalentReferences: true})
+	assert.Nil(t, changes)
+}
+
+func TestCompareDocumentsWithOptions_IgnoreEquivalentReferences_Different(t *testing.T) {
+	left := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object`
+	right := `openapi: 3.1.0
+paths:
+  /burgers:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: string
+components:
+  schemas:
+    Burger:
+      type: object`
+
+	changes := compareTestDocs(t, left, right, &ComparisonOptions{IgnoreEquivalentReferences: true})
+	assert.Equal(t, 1, changes.TotalChanges())
+}
+
+func TestCompareDocuments_Reordering(t *testing.T) {
+	left := `openapi: 3.1.0
+tags:
+  - name: burgers
+  - name: fries
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [name, size]
+      properties:
+        size:
+          type: string
+          enum: [small, large]`
+	right := `openapi: 3.1.0
+tags:
+  - name: fries
+  - name: burgers
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [size, name]
+      properties:
+        size:
+          type: string
+          enum: [large, small]`
+
+	// required, enum and tags are compared as sets, so reordering them is never a change.
+	assert.Nil(t, compareTestDocs(t, left, right, nil))
+}
+
+func TestIgnoreCosmeticChanges(t *testing.T) {
+	opts, err := IgnoreCosmeticChanges("^x-gen")
+	assert.NoError(t, err)
+	assert.True(t, opts.IgnoreDescriptions)
+	assert.True(t, opts.IgnoreExamples)
+	assert.True(t, opts.IgnoreEquivalentReferences)
+	assert.True(t, opts.IgnoreExtensions.MatchString("x-generated"))
+
+	_, err = IgnoreCosmeticChanges("(")
+	assert.Error(t, err)
+}
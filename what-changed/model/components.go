@@ -93,6 +93,7 @@ func CompareComponents(l, r any) *ComponentsChanges {
 			b = rDef.Schemas
 		}
 		cc.SchemaChanges = CheckMapForChanges(a, b, &changes, v2.DefinitionsLabel, CompareSchemas)
+		changes, cc.SchemaChanges = checkSchemaRenames(changes, v2.DefinitionsLabel, cc.SchemaChanges)
 	}
 
 	// Swagger Security Definitions
@@ -202,6 +203,9 @@ func CompareComponents(l, r any) *ComponentsChanges {
 				}
 			}
 		}
+
+		// look for any schemas that have been renamed, rather than removed and added.
+		changes, cc.SchemaChanges = checkSchemaRenames(changes, v3.SchemasLabel, cc.SchemaChanges)
 	}
 
 	cc.PropertyChanges = NewPropertyChanges(changes)
@@ -7,6 +7,7 @@ import (
 	"github.com/pb33f/libopenapi/datamodel/low"
 	"github.com/pb33f/libopenapi/datamodel/low/v2"
 	v3 "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"gopkg.in/yaml.v3"
 	"reflect"
 	"sync"
 )
@@ -75,11 +76,14 @@ func ComparePaths(l, r any) *PathsChanges {
 
 		lKeys := make(map[string]low.ValueReference[*v2.PathItem])
 		rKeys := make(map[string]low.ValueReference[*v2.PathItem])
+		nodes := make(map[any]*yaml.Node)
 		for k := range lPath.PathItems {
 			lKeys[k.Value] = lPath.PathItems[k]
+			nodes[lPath.PathItems[k].Value] = lPath.PathItems[k].ValueNode
 		}
 		for k := range rPath.PathItems {
 			rKeys[k.Value] = rPath.PathItems[k]
+			nodes[rPath.PathItems[k].Value] = rPath.PathItems[k].ValueNode
 		}
 
 		// run every comparison in a thread.
@@ -125,6 +129,10 @@ func ComparePaths(l, r any) *PathsChanges {
 				completedChecks++
 			}
 		}
+
+		// look for any paths that have been moved, rather than removed and added.
+		changes = checkPathMoves(changes, pathChanges, nodes)
+
 		if len(pathChanges) > 0 {
 			pc.PathItemsChanges = pathChanges
 		}
@@ -146,11 +154,14 @@ func ComparePaths(l, r any) *PathsChanges {
 
 		lKeys := make(map[string]low.ValueReference[*v3.PathItem])
 		rKeys := make(map[string]low.ValueReference[*v3.PathItem])
+		nodes := make(map[any]*yaml.Node)
 		for k := range lPath.PathItems {
 			lKeys[k.Value] = lPath.PathItems[k]
+			nodes[lPath.PathItems[k].Value] = lPath.PathItems[k].ValueNode
 		}
 		for k := range rPath.PathItems {
 			rKeys[k.Value] = rPath.PathItems[k]
+			nodes[rPath.PathItems[k].Value] = rPath.PathItems[k].ValueNode
 		}
 
 		// run every comparison in a thread.
@@ -195,6 +206,10 @@ func ComparePaths(l, r any) *PathsChanges {
 				completedChecks++
 			}
 		}
+
+		// look for any paths that have been moved, rather than removed and added.
+		changes = checkPathMoves(changes, pathChanges, nodes)
+
 		if len(pathChanges) > 0 {
 			pc.PathItemsChanges = pathChanges
 		}
@@ -0,0 +1,327 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// RenameSimilarityThreshold is the minimum structural similarity (between 0 and 1) two objects need to share
+// before an object removed under one key, and an object added under another key, are considered to be the same
+// object that has been renamed or moved.
+var RenameSimilarityThreshold = 0.8
+
+// renameMatch pairs up an ObjectRemoved change with the ObjectAdded change that it was renamed / moved to.
+type renameMatch struct {
+	removed *Change
+	added   *Change
+	score   float64
+}
+
+// findRenames will look through a slice of changes for objects removed and added under the same label, and pair them
+// up if they are identical (the same Hash()) or near-identical (structural similarity above RenameSimilarityThreshold).
+// The nodeLookup function is used to locate the yaml.Node of an original or new object, so the structure can be
+// compared. The always function can be used to force a match between two keys regardless of similarity.
+func findRenames(changes []*Change, label string, nodeLookup func(obj any) *yaml.Node,
+	always func(originalKey, newKey string) bool) []*renameMatch {
+
+	var removed, added []*Change
+	for _, c := range changes {
+		if c.Property != label {
+			continue
+		}
+		if c.ChangeType == ObjectRemoved && c.OriginalObject != nil {
+			removed = append(removed, c)
+		}
+		if c.ChangeType == ObjectAdded && c.NewObject != nil {
+			added = append(added, c)
+		}
+	}
+	if len(removed) == 0 || len(added) == 0 {
+		return nil
+	}
+
+	var candidates []*renameMatch
+	for _, l := range removed {
+		for _, r := range added {
+			var score float64
+			switch {
+			case always != nil && always(l.Original, r.New):
+				score = 2 // forced matches always win.
+			case low.GenerateHashString(l.OriginalObject) == low.GenerateHashString(r.NewObject):
+				score = 1
+			default:
+				score = NodeSimilarity(nodeLookup(l.OriginalObject), nodeLookup(r.NewObject))
+			}
+			if score >= RenameSimilarityThreshold {
+				candidates = append(candidates, &renameMatch{removed: l, added: r, score: score})
+			}
+		}
+	}
+
+	// highest scores are matched first, ties are broken by key names, so results are stable.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].removed.Original != candidates[j].removed.Original {
+			return candidates[i].removed.Original < candidates[j].removed.Original
+		}
+		return candidates[i].added.New < candidates[j].added.New
+	})
+
+	used := make(map[*Change]bool)
+	var matches []*renameMatch
+	for _, m := range candidates {
+		if used[m.removed] || used[m.added] {
+			continue
+		}
+		used[m.removed] = true
+		used[m.added] = true
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// replaceRenames swaps the removal and addition changes of every match for a single change of changeType. The
+// breaking function determines if each rename is a breaking change.
+func replaceRenames(changes []*Change, matches []*renameMatch, changeType int,
+	breaking func(m *renameMatch) bool) []*Change {
+
+	if len(matches) == 0 {
+		return changes
+	}
+	replaced := make(map[*Change]*Change)
+	for _, m := range matches {
+		ctx := new(ChangeContext)
+		if m.removed.Context != nil {
+			ctx.OriginalLine = m.removed.Context.OriginalLine
+			ctx.OriginalColumn = m.removed.Context.OriginalColumn
+		}
+		if m.added.Context != nil {
+			ctx.NewLine = m.added.Context.NewLine
+			ctx.NewColumn = m.added.Context.NewColumn
+		}
+		replaced[m.removed] = &Change{
+			Context:        ctx,
+			ChangeType:     changeType,
+			Property:       m.removed.Property,
+			Original:       m.removed.Original,
+			New:            m.added.New,
+			Breaking:       breaking(m),
+			OriginalObject: m.removed.OriginalObject,
+			NewObject:      m.added.NewObject,
+		}
+		replaced[m.added] = nil
+	}
+	var result []*Change
+	for _, c := range changes {
+		if r, ok := replaced[c]; ok {
+			if r != nil {
+				result = append(result, r)
+			}
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// NodeSimilarity returns a score between 0 and 1 that represents how structurally similar two yaml.Node trees are.
+// Each tree is flattened into a set of leaf paths and values, a score of 1 means both trees are identical, a score
+// of 0 means they share nothing at all.
+func NodeSimilarity(l, r *yaml.Node) float64 {
+	if l == nil || r == nil {
+		return 0
+	}
+	lLeaves := make(map[string]int)
+	rLeaves := make(map[string]int)
+	flattenNode(l, "", lLeaves)
+	flattenNode(r, "", rLeaves)
+
+	var shared, total int
+	for k, lc := range lLeaves {
+		rc := rLeaves[k]
+		if lc < rc {
+			shared += lc
+			total += rc
+		} else {
+			shared += rc
+			total += lc
+		}
+	}
+	for k, rc := range rLeaves {
+		if _, ok := lLeaves[k]; !ok {
+			total += rc
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(shared) / float64(total)
+}
+
+func flattenNode(node *yaml.Node, path string, leaves map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			flattenNode(n, path, leaves)
+		}
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			leaves[path+"={}"]++
+		}
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			flattenNode(node.Content[i+1], path+"/"+node.Content[i].Value, leaves)
+		}
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			leaves[path+"=[]"]++
+		}
+		for _, n := range node.Content {
+			flattenNode(n, path+"/[]", leaves)
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			flattenNode(node.Alias, path, leaves)
+		}
+	default:
+		leaves[fmt.Sprintf("%s=%s", path, node.Value)]++
+	}
+}
+
+// PathTemplatesEquivalent returns true if two path templates would match exactly the same set of URLs, meaning
+// the only difference (if any) between them is the naming of path template parameters. For example
+// `/pets/{id}` and `/pets/{petId}` are equivalent.
+func PathTemplatesEquivalent(l, r string) bool {
+	return normalizePathTemplate(l) == normalizePathTemplate(r)
+}
+
+func normalizePathTemplate(path string) string {
+	var sb strings.Builder
+	inParam := false
+	for _, c := range path {
+		switch {
+		case c == '{':
+			inParam = true
+			sb.WriteString("{}")
+		case c == '}':
+			inParam = false
+		case !inParam:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// extractPathTemplateParams returns the names of every template parameter in a path, in order.
+func extractPathTemplateParams(path string) []string {
+	var params []string
+	for {
+		s := strings.Index(path, "{")
+		if s < 0 {
+			return params
+		}
+		e := strings.Index(path[s:], "}")
+		if e < 0 {
+			return params
+		}
+		params = append(params, path[s+1:s+e])
+		path = path[s+e+1:]
+	}
+}
+
+// checkPathMoves looks for paths that were removed and then added under a different key with identical or
+// near-identical path items, and replaces each removal / addition pair with a single Moved change. Paths that only
+// differ by the names of template parameters are always considered to be moved, and are not breaking changes for
+// clients. Any differences found between the moved path items are added to pathChanges under the new path.
+func checkPathMoves(changes []*Change, pathChanges map[string]*PathItemChanges,
+	nodes map[any]*yaml.Node) []*Change {
+
+	matches := findRenames(changes, v3.PathLabel,
+		func(obj any) *yaml.Node {
+			return nodes[obj]
+		}, PathTemplatesEquivalent)
+
+	for _, m := range matches {
+		l, _ := m.removed.OriginalObject.(low.Hashable)
+		r, _ := m.added.NewObject.(low.Hashable)
+		if low.AreEqual(l, r) {
+			continue
+		}
+		if pic := ComparePathItems(m.removed.OriginalObject, m.added.NewObject); pic != nil {
+			if PathTemplatesEquivalent(m.removed.Original, m.added.New) {
+				relaxPathParameterRenames(pic.GetAllChanges(),
+					extractPathTemplateParams(m.removed.Original), extractPathTemplateParams(m.added.New))
+			}
+			if pic.TotalChanges() > 0 {
+				pathChanges[m.added.New] = pic
+			}
+		}
+	}
+	return replaceRenames(changes, matches, Moved, func(m *renameMatch) bool {
+		return !PathTemplatesEquivalent(m.removed.Original, m.added.New)
+	})
+}
+
+// relaxPathParameterRenames marks the removal and addition of path parameters that were renamed alongside their
+// path template as non-breaking, the URL used by a client remains exactly the same.
+func relaxPathParameterRenames(changes []*Change, originalParams, newParams []string) {
+	renamedFrom := make(map[string]bool)
+	renamedTo := make(map[string]bool)
+	for i := range originalParams {
+		if i < len(newParams) && originalParams[i] != newParams[i] {
+			renamedFrom[originalParams[i]] = true
+			renamedTo[newParams[i]] = true
+		}
+	}
+	for _, c := range changes {
+		if c.Property != v3.ParametersLabel {
+			continue
+		}
+		if (c.ChangeType == ObjectRemoved && renamedFrom[c.Original]) ||
+			(c.ChangeType == ObjectAdded && renamedTo[c.New]) {
+			c.Breaking = false
+		}
+	}
+}
+
+// checkSchemaRenames looks for schemas that were removed and then added under a different name with identical or
+// near-identical content, and replaces each removal / addition pair with a single Renamed change. Renaming a schema
+// does not change the contract, so it's not a breaking change, however any differences found between the two schemas
+// are added to schemaChanges under the new name.
+func checkSchemaRenames(changes []*Change, label string,
+	schemaChanges map[string]*SchemaChanges) ([]*Change, map[string]*SchemaChanges) {
+
+	matches := findRenames(changes, label, func(obj any) *yaml.Node {
+		if sp, ok := obj.(*base.SchemaProxy); ok {
+			return sp.GetValueNode()
+		}
+		return nil
+	}, nil)
+
+	for _, m := range matches {
+		l, _ := m.removed.OriginalObject.(*base.SchemaProxy)
+		r, _ := m.added.NewObject.(*base.SchemaProxy)
+		if l == nil || r == nil || low.AreEqual(l, r) {
+			continue
+		}
+		if sc := CompareSchemas(l, r); sc != nil {
+			if schemaChanges == nil {
+				schemaChanges = make(map[string]*SchemaChanges)
+			}
+			schemaChanges[m.added.New] = sc
+		}
+	}
+	return replaceRenames(changes, matches, Renamed, func(m *renameMatch) bool {
+		return false
+	}), schemaChanges
+}
@@ -0,0 +1,343 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	v2 "github.com/pb33f/libopenapi/datamodel/low/v2"
+	"github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCompareComponents_OpenAPI_Schemas_Renamed(t *testing.T) {
+
+	left := `schemas:
+  Pet:
+    type: object
+    description: a furry friend
+    properties:
+      name:
+        type: string
+      age:
+        type: integer
+  tv:
+    description: mostly boring.`
+
+	right := `schemas:
+  Animal:
+    type: object
+    description: a furry friend
+    properties:
+      name:
+        type: string
+      age:
+        type: integer
+  tv:
+    description: mostly boring.`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v3.Components
+	var rDoc v3.Components
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(lNode.Content[0], nil)
+	_ = rDoc.Build(rNode.Content[0], nil)
+
+	// compare.
+	extChanges := CompareComponents(&lDoc, &rDoc)
+	assert.Equal(t, 1, extChanges.TotalChanges())
+	assert.Equal(t, 0, extChanges.TotalBreakingChanges())
+	assert.Equal(t, Renamed, extChanges.Changes[0].ChangeType)
+	assert.Equal(t, v3.SchemasLabel, extChanges.Changes[0].Property)
+	assert.Equal(t, "Pet", extChanges.Changes[0].Original)
+	assert.Equal(t, "Animal", extChanges.Changes[0].New)
+	assert.Equal(t, 3, *extChanges.Changes[0].Context.OriginalLine)
+	assert.Equal(t, 3, *extChanges.Changes[0].Context.NewLine)
+}
+
+func TestCompareComponents_OpenAPI_Schemas_Renamed_NearIdentical(t *testing.T) {
+
+	left := `schemas:
+  Pet:
+    type: object
+    description: a furry friend
+    required:
+      - name
+    properties:
+      name:
+        type: string
+      age:
+        type: integer
+      colour:
+        type: string
+      owner:
+        type: string`
+
+	right := `schemas:
+  Animal:
+    type: object
+    description: a furry friend
+    required:
+      - name
+    properties:
+      name:
+        type: string
+      age:
+        type: integer
+      colour:
+        type: string
+      owner:
+        type: string
+      weight:
+        type: number`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v3.Components
+	var rDoc v3.Components
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(lNode.Content[0], nil)
+	_ = rDoc.Build(rNode.Content[0], nil)
+
+	// compare.
+	extChanges := CompareComponents(&lDoc, &rDoc)
+	assert.Equal(t, 2, extChanges.TotalChanges())
+	assert.Equal(t, Renamed, extChanges.Changes[0].ChangeType)
+	assert.Equal(t, "Pet", extChanges.Changes[0].Original)
+	assert.Equal(t, "Animal", extChanges.Changes[0].New)
+	assert.NotNil(t, extChanges.SchemaChanges["Animal"])
+	assert.Equal(t, ObjectAdded, extChanges.SchemaChanges["Animal"].GetAllChanges()[0].ChangeType)
+}
+
+func TestCompareComponents_OpenAPI_Schemas_NotRenamed(t *testing.T) {
+
+	left := `schemas:
+  Pet:
+    type: object
+    description: a furry friend`
+
+	right := `schemas:
+  Burger:
+    type: string
+    description: a tasty treat`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v3.Components
+	var rDoc v3.Components
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(lNode.Content[0], nil)
+	_ = rDoc.Build(rNode.Content[0], nil)
+
+	// compare.
+	extChanges := CompareComponents(&lDoc, &rDoc)
+	assert.Equal(t, 2, extChanges.TotalChanges())
+	assert.Equal(t, 1, extChanges.TotalBreakingChanges())
+	for _, c := range extChanges.Changes {
+		assert.NotEqual(t, Renamed, c.ChangeType)
+	}
+}
+
+func TestCompareComponents_Swagger_Definitions_Renamed(t *testing.T) {
+
+	left := `thing1:
+ type: int
+ description: a thing`
+
+	right := `thingOne:
+ type: int
+ description: a thing`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v2.Definitions
+	var rDoc v2.Definitions
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(nil, lNode.Content[0], nil)
+	_ = rDoc.Build(nil, rNode.Content[0], nil)
+
+	// compare.
+	extChanges := CompareComponents(&lDoc, &rDoc)
+	assert.Equal(t, 1, extChanges.TotalChanges())
+	assert.Equal(t, 0, extChanges.TotalBreakingChanges())
+	assert.Equal(t, Renamed, extChanges.Changes[0].ChangeType)
+	assert.Equal(t, "thing1", extChanges.Changes[0].Original)
+	assert.Equal(t, "thingOne", extChanges.Changes[0].New)
+}
+
+func TestComparePaths_v3_Moved_TemplateParamRenamed(t *testing.T) {
+
+	left := `/pets/{id}:
+  get:
+    description: get a pet
+    parameters:
+      - name: id
+        in: path
+        required: true
+/crispy/chips:
+  head:
+    description: a thang?`
+
+	right := `/pets/{petId}:
+  get:
+    description: get a pet
+    parameters:
+      - name: petId
+        in: path
+        required: true
+/crispy/chips:
+  head:
+    description: a thang?`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v3.Paths
+	var rDoc v3.Paths
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(nil, lNode.Content[0], nil)
+	_ = rDoc.Build(nil, rNode.Content[0], nil)
+
+	// compare.
+	extChanges := ComparePaths(&lDoc, &rDoc)
+	assert.Equal(t, 3, extChanges.TotalChanges())
+	assert.Equal(t, 0, extChanges.TotalBreakingChanges())
+	assert.Len(t, extChanges.Changes, 1)
+	assert.Equal(t, Moved, extChanges.Changes[0].ChangeType)
+	assert.Equal(t, "/pets/{id}", extChanges.Changes[0].Original)
+	assert.Equal(t, "/pets/{petId}", extChanges.Changes[0].New)
+	assert.NotNil(t, extChanges.PathItemsChanges["/pets/{petId}"])
+}
+
+func TestComparePaths_v3_Moved_Breaking(t *testing.T) {
+
+	left := `/pets:
+  get:
+    description: list all the pets
+    operationId: listPets
+/crispy/chips:
+  head:
+    description: a thang?`
+
+	right := `/animals:
+  get:
+    description: list all the pets
+    operationId: listPets
+/crispy/chips:
+  head:
+    description: a thang?`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v3.Paths
+	var rDoc v3.Paths
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(nil, lNode.Content[0], nil)
+	_ = rDoc.Build(nil, rNode.Content[0], nil)
+
+	// compare.
+	extChanges := ComparePaths(&lDoc, &rDoc)
+	assert.Equal(t, 1, extChanges.TotalChanges())
+	assert.Equal(t, 1, extChanges.TotalBreakingChanges())
+	assert.Equal(t, Moved, extChanges.Changes[0].ChangeType)
+	assert.Equal(t, "/pets", extChanges.Changes[0].Original)
+	assert.Equal(t, "/animals", extChanges.Changes[0].New)
+	assert.Nil(t, extChanges.PathItemsChanges)
+}
+
+func TestComparePaths_v2_Moved_TemplateParamRenamed(t *testing.T) {
+
+	left := `/pets/{id}:
+  get:
+    description: get a pet
+    parameters:
+      - name: id
+        in: path
+        type: string
+        required: true`
+
+	right := `/pets/{petId}:
+  get:
+    description: get a pet
+    parameters:
+      - name: petId
+        in: path
+        type: string
+        required: true`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	// create low level objects
+	var lDoc v2.Paths
+	var rDoc v2.Paths
+	_ = low.BuildModel(lNode.Content[0], &lDoc)
+	_ = low.BuildModel(rNode.Content[0], &rDoc)
+	_ = lDoc.Build(nil, lNode.Content[0], nil)
+	_ = rDoc.Build(nil, rNode.Content[0], nil)
+
+	// compare.
+	extChanges := ComparePaths(&lDoc, &rDoc)
+	assert.Equal(t, 3, extChanges.TotalChanges())
+	assert.Equal(t, 0, extChanges.TotalBreakingChanges())
+	assert.Equal(t, Moved, extChanges.Changes[0].ChangeType)
+}
+
+func TestNodeSimilarity(t *testing.T) {
+
+	var a, b, c yaml.Node
+	_ = yaml.Unmarshal([]byte(`type: object
+properties:
+  name:
+    type: string
+  age:
+    type: integer`), &a)
+	_ = yaml.Unmarshal([]byte(`type: object
+properties:
+  name:
+    type: string
+  age:
+    type: number`), &b)
+	_ = yaml.Unmarshal([]byte(`description: nothing in common`), &c)
+
+	assert.Equal(t, 1.0, NodeSimilarity(&a, &a))
+	assert.Equal(t, 0.5, NodeSimilarity(&a, &b))
+	assert.Equal(t, 0.0, NodeSimilarity(&a, &c))
+	assert.Equal(t, 0.0, NodeSimilarity(&a, nil))
+}
+
+func TestPathTemplatesEquivalent(t *testing.T) {
+	assert.True(t, PathTemplatesEquivalent("/pets/{id}", "/pets/{petId}"))
+	assert.True(t, PathTemplatesEquivalent("/pets/{id}/toys/{toy}", "/pets/{petId}/toys/{toyId}"))
+	assert.False(t, PathTemplatesEquivalent("/pets/{id}", "/animals/{id}"))
+	assert.False(t, PathTemplatesEquivalent("/pets/{id}", "/pets/{id}/toys"))
+	assert.Equal(t, []string{"id", "toy"}, extractPathTemplateParams("/pets/{id}/toys/{toy}"))
+}
@@ -340,6 +340,7 @@ func CompareSchemas(l, r *base.SchemaProxy) *SchemaChanges {
 		if !l.IsSchemaReference() && r.IsSchemaReference() {
 			CreateChange(&changes, Modified, v3.RefLabel,
 				l.GetValueNode(), r.GetValueNode().Content[1], true, l, r.GetSchemaReference())
+			changes[0].equivalentReference = equivalentSchemaReferences(l, r)
 			sc.PropertyChanges = NewPropertyChanges(changes)
 			return sc // we're done here
 		}
@@ -348,6 +349,7 @@ func CompareSchemas(l, r *base.SchemaProxy) *SchemaChanges {
 		if l.IsSchemaReference() && !r.IsSchemaReference() {
 			CreateChange(&changes, Modified, v3.RefLabel,
 				l.GetValueNode().Content[1], r.GetValueNode(), true, l.GetSchemaReference(), r)
+			changes[0].equivalentReference = equivalentSchemaReferences(l, r)
 			sc.PropertyChanges = NewPropertyChanges(changes)
 			return sc // done, nothing else to do.
 		}
@@ -0,0 +1,894 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package what_changed
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/low/v2"
+	"github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/pb33f/libopenapi/patch"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultNormalizedVersion is the OpenAPI version used when normalizing a Swagger document, if no version is supplied.
+const DefaultNormalizedVersion = "3.0.3"
+
+// the properties of a Swagger parameter, header or items object that belong in an OpenAPI 3 schema.
+var swaggerSchemaProperties = []string{"type", "format", "items", "default", "maximum", "exclusiveMaximum",
+	"minimum", "exclusiveMinimum", "maxLength", "minLength", "pattern", "maxItems", "minItems", "uniqueItems",
+	"enum", "multipleOf"}
+
+// NormalizeSwaggerDocument will convert a Swagger (OpenAPI 2) document into an equivalent OpenAPI 3 document, so it
+// can be compared with an OpenAPI 3 document. The version is the OpenAPI version the normalized document will
+// declare, if empty, DefaultNormalizedVersion is used.
+//
+// The conversion is structural and loses nothing that has an equivalent in OpenAPI 3:
+//   - host, basePath and schemes become servers (https is assumed when no schemes are defined).
+//   - body parameters become a requestBody, with a media type for each consumes value.
+//   - formData parameters become a form requestBody (multipart/form-data when consumes asks for it, or a file
+//     is uploaded, otherwise application/x-www-form-urlencoded).
+//   - the type, format, items and validation properties of parameters and headers become a schema, and
+//     collectionFormat becomes style and explode. tsv has no equivalent, it is kept as an x-collectionFormat
+//     extension so a change to or from it is still reported.
+//   - response schemas and examples become media types, with a media type for each produces value.
+//   - definitions, parameters, responses and securityDefinitions become components and every reference is updated.
+//
+// Wherever possible, nodes are copied from the Swagger document, so line and column numbers of any changes
+// reported against the normalized document, point to the original Swagger document.
+func NormalizeSwaggerDocument(swagger *v2.Swagger, version string) (*v3.Document, []error) {
+	if swagger == nil || swagger.SpecInfo == nil || swagger.SpecInfo.RootNode == nil {
+		return nil, []error{errors.New("unable to normalize swagger document, the document has no root node")}
+	}
+	if version == "" {
+		version = DefaultNormalizedVersion
+	}
+	root := NormalizeSwaggerNode(swagger.SpecInfo.RootNode, version)
+	info := &datamodel.SpecInfo{
+		SpecType:            utils.OpenApi3,
+		Version:             version,
+		SpecFormat:          datamodel.OAS3,
+		SpecFileType:        swagger.SpecInfo.SpecFileType,
+		RootNode:            root,
+		OriginalIndentation: swagger.SpecInfo.OriginalIndentation,
+	}
+	return v3.CreateDocumentFromConfig(info, datamodel.NewClosedDocumentConfiguration())
+}
+
+// NormalizeSwaggerNode performs the same conversion as NormalizeSwaggerDocument, on the yaml.Node tree of a Swagger
+// document, returning the yaml.Node tree of the equivalent OpenAPI 3 document. The supplied tree is not modified.
+func NormalizeSwaggerNode(root *yaml.Node, version string) *yaml.Node {
+	doc := root
+	if doc != nil && doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	n := &swaggerNormalizer{
+		version:    version,
+		consumes:   stringValues(value(doc, "consumes")),
+		produces:   stringValues(value(doc, "produces")),
+		parameters: value(doc, v2.ParametersLabel),
+	}
+
+	out := mappingFrom(doc)
+	vKey, _ := entry(doc, utils.OpenApi2)
+	appendEntry(out, scalarFrom(vKey, v3.OpenAPILabel), scalarFrom(vKey, version))
+
+	components := mappingFrom(doc)
+	for i := 0; i < len(doc.Content)-1; i += 2 {
+		k, v := doc.Content[i], doc.Content[i+1]
+		switch k.Value {
+		case utils.OpenApi2, "host", "basePath", "schemes", "consumes", "produces":
+			// handled elsewhere.
+		case v3.InfoLabel:
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+			if servers := n.servers(doc, k); servers != nil {
+				appendEntry(out, scalarFrom(k, v3.ServersLabel), servers)
+			}
+		case v2.PathsLabel:
+			appendEntry(out, patch.CopyNode(k), n.paths(v))
+		case v2.DefinitionsLabel:
+			appendEntry(components, scalarFrom(k, v3.SchemasLabel), n.schemas(v))
+		case v2.ParametersLabel:
+			params, bodies := n.componentParameters(v)
+			if len(params.Content) > 0 {
+				appendEntry(components, scalarFrom(k, v3.ParametersLabel), params)
+			}
+			if len(bodies.Content) > 0 {
+				appendEntry(components, scalarFrom(k, v3.RequestBodiesLabel), bodies)
+			}
+		case v2.ResponsesLabel:
+			responses := mappingFrom(v)
+			for j := 0; j < len(v.Content)-1; j += 2 {
+				appendEntry(responses, patch.CopyNode(v.Content[j]), n.response(v.Content[j+1], n.produces))
+			}
+			appendEntry(components, scalarFrom(k, v3.ResponsesLabel), responses)
+		case v2.SecurityDefinitionsLabel:
+			appendEntry(components, scalarFrom(k, v3.SecuritySchemesLabel), n.securitySchemes(v))
+		default:
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+	if len(components.Content) > 0 {
+		appendEntry(out, scalarFrom(doc, v3.ComponentsLabel), components)
+	}
+	rewriteReferences(out, n.bodyParameters)
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: root.Line, Column: root.Column, Content: []*yaml.Node{out}}
+}
+
+type swaggerNormalizer struct {
+	version        string // the OpenAPI version of the normalized document.
+	consumes       []string
+	produces       []string
+	parameters     *yaml.Node      // global parameters.
+	bodyParameters map[string]bool // global parameters that are now request bodies.
+}
+
+func (n *swaggerNormalizer) servers(doc, src *yaml.Node) *yaml.Node {
+	host := value(doc, "host")
+	basePath := value(doc, "basePath")
+	if host == nil && basePath == nil {
+		return nil
+	}
+	schemes := stringValues(value(doc, "schemes"))
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	var url string
+	if host != nil {
+		url = host.Value
+		src = host
+	}
+	if basePath != nil && basePath.Value != "/" {
+		url += basePath.Value
+		if host == nil {
+			src = basePath
+		}
+	}
+	servers := sequenceFrom(src)
+	for _, scheme := range schemes {
+		server := mappingFrom(src)
+		u := url
+		if host != nil {
+			u = scheme + "://" + url
+		} else if u == "" {
+			u = "/"
+		}
+		appendEntry(server, scalarFrom(src, v3.URLLabel), scalarFrom(src, u))
+		servers.Content = append(servers.Content, server)
+		if host == nil {
+			break // relative servers have no scheme.
+		}
+	}
+	return servers
+}
+
+func (n *swaggerNormalizer) paths(paths *yaml.Node) *yaml.Node {
+	out := mappingFrom(paths)
+	for i := 0; i < len(paths.Content)-1; i += 2 {
+		k, pathItem := paths.Content[i], paths.Content[i+1]
+		if pathItem.Kind != yaml.MappingNode {
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(pathItem))
+			continue
+		}
+		shared := value(pathItem, v2.ParametersLabel)
+		item := mappingFrom(pathItem)
+		for j := 0; j < len(pathItem.Content)-1; j += 2 {
+			pk, pv := pathItem.Content[j], pathItem.Content[j+1]
+			switch pk.Value {
+			case v2.GetLabel, v2.PutLabel, v2.PostLabel, v2.DeleteLabel, v2.OptionsLabel, v2.HeadLabel, v2.PatchLabel:
+				appendEntry(item, patch.CopyNode(pk), n.operation(pv, shared))
+			case v2.ParametersLabel:
+				// body and form parameters belong to each operation in OpenAPI 3.
+				if params := n.parameterList(pv, func(p *yaml.Node) bool { return !isBodyOrForm(p) }); params != nil {
+					appendEntry(item, patch.CopyNode(pk), params)
+				}
+			default:
+				appendEntry(item, patch.CopyNode(pk), patch.CopyNode(pv))
+			}
+		}
+		appendEntry(out, patch.CopyNode(k), item)
+	}
+	return out
+}
+
+func (n *swaggerNormalizer) operation(op, shared *yaml.Node) *yaml.Node {
+	if op.Kind != yaml.MappingNode {
+		return patch.CopyNode(op)
+	}
+	consumes := n.consumes
+	if c := value(op, "consumes"); c != nil {
+		consumes = stringValues(c)
+	}
+	produces := n.produces
+	if p := value(op, "produces"); p != nil {
+		produces = stringValues(p)
+	}
+
+	// collect body and form parameters from the operation and the path item, operation parameters win.
+	var body *yaml.Node
+	var form []*yaml.Node
+	seen := make(map[string]bool)
+	opParams := value(op, v2.ParametersLabel)
+	for _, params := range []*yaml.Node{opParams, shared} {
+		if params == nil {
+			continue
+		}
+		for _, p := range params.Content {
+			resolved := n.resolveParameter(p)
+			in := value(resolved, "in")
+			if in == nil {
+				continue
+			}
+			id := in.Value
+			if name := value(resolved, "name"); name != nil {
+				id = name.Value + ":" + id
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			// an operation can have a body or form parameters, not both.
+			switch in.Value {
+			case "body":
+				if body == nil && form == nil {
+					body = p
+				}
+			case "formData":
+				if body == nil {
+					form = append(form, resolved)
+				}
+			}
+		}
+	}
+
+	out := mappingFrom(op)
+	for i := 0; i < len(op.Content)-1; i += 2 {
+		k, v := op.Content[i], op.Content[i+1]
+		switch k.Value {
+		case "consumes", "produces", "schemes":
+		case v2.ParametersLabel:
+			if params := n.parameterList(v, func(p *yaml.Node) bool { return !isBodyOrForm(p) }); params != nil {
+				appendEntry(out, patch.CopyNode(k), params)
+			}
+			if body != nil {
+				appendEntry(out, scalarFrom(body, v3.RequestBodyLabel), n.requestBody(body, consumes))
+				body = nil
+			}
+			if form != nil {
+				appendEntry(out, scalarFrom(form[0], v3.RequestBodyLabel), n.formRequestBody(form, consumes))
+				form = nil
+			}
+		case v2.ResponsesLabel:
+			responses := mappingFrom(v)
+			for j := 0; j < len(v.Content)-1; j += 2 {
+				appendEntry(responses, patch.CopyNode(v.Content[j]), n.response(v.Content[j+1], produces))
+			}
+			appendEntry(out, patch.CopyNode(k), responses)
+		default:
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+	// body and form parameters only defined by the path item.
+	if body != nil {
+		appendEntry(out, scalarFrom(body, v3.RequestBodyLabel), n.requestBody(body, consumes))
+	}
+	if form != nil {
+		appendEntry(out, scalarFrom(form[0], v3.RequestBodyLabel), n.formRequestBody(form, consumes))
+	}
+	return out
+}
+
+// resolveParameter returns the global parameter a parameter references, or the parameter itself.
+func (n *swaggerNormalizer) resolveParameter(p *yaml.Node) *yaml.Node {
+	if ref := value(p, "$ref"); ref != nil && n.parameters != nil &&
+		strings.HasPrefix(ref.Value, "#/parameters/") {
+		if resolved := value(n.parameters, patch.UnescapeToken(strings.TrimPrefix(ref.Value, "#/parameters/"))); resolved != nil {
+			return resolved
+		}
+	}
+	return p
+}
+
+func isBodyOrForm(p *yaml.Node) bool {
+	in := value(p, "in")
+	return in != nil && (in.Value == "body" || in.Value == "formData")
+}
+
+func (n *swaggerNormalizer) parameterList(params *yaml.Node, include func(p *yaml.Node) bool) *yaml.Node {
+	if params.Kind != yaml.SequenceNode {
+		return patch.CopyNode(params)
+	}
+	out := sequenceFrom(params)
+	for _, p := range params.Content {
+		if !include(n.resolveParameter(p)) {
+			continue
+		}
+		out.Content = append(out.Content, n.parameter(p))
+	}
+	if len(out.Content) == 0 {
+		return nil
+	}
+	return out
+}
+
+// parameter converts a non-body parameter, moving the type and validation properties into a schema.
+func (n *swaggerNormalizer) parameter(p *yaml.Node) *yaml.Node {
+	if p.Kind != yaml.MappingNode || value(p, "$ref") != nil {
+		return patch.CopyNode(p)
+	}
+	out := mappingFrom(p)
+	var schemaKey *yaml.Node
+	for i := 0; i < len(p.Content)-1; i += 2 {
+		k, v := p.Content[i], p.Content[i+1]
+		switch {
+		case isSchemaProperty(k.Value):
+			if schemaKey == nil {
+				schemaKey = k
+			}
+		case k.Value == "collectionFormat" && v.Value == "tsv":
+			// tab separated values have no OpenAPI 3 style, so the format is kept as an extension.
+			appendEntry(out, scalarFrom(k, "x-collectionFormat"), patch.CopyNode(v))
+		case k.Value == "collectionFormat":
+			style, explode := collectionStyle(v.Value, value(p, "in"))
+			if style != "" {
+				appendEntry(out, scalarFrom(k, v3.StyleLabel), scalarFrom(v, style))
+			}
+			if explode != "" {
+				appendEntry(out, scalarFrom(k, v3.ExplodeLabel), typedScalarFrom(v, explode, "!!bool"))
+			}
+		default:
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+	if schemaKey != nil {
+		appendEntry(out, scalarFrom(schemaKey, v3.SchemaLabel), schemaFromProperties(p))
+	}
+	// arrays in a query default to csv, which is not exploded in OpenAPI 3.
+	if in := value(p, "in"); in != nil && in.Value == "query" && value(p, "collectionFormat") == nil {
+		if t := value(p, "type"); t != nil && t.Value == "array" {
+			appendEntry(out, scalarFrom(t, v3.ExplodeLabel), typedScalarFrom(t, "false", "!!bool"))
+		}
+	}
+	return out
+}
+
+func isSchemaProperty(key string) bool {
+	for _, p := range swaggerSchemaProperties {
+		if p == key {
+			return true
+		}
+	}
+	return false
+}
+
+// collectionStyle returns the OpenAPI 3 style and explode values for a Swagger collectionFormat. Empty values
+// mean the OpenAPI 3 default is equivalent. tsv is not converted, see parameter.
+func collectionStyle(format string, in *yaml.Node) (string, string) {
+	query := in != nil && (in.Value == "query" || in.Value == "formData")
+	switch format {
+	case "csv":
+		if query {
+			return "", "false"
+		}
+	case "multi":
+		if query {
+			return "", ""
+		}
+		return "", "true"
+	case "ssv":
+		return "spaceDelimited", ""
+	case "pipes":
+		return "pipeDelimited", ""
+	}
+	return "", ""
+}
+
+// schemaFromProperties builds a schema from the type and validation properties of a parameter, header or items.
+func schemaFromProperties(p *yaml.Node) *yaml.Node {
+	schema := mappingFrom(p)
+	file := isFileType(value(p, "type"))
+	for i := 0; i < len(p.Content)-1; i += 2 {
+		k, v := p.Content[i], p.Content[i+1]
+		if !isSchemaProperty(k.Value) {
+			continue
+		}
+		switch k.Value {
+		case "items":
+			appendEntry(schema, patch.CopyNode(k), schemaFromProperties(v))
+		case "type":
+			if file {
+				appendEntry(schema, patch.CopyNode(k), scalarFrom(v, "string"))
+				appendEntry(schema, scalarFrom(k, "format"), scalarFrom(v, "binary"))
+				continue
+			}
+			appendEntry(schema, patch.CopyNode(k), patch.CopyNode(v))
+		case "format":
+			// files are always binary strings.
+			if !file {
+				appendEntry(schema, patch.CopyNode(k), patch.CopyNode(v))
+			}
+		default:
+			appendEntry(schema, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+	return schema
+}
+
+// schema converts a Swagger schema, file types become binary strings, discriminators become objects and
+// x-nullable becomes nullable (or a null type, when normalizing to OpenAPI 3.1).
+func (n *swaggerNormalizer) schema(s *yaml.Node) *yaml.Node {
+	out := patch.CopyNode(s)
+	n.convertSchemaNode(out)
+	return out
+}
+
+func (n *swaggerNormalizer) convertSchemaNode(s *yaml.Node) {
+	if s == nil {
+		return
+	}
+	switch s.Kind {
+	case yaml.SequenceNode:
+		for _, c := range s.Content {
+			n.convertSchemaNode(c)
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(s.Content)-1; i += 2 {
+			k, v := s.Content[i], s.Content[i+1]
+			switch k.Value {
+			case "type":
+				if isFileType(v) {
+					v.Value = "string"
+					if format := value(s, "format"); format != nil {
+						format.Value = "binary"
+					} else {
+						appendEntry(s, scalarFrom(k, "format"), scalarFrom(v, "binary"))
+					}
+				}
+			case "discriminator":
+				if v.Kind == yaml.ScalarNode {
+					d := mappingFrom(v)
+					appendEntry(d, scalarFrom(v, "propertyName"), patch.CopyNode(v))
+					s.Content[i+1] = d
+				}
+			case "x-nullable":
+				if !n.jsonSchemaTypes() {
+					k.Value = "nullable"
+				}
+			case "properties", "patternProperties", "definitions":
+				for j := 1; j < len(v.Content); j += 2 {
+					n.convertSchemaNode(v.Content[j])
+				}
+			case "example", "default", "enum", "x-example":
+				// values, not schemas.
+			default:
+				if !strings.HasPrefix(k.Value, "x-") {
+					n.convertSchemaNode(v)
+				}
+			}
+		}
+		if n.jsonSchemaTypes() {
+			nullType(s)
+		}
+	}
+}
+
+// jsonSchemaTypes returns true if the normalized document is OpenAPI 3.1, where schemas have no nullable keyword
+// and null is a type.
+func (n *swaggerNormalizer) jsonSchemaTypes() bool {
+	return strings.HasPrefix(n.version, "3.1")
+}
+
+// nullType removes x-nullable from a schema, adding the null type to the schema if it is true. A schema without a
+// type allows null already.
+func nullType(s *yaml.Node) {
+	nullable := false
+	for i := 0; i < len(s.Content)-1; i += 2 {
+		if s.Content[i].Value == "x-nullable" {
+			nullable = s.Content[i+1].Value == "true"
+			s.Content = append(s.Content[:i], s.Content[i+2:]...)
+			break
+		}
+	}
+	if !nullable {
+		return
+	}
+	for i := 0; i < len(s.Content)-1; i += 2 {
+		if s.Content[i].Value != "type" {
+			continue
+		}
+		t := s.Content[i+1]
+		switch t.Kind {
+		case yaml.ScalarNode:
+			types := sequenceFrom(t)
+			types.Content = []*yaml.Node{t, scalarFrom(t, "null")}
+			s.Content[i+1] = types
+		case yaml.SequenceNode:
+			for _, c := range t.Content {
+				if c.Value == "null" {
+					return
+				}
+			}
+			t.Content = append(t.Content, scalarFrom(t, "null"))
+		}
+		return
+	}
+}
+
+func isFileType(t *yaml.Node) bool {
+	return t != nil && t.Kind == yaml.ScalarNode && t.Value == "file"
+}
+
+func (n *swaggerNormalizer) schemas(definitions *yaml.Node) *yaml.Node {
+	out := mappingFrom(definitions)
+	for i := 0; i < len(definitions.Content)-1; i += 2 {
+		appendEntry(out, patch.CopyNode(definitions.Content[i]), n.schema(definitions.Content[i+1]))
+	}
+	return out
+}
+
+func (n *swaggerNormalizer) requestBody(p *yaml.Node, consumes []string) *yaml.Node {
+	p = n.resolveOrReference(p)
+	if ref := value(p, "$ref"); ref != nil {
+		out := mappingFrom(p)
+		appendEntry(out, patch.CopyNode(keyOf(p, "$ref")), scalarFrom(ref, ref.Value))
+		return out
+	}
+	out := mappingFrom(p)
+	for i := 0; i < len(p.Content)-1; i += 2 {
+		k, v := p.Content[i], p.Content[i+1]
+		switch {
+		case k.Value == v3.DescriptionLabel, k.Value == v3.RequiredLabel, strings.HasPrefix(k.Value, "x-"):
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+		case k.Value == v3.SchemaLabel:
+			appendEntry(out, scalarFrom(k, v3.ContentLabel), n.content(k, v, nil, mediaTypes(consumes)))
+		}
+	}
+	return out
+}
+
+// resolveOrReference leaves references to global body parameters in place (they become request bodies).
+func (n *swaggerNormalizer) resolveOrReference(p *yaml.Node) *yaml.Node {
+	if value(p, "$ref") != nil {
+		return p
+	}
+	return n.resolveParameter(p)
+}
+
+func (n *swaggerNormalizer) formRequestBody(params []*yaml.Node, consumes []string) *yaml.Node {
+	first := params[0]
+	schema := mappingFrom(first)
+	appendEntry(schema, scalarFrom(first, v3.TypeLabel), scalarFrom(first, "object"))
+	properties := mappingFrom(first)
+	required := sequenceFrom(first)
+	hasFile := false
+	for _, p := range params {
+		name := value(p, "name")
+		if name == nil {
+			// a form parameter without a name cannot be a property.
+			continue
+		}
+		prop := schemaFromProperties(p)
+		if d := value(p, v3.DescriptionLabel); d != nil {
+			appendEntry(prop, patch.CopyNode(keyOf(p, v3.DescriptionLabel)), patch.CopyNode(d))
+		}
+		if t := value(p, "type"); t != nil && t.Value == "file" {
+			hasFile = true
+		}
+		appendEntry(properties, scalarFrom(name, name.Value), prop)
+		if r := value(p, v3.RequiredLabel); r != nil && r.Value == "true" {
+			required.Content = append(required.Content, scalarFrom(name, name.Value))
+		}
+	}
+	appendEntry(schema, scalarFrom(first, v3.PropertiesLabel), properties)
+	if len(required.Content) > 0 {
+		appendEntry(schema, scalarFrom(first, v3.RequiredLabel), required)
+	}
+
+	var types []string
+	for _, c := range consumes {
+		if c == "multipart/form-data" || c == "application/x-www-form-urlencoded" {
+			types = append(types, c)
+		}
+	}
+	if len(types) == 0 {
+		if hasFile {
+			types = []string{"multipart/form-data"}
+		} else {
+			types = []string{"application/x-www-form-urlencoded"}
+		}
+	}
+
+	out := mappingFrom(first)
+	content := mappingFrom(first)
+	for _, t := range types {
+		mt := mappingFrom(first)
+		appendEntry(mt, scalarFrom(first, v3.SchemaLabel), patch.CopyNode(schema))
+		appendEntry(content, scalarFrom(first, t), mt)
+	}
+	appendEntry(out, scalarFrom(first, v3.ContentLabel), content)
+	if len(required.Content) > 0 {
+		appendEntry(out, scalarFrom(first, v3.RequiredLabel), typedScalarFrom(first, "true", "!!bool"))
+	}
+	return out
+}
+
+// content builds media types from a schema and Swagger examples (which are keyed by media type).
+func (n *swaggerNormalizer) content(src, schema, examples *yaml.Node, types []string) *yaml.Node {
+	out := mappingFrom(src)
+	added := make(map[string]*yaml.Node)
+	if schema != nil {
+		for _, t := range types {
+			mt := mappingFrom(schema)
+			appendEntry(mt, scalarFrom(src, v3.SchemaLabel), n.schema(schema))
+			appendEntry(out, scalarFrom(src, t), mt)
+			added[t] = mt
+		}
+	}
+	if examples != nil {
+		for i := 0; i < len(examples.Content)-1; i += 2 {
+			k, v := examples.Content[i], examples.Content[i+1]
+			mt := added[k.Value]
+			if mt == nil {
+				mt = mappingFrom(v)
+				if schema != nil {
+					appendEntry(mt, scalarFrom(src, v3.SchemaLabel), n.schema(schema))
+				}
+				appendEntry(out, patch.CopyNode(k), mt)
+				added[k.Value] = mt
+			}
+			appendEntry(mt, scalarFrom(k, v3.ExampleLabel), patch.CopyNode(v))
+		}
+	}
+	return out
+}
+
+func (n *swaggerNormalizer) response(r *yaml.Node, produces []string) *yaml.Node {
+	if r.Kind != yaml.MappingNode || value(r, "$ref") != nil {
+		return patch.CopyNode(r)
+	}
+	out := mappingFrom(r)
+	schemaKey, schema := entry(r, v3.SchemaLabel)
+	examplesKey, examples := entry(r, v2.ExamplesLabel)
+	contentDone := false
+	for i := 0; i < len(r.Content)-1; i += 2 {
+		k, v := r.Content[i], r.Content[i+1]
+		switch k.Value {
+		case v3.SchemaLabel, v2.ExamplesLabel:
+			if !contentDone {
+				src := schemaKey
+				if src == nil {
+					src = examplesKey
+				}
+				appendEntry(out, scalarFrom(src, v3.ContentLabel), n.content(src, schema, examples, mediaTypes(produces)))
+				contentDone = true
+			}
+		case v2.HeadersLabel:
+			headers := mappingFrom(v)
+			for j := 0; j < len(v.Content)-1; j += 2 {
+				appendEntry(headers, patch.CopyNode(v.Content[j]), n.header(v.Content[j+1]))
+			}
+			appendEntry(out, patch.CopyNode(k), headers)
+		default:
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+	return out
+}
+
+func (n *swaggerNormalizer) header(h *yaml.Node) *yaml.Node {
+	if h.Kind != yaml.MappingNode {
+		return patch.CopyNode(h)
+	}
+	out := mappingFrom(h)
+	var schemaKey *yaml.Node
+	for i := 0; i < len(h.Content)-1; i += 2 {
+		k, v := h.Content[i], h.Content[i+1]
+		switch {
+		case isSchemaProperty(k.Value):
+			if schemaKey == nil {
+				schemaKey = k
+			}
+		case k.Value == "collectionFormat":
+			// headers can only use the simple style, which is the same as csv.
+		default:
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+	if schemaKey != nil {
+		appendEntry(out, scalarFrom(schemaKey, v3.SchemaLabel), schemaFromProperties(h))
+	}
+	return out
+}
+
+// componentParameters splits global parameters into parameters and request bodies. Form parameters have no
+// equivalent component, so they are left out (references to them are resolved inline).
+func (n *swaggerNormalizer) componentParameters(params *yaml.Node) (*yaml.Node, *yaml.Node) {
+	n.bodyParameters = make(map[string]bool)
+	out := mappingFrom(params)
+	bodies := mappingFrom(params)
+	for i := 0; i < len(params.Content)-1; i += 2 {
+		k, v := params.Content[i], params.Content[i+1]
+		in := value(v, "in")
+		switch {
+		case in != nil && in.Value == "body":
+			n.bodyParameters[k.Value] = true
+			appendEntry(bodies, patch.CopyNode(k), n.requestBody(v, n.consumes))
+		case in != nil && in.Value == "formData":
+		default:
+			appendEntry(out, patch.CopyNode(k), n.parameter(v))
+		}
+	}
+	return out, bodies
+}
+
+func (n *swaggerNormalizer) securitySchemes(defs *yaml.Node) *yaml.Node {
+	out := mappingFrom(defs)
+	for i := 0; i < len(defs.Content)-1; i += 2 {
+		k, v := defs.Content[i], defs.Content[i+1]
+		t := value(v, v3.TypeLabel)
+		if t == nil {
+			appendEntry(out, patch.CopyNode(k), patch.CopyNode(v))
+			continue
+		}
+		scheme := mappingFrom(v)
+		switch t.Value {
+		case "basic":
+			appendEntry(scheme, patch.CopyNode(keyOf(v, v3.TypeLabel)), scalarFrom(t, "http"))
+			appendEntry(scheme, scalarFrom(t, v3.SchemeLabel), scalarFrom(t, "basic"))
+			copyEntries(scheme, v, v3.DescriptionLabel)
+		case "oauth2":
+			appendEntry(scheme, patch.CopyNode(keyOf(v, v3.TypeLabel)), patch.CopyNode(t))
+			copyEntries(scheme, v, v3.DescriptionLabel)
+			flows := mappingFrom(v)
+			flow := mappingFrom(v)
+			copyEntries(flow, v, v3.AuthorizationUrlLabel, v3.TokenUrlLabel, v3.ScopesLabel)
+			name := "implicit"
+			if f := value(v, "flow"); f != nil {
+				switch f.Value {
+				case "application":
+					name = "clientCredentials"
+				case "accessCode":
+					name = "authorizationCode"
+				default:
+					name = f.Value
+				}
+				appendEntry(flows, scalarFrom(f, name), flow)
+			} else {
+				appendEntry(flows, scalarFrom(t, name), flow)
+			}
+			appendEntry(scheme, scalarFrom(t, v3.FlowsLabel), flows)
+		default:
+			appendEntry(scheme, patch.CopyNode(keyOf(v, v3.TypeLabel)), patch.CopyNode(t))
+			copyEntries(scheme, v, v3.DescriptionLabel, v3.NameLabel, v3.InLabel)
+		}
+		for j := 0; j < len(v.Content)-1; j += 2 {
+			if strings.HasPrefix(v.Content[j].Value, "x-") {
+				appendEntry(scheme, patch.CopyNode(v.Content[j]), patch.CopyNode(v.Content[j+1]))
+			}
+		}
+		appendEntry(out, patch.CopyNode(k), scheme)
+	}
+	return out
+}
+
+// rewriteReferences updates every reference to a Swagger definition, parameter or response, to the equivalent
+// OpenAPI 3 component.
+func rewriteReferences(n *yaml.Node, bodyParameters map[string]bool) {
+	if n == nil {
+		return
+	}
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			if n.Content[i].Value != "$ref" || n.Content[i+1].Kind != yaml.ScalarNode {
+				continue
+			}
+			ref := n.Content[i+1]
+			switch {
+			case strings.HasPrefix(ref.Value, "#/definitions/"):
+				ref.Value = "#/components/schemas/" + strings.TrimPrefix(ref.Value, "#/definitions/")
+			case strings.HasPrefix(ref.Value, "#/parameters/"):
+				name := strings.TrimPrefix(ref.Value, "#/parameters/")
+				if bodyParameters[patch.UnescapeToken(name)] {
+					ref.Value = "#/components/requestBodies/" + name
+				} else {
+					ref.Value = "#/components/parameters/" + name
+				}
+			case strings.HasPrefix(ref.Value, "#/responses/"):
+				ref.Value = "#/components/responses/" + strings.TrimPrefix(ref.Value, "#/responses/")
+			}
+		}
+	}
+	for _, c := range n.Content {
+		rewriteReferences(c, bodyParameters)
+	}
+}
+
+func mediaTypes(types []string) []string {
+	if len(types) == 0 {
+		return []string{"application/json"}
+	}
+	return types
+}
+
+func stringValues(n *yaml.Node) []string {
+	if n == nil {
+		return nil
+	}
+	var s []string
+	for _, v := range n.Content {
+		s = append(s, v.Value)
+	}
+	return s
+}
+
+func entry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func value(n *yaml.Node, key string) *yaml.Node {
+	_, v := entry(n, key)
+	return v
+}
+
+func keyOf(n *yaml.Node, key string) *yaml.Node {
+	k, _ := entry(n, key)
+	return k
+}
+
+func copyEntries(dst, src *yaml.Node, keys ...string) {
+	for _, key := range keys {
+		if k, v := entry(src, key); k != nil {
+			appendEntry(dst, patch.CopyNode(k), patch.CopyNode(v))
+		}
+	}
+}
+
+func appendEntry(m, k, v *yaml.Node) {
+	m.Content = append(m.Content, k, v)
+}
+
+// mappingFrom creates a new mapping, positioned at the source node.
+func mappingFrom(src *yaml.Node) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	position(n, src)
+	return n
+}
+
+func sequenceFrom(src *yaml.Node) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	position(n, src)
+	return n
+}
+
+func scalarFrom(src *yaml.Node, value string) *yaml.Node {
+	return typedScalarFrom(src, value, "!!str")
+}
+
+func typedScalarFrom(src *yaml.Node, value, tag string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	position(n, src)
+	return n
+}
+
+func position(n, src *yaml.Node) {
+	if src != nil {
+		n.Line = src.Line
+		n.Column = src.Column
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package what_changed
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	v2 "github.com/pb33f/libopenapi/datamodel/low/v2"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func normalize(t *testing.T, spec string) string {
+	var root yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(spec), &root))
+	b, err := yaml.Marshal(NormalizeSwaggerNode(&root, "3.0.3"))
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestNormalizeSwaggerNode_Servers(t *testing.T) {
+	assert.Equal(t, `openapi: 3.0.3
+info:
+    title: burgers
+servers:
+    - url: http://burgers.com/api
+    - url: https://burgers.com/api
+`, normalize(t, `swagger: "2.0"
+info:
+  title: burgers
+host: burgers.com
+basePath: /api
+schemes: [http, https]`))
+
+	assert.Equal(t, `openapi: 3.0.3
+info:
+    title: burgers
+servers:
+    - url: https://burgers.com
+`, normalize(t, `swagger: "2.0"
+info:
+  title: burgers
+host: burgers.com
+basePath: /`))
+
+	assert.Equal(t, `openapi: 3.0.3
+info:
+    title: burgers
+servers:
+    - url: /api
+`, normalize(t, `swagger: "2.0"
+info:
+  title: burgers
+basePath: /api`))
+}
+
+func TestNormalizeSwaggerNode_CollectionFormats(t *testing.T) {
+	assert.Equal(t, `openapi: 3.0.3
+paths:
+    /burgers:
+        get:
+            parameters:
+                - name: a
+                  in: query
+                  schema:
+                    type: array
+                    items:
+                        type: string
+                  explode: false
+                - name: b
+                  in: query
+                  explode: false
+                  schema:
+                    type: array
+                - name: c
+                  in: query
+                  style: pipeDelimited
+                  schema:
+                    type: array
+                - name: d
+                  in: path
+                  explode: true
+                  schema:
+                    type: array
+                - name: e
+                  in: query
+                  x-collectionFormat: tsv
+                  schema:
+                    type: array
+            responses:
+                "200":
+                    description: ok
+                    headers:
+                        X-Things:
+                            schema:
+                                type: array
+`, normalize(t, `swagger: "2.0"
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: a
+          in: query
+          type: array
+          items:
+            type: string
+        - name: b
+          in: query
+          collectionFormat: csv
+          type: array
+        - name: c
+          in: query
+          collectionFormat: pipes
+          type: array
+        - name: d
+          in: path
+          collectionFormat: multi
+          type: array
+        - name: e
+          in: query
+          collectionFormat: tsv
+          type: array
+      responses:
+        "200":
+          description: ok
+          headers:
+            X-Things:
+              type: array
+              collectionFormat: csv`))
+}
+
+func TestNormalizeSwaggerNode_Bodies(t *testing.T) {
+	assert.Equal(t, `openapi: 3.0.3
+paths:
+    /burgers:
+        post:
+            parameters:
+                - $ref: '#/components/parameters/Query'
+            requestBody:
+                content:
+                    application/x-www-form-urlencoded:
+                        schema:
+                            type: object
+                            properties:
+                                name:
+                                    type: string
+                                    description: burger name
+            responses:
+                "200":
+                    description: ok
+                    content:
+                        application/xml:
+                            schema:
+                                type: string
+                        application/json:
+                            schema:
+                                type: string
+                            example: burger
+        put:
+            requestBody:
+                $ref: '#/components/requestBodies/Burger'
+components:
+    parameters:
+        Query:
+            name: q
+            in: query
+            schema:
+                type: string
+    requestBodies:
+        Burger:
+            content:
+                application/json:
+                    schema:
+                        type: string
+`, normalize(t, `swagger: "2.0"
+produces: [application/xml]
+paths:
+  /burgers:
+    parameters:
+      - $ref: '#/parameters/Burger'
+    post:
+      parameters:
+        - $ref: '#/parameters/Query'
+        - $ref: '#/parameters/Name'
+      responses:
+        "200":
+          description: ok
+          schema:
+            type: string
+          examples:
+            application/json: burger
+    put: {}
+parameters:
+  Query:
+    name: q
+    in: query
+    type: string
+  Name:
+    name: name
+    in: formData
+    type: string
+    description: burger name
+  Burger:
+    name: burger
+    in: body
+    schema:
+      type: string`))
+}
+
+func TestNormalizeSwaggerNode_SecuritySchemes(t *testing.T) {
+	assert.Equal(t, `openapi: 3.0.3
+components:
+    securitySchemes:
+        basic:
+            type: http
+            scheme: basic
+        key:
+            type: apiKey
+            description: a key
+            name: X-Key
+            in: header
+            x-thing: true
+        implicit:
+            type: oauth2
+            flows:
+                implicit:
+                    authorizationUrl: https://auth.com
+                    scopes: {}
+        app:
+            type: oauth2
+            flows:
+                clientCredentials:
+                    tokenUrl: https://auth.com/token
+                    scopes: {}
+        nothing: {}
+`, normalize(t, `swagger: "2.0"
+securityDefinitions:
+  basic:
+    type: basic
+  key:
+    type: apiKey
+    description: a key
+    name: X-Key
+    in: header
+    x-thing: true
+  implicit:
+    type: oauth2
+    authorizationUrl: https://auth.com
+    scopes: {}
+  app:
+    type: oauth2
+    flow: application
+    tokenUrl: https://auth.com/token
+    scopes: {}
+  nothing: {}`))
+}
+
+func TestNormalizeSwaggerNode_Schemas(t *testing.T) {
+	spec := `swagger: "2.0"
+paths:
+  /burgers:
+    post:
+      consumes: [multipart/form-data]
+      parameters:
+        - name: photo
+          in: formData
+          type: file
+          format: byte
+definitions:
+  Burger:
+    type: object
+    properties:
+      name:
+        type: string
+        x-nullable: true
+      sauce:
+        type: [string, integer]
+        x-nullable: true
+      anything:
+        x-nullable: true
+      photo:
+        type: file
+        format: byte
+        x-nullable: false`
+
+	assert.Equal(t, `openapi: 3.0.3
+paths:
+    /burgers:
+        post:
+            requestBody:
+                content:
+                    multipart/form-data:
+                        schema:
+                            type: object
+                            properties:
+                                photo:
+                                    type: string
+                                    format: binary
+components:
+    schemas:
+        Burger:
+            type: object
+            properties:
+                name:
+                    type: string
+                    nullable: true
+                sauce:
+                    type: [string, integer]
+                    nullable: true
+                anything:
+                    nullable: true
+                photo:
+                    type: string
+                    format: binary
+                    nullable: false
+`, normalize(t, spec))
+
+	// OpenAPI 3.1 has no nullable keyword, null is a type.
+	var root yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(spec), &root))
+	b, err := yaml.Marshal(NormalizeSwaggerNode(&root, "3.1.0"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `components:
+    schemas:
+        Burger:
+            type: object
+            properties:
+                name:
+                    type:
+                        - string
+                        - "null"
+                sauce:
+                    type: [string, integer, "null"]
+                anything: {}
+                photo:
+                    type: string
+                    format: binary
+`)
+}
+
+func TestNormalizeSwaggerNode_Invalid(t *testing.T) {
+	assert.Nil(t, NormalizeSwaggerNode(nil, ""))
+	assert.Nil(t, NormalizeSwaggerNode(&yaml.Node{Kind: yaml.ScalarNode}, ""))
+}
+
+func TestNormalizeSwaggerDocument(t *testing.T) {
+	info, _ := datamodel.ExtractSpecInfo([]byte(`swagger: "2.0"
+info:
+  title: burgers
+definitions:
+  Burger:
+    type: file`))
+	swagger, _ := v2.CreateDocument(info)
+
+	doc, errs := NormalizeSwaggerDocument(swagger, "")
+	assert.Len(t, errs, 0)
+	assert.Equal(t, DefaultNormalizedVersion, doc.Version.Value)
+	assert.Equal(t, "burgers", doc.Info.Value.Title.Value)
+
+	burger := doc.Components.Value.FindSchema("Burger").Value.Schema()
+	assert.Equal(t, "string", burger.Type.Value.A)
+	assert.Equal(t, "binary", burger.Format.Value)
+
+	_, errs = NormalizeSwaggerDocument(&v2.Swagger{}, "")
+	assert.Len(t, errs, 1)
+}
+
+func TestNormalizeSwaggerNode_NamelessFormParameter(t *testing.T) {
+	assert.Equal(t, `openapi: 3.0.3
+paths:
+    /burgers:
+        post:
+            requestBody:
+                content:
+                    application/x-www-form-urlencoded:
+                        schema:
+                            type: object
+                            properties:
+                                name:
+                                    type: string
+`, normalize(t, `swagger: "2.0"
+paths:
+  /burgers:
+    post:
+      parameters:
+        - in: formData
+          type: string
+        - name: name
+          in: formData
+          type: string`))
+}
@@ -16,6 +16,8 @@
 package what_changed
 
 import (
+	"errors"
+
 	"github.com/pb33f/libopenapi/datamodel/low/v2"
 	"github.com/pb33f/libopenapi/datamodel/low/v3"
 	"github.com/pb33f/libopenapi/what-changed/model"
@@ -34,3 +36,49 @@ func CompareOpenAPIDocuments(original, updated *v3.Document) *model.DocumentChan
 func CompareSwaggerDocuments(original, updated *v2.Swagger) *model.DocumentChanges {
 	return model.CompareDocuments(original, updated)
 }
+
+// CompareOpenAPIDocumentsWithOptions is the same as CompareOpenAPIDocuments, except any changes the supplied
+// options have been configured to ignore (like cosmetic changes to descriptions or examples) are not reported.
+// If options is nil, every change is reported.
+func CompareOpenAPIDocumentsWithOptions(original, updated *v3.Document,
+	options *model.ComparisonOptions) *model.DocumentChanges {
+	return model.CompareDocumentsWithOptions(original, updated, options)
+}
+
+// CompareSwaggerDocumentsWithOptions is the same as CompareSwaggerDocuments, except any changes the supplied
+// options have been configured to ignore (like cosmetic changes to descriptions or examples) are not reported.
+// If options is nil, every change is reported.
+func CompareSwaggerDocumentsWithOptions(original, updated *v2.Swagger,
+	options *model.ComparisonOptions) *model.DocumentChanges {
+	return model.CompareDocumentsWithOptions(original, updated, options)
+}
+
+// CompareSwaggerToOpenAPIDocuments will compare a Swagger (original) document against an OpenAPI 3+ (updated)
+// document, which is useful to prove a contract has not changed when migrating from Swagger to OpenAPI 3.
+//
+// The Swagger document is normalized into an equivalent OpenAPI 3 document (see NormalizeSwaggerDocument) before
+// being compared, so differences in how each version expresses the same thing (body parameters vs request bodies,
+// definitions vs component schemas, produces / consumes vs media types) are not reported, only semantic differences.
+func CompareSwaggerToOpenAPIDocuments(original *v2.Swagger, updated *v3.Document) (*model.DocumentChanges, []error) {
+	if updated == nil {
+		return nil, []error{errors.New("unable to compare documents, the OpenAPI document is nil")}
+	}
+	normalized, errs := NormalizeSwaggerDocument(original, updated.Version.Value)
+	if normalized == nil {
+		return nil, errs
+	}
+	return model.CompareDocuments(normalized, updated), errs
+}
+
+// CompareOpenAPIToSwaggerDocuments is the same as CompareSwaggerToOpenAPIDocuments, except the original document
+// is an OpenAPI 3+ document and the updated document is a Swagger document.
+func CompareOpenAPIToSwaggerDocuments(original *v3.Document, updated *v2.Swagger) (*model.DocumentChanges, []error) {
+	if original == nil {
+		return nil, []error{errors.New("unable to compare documents, the OpenAPI document is nil")}
+	}
+	normalized, errs := NormalizeSwaggerDocument(updated, original.Version.Value)
+	if normalized == nil {
+		return nil, errs
+	}
+	return model.CompareDocuments(original, normalized), errs
+}
@@ -6,11 +6,13 @@ package what_changed
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/pb33f/libopenapi/datamodel"
 	v2 "github.com/pb33f/libopenapi/datamodel/low/v2"
 	v3 "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/pb33f/libopenapi/what-changed/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,6 +52,41 @@ func TestCompareSwaggerDocuments(t *testing.T) {
 
 }
 
+func TestCompareOpenAPIDocumentsWithOptions(t *testing.T) {
+
+	original, _ := os.ReadFile("../test_specs/burgershop.openapi.yaml")
+	modified, _ := os.ReadFile("../test_specs/burgershop.openapi-modified.yaml")
+	infoOrig, _ := datamodel.ExtractSpecInfo(original)
+	infoMod, _ := datamodel.ExtractSpecInfo(modified)
+
+	origDoc, _ := v3.CreateDocument(infoOrig)
+	modDoc, _ := v3.CreateDocument(infoMod)
+
+	all := CompareOpenAPIDocumentsWithOptions(origDoc, modDoc, nil)
+	assert.Equal(t, 75, all.TotalChanges())
+
+	opts, _ := model.IgnoreCosmeticChanges("")
+	changes := CompareOpenAPIDocumentsWithOptions(origDoc, modDoc, opts)
+	assert.Less(t, changes.TotalChanges(), all.TotalChanges())
+	assert.Equal(t, all.TotalBreakingChanges(), changes.TotalBreakingChanges())
+}
+
+func TestCompareSwaggerDocumentsWithOptions(t *testing.T) {
+
+	original, _ := os.ReadFile("../test_specs/petstorev2-complete.yaml")
+	modified, _ := os.ReadFile("../test_specs/petstorev2-complete-modified.yaml")
+	infoOrig, _ := datamodel.ExtractSpecInfo(original)
+	infoMod, _ := datamodel.ExtractSpecInfo(modified)
+
+	origDoc, _ := v2.CreateDocument(infoOrig)
+	modDoc, _ := v2.CreateDocument(infoMod)
+
+	opts, _ := model.IgnoreCosmeticChanges("")
+	changes := CompareSwaggerDocumentsWithOptions(origDoc, modDoc, opts)
+	assert.Less(t, changes.TotalChanges(), 52)
+	assert.Equal(t, 27, changes.TotalBreakingChanges())
+}
+
 func Benchmark_CompareOpenAPIDocuments(b *testing.B) {
 
 	original, _ := os.ReadFile("../test_specs/burgershop.openapi.yaml")
@@ -152,3 +189,67 @@ func ExampleCompareOpenAPIDocuments() {
 		changes.TotalChanges(), changes.TotalBreakingChanges(), len(schemaChanges))
 	//Output: There are 75 changes, of which 19 are breaking. 6 schemas have changes.
 }
+
+func TestCompareSwaggerToOpenAPIDocuments(t *testing.T) {
+
+	original, _ := os.ReadFile("../test_specs/burgershop-cross-version.swagger.yaml")
+	migrated, _ := os.ReadFile("../test_specs/burgershop-cross-version.openapi.yaml")
+	infoOrig, _ := datamodel.ExtractSpecInfo(original)
+	infoMig, _ := datamodel.ExtractSpecInfo(migrated)
+
+	origDoc, _ := v2.CreateDocument(infoOrig)
+	migDoc, _ := v3.CreateDocument(infoMig)
+
+	changes, errs := CompareSwaggerToOpenAPIDocuments(origDoc, migDoc)
+	assert.Len(t, errs, 0)
+	assert.Nil(t, changes)
+
+	changes, errs = CompareOpenAPIToSwaggerDocuments(migDoc, origDoc)
+	assert.Len(t, errs, 0)
+	assert.Nil(t, changes)
+}
+
+func TestCompareSwaggerToOpenAPIDocuments_Changes(t *testing.T) {
+
+	original, _ := os.ReadFile("../test_specs/burgershop-cross-version.swagger.yaml")
+	migrated, _ := os.ReadFile("../test_specs/burgershop-cross-version.openapi.yaml")
+	migrated = []byte(strings.Replace(string(migrated), "maximum: 100", "maximum: 50", 1))
+
+	infoOrig, _ := datamodel.ExtractSpecInfo(original)
+	infoMig, _ := datamodel.ExtractSpecInfo(migrated)
+	origDoc, _ := v2.CreateDocument(infoOrig)
+	migDoc, _ := v3.CreateDocument(infoMig)
+
+	changes, errs := CompareSwaggerToOpenAPIDocuments(origDoc, migDoc)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, changes.TotalChanges())
+	assert.Equal(t, 1, changes.TotalBreakingChanges())
+
+	change := changes.GetAllChanges()[0]
+	assert.Equal(t, "maximum", change.Property)
+	assert.Equal(t, "100", change.Original)
+	assert.Equal(t, "50", change.New)
+
+	// the original position is from the swagger document.
+	assert.Equal(t, 22, *change.Context.OriginalLine)
+	assert.Equal(t, 20, *change.Context.NewLine)
+}
+
+func TestCompareSwaggerToOpenAPIDocuments_Errors(t *testing.T) {
+
+	changes, errs := CompareSwaggerToOpenAPIDocuments(nil, nil)
+	assert.Nil(t, changes)
+	assert.Len(t, errs, 1)
+
+	changes, errs = CompareOpenAPIToSwaggerDocuments(nil, nil)
+	assert.Nil(t, changes)
+	assert.Len(t, errs, 1)
+
+	changes, errs = CompareSwaggerToOpenAPIDocuments(nil, &v3.Document{})
+	assert.Nil(t, changes)
+	assert.Len(t, errs, 1)
+
+	changes, errs = CompareOpenAPIToSwaggerDocuments(&v3.Document{}, &v2.Swagger{})
+	assert.Nil(t, changes)
+	assert.Len(t, errs, 1)
+}